@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"iyaem/internal/app/commands"
+	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/providers"
+)
+
+// runClientCommand handles the API client management and maintenance
+// subcommands:
+//
+//	app create-client <name> [scope...]
+//	app revoke-client <client_id>
+//	app seal-secrets
+//
+// It returns false when args is not a client subcommand.
+func runClientCommand(db *sql.DB, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	clientRepo := postgresql.NewApiClientRepository(db)
+
+	switch args[0] {
+	case "create-client":
+		if len(args) < 2 {
+			return true, fmt.Errorf("usage: create-client <name> [scope...]")
+		}
+
+		res, err := commands.NewCreateApiClientCommand(clientRepo).Execute(context.Background(), commands.CreateApiClientRequest{
+			Name:   args[1],
+			Scopes: args[2:],
+		})
+		if err != nil {
+			return true, err
+		}
+
+		fmt.Printf("client_id: %s\nclient_secret: %s\n", res.ClientId, res.ClientSecret)
+		return true, nil
+	case "revoke-client":
+		if len(args) != 2 {
+			return true, fmt.Errorf("usage: revoke-client <client_id>")
+		}
+
+		clientId, err := commands.NewRevokeApiClientCommand(clientRepo).Execute(context.Background(), commands.RevokeApiClientRequest{
+			ClientId: args[1],
+		})
+		if err != nil {
+			return true, err
+		}
+
+		fmt.Printf("revoked %s\n", clientId)
+		return true, nil
+	case "seal-secrets":
+		secrets, err := providers.NewSecretBoxFromEnv()
+		if err != nil {
+			return true, err
+		}
+
+		sealed, err := postgresql.SealSecrets(context.Background(), db, secrets)
+		if err != nil {
+			return true, err
+		}
+
+		fmt.Printf("sealed %d secrets\n", sealed)
+		return true, nil
+	}
+
+	return false, nil
+}
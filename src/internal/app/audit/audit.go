@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Actor identifies who performed a change. Changes made by a machine
+// client only carry a client id.
+type Actor struct {
+	UserId   string `json:"user_id,omitempty"`
+	Email    string `json:"email,omitempty"`
+	ClientId string `json:"client_id,omitempty"`
+}
+
+// Metadata describes the request that caused a change.
+type Metadata struct {
+	IpAddress string `json:"ip_address,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	RequestId string `json:"request_id,omitempty"`
+}
+
+// Entry is a single record of the audit log. Entries are never updated or
+// deleted once written.
+type Entry struct {
+	Id             string            `json:"id"`
+	OrganizationId string            `json:"organization_id,omitempty"`
+	TenantId       string            `json:"tenant_id,omitempty"`
+	Actor          Actor             `json:"actor"`
+	Action         string            `json:"action"`
+	TargetType     string            `json:"target_type"`
+	TargetId       string            `json:"target_id"`
+	Before         map[string]string `json:"before,omitempty"`
+	After          map[string]string `json:"after,omitempty"`
+	Metadata       Metadata          `json:"metadata"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// Scope is the organization and tenant an aggregate belongs to. Either can
+// be empty, for example for application-defined roles.
+type Scope struct {
+	OrganizationId string
+	TenantId       string
+}
+
+// Filter selects audit entries of an organization. Zero values match
+// everything.
+type Filter struct {
+	OrganizationId string
+	TenantId       string
+	ActorId        string
+	Action         string
+	TargetId       string
+	From           time.Time
+	To             time.Time
+	Limit          int
+	Offset         int
+}
+
+type Query interface {
+	Find(ctx context.Context, filter Filter) ([]Entry, error)
+	// Each calls fn for every matching entry in order, ignoring the limit
+	// and offset of the filter.
+	Each(ctx context.Context, filter Filter, fn func(Entry) error) error
+}
+
+type contextKey struct{}
+
+type requestContext struct {
+	actor    Actor
+	metadata Metadata
+}
+
+// WithActor attaches the actor and request metadata to the context so
+// repositories can record them next to the changes they persist.
+func WithActor(ctx context.Context, actor Actor, metadata Metadata) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestContext{actor, metadata})
+}
+
+// FromContext returns the actor and request metadata of the context.
+// Changes made outside a request, such as from the command line, have an
+// empty actor.
+func FromContext(ctx context.Context) (Actor, Metadata) {
+	rc, _ := ctx.Value(contextKey{}).(requestContext)
+	return rc.actor, rc.metadata
+}
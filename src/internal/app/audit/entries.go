@@ -0,0 +1,235 @@
+package audit
+
+import (
+	"context"
+	"iyaem/internal/domain/events"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// change is what an event tells about its target.
+type change struct {
+	targetType string
+	targetId   string
+	tenantId   string
+	before     map[string]string
+	after      map[string]string
+}
+
+// Entries builds the audit entries for the events of an aggregate, using
+// the actor and request metadata of the context.
+func Entries(ctx context.Context, scope Scope, aggregateType string, aggregateId string, evts []events.Event) []Entry {
+	actor, metadata := FromContext(ctx)
+	entries := make([]Entry, 0, len(evts))
+
+	for _, event := range evts {
+		c := describe(event)
+		if c.targetType == "" {
+			c.targetType, c.targetId = aggregateType, aggregateId
+		}
+		if c.tenantId == "" {
+			c.tenantId = scope.TenantId
+		}
+
+		entries = append(entries, Entry{
+			Id:             uuid.NewString(),
+			OrganizationId: scope.OrganizationId,
+			TenantId:       c.tenantId,
+			Actor:          actor,
+			Action:         event.Name(),
+			TargetType:     c.targetType,
+			TargetId:       c.targetId,
+			Before:         c.before,
+			After:          c.after,
+			Metadata:       metadata,
+			CreatedAt:      event.OccuredOn(),
+		})
+	}
+
+	return entries
+}
+
+func describe(event events.Event) change {
+	switch e := event.(type) {
+	case events.OrganizationCreated:
+		return change{targetType: "organization", targetId: e.OrganizationId,
+			after: map[string]string{"name": e.OrganizationName, "identifier": e.Identifier}}
+	case events.MemberAdded:
+		return change{targetType: "member", targetId: e.MembershipId,
+			after: map[string]string{"user_id": e.UserId, "level": e.Level}}
+	case events.MemberPromoted:
+		return change{targetType: "member", targetId: e.MembershipId,
+			before: map[string]string{"level": e.PreviousLevel}, after: map[string]string{"level": "manager"}}
+	case events.MemberDemoted:
+		return change{targetType: "member", targetId: e.MembershipId,
+			before: map[string]string{"level": e.PreviousLevel}, after: map[string]string{"level": "member"}}
+	case events.OwnerAdded:
+		return change{targetType: "member", targetId: e.MembershipId,
+			before: map[string]string{"level": e.PreviousLevel}, after: map[string]string{"level": "owner"}}
+	case events.OwnershipTransferRequested:
+		return change{targetType: "member", targetId: e.ToMembershipId,
+			after: map[string]string{"transfer_id": e.TransferId, "from_membership_id": e.FromMembershipId}}
+	case events.OwnershipTransferred:
+		return change{targetType: "member", targetId: e.ToMembershipId,
+			before: map[string]string{"level": e.PreviousLevel, "owner": e.FromMembershipId},
+			after:  map[string]string{"level": "owner", "transfer_id": e.TransferId}}
+	case events.MemberRemoved:
+		return change{targetType: "member", targetId: e.MembershipId,
+			before: map[string]string{"user_id": e.UserId, "level": e.Level}}
+	case events.RoleAddedToMember:
+		return change{targetType: "member", targetId: e.MembershipId, tenantId: e.TenantId,
+			after: map[string]string{"role_id": e.RoleId}}
+	case events.RoleRemovedFromMember:
+		return change{targetType: "member", targetId: e.MembershipId, tenantId: e.TenantId,
+			before: map[string]string{"role_id": e.RoleId}}
+	case events.GroupAddedToMember:
+		return change{targetType: "member", targetId: e.MembershipId, tenantId: e.TenantId,
+			after: map[string]string{"group_id": e.GroupId}}
+	case events.GroupRemovedFromMember:
+		return change{targetType: "member", targetId: e.MembershipId, tenantId: e.TenantId,
+			before: map[string]string{"group_id": e.GroupId}}
+	case events.InvitationCreated:
+		return change{targetType: "invitation", targetId: e.InvitationId,
+			after: map[string]string{"email": e.Email, "level": e.Level, "status": "pending"}}
+	case events.InvitationAccepted:
+		return change{targetType: "invitation", targetId: e.InvitationId,
+			before: map[string]string{"status": "pending"}, after: map[string]string{"status": "accepted", "membership_id": e.MembershipId}}
+	case events.InvitationDeclined:
+		return change{targetType: "invitation", targetId: e.InvitationId,
+			before: map[string]string{"status": "pending"}, after: map[string]string{"status": "declined"}}
+	case events.InvitationRevoked:
+		return change{targetType: "invitation", targetId: e.InvitationId,
+			before: map[string]string{"status": "pending"}, after: map[string]string{"status": "revoked"}}
+	case events.SsoConnectionCreated:
+		return change{targetType: "sso_connection", targetId: e.ConnectionId,
+			after: map[string]string{"protocol": e.Protocol, "issuer_url": e.IssuerUrl, "client_id": e.ClientId, "domains": e.Domains}}
+	case events.SsoConnectionUpdated:
+		return change{targetType: "sso_connection", targetId: e.ConnectionId,
+			before: map[string]string{"issuer_url": e.PreviousIssuerUrl, "client_id": e.PreviousClientId, "domains": e.PreviousDomains},
+			after:  map[string]string{"issuer_url": e.IssuerUrl, "client_id": e.ClientId, "domains": e.Domains}}
+	case events.SsoConnectionDeleted:
+		return change{targetType: "sso_connection", targetId: e.ConnectionId}
+	case events.SsoDomainVerified:
+		return change{targetType: "sso_connection", targetId: e.ConnectionId,
+			after: map[string]string{"verified_domain": e.Domain}}
+	case events.IdentityLinkRequested:
+		return change{targetType: "user", targetId: e.UserId,
+			after: map[string]string{"pending_identity": e.IdpId}}
+	case events.IdentityLinked:
+		return change{targetType: "user", targetId: e.UserId,
+			after: map[string]string{"identity": e.IdpId}}
+	case events.ScimTokenCreated:
+		return change{targetType: "scim_token", targetId: e.TokenId, tenantId: e.TenantId,
+			after: map[string]string{"description": e.Description}}
+	case events.ScimTokenRevoked:
+		return change{targetType: "scim_token", targetId: e.TokenId,
+			after: map[string]string{"revoked": "true"}}
+	case events.CredentialCreated:
+		return change{targetType: "credential", targetId: e.CredentialId,
+			after: map[string]string{"user_id": e.UserId, "email": e.Email}}
+	case events.CredentialLocked:
+		return change{targetType: "credential", targetId: e.CredentialId,
+			after: map[string]string{"locked": "true"}}
+	case events.PasswordChanged:
+		return change{targetType: "credential", targetId: e.CredentialId,
+			after: map[string]string{"reason": e.Reason, "reset_id": e.ResetId}}
+	case events.PasswordResetRequested:
+		return change{targetType: "credential", targetId: e.CredentialId,
+			after: map[string]string{"reset_id": e.ResetId}}
+	case events.SessionStarted:
+		return change{targetType: "session", targetId: e.SessionId,
+			after: map[string]string{"user_id": e.UserId, "user_agent": e.UserAgent, "ip_address": e.IpAddress}}
+	case events.SessionRevoked:
+		return change{targetType: "session", targetId: e.SessionId,
+			after: map[string]string{"revoked": "true", "reason": e.Reason}}
+	case events.SessionMfaVerified:
+		return change{targetType: "session", targetId: e.SessionId,
+			after: map[string]string{"mfa_verified": "true"}}
+	case events.MfaEnabled:
+		return change{targetType: "mfa_factor", targetId: e.FactorId,
+			after: map[string]string{"user_id": e.UserId, "enabled": "true"}}
+	case events.MfaDisabled:
+		return change{targetType: "mfa_factor", targetId: e.FactorId,
+			before: map[string]string{"user_id": e.UserId, "enabled": "true"}}
+	case events.RecoveryCodeUsed:
+		return change{targetType: "mfa_factor", targetId: e.FactorId,
+			after: map[string]string{"recovery_codes_remaining": strconv.Itoa(e.Remaining)}}
+	case events.RecoveryCodesRegenerated:
+		return change{targetType: "mfa_factor", targetId: e.FactorId,
+			after: map[string]string{"recovery_codes_regenerated": "true"}}
+	case events.OrganizationMfaRequirementChanged:
+		return change{targetType: "organization", targetId: e.OrganizationId,
+			after: map[string]string{"require_mfa": strconv.FormatBool(e.Required)}}
+	case events.PersonalAccessTokenCreated:
+		return change{targetType: "personal_access_token", targetId: e.TokenId,
+			after: map[string]string{"user_id": e.UserId, "name": e.TokenName, "scopes": strings.Join(e.Scopes, " "),
+				"organization_ids": strings.Join(e.OrganizationIds, " "), "expires_at": e.ExpiresAt.Format(time.RFC3339)}}
+	case events.PersonalAccessTokenRevoked:
+		return change{targetType: "personal_access_token", targetId: e.TokenId,
+			after: map[string]string{"user_id": e.UserId, "revoked": "true"}}
+	case events.MemberImportRequested:
+		return change{targetType: "member_import", targetId: e.ImportId,
+			after: map[string]string{"requested_by": e.RequestedBy, "dry_run": strconv.FormatBool(e.DryRun), "rows": strconv.Itoa(e.Rows)}}
+	case events.MemberImportCompleted:
+		return change{targetType: "member_import", targetId: e.ImportId,
+			after: map[string]string{"dry_run": strconv.FormatBool(e.DryRun), "created": strconv.Itoa(e.Created), "updated": strconv.Itoa(e.Updated),
+				"unchanged": strconv.Itoa(e.Unchanged), "failed": strconv.Itoa(e.Failed)}}
+	case events.MemberImportFailed:
+		return change{targetType: "member_import", targetId: e.ImportId,
+			after: map[string]string{"failure": e.Reason}}
+	case events.TenantAdded:
+		return change{targetType: "tenant", targetId: e.TenantId, tenantId: e.TenantId,
+			after: map[string]string{"application_id": e.ApplicationId}}
+	case events.ApplicationCreated:
+		return change{targetType: "application", targetId: e.ApplicationId,
+			after: map[string]string{"name": e.ApplicationName}}
+	case events.ApplicationRenamed:
+		return change{targetType: "application", targetId: e.ApplicationId,
+			before: map[string]string{"name": e.PreviousName}, after: map[string]string{"name": e.ApplicationName}}
+	case events.ApplicationDeleted:
+		return change{targetType: "application", targetId: e.ApplicationId}
+	case events.PermissionAdded:
+		return change{targetType: "permission", targetId: e.PermissionId,
+			after: map[string]string{"application_id": e.ApplicationId, "name": e.PermissionName}}
+	case events.PermissionRenamed:
+		return change{targetType: "permission", targetId: e.PermissionId,
+			before: map[string]string{"name": e.PreviousName}, after: map[string]string{"name": e.PermissionName}}
+	case events.PermissionRemoved:
+		return change{targetType: "permission", targetId: e.PermissionId}
+	case events.RoleCreated:
+		return change{targetType: "role", targetId: e.RoleId,
+			after: map[string]string{"application_id": e.ApplicationId, "name": e.RoleName}}
+	case events.RoleUpdated:
+		return change{targetType: "role", targetId: e.RoleId,
+			before: map[string]string{"name": e.PreviousName, "description": e.PreviousDescription},
+			after:  map[string]string{"name": e.RoleName, "description": e.Description}}
+	case events.RoleDeleted:
+		return change{targetType: "role", targetId: e.RoleId}
+	case events.PermissionAttachedToRole:
+		return change{targetType: "role", targetId: e.RoleId,
+			after: map[string]string{"permission_id": e.PermissionId}}
+	case events.PermissionDetachedFromRole:
+		return change{targetType: "role", targetId: e.RoleId,
+			before: map[string]string{"permission_id": e.PermissionId}}
+	case events.GroupCreated:
+		return change{targetType: "group", targetId: e.GroupId,
+			after: map[string]string{"application_id": e.ApplicationId, "name": e.GroupName}}
+	case events.GroupUpdated:
+		return change{targetType: "group", targetId: e.GroupId,
+			before: map[string]string{"name": e.PreviousName, "description": e.PreviousDescription},
+			after:  map[string]string{"name": e.GroupName, "description": e.Description}}
+	case events.GroupDeleted:
+		return change{targetType: "group", targetId: e.GroupId}
+	case events.RoleAttachedToGroup:
+		return change{targetType: "group", targetId: e.GroupId,
+			after: map[string]string{"role_id": e.RoleId}}
+	case events.RoleDetachedFromGroup:
+		return change{targetType: "group", targetId: e.GroupId,
+			before: map[string]string{"role_id": e.RoleId}}
+	}
+
+	return change{}
+}
@@ -0,0 +1,57 @@
+package authorization
+
+import "context"
+
+// Grant is a permission held by a membership in a tenant, together with
+// the role that grants it and, for inherited roles, the group the role
+// comes from.
+type Grant struct {
+	OrganizationId string
+	TenantId       string
+	PermissionId   string
+	PermissionName string
+	RoleId         string
+	RoleName       string
+	GroupId        string
+	GroupName      string
+}
+
+func (g Grant) Inherited() bool {
+	return g.GroupId != ""
+}
+
+type GrantQuery interface {
+	// IsMemberOfTenant reports whether the membership belongs to the
+	// organization that owns the tenant.
+	IsMemberOfTenant(ctx context.Context, membershipId string, tenantId string) (bool, error)
+	Grants(ctx context.Context, membershipId string, tenantId string) ([]Grant, error)
+	// UserGrants returns the grants of a user across all of their
+	// memberships. Roles without permissions are returned with an empty
+	// permission.
+	UserGrants(ctx context.Context, userId string) ([]Grant, error)
+}
+
+type CheckRequest struct {
+	MembershipId string `json:"user_org_id" binding:"required"`
+	TenantId     string `json:"tenant_id" binding:"required"`
+	Permission   string `json:"permission" binding:"required"`
+}
+
+// Reason is one link of the chain that led to a permission being granted.
+type Reason struct {
+	Permission string `json:"permission"`
+	Via        string `json:"via"`
+	RoleId     string `json:"role_id"`
+	RoleName   string `json:"role_name"`
+	GroupId    string `json:"group_id,omitempty"`
+	GroupName  string `json:"group_name,omitempty"`
+}
+
+type Decision struct {
+	MembershipId string   `json:"user_org_id"`
+	TenantId     string   `json:"tenant_id"`
+	Permission   string   `json:"permission"`
+	Allowed      bool     `json:"allowed"`
+	Message      string   `json:"message"`
+	Reasons      []Reason `json:"reasons"`
+}
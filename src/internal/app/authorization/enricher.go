@@ -0,0 +1,119 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+// TenantClaims are the effective roles and permissions of a user in one
+// tenant, as embedded in issued tokens.
+type TenantClaims struct {
+	OrganizationId string   `json:"org"`
+	Roles          []string `json:"roles"`
+	Permissions    []string `json:"permissions"`
+}
+
+type EnricherConfig struct {
+	// MaxBytes is the largest serialized size of the "authz" claim. Larger
+	// sets are replaced by a reference claim.
+	MaxBytes int
+	// ReferenceUrl is where clients fetch the full set when the token only
+	// carries the reference claim.
+	ReferenceUrl string
+}
+
+// TokenEnricher adds the effective roles and permissions of a user to the
+// claims of the tokens minted by AuthController.Callback, so tenant
+// applications do not need to call back to fetch them.
+type TokenEnricher struct {
+	grantQuery GrantQuery
+	config     EnricherConfig
+}
+
+func NewTokenEnricher(grantQuery GrantQuery, config EnricherConfig) *TokenEnricher {
+	return &TokenEnricher{
+		grantQuery: grantQuery,
+		config:     config,
+	}
+}
+
+// TenantClaims resolves direct and group-inherited roles of the user,
+// keyed by tenant id.
+func (e *TokenEnricher) TenantClaims(ctx context.Context, userId string) (map[string]TenantClaims, error) {
+	grants, err := e.grantQuery.UserGrants(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupByTenant(grants), nil
+}
+
+// Claims returns either an "authz" claim with the tenant claims, or an
+// "authz_ref" claim when they do not fit in MaxBytes.
+func (e *TokenEnricher) Claims(ctx context.Context, userId string) (map[string]interface{}, error) {
+	tenants, err := e.TenantClaims(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.config.MaxBytes > 0 && len(encoded) > e.config.MaxBytes {
+		return map[string]interface{}{
+			"authz_ref": e.config.ReferenceUrl,
+		}, nil
+	}
+
+	return map[string]interface{}{
+		"authz": json.RawMessage(encoded),
+	}, nil
+}
+
+func GroupByTenant(grants []Grant) map[string]TenantClaims {
+	type sets struct {
+		organizationId string
+		roles          map[string]bool
+		permissions    map[string]bool
+	}
+
+	byTenant := make(map[string]*sets)
+
+	for _, g := range grants {
+		s, ok := byTenant[g.TenantId]
+		if !ok {
+			s = &sets{g.OrganizationId, make(map[string]bool), make(map[string]bool)}
+			byTenant[g.TenantId] = s
+		}
+
+		s.roles[g.RoleName] = true
+		if g.PermissionName != "" {
+			s.permissions[g.PermissionName] = true
+		}
+	}
+
+	tenants := make(map[string]TenantClaims)
+	for tenantId, s := range byTenant {
+		tenants[tenantId] = TenantClaims{
+			OrganizationId: s.organizationId,
+			Roles:          sortedKeys(s.roles),
+			Permissions:    sortedKeys(s.permissions),
+		}
+	}
+
+	return tenants
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
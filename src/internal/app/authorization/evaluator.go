@@ -0,0 +1,147 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type Evaluator struct {
+	grantQuery GrantQuery
+}
+
+func NewEvaluator(grantQuery GrantQuery) *Evaluator {
+	return &Evaluator{
+		grantQuery: grantQuery,
+	}
+}
+
+func (e *Evaluator) Check(ctx context.Context, r CheckRequest) (Decision, error) {
+	decisions, err := e.CheckBatch(ctx, []CheckRequest{r})
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return decisions[0], nil
+}
+
+// CheckBatch evaluates every request, loading the grants of each
+// membership and tenant pair only once.
+func (e *Evaluator) CheckBatch(ctx context.Context, requests []CheckRequest) ([]Decision, error) {
+	type key struct{ membershipId, tenantId string }
+
+	cache := make(map[key][]Grant)
+	members := make(map[key]bool)
+
+	decisions := make([]Decision, 0, len(requests))
+
+	for _, r := range requests {
+		k := key{r.MembershipId, r.TenantId}
+
+		isMember, ok := members[k]
+		if !ok {
+			var err error
+			isMember, err = e.grantQuery.IsMemberOfTenant(ctx, r.MembershipId, r.TenantId)
+			if err != nil {
+				return nil, fmt.Errorf("check membership: %w", err)
+			}
+			members[k] = isMember
+		}
+
+		if !isMember {
+			decisions = append(decisions, deny(r, "membership does not belong to the tenant's organization"))
+			continue
+		}
+
+		grants, ok := cache[k]
+		if !ok {
+			var err error
+			grants, err = e.grantQuery.Grants(ctx, r.MembershipId, r.TenantId)
+			if err != nil {
+				return nil, fmt.Errorf("load grants: %w", err)
+			}
+			cache[k] = grants
+		}
+
+		decisions = append(decisions, Evaluate(r, grants))
+	}
+
+	return decisions, nil
+}
+
+// EffectivePermissions resolves direct and group-inherited roles into the
+// set of permission names held by a membership in a tenant.
+func (e *Evaluator) EffectivePermissions(ctx context.Context, membershipId string, tenantId string) ([]string, error) {
+	grants, err := e.grantQuery.Grants(ctx, membershipId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return PermissionNames(grants), nil
+}
+
+// Evaluate decides a request against an already resolved set of grants.
+func Evaluate(r CheckRequest, grants []Grant) Decision {
+	reasons := make([]Reason, 0)
+
+	for _, g := range grants {
+		if g.PermissionName != r.Permission {
+			continue
+		}
+
+		reason := Reason{
+			Permission: g.PermissionName,
+			Via:        "role",
+			RoleId:     g.RoleId,
+			RoleName:   g.RoleName,
+		}
+
+		if g.Inherited() {
+			reason.Via = "group"
+			reason.GroupId = g.GroupId
+			reason.GroupName = g.GroupName
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	if len(reasons) == 0 {
+		return deny(r, "no role grants this permission")
+	}
+
+	return Decision{
+		MembershipId: r.MembershipId,
+		TenantId:     r.TenantId,
+		Permission:   r.Permission,
+		Allowed:      true,
+		Message:      "granted",
+		Reasons:      reasons,
+	}
+}
+
+func PermissionNames(grants []Grant) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+
+	for _, g := range grants {
+		if g.PermissionName != "" && !seen[g.PermissionName] {
+			seen[g.PermissionName] = true
+			names = append(names, g.PermissionName)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func deny(r CheckRequest, message string) Decision {
+	return Decision{
+		MembershipId: r.MembershipId,
+		TenantId:     r.TenantId,
+		Permission:   r.Permission,
+		Allowed:      false,
+		Message:      message,
+		Reasons:      make([]Reason, 0),
+	}
+}
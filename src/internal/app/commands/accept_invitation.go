@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type AcceptInvitationRequest struct {
+	Token  string `json:"token"`
+	UserId string `json:"-"`
+	Email  string `json:"-"`
+}
+
+type AcceptInvitationCommand struct {
+	orgRepo repositories.OrganizationRepository
+	invRepo repositories.InvitationRepository
+}
+
+func NewAcceptInvitationCommand(
+	orgRepo repositories.OrganizationRepository,
+	invRepo repositories.InvitationRepository,
+) *AcceptInvitationCommand {
+	return &AcceptInvitationCommand{
+		orgRepo: orgRepo,
+		invRepo: invRepo,
+	}
+}
+
+// Execute adds a signed in user to the organization they were invited to
+// and returns the new membership.
+func (c *AcceptInvitationCommand) Execute(ctx context.Context, r AcceptInvitationRequest) (membershipId string, err error) {
+
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return "", fmt.Errorf("%w: sign in to accept the invitation", entities.ErrForbidden)
+	}
+
+	invitation, err := findInvitationByToken(ctx, c.invRepo, r.Token)
+	if err != nil {
+		return "", err
+	}
+
+	return acceptInvitation(ctx, c.orgRepo, c.invRepo, invitation, userId, r.Email)
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
 	"iyaem/internal/domain/repositories"
 	"iyaem/internal/domain/valueobjects"
 )
@@ -14,14 +16,17 @@ type AddGroupToMemberRequest struct {
 }
 
 type AddGroupToMemberCommand struct {
-	orgRepo repositories.OrganizationRepository
+	orgRepo   repositories.OrganizationRepository
+	groupRepo repositories.GroupRepository
 }
 
 func NewAddGroupToMemberCommand(
 	orgRepo repositories.OrganizationRepository,
+	groupRepo repositories.GroupRepository,
 ) *AddGroupToMemberCommand {
 	return &AddGroupToMemberCommand{
-		orgRepo: orgRepo,
+		orgRepo:   orgRepo,
+		groupRepo: groupRepo,
 	}
 }
 
@@ -47,6 +52,20 @@ func (c *AddGroupToMemberCommand) Execute(ctx context.Context, r AddGroupToMembe
 		return "", err
 	}
 
+	tenant := organization.FindTenantById(tenantId)
+	if tenant == nil {
+		return "", fmt.Errorf("could not find tenant")
+	}
+
+	group, err := c.groupRepo.FindById(ctx, groupId)
+	if err != nil || group == nil {
+		return "", fmt.Errorf("could not find group")
+	}
+
+	if !group.IsAvailableIn(*tenant) {
+		return "", fmt.Errorf("%w: group is not available in tenant", entities.ErrInvalid)
+	}
+
 	err = organization.AddGroupToMember(memberId, groupId, tenantId)
 	if err != nil {
 		return "", err
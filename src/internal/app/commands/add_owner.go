@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type AddOwnerRequest struct {
+	OrganizationId string `json:"organization_id"`
+	MembershipId   string `json:"user_org_id"`
+	ActorUserId    string `json:"-"`
+}
+
+type AddOwnerCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewAddOwnerCommand(
+	orgRepo repositories.OrganizationRepository,
+) *AddOwnerCommand {
+	return &AddOwnerCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute makes a member an additional owner. The acting user must be an
+// owner.
+func (c *AddOwnerCommand) Execute(ctx context.Context, r AddOwnerRequest) (membershipId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	actor, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	memberId, err := parseMembershipId(r.MembershipId)
+	if err != nil {
+		return "", err
+	}
+
+	err = organization.AddOwner(actor.Id(), memberId)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not add owner: %s", err)
+	}
+
+	return memberId.Value(), nil
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type AddPermissionRequest struct {
+	ApplicationId string `json:"application_id"`
+	Name          string `json:"name"`
+}
+
+type AddPermissionCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewAddPermissionCommand(
+	appRepo repositories.ApplicationRepository,
+) *AddPermissionCommand {
+	return &AddPermissionCommand{
+		appRepo: appRepo,
+	}
+}
+
+func (c *AddPermissionCommand) Execute(ctx context.Context, r AddPermissionRequest) (permissionId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	permission, err := application.AddPermission(r.Name)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.appRepo.Update(ctx, application)
+	if err != nil {
+		return "", fmt.Errorf("could not update application: %s", err)
+	}
+
+	return permission.Id().Value(), nil
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
 	"iyaem/internal/domain/repositories"
 	"iyaem/internal/domain/valueobjects"
 )
@@ -14,17 +16,20 @@ type AddRoleToMemberRequest struct {
 }
 
 type AddRoleToMemberCommand struct {
-	orgRepo repositories.OrganizationRepository
-	memRepo repositories.MembershipRepository
+	orgRepo  repositories.OrganizationRepository
+	memRepo  repositories.MembershipRepository
+	roleRepo repositories.RoleRepository
 }
 
 func NewAddRoleToMemberCommand(
 	orgRepo repositories.OrganizationRepository,
 	memRepo repositories.MembershipRepository,
+	roleRepo repositories.RoleRepository,
 ) *AddRoleToMemberCommand {
 	return &AddRoleToMemberCommand{
-		orgRepo: orgRepo,
-		memRepo: memRepo,
+		orgRepo:  orgRepo,
+		memRepo:  memRepo,
+		roleRepo: roleRepo,
 	}
 }
 
@@ -50,6 +55,20 @@ func (c *AddRoleToMemberCommand) Execute(ctx context.Context, r AddRoleToMemberR
 		return "", err
 	}
 
+	tenant := organization.FindTenantById(tenantId)
+	if tenant == nil {
+		return "", fmt.Errorf("could not find tenant")
+	}
+
+	role, err := c.roleRepo.FindById(ctx, roleId)
+	if err != nil || role == nil {
+		return "", fmt.Errorf("could not find role")
+	}
+
+	if !role.IsAvailableIn(*tenant) {
+		return "", fmt.Errorf("%w: role is not available in tenant", entities.ErrInvalid)
+	}
+
 	err = organization.AddRoleToMember(memberId, roleId, tenantId)
 	if err != nil {
 		return "", err
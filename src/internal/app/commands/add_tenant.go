@@ -26,21 +26,31 @@ func NewAddTenantCommand(
 	}
 }
 
+// Execute adds the tenant to the organization. Adding a tenant that is
+// already part of the organization does nothing, so that the command can
+// be retried.
 func (c *AddTenantCommand) Execute(ctx context.Context, r AddTenantRequest) (membershipId string, err error) {
 
 	organization, err := c.orgRepo.FindById(ctx, r.OrganizationId)
-	if err != nil || organization == nil {
-		return "", fmt.Errorf("could not find organization")
+	if err != nil {
+		return "", fmt.Errorf("could not find organization: %s", err)
+	}
+	if organization == nil {
+		return "", fmt.Errorf("could not find organization %v: %w", r.OrganizationId, entities.ErrNotFound)
 	}
 
 	newTenantId, err := valueobjects.NewTenantId(r.TenantId)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	if organization.FindTenantById(newTenantId) != nil {
+		return newTenantId.Value(), nil
 	}
 
 	newApplicationId, err := valueobjects.NewApplicationId(r.ApplicationId)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", entities.ErrInvalid, err)
 	}
 
 	newTenant := entities.NewTenant(
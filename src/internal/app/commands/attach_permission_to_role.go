@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type AttachPermissionToRoleRequest struct {
+	RoleId       string `json:"role_id"`
+	PermissionId string `json:"permission_id"`
+	TenantId     string `json:"tenant_id"`
+}
+
+type AttachPermissionToRoleCommand struct {
+	appRepo  repositories.ApplicationRepository
+	roleRepo repositories.RoleRepository
+}
+
+func NewAttachPermissionToRoleCommand(
+	appRepo repositories.ApplicationRepository,
+	roleRepo repositories.RoleRepository,
+) *AttachPermissionToRoleCommand {
+	return &AttachPermissionToRoleCommand{
+		appRepo:  appRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (c *AttachPermissionToRoleCommand) Execute(ctx context.Context, r AttachPermissionToRoleRequest) (roleId string, err error) {
+	role, err := findRole(ctx, c.roleRepo, r.RoleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("role", role.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	permissionId, err := parsePermissionId(r.PermissionId)
+	if err != nil {
+		return "", err
+	}
+
+	application, err := findApplication(ctx, c.appRepo, role.ApplicationId().Value())
+	if err != nil {
+		return "", err
+	}
+
+	permission := application.FindPermissionById(permissionId)
+	if permission == nil {
+		return "", fmt.Errorf("could not find permission in application: %w", entities.ErrNotFound)
+	}
+
+	err = role.AttachPermission(*permission)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.roleRepo.Update(ctx, role)
+	if err != nil {
+		return "", fmt.Errorf("could not update role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type AttachRoleToGroupRequest struct {
+	GroupId  string `json:"group_id"`
+	RoleId   string `json:"role_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+type AttachRoleToGroupCommand struct {
+	roleRepo  repositories.RoleRepository
+	groupRepo repositories.GroupRepository
+}
+
+func NewAttachRoleToGroupCommand(
+	roleRepo repositories.RoleRepository,
+	groupRepo repositories.GroupRepository,
+) *AttachRoleToGroupCommand {
+	return &AttachRoleToGroupCommand{
+		roleRepo:  roleRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+func (c *AttachRoleToGroupCommand) Execute(ctx context.Context, r AttachRoleToGroupRequest) (groupId string, err error) {
+	group, err := findGroup(ctx, c.groupRepo, r.GroupId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("group", group.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := findRole(ctx, c.roleRepo, r.RoleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = group.AttachRole(*role)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.groupRepo.Update(ctx, group)
+	if err != nil {
+		return "", fmt.Errorf("could not update group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+var (
+	ErrInvalidClient = errors.New("invalid_client")
+	ErrInvalidScope  = errors.New("invalid_scope")
+)
+
+type AuthenticateApiClientRequest struct {
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type AuthenticateApiClientCommand struct {
+	clientRepo repositories.ApiClientRepository
+}
+
+func NewAuthenticateApiClientCommand(
+	clientRepo repositories.ApiClientRepository,
+) *AuthenticateApiClientCommand {
+	return &AuthenticateApiClientCommand{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute checks the client credentials and returns the scopes to put in
+// the access token.
+func (c *AuthenticateApiClientCommand) Execute(ctx context.Context, r AuthenticateApiClientRequest) (scopes []string, err error) {
+	clientId, err := valueobjects.NewApiClientId(r.ClientId)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := c.clientRepo.FindById(ctx, clientId)
+	if err != nil || client == nil {
+		return nil, ErrInvalidClient
+	}
+
+	err = client.Authenticate(r.ClientSecret)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	scopes, err = client.GrantScopes(r.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidScope, err)
+	}
+
+	return scopes, nil
+}
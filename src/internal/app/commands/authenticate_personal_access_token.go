@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// AuthenticatePersonalAccessTokenResponse describes the token and the user
+// it acts for.
+type AuthenticatePersonalAccessTokenResponse struct {
+	TokenId         string
+	UserId          string
+	Email           string
+	Name            string
+	Scopes          []string
+	OrganizationIds []string
+}
+
+type AuthenticatePersonalAccessTokenCommand struct {
+	patRepo  repositories.PersonalAccessTokenRepository
+	userRepo repositories.UserRepository
+}
+
+func NewAuthenticatePersonalAccessTokenCommand(
+	patRepo repositories.PersonalAccessTokenRepository,
+	userRepo repositories.UserRepository,
+) *AuthenticatePersonalAccessTokenCommand {
+	return &AuthenticatePersonalAccessTokenCommand{
+		patRepo:  patRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Execute checks the personal access token presented with a request and
+// records its use. Unknown, expired and revoked tokens fail with
+// entities.ErrInvalidPersonalAccessToken.
+func (c *AuthenticatePersonalAccessTokenCommand) Execute(ctx context.Context, token string, ipAddress string) (AuthenticatePersonalAccessTokenResponse, error) {
+	pat, err := c.patRepo.FindByToken(ctx, valueobjects.NewTokenHash(token))
+	if err != nil {
+		return AuthenticatePersonalAccessTokenResponse{}, err
+	}
+	if pat == nil {
+		return AuthenticatePersonalAccessTokenResponse{}, entities.ErrInvalidPersonalAccessToken
+	}
+
+	used, err := pat.Use(ipAddress, time.Now())
+	if err != nil {
+		return AuthenticatePersonalAccessTokenResponse{}, err
+	}
+
+	if used {
+		err = c.patRepo.Update(ctx, pat)
+		if err != nil {
+			return AuthenticatePersonalAccessTokenResponse{}, fmt.Errorf("could not record personal access token use: %s", err)
+		}
+	}
+
+	user, err := c.userRepo.FindById(ctx, pat.UserId())
+	if err != nil {
+		return AuthenticatePersonalAccessTokenResponse{}, err
+	}
+	if user == nil {
+		return AuthenticatePersonalAccessTokenResponse{}, entities.ErrInvalidPersonalAccessToken
+	}
+
+	organizationIds := make([]string, 0, len(pat.OrganizationIds()))
+	for _, id := range pat.OrganizationIds() {
+		organizationIds = append(organizationIds, id.Value())
+	}
+
+	return AuthenticatePersonalAccessTokenResponse{
+		TokenId:         pat.Id().Value(),
+		UserId:          user.Id().Value(),
+		Email:           user.Email(),
+		Name:            user.Name(),
+		Scopes:          pat.Scopes(),
+		OrganizationIds: organizationIds,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type AuthenticateScimTokenCommand struct {
+	scimRepo repositories.ScimTokenRepository
+}
+
+func NewAuthenticateScimTokenCommand(
+	scimRepo repositories.ScimTokenRepository,
+) *AuthenticateScimTokenCommand {
+	return &AuthenticateScimTokenCommand{
+		scimRepo: scimRepo,
+	}
+}
+
+// Execute returns the SCIM token presented by a provisioning client, which
+// names the organization and tenant the client manages.
+func (c *AuthenticateScimTokenCommand) Execute(ctx context.Context, token string) (*entities.ScimToken, error) {
+	if token == "" {
+		return nil, ErrInvalidScimToken
+	}
+
+	scimToken, err := c.scimRepo.FindByToken(ctx, valueobjects.NewTokenHash(token))
+	if err != nil {
+		return nil, err
+	}
+	if scimToken == nil || scimToken.IsRevoked() {
+		return nil, ErrInvalidScimToken
+	}
+
+	return scimToken, nil
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"strings"
+)
+
+// Helpers shared by the commands that manage applications, permissions,
+// roles and groups.
+
+func findApplication(ctx context.Context, appRepo repositories.ApplicationRepository, id string) (*entities.Application, error) {
+	applicationId, err := valueobjects.NewApplicationId(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	application, err := appRepo.FindById(ctx, applicationId)
+	if err != nil {
+		return nil, err
+	}
+	if application == nil {
+		return nil, fmt.Errorf("could not find application: %w", entities.ErrNotFound)
+	}
+
+	return application, nil
+}
+
+func findRole(ctx context.Context, roleRepo repositories.RoleRepository, id string) (*entities.Role, error) {
+	roleId, err := valueobjects.NewRoleId(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	role, err := roleRepo.FindById(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("could not find role: %w", entities.ErrNotFound)
+	}
+
+	return role, nil
+}
+
+func findGroup(ctx context.Context, groupRepo repositories.GroupRepository, id string) (*entities.Group, error) {
+	groupId, err := valueobjects.NewGroupId(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	group, err := groupRepo.FindById(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("could not find group: %w", entities.ErrNotFound)
+	}
+
+	return group, nil
+}
+
+func parsePermissionId(id string) (valueobjects.PermissionId, error) {
+	permissionId, err := valueobjects.NewPermissionId(id)
+	if err != nil {
+		return valueobjects.PermissionId{}, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	return permissionId, nil
+}
+
+func findTenant(ctx context.Context, orgRepo repositories.OrganizationRepository, organizationId string, id string) (*entities.Tenant, error) {
+	tenantId, err := valueobjects.NewTenantId(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	organization, err := orgRepo.FindById(ctx, organizationId)
+	if err != nil || organization == nil {
+		return nil, fmt.Errorf("could not find organization: %w", entities.ErrNotFound)
+	}
+
+	tenant := organization.FindTenantById(tenantId)
+	if tenant == nil {
+		return nil, fmt.Errorf("could not find tenant: %w", entities.ErrNotFound)
+	}
+
+	return tenant, nil
+}
+
+// requireOwner checks that a role or group is managed through the tenant
+// of the request. Application-defined ones have no owner and can only be
+// managed without a tenant.
+func requireOwner(kind string, owner *valueobjects.TenantId, tenantId string) error {
+	if owner == nil && tenantId == "" {
+		return nil
+	}
+
+	if owner != nil && strings.EqualFold(owner.Value(), tenantId) {
+		return nil
+	}
+
+	return fmt.Errorf("could not find %s in tenant: %w", kind, entities.ErrNotFound)
+}
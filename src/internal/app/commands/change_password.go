@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+	"time"
+)
+
+type ChangePasswordRequest struct {
+	UserId          string `json:"-"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangePasswordCommand struct {
+	credRepo repositories.CredentialRepository
+}
+
+func NewChangePasswordCommand(
+	credRepo repositories.CredentialRepository,
+) *ChangePasswordCommand {
+	return &ChangePasswordCommand{
+		credRepo: credRepo,
+	}
+}
+
+func (c *ChangePasswordCommand) Execute(ctx context.Context, r ChangePasswordRequest) (credentialId string, err error) {
+	credential, err := findCredential(ctx, c.credRepo, r.UserId)
+	if err != nil {
+		return "", err
+	}
+
+	changeErr := credential.ChangePassword(r.CurrentPassword, r.NewPassword, time.Now())
+
+	// A wrong current password counts towards the lockout like a failed
+	// login does.
+	err = c.credRepo.Update(ctx, credential)
+	if err != nil {
+		return "", fmt.Errorf("could not update password credential: %s", err)
+	}
+
+	if changeErr != nil {
+		return "", changeErr
+	}
+
+	return credential.Id().Value(), nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type ConfirmIdentityLinkRequest struct {
+	Token       string `json:"token"`
+	ActorUserId string `json:"-"`
+}
+
+type ConfirmIdentityLinkCommand struct {
+	linkRepo repositories.IdentityLinkRepository
+}
+
+func NewConfirmIdentityLinkCommand(linkRepo repositories.IdentityLinkRepository) *ConfirmIdentityLinkCommand {
+	return &ConfirmIdentityLinkCommand{
+		linkRepo: linkRepo,
+	}
+}
+
+// Execute links an SSO identity to the account of the user making the
+// request, who signed in with their existing method. The token comes from
+// the SSO login that requested the link.
+func (c *ConfirmIdentityLinkCommand) Execute(ctx context.Context, r ConfirmIdentityLinkRequest) (userId string, err error) {
+
+	actorId, err := vo.NewUserId(r.ActorUserId)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	link, err := c.linkRepo.FindByToken(ctx, vo.NewTokenHash(r.Token))
+	if err != nil {
+		return "", err
+	}
+	if link == nil {
+		return "", fmt.Errorf("could not find identity link: %w", entities.ErrNotFound)
+	}
+
+	identity, err := link.Confirm(actorId)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.linkRepo.Confirm(ctx, link, identity)
+	if err != nil {
+		return "", fmt.Errorf("could not link identity: %s", err)
+	}
+
+	return actorId.Value(), nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type ConfirmOwnershipTransferRequest struct {
+	OrganizationId string `json:"organization_id"`
+	TransferId     string `json:"transfer_id"`
+	ActorUserId    string `json:"-"`
+}
+
+type ConfirmOwnershipTransferCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewConfirmOwnershipTransferCommand(
+	orgRepo repositories.OrganizationRepository,
+) *ConfirmOwnershipTransferCommand {
+	return &ConfirmOwnershipTransferCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute completes a pending transfer on behalf of the owner who
+// requested it and returns the membership of the new owner.
+func (c *ConfirmOwnershipTransferCommand) Execute(ctx context.Context, r ConfirmOwnershipTransferRequest) (membershipId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	actor, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	transfer, err := c.orgRepo.FindOwnershipTransfer(ctx, organization.Id().Value(), r.TransferId)
+	if err != nil {
+		return "", err
+	}
+	if transfer == nil {
+		return "", fmt.Errorf("could not find ownership transfer: %w", entities.ErrNotFound)
+	}
+
+	err = organization.TransferOwnership(*transfer, actor.Id())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not transfer ownership: %s", err)
+	}
+
+	return transfer.To().Value(), nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type ConfirmTotpRequest struct {
+	UserId    string `json:"-"`
+	SessionId string `json:"-"`
+	Code      string `json:"code"`
+}
+
+type ConfirmTotpCommand struct {
+	mfaRepo     repositories.MfaFactorRepository
+	sessionRepo repositories.SessionRepository
+}
+
+func NewConfirmTotpCommand(
+	mfaRepo repositories.MfaFactorRepository,
+	sessionRepo repositories.SessionRepository,
+) *ConfirmTotpCommand {
+	return &ConfirmTotpCommand{
+		mfaRepo:     mfaRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute enables the authenticator being enrolled with a first password
+// from it, and returns the recovery codes, which are only known at this
+// point. The code also counts as a second factor for the current session.
+func (c *ConfirmTotpCommand) Execute(ctx context.Context, r ConfirmTotpRequest) (recoveryCodes []string, err error) {
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	factor, err := c.mfaRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, fmt.Errorf("could not find authenticator: %w", entities.ErrNotFound)
+	}
+
+	recoveryCodes, err = factor.Confirm(r.Code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.mfaRepo.Update(ctx, factor)
+	if errors.Is(err, entities.ErrInvalidMfaCode) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not confirm authenticator: %s", err)
+	}
+
+	if r.SessionId != "" {
+		_, err = verifySession(ctx, c.sessionRepo, r.SessionId, r.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return recoveryCodes, nil
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type CreateApiClientRequest struct {
+	Name   string
+	Scopes []string
+}
+
+type CreateApiClientResponse struct {
+	ClientId     string
+	ClientSecret string
+}
+
+type CreateApiClientCommand struct {
+	clientRepo repositories.ApiClientRepository
+}
+
+func NewCreateApiClientCommand(
+	clientRepo repositories.ApiClientRepository,
+) *CreateApiClientCommand {
+	return &CreateApiClientCommand{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute registers a client and returns its secret. Only the hash of the
+// secret is stored, so it cannot be retrieved again.
+func (c *CreateApiClientCommand) Execute(ctx context.Context, r CreateApiClientRequest) (CreateApiClientResponse, error) {
+	if r.Name == "" {
+		return CreateApiClientResponse{}, fmt.Errorf("client name is required")
+	}
+
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return CreateApiClientResponse{}, err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	secretHash, err := valueobjects.NewSecretHash(secret)
+	if err != nil {
+		return CreateApiClientResponse{}, fmt.Errorf("hash secret: %w", err)
+	}
+
+	client := entities.NewApiClient(
+		valueobjects.GenerateApiClientId(),
+		r.Name,
+		secretHash,
+		r.Scopes,
+		time.Now(),
+		nil,
+	)
+
+	err = c.clientRepo.Insert(ctx, &client)
+	if err != nil {
+		return CreateApiClientResponse{}, fmt.Errorf("insert client: %w", err)
+	}
+
+	return CreateApiClientResponse{
+		ClientId:     client.Id().Value(),
+		ClientSecret: secret,
+	}, nil
+}
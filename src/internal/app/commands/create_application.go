@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateApplicationRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateApplicationCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewCreateApplicationCommand(
+	appRepo repositories.ApplicationRepository,
+) *CreateApplicationCommand {
+	return &CreateApplicationCommand{
+		appRepo: appRepo,
+	}
+}
+
+func (c *CreateApplicationCommand) Execute(ctx context.Context, r CreateApplicationRequest) (applicationId string, err error) {
+	application, err := entities.CreateApplication(r.Name)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.appRepo.Insert(ctx, &application)
+	if err != nil {
+		return "", fmt.Errorf("could not insert application: %s", err)
+	}
+
+	return application.Id().Value(), nil
+}
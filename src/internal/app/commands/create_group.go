@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateGroupRequest struct {
+	ApplicationId string `json:"application_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+}
+
+type CreateGroupCommand struct {
+	appRepo   repositories.ApplicationRepository
+	groupRepo repositories.GroupRepository
+}
+
+func NewCreateGroupCommand(
+	appRepo repositories.ApplicationRepository,
+	groupRepo repositories.GroupRepository,
+) *CreateGroupCommand {
+	return &CreateGroupCommand{
+		appRepo:   appRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+func (c *CreateGroupCommand) Execute(ctx context.Context, r CreateGroupRequest) (groupId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	group, err := entities.CreateGroup(r.Name, r.Description, application.Id())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.groupRepo.Insert(ctx, &group)
+	if err != nil {
+		return "", fmt.Errorf("could not insert group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
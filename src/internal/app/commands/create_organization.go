@@ -40,18 +40,13 @@ func (c *CreateOrganizationCommand) Execute(ctx context.Context, r CreateOrganiz
 
 	organizationId := valueobjects.GenerateOrganizationId()
 
-	ownerOrgId, err := valueobjects.NewMembershipId(r.UserId)
-	if err != nil {
-		return "", fmt.Errorf("new membership id: %w", err)
-	}
-
 	ownerId, err := valueobjects.NewUserId(r.UserId)
 	if err != nil {
 		return "", fmt.Errorf("new user id: %w", err)
 	}
 
 	owner := entities.NewMembership(
-		valueobjects.MembershipId(ownerOrgId),
+		valueobjects.GenerateMembershipId(),
 		ownerId,
 		organizationId,
 		"owner",
@@ -59,10 +54,7 @@ func (c *CreateOrganizationCommand) Execute(ctx context.Context, r CreateOrganiz
 		make([]valueobjects.UserGroup, 0),
 	)
 
-	members := make([]entities.Membership, 0)
-	members = append(members, owner)
-
-	organization := entities.NewOrganization(organizationId, r.Name, r.Identifier, members, make([]entities.Tenant, 0))
+	organization := entities.CreateOrganization(organizationId, r.Name, r.Identifier, owner)
 
 	err = c.orgRepo.Insert(ctx, &organization)
 	if err != nil {
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type CreatePersonalAccessTokenRequest struct {
+	UserId          string   `json:"-"`
+	Name            string   `json:"name"`
+	Scopes          []string `json:"scopes"`
+	OrganizationIds []string `json:"organization_ids"`
+	ExpiresInDays   int      `json:"expires_in_days"`
+}
+
+type CreatePersonalAccessTokenResponse struct {
+	TokenId   string    `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CreatePersonalAccessTokenCommand struct {
+	orgRepo repositories.OrganizationRepository
+	patRepo repositories.PersonalAccessTokenRepository
+}
+
+func NewCreatePersonalAccessTokenCommand(
+	orgRepo repositories.OrganizationRepository,
+	patRepo repositories.PersonalAccessTokenRepository,
+) *CreatePersonalAccessTokenCommand {
+	return &CreatePersonalAccessTokenCommand{
+		orgRepo: orgRepo,
+		patRepo: patRepo,
+	}
+}
+
+// Execute issues a personal access token of the user for organizations
+// they belong to, and returns it. Only the hash of the token is stored, so
+// it cannot be retrieved again.
+func (c *CreatePersonalAccessTokenCommand) Execute(ctx context.Context, r CreatePersonalAccessTokenRequest) (CreatePersonalAccessTokenResponse, error) {
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return CreatePersonalAccessTokenResponse{}, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	organizationIds := make([]valueobjects.OrganizationId, 0, len(r.OrganizationIds))
+	for _, id := range r.OrganizationIds {
+		organization, err := findOrganization(ctx, c.orgRepo, id)
+		if err != nil {
+			return CreatePersonalAccessTokenResponse{}, err
+		}
+
+		_, err = findActingMember(organization, r.UserId)
+		if err != nil {
+			return CreatePersonalAccessTokenResponse{}, err
+		}
+
+		organizationIds = append(organizationIds, organization.Id())
+	}
+
+	token, secret, err := entities.CreatePersonalAccessToken(userId, r.Name, r.Scopes, organizationIds, time.Duration(r.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		return CreatePersonalAccessTokenResponse{}, err
+	}
+
+	err = c.patRepo.Insert(ctx, &token)
+	if err != nil {
+		return CreatePersonalAccessTokenResponse{}, fmt.Errorf("could not create personal access token: %s", err)
+	}
+
+	return CreatePersonalAccessTokenResponse{
+		TokenId:   token.Id().Value(),
+		Token:     secret,
+		ExpiresAt: token.ExpiresAt(),
+	}, nil
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateRoleRequest struct {
+	ApplicationId string `json:"application_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+}
+
+type CreateRoleCommand struct {
+	appRepo  repositories.ApplicationRepository
+	roleRepo repositories.RoleRepository
+}
+
+func NewCreateRoleCommand(
+	appRepo repositories.ApplicationRepository,
+	roleRepo repositories.RoleRepository,
+) *CreateRoleCommand {
+	return &CreateRoleCommand{
+		appRepo:  appRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (c *CreateRoleCommand) Execute(ctx context.Context, r CreateRoleRequest) (roleId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := entities.CreateRole(r.Name, r.Description, application.Id())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.roleRepo.Insert(ctx, &role)
+	if err != nil {
+		return "", fmt.Errorf("could not insert role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateScimTokenRequest struct {
+	OrganizationId string `json:"organization_id"`
+	TenantId       string `json:"tenant_id"`
+	Description    string `json:"description"`
+}
+
+type CreateScimTokenResponse struct {
+	TokenId string
+	Token   string
+}
+
+type CreateScimTokenCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	scimRepo repositories.ScimTokenRepository
+}
+
+func NewCreateScimTokenCommand(
+	orgRepo repositories.OrganizationRepository,
+	scimRepo repositories.ScimTokenRepository,
+) *CreateScimTokenCommand {
+	return &CreateScimTokenCommand{
+		orgRepo:  orgRepo,
+		scimRepo: scimRepo,
+	}
+}
+
+// Execute issues a SCIM token for the organization and returns it. Only
+// the hash of the token is stored, so it cannot be retrieved again.
+func (c *CreateScimTokenCommand) Execute(ctx context.Context, r CreateScimTokenRequest) (CreateScimTokenResponse, error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return CreateScimTokenResponse{}, err
+	}
+
+	var tenant *entities.Tenant
+	if r.TenantId != "" {
+		tenant, err = findTenant(ctx, c.orgRepo, r.OrganizationId, r.TenantId)
+		if err != nil {
+			return CreateScimTokenResponse{}, err
+		}
+	}
+
+	token, secret, err := entities.CreateScimToken(organization.Id(), tenant, r.Description)
+	if err != nil {
+		return CreateScimTokenResponse{}, err
+	}
+
+	err = c.scimRepo.Insert(ctx, &token)
+	if err != nil {
+		return CreateScimTokenResponse{}, fmt.Errorf("could not create SCIM token: %s", err)
+	}
+
+	return CreateScimTokenResponse{
+		TokenId: token.Id().Value(),
+		Token:   secret,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateSsoConnectionRequest struct {
+	OrganizationId string   `json:"organization_id"`
+	Protocol       string   `json:"protocol"`
+	IssuerUrl      string   `json:"issuer_url"`
+	ClientId       string   `json:"client_id"`
+	ClientSecret   string   `json:"client_secret"`
+	Domains        []string `json:"domains"`
+	ActorUserId    string   `json:"-"`
+}
+
+type CreateSsoConnectionCommand struct {
+	orgRepo repositories.OrganizationRepository
+	ssoRepo repositories.SsoConnectionRepository
+}
+
+func NewCreateSsoConnectionCommand(
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+) *CreateSsoConnectionCommand {
+	return &CreateSsoConnectionCommand{
+		orgRepo: orgRepo,
+		ssoRepo: ssoRepo,
+	}
+}
+
+// Execute registers the identity provider of the organization. An
+// organization has at most one connection.
+func (c *CreateSsoConnectionCommand) Execute(ctx context.Context, r CreateSsoConnectionRequest) (connectionId string, err error) {
+
+	organization, err := requireOwningMember(ctx, c.orgRepo, r.OrganizationId, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := c.ssoRepo.FindByOrganization(ctx, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("%w: the organization already has an SSO connection", entities.ErrConflict)
+	}
+
+	protocol := r.Protocol
+	if protocol == "" {
+		protocol = entities.SsoProtocolOIDC
+	}
+
+	connection, err := entities.CreateSsoConnection(organization.Id(), protocol, r.IssuerUrl, r.ClientId, r.ClientSecret, r.Domains)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireFreeDomains(ctx, c.ssoRepo, &connection)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.ssoRepo.Insert(ctx, &connection)
+	if err != nil {
+		return "", fmt.Errorf("could not create SSO connection: %s", err)
+	}
+
+	return connection.Id().Value(), nil
+}
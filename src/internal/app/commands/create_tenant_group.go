@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateTenantGroupRequest struct {
+	OrganizationId string `json:"organization_id"`
+	TenantId       string `json:"tenant_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+}
+
+type CreateTenantGroupCommand struct {
+	orgRepo   repositories.OrganizationRepository
+	groupRepo repositories.GroupRepository
+}
+
+func NewCreateTenantGroupCommand(
+	orgRepo repositories.OrganizationRepository,
+	groupRepo repositories.GroupRepository,
+) *CreateTenantGroupCommand {
+	return &CreateTenantGroupCommand{
+		orgRepo:   orgRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+// Execute defines a group owned by a tenant of the organization, built on
+// the tenant's application.
+func (c *CreateTenantGroupCommand) Execute(ctx context.Context, r CreateTenantGroupRequest) (groupId string, err error) {
+	tenant, err := findTenant(ctx, c.orgRepo, r.OrganizationId, r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	group, err := entities.CreateTenantGroup(r.Name, r.Description, *tenant)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.groupRepo.Insert(ctx, &group)
+	if err != nil {
+		return "", fmt.Errorf("could not insert group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
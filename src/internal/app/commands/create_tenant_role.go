@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type CreateTenantRoleRequest struct {
+	OrganizationId string `json:"organization_id"`
+	TenantId       string `json:"tenant_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+}
+
+type CreateTenantRoleCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	roleRepo repositories.RoleRepository
+}
+
+func NewCreateTenantRoleCommand(
+	orgRepo repositories.OrganizationRepository,
+	roleRepo repositories.RoleRepository,
+) *CreateTenantRoleCommand {
+	return &CreateTenantRoleCommand{
+		orgRepo:  orgRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute defines a role owned by a tenant of the organization, built on
+// the tenant's application.
+func (c *CreateTenantRoleCommand) Execute(ctx context.Context, r CreateTenantRoleRequest) (roleId string, err error) {
+	tenant, err := findTenant(ctx, c.orgRepo, r.OrganizationId, r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := entities.CreateTenantRole(r.Name, r.Description, *tenant)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.roleRepo.Insert(ctx, &role)
+	if err != nil {
+		return "", fmt.Errorf("could not insert role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type DeclineInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type DeclineInvitationCommand struct {
+	invRepo repositories.InvitationRepository
+}
+
+func NewDeclineInvitationCommand(
+	invRepo repositories.InvitationRepository,
+) *DeclineInvitationCommand {
+	return &DeclineInvitationCommand{
+		invRepo: invRepo,
+	}
+}
+
+// Execute declines an invitation. The token is enough to identify the
+// invitee, so no account is needed.
+func (c *DeclineInvitationCommand) Execute(ctx context.Context, r DeclineInvitationRequest) (invitationId string, err error) {
+
+	invitation, err := findInvitationByToken(ctx, c.invRepo, r.Token)
+	if err != nil {
+		return "", err
+	}
+
+	err = invitation.Decline()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.invRepo.Update(ctx, invitation)
+	if err != nil {
+		return "", fmt.Errorf("could not decline invitation: %w", err)
+	}
+
+	return invitation.Id().Value(), nil
+}
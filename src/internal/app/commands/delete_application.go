@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type DeleteApplicationRequest struct {
+	ApplicationId string `json:"application_id"`
+}
+
+type DeleteApplicationCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewDeleteApplicationCommand(
+	appRepo repositories.ApplicationRepository,
+) *DeleteApplicationCommand {
+	return &DeleteApplicationCommand{
+		appRepo: appRepo,
+	}
+}
+
+// Execute deletes the application with its permissions, roles and groups.
+// Applications that still have tenants cannot be deleted.
+func (c *DeleteApplicationCommand) Execute(ctx context.Context, r DeleteApplicationRequest) (applicationId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	hasTenants, err := c.appRepo.HasTenants(ctx, application.Id())
+	if err != nil {
+		return "", err
+	}
+	if hasTenants {
+		return "", fmt.Errorf("%w: application still has tenants", entities.ErrConflict)
+	}
+
+	application.Delete()
+
+	err = c.appRepo.Delete(ctx, application)
+	if err != nil {
+		return "", fmt.Errorf("could not delete application: %s", err)
+	}
+
+	return application.Id().Value(), nil
+}
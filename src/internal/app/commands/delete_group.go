@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type DeleteGroupRequest struct {
+	GroupId  string `json:"group_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+type DeleteGroupCommand struct {
+	groupRepo repositories.GroupRepository
+}
+
+func NewDeleteGroupCommand(
+	groupRepo repositories.GroupRepository,
+) *DeleteGroupCommand {
+	return &DeleteGroupCommand{
+		groupRepo: groupRepo,
+	}
+}
+
+// Execute deletes the group. Members of the group lose it.
+func (c *DeleteGroupCommand) Execute(ctx context.Context, r DeleteGroupRequest) (groupId string, err error) {
+	group, err := findGroup(ctx, c.groupRepo, r.GroupId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("group", group.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	group.Delete()
+
+	err = c.groupRepo.Delete(ctx, group)
+	if err != nil {
+		return "", fmt.Errorf("could not delete group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
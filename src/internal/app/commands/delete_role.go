@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type DeleteRoleRequest struct {
+	RoleId   string `json:"role_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+type DeleteRoleCommand struct {
+	roleRepo repositories.RoleRepository
+}
+
+func NewDeleteRoleCommand(
+	roleRepo repositories.RoleRepository,
+) *DeleteRoleCommand {
+	return &DeleteRoleCommand{
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute deletes the role. Members and groups that had the role lose it.
+func (c *DeleteRoleCommand) Execute(ctx context.Context, r DeleteRoleRequest) (roleId string, err error) {
+	role, err := findRole(ctx, c.roleRepo, r.RoleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("role", role.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	role.Delete()
+
+	err = c.roleRepo.Delete(ctx, role)
+	if err != nil {
+		return "", fmt.Errorf("could not delete role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
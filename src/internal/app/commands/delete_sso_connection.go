@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type DeleteSsoConnectionRequest struct {
+	OrganizationId string `json:"organization_id"`
+	ActorUserId    string `json:"-"`
+}
+
+type DeleteSsoConnectionCommand struct {
+	orgRepo repositories.OrganizationRepository
+	ssoRepo repositories.SsoConnectionRepository
+}
+
+func NewDeleteSsoConnectionCommand(
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+) *DeleteSsoConnectionCommand {
+	return &DeleteSsoConnectionCommand{
+		orgRepo: orgRepo,
+		ssoRepo: ssoRepo,
+	}
+}
+
+// Execute removes the organization's connection. Users it provisioned
+// keep their accounts and memberships.
+func (c *DeleteSsoConnectionCommand) Execute(ctx context.Context, r DeleteSsoConnectionRequest) (connectionId string, err error) {
+
+	organization, err := requireOwningMember(ctx, c.orgRepo, r.OrganizationId, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	connection, err := findSsoConnection(ctx, c.ssoRepo, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+
+	connection.Delete()
+
+	err = c.ssoRepo.Delete(ctx, connection)
+	if err != nil {
+		return "", fmt.Errorf("could not delete SSO connection: %s", err)
+	}
+
+	return connection.Id().Value(), nil
+}
@@ -10,6 +10,7 @@ import (
 type DemoteUserRequest struct {
 	MembershipId   string `json:"user_org_id"`
 	OrganizationId string `json:"organization_id"`
+	ActorUserId    string `json:"-"`
 }
 
 type DemoteUserCommand struct {
@@ -27,6 +28,8 @@ func NewDemoteUserCommand(
 	}
 }
 
+// Execute lowers the level of the member by one. Only owners can demote
+// an owner.
 func (c *DemoteUserCommand) Execute(ctx context.Context, r DemoteUserRequest) (membershipId string, err error) {
 
 	organization, err := c.orgRepo.FindById(ctx, r.OrganizationId)
@@ -44,9 +47,14 @@ func (c *DemoteUserCommand) Execute(ctx context.Context, r DemoteUserRequest) (m
 		return "", fmt.Errorf("could not find user")
 	}
 
-	err = organization.DemoteMember(*member)
+	actor, err := findActingMember(organization, r.ActorUserId)
 	if err != nil {
-		return "", fmt.Errorf("could not demote user")
+		return "", err
+	}
+
+	err = organization.DemoteMember(actor.Id(), *member)
+	if err != nil {
+		return "", fmt.Errorf("could not demote user: %w", err)
 	}
 
 	err = c.orgRepo.Update(ctx, organization)
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type DetachPermissionFromRoleRequest struct {
+	RoleId       string `json:"role_id"`
+	PermissionId string `json:"permission_id"`
+	TenantId     string `json:"tenant_id"`
+}
+
+type DetachPermissionFromRoleCommand struct {
+	roleRepo repositories.RoleRepository
+}
+
+func NewDetachPermissionFromRoleCommand(
+	roleRepo repositories.RoleRepository,
+) *DetachPermissionFromRoleCommand {
+	return &DetachPermissionFromRoleCommand{
+		roleRepo: roleRepo,
+	}
+}
+
+func (c *DetachPermissionFromRoleCommand) Execute(ctx context.Context, r DetachPermissionFromRoleRequest) (roleId string, err error) {
+	role, err := findRole(ctx, c.roleRepo, r.RoleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("role", role.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	permissionId, err := parsePermissionId(r.PermissionId)
+	if err != nil {
+		return "", err
+	}
+
+	err = role.DetachPermission(permissionId)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.roleRepo.Update(ctx, role)
+	if err != nil {
+		return "", fmt.Errorf("could not update role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
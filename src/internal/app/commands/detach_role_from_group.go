@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type DetachRoleFromGroupRequest struct {
+	GroupId  string `json:"group_id"`
+	RoleId   string `json:"role_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+type DetachRoleFromGroupCommand struct {
+	groupRepo repositories.GroupRepository
+}
+
+func NewDetachRoleFromGroupCommand(
+	groupRepo repositories.GroupRepository,
+) *DetachRoleFromGroupCommand {
+	return &DetachRoleFromGroupCommand{
+		groupRepo: groupRepo,
+	}
+}
+
+func (c *DetachRoleFromGroupCommand) Execute(ctx context.Context, r DetachRoleFromGroupRequest) (groupId string, err error) {
+	group, err := findGroup(ctx, c.groupRepo, r.GroupId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("group", group.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	roleId, err := valueobjects.NewRoleId(r.RoleId)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	err = group.DetachRole(roleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.groupRepo.Update(ctx, group)
+	if err != nil {
+		return "", fmt.Errorf("could not update group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+	"time"
+)
+
+type DisableMfaRequest struct {
+	UserId string `json:"-"`
+	Code   string `json:"code"`
+}
+
+type DisableMfaCommand struct {
+	mfaRepo repositories.MfaFactorRepository
+}
+
+func NewDisableMfaCommand(
+	mfaRepo repositories.MfaFactorRepository,
+) *DisableMfaCommand {
+	return &DisableMfaCommand{
+		mfaRepo: mfaRepo,
+	}
+}
+
+// Execute removes the authenticator of the user, given a valid code. Users
+// of an organization that requires multi-factor authentication are asked
+// to enroll again when they next sign in.
+func (c *DisableMfaCommand) Execute(ctx context.Context, r DisableMfaRequest) (factorId string, err error) {
+	factor, err := findMfaFactor(ctx, c.mfaRepo, r.UserId)
+	if err != nil {
+		return "", err
+	}
+
+	err = factor.Disable(r.Code, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.mfaRepo.Delete(ctx, factor)
+	if err != nil {
+		return "", fmt.Errorf("could not disable multi-factor authentication: %s", err)
+	}
+
+	return factor.Id().Value(), nil
+}
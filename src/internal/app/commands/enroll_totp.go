@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type EnrollTotpRequest struct {
+	UserId string `json:"-"`
+	Email  string `json:"-"`
+}
+
+// EnrollTotpResponse carries the secret to add to an authenticator app,
+// as text and as an otpauth URI for a QR code.
+type EnrollTotpResponse struct {
+	FactorId string `json:"factor_id"`
+	Secret   string `json:"secret"`
+	Uri      string `json:"uri"`
+}
+
+type EnrollTotpCommand struct {
+	mfaRepo repositories.MfaFactorRepository
+	issuer  string
+}
+
+// NewEnrollTotpCommand builds the command. The issuer names the service in
+// authenticator apps.
+func NewEnrollTotpCommand(
+	mfaRepo repositories.MfaFactorRepository,
+	issuer string,
+) *EnrollTotpCommand {
+	return &EnrollTotpCommand{
+		mfaRepo: mfaRepo,
+		issuer:  issuer,
+	}
+}
+
+// Execute starts enrolling an authenticator app, replacing an enrollment
+// that was never confirmed. It takes effect once confirmed with
+// ConfirmTotpCommand.
+func (c *EnrollTotpCommand) Execute(ctx context.Context, r EnrollTotpRequest) (EnrollTotpResponse, error) {
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return EnrollTotpResponse{}, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	existing, err := c.mfaRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		return EnrollTotpResponse{}, err
+	}
+
+	if existing != nil {
+		if existing.IsConfirmed() {
+			return EnrollTotpResponse{}, fmt.Errorf("%w: multi-factor authentication is already enabled", entities.ErrConflict)
+		}
+
+		err = c.mfaRepo.Delete(ctx, existing)
+		if err != nil {
+			return EnrollTotpResponse{}, fmt.Errorf("could not replace authenticator: %s", err)
+		}
+	}
+
+	factor, err := entities.EnrollTotp(userId)
+	if err != nil {
+		return EnrollTotpResponse{}, err
+	}
+
+	err = c.mfaRepo.Insert(ctx, &factor)
+	if err != nil {
+		return EnrollTotpResponse{}, fmt.Errorf("could not enroll authenticator: %s", err)
+	}
+
+	return EnrollTotpResponse{
+		FactorId: factor.Id().Value(),
+		Secret:   factor.Secret().Value(),
+		Uri:      factor.Secret().URI(c.issuer, r.Email),
+	}, nil
+}
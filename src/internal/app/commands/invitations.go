@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// Helpers shared by the commands that manage invitations.
+
+// invitationTTL is how long an invitation can be accepted.
+const invitationTTL = 7 * 24 * time.Hour
+
+func findInvitationByToken(ctx context.Context, invRepo repositories.InvitationRepository, token string) (*entities.Invitation, error) {
+	invitation, err := invRepo.FindByToken(ctx, valueobjects.NewTokenHash(token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, fmt.Errorf("could not find invitation: %w", entities.ErrNotFound)
+	}
+
+	return invitation, nil
+}
+
+// acceptInvitation adds the user to the invitation's organization and
+// records the invitation as accepted, both or neither.
+func acceptInvitation(
+	ctx context.Context,
+	orgRepo repositories.OrganizationRepository,
+	invRepo repositories.InvitationRepository,
+	invitation *entities.Invitation,
+	userId valueobjects.UserId,
+	email string,
+) (string, error) {
+	organization, err := findOrganization(ctx, orgRepo, invitation.OrganizationId().Value())
+	if err != nil {
+		return "", err
+	}
+
+	if organization.FindMemberByUserId(userId) != nil {
+		return "", fmt.Errorf("%w: already a member of the organization", entities.ErrConflict)
+	}
+
+	membership, err := invitation.Accept(userId, email)
+	if err != nil {
+		return "", err
+	}
+
+	organization.AddMember(membership)
+
+	err = invRepo.Accept(ctx, invitation, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not accept invitation: %w", err)
+	}
+
+	return membership.Id().Value(), nil
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/app/mail"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"net/url"
+	"strings"
+)
+
+type InviteMemberRequest struct {
+	OrganizationId string `json:"organization_id"`
+	Email          string `json:"email"`
+	Level          string `json:"level"`
+	InvitedBy      string `json:"-"`
+}
+
+type InviteMemberCommand struct {
+	orgRepo   repositories.OrganizationRepository
+	userRepo  repositories.UserRepository
+	invRepo   repositories.InvitationRepository
+	mailer    mail.Mailer
+	acceptUrl string
+}
+
+// NewInviteMemberCommand creates the command. acceptUrl is the page that
+// receives the invitation token in its "token" query parameter.
+func NewInviteMemberCommand(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	invRepo repositories.InvitationRepository,
+	mailer mail.Mailer,
+	acceptUrl string,
+) *InviteMemberCommand {
+	return &InviteMemberCommand{
+		orgRepo:   orgRepo,
+		userRepo:  userRepo,
+		invRepo:   invRepo,
+		mailer:    mailer,
+		acceptUrl: acceptUrl,
+	}
+}
+
+// Execute records an invitation and emails its token to the invitee.
+func (c *InviteMemberCommand) Execute(ctx context.Context, r InviteMemberRequest) (invitationId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	email := entities.NormalizeEmail(r.Email)
+
+	user, err := c.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if user != nil && organization.FindMemberByUserId(user.Id()) != nil {
+		return "", fmt.Errorf("%w: %s is already a member of the organization", entities.ErrConflict, email)
+	}
+
+	pending, err := c.invRepo.FindPendingByOrganization(ctx, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+	for _, invitation := range pending {
+		if invitation.Email() == email && !invitation.IsExpired() {
+			return "", fmt.Errorf("%w: %s has already been invited", entities.ErrConflict, email)
+		}
+	}
+
+	level := r.Level
+	if level == "" {
+		level = "member"
+	}
+
+	invitation, token, err := entities.CreateInvitation(organization.Id(), email, valueobjects.MembershipLevel(level), r.InvitedBy, invitationTTL)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.invRepo.Insert(ctx, &invitation)
+	if err != nil {
+		return "", fmt.Errorf("could not create invitation: %s", err)
+	}
+
+	err = c.mailer.Send(ctx, mail.Message{
+		To:      invitation.Email(),
+		Subject: "You are invited to join " + organization.Name(),
+		Body: fmt.Sprintf(
+			"You have been invited to join %s.\n\nAccept the invitation: %s\n\nThe invitation expires on %s.\n",
+			organization.Name(), c.link(token), invitation.ExpiresAt().UTC().Format("January 2, 2006 15:04 MST"),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not send invitation: %s", err)
+	}
+
+	return invitation.Id().Value(), nil
+}
+
+func (c *InviteMemberCommand) link(token string) string {
+	separator := "?"
+	if strings.Contains(c.acceptUrl, "?") {
+		separator = "&"
+	}
+
+	return c.acceptUrl + separator + url.Values{"token": {token}}.Encode()
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"log"
+)
+
+type JoinInvitedOrganizationsRequest struct {
+	IdpId   string `json:"idp_id"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+}
+
+type JoinInvitedOrganizationsCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+	invRepo  repositories.InvitationRepository
+}
+
+func NewJoinInvitedOrganizationsCommand(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	invRepo repositories.InvitationRepository,
+) *JoinInvitedOrganizationsCommand {
+	return &JoinInvitedOrganizationsCommand{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		invRepo:  invRepo,
+	}
+}
+
+// Execute runs on the first login of someone without an account. When the
+// verified email has pending invitations, the user is created and joins
+// every inviting organization. It returns an empty id when there is
+// nothing to join, or when the email already belongs to an account, whose
+// owner accepts the invitations by signing in to it.
+func (c *JoinInvitedOrganizationsCommand) Execute(ctx context.Context, r JoinInvitedOrganizationsRequest) (userId string, err error) {
+
+	existing, err := c.userRepo.FindByEmail(ctx, entities.NormalizeEmail(r.Email))
+	if err != nil || existing != nil {
+		return "", err
+	}
+
+	invitations, err := c.invRepo.FindPendingByEmail(ctx, r.Email)
+	if err != nil {
+		return "", err
+	}
+	if len(invitations) == 0 {
+		return "", nil
+	}
+
+	id := valueobjects.GenerateUserId()
+	user := entities.NewUser(
+		id,
+		r.Name,
+		entities.NormalizeEmail(r.Email),
+		r.Picture,
+		[]valueobjects.Identity{valueobjects.NewIdentity(r.IdpId, id)},
+		make([]entities.Membership, 0),
+	)
+
+	err = c.userRepo.Insert(ctx, &user)
+	if err != nil {
+		return "", fmt.Errorf("could not create user: %s", err)
+	}
+
+	for i := range invitations {
+		_, err = acceptInvitation(ctx, c.orgRepo, c.invRepo, &invitations[i], id, r.Email)
+		if err != nil {
+			log.Printf("Error: could not accept invitation %s: %v", invitations[i].Id().Value(), err)
+		}
+	}
+
+	return id.Value(), nil
+}
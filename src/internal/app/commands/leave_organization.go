@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type LeaveOrganizationRequest struct {
+	OrganizationId string `json:"organization_id"`
+	ActorUserId    string `json:"-"`
+}
+
+type LeaveOrganizationCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewLeaveOrganizationCommand(
+	orgRepo repositories.OrganizationRepository,
+) *LeaveOrganizationCommand {
+	return &LeaveOrganizationCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute removes the acting user from the organization. The last owner
+// cannot leave.
+func (c *LeaveOrganizationCommand) Execute(ctx context.Context, r LeaveOrganizationRequest) (membershipId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	member, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	err = organization.RemoveMember(member.Id(), member.Id())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not leave organization: %s", err)
+	}
+
+	return member.Id().Value(), nil
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// Helpers shared by the commands that manage multi-factor authentication.
+
+// findMfaFactor returns the enabled factor of the user.
+func findMfaFactor(ctx context.Context, mfaRepo repositories.MfaFactorRepository, userId string) (*entities.MfaFactor, error) {
+	id, err := valueobjects.NewUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	factor, err := mfaRepo.FindByUserId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil || !factor.IsConfirmed() {
+		return nil, fmt.Errorf("%w: multi-factor authentication is not enabled", entities.ErrConflict)
+	}
+
+	return factor, nil
+}
+
+// verifySession completes the sign in of a pending session, or records a
+// step-up of an active one, once the user proved a second factor.
+func verifySession(ctx context.Context, sessionRepo repositories.SessionRepository, sessionId string, userId string) (*entities.Session, error) {
+	session, err := findUserSession(ctx, sessionRepo, sessionId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = session.VerifyMfa(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = sessionRepo.Update(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify session: %s", err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// Helpers shared by the commands that manage the owners of an
+// organization.
+
+// ownershipTransferTTL is how long the owner has to confirm a transfer.
+const ownershipTransferTTL = 24 * time.Hour
+
+func findOrganization(ctx context.Context, orgRepo repositories.OrganizationRepository, id string) (*entities.Organization, error) {
+	organization, err := orgRepo.FindById(ctx, id)
+	if err != nil || organization == nil {
+		return nil, fmt.Errorf("could not find organization: %w", entities.ErrNotFound)
+	}
+
+	return organization, nil
+}
+
+// findActingMember returns the membership of the user making the request.
+func findActingMember(organization *entities.Organization, userId string) (*entities.Membership, error) {
+	id, err := valueobjects.NewUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a member of the organization", entities.ErrForbidden)
+	}
+
+	member := organization.FindMemberByUserId(id)
+	if member == nil {
+		return nil, fmt.Errorf("%w: not a member of the organization", entities.ErrForbidden)
+	}
+
+	return member, nil
+}
+
+func parseMembershipId(id string) (valueobjects.MembershipId, error) {
+	membershipId, err := valueobjects.NewMembershipId(id)
+	if err != nil {
+		return valueobjects.MembershipId{}, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	return membershipId, nil
+}
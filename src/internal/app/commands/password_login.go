@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"time"
+)
+
+type PasswordLoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// PasswordLoginResponse describes the user who signed in, for the claims
+// of their token.
+type PasswordLoginResponse struct {
+	UserId  string
+	Subject string
+	Email   string
+	Name    string
+	Picture string
+}
+
+type PasswordLoginCommand struct {
+	userRepo repositories.UserRepository
+	credRepo repositories.CredentialRepository
+}
+
+func NewPasswordLoginCommand(
+	userRepo repositories.UserRepository,
+	credRepo repositories.CredentialRepository,
+) *PasswordLoginCommand {
+	return &PasswordLoginCommand{
+		userRepo: userRepo,
+		credRepo: credRepo,
+	}
+}
+
+// Execute checks the password of the credential with the email. Unknown
+// emails, wrong passwords and locked credentials all fail with
+// entities.ErrInvalidPassword.
+func (c *PasswordLoginCommand) Execute(ctx context.Context, r PasswordLoginRequest) (PasswordLoginResponse, error) {
+	credential, err := c.credRepo.FindByEmail(ctx, r.Email)
+	if err != nil {
+		return PasswordLoginResponse{}, err
+	}
+	if credential == nil {
+		hashDecoyPassword(r.Password)
+		return PasswordLoginResponse{}, entities.ErrInvalidPassword
+	}
+
+	authErr := credential.Authenticate(r.Password, time.Now())
+
+	// Failed attempts, lockouts and rehashed passwords are stored either
+	// way.
+	err = c.credRepo.Update(ctx, credential)
+	if err != nil {
+		return PasswordLoginResponse{}, fmt.Errorf("could not update password credential: %s", err)
+	}
+
+	if authErr != nil {
+		return PasswordLoginResponse{}, authErr
+	}
+
+	user, err := c.userRepo.FindById(ctx, credential.UserId())
+	if err != nil {
+		return PasswordLoginResponse{}, err
+	}
+	if user == nil {
+		return PasswordLoginResponse{}, fmt.Errorf("could not find user: %w", entities.ErrNotFound)
+	}
+
+	return PasswordLoginResponse{
+		UserId:  user.Id().Value(),
+		Subject: credential.Subject(),
+		Email:   user.Email(),
+		Name:    user.Name(),
+		Picture: user.Picture(),
+	}, nil
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"sync"
+	"time"
+)
+
+// Helpers shared by the commands that manage password credentials.
+
+// passwordResetTTL is how long a password reset can be used.
+const passwordResetTTL = time.Hour
+
+var (
+	decoyPasswordHash     valueobjects.PasswordHash
+	decoyPasswordHashOnce sync.Once
+)
+
+// hashDecoyPassword spends about as long as checking a password does, so
+// that a login for an unknown email cannot be told apart by its timing.
+func hashDecoyPassword(password string) {
+	decoyPasswordHashOnce.Do(func() {
+		decoyPasswordHash, _ = valueobjects.NewPasswordHash("decoy password, never matched")
+	})
+
+	decoyPasswordHash.Matches(password)
+}
+
+func findCredential(ctx context.Context, credRepo repositories.CredentialRepository, userId string) (*entities.Credential, error) {
+	id, err := valueobjects.NewUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	credential, err := credRepo.FindByUserId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, fmt.Errorf("could not find password credential: %w", entities.ErrNotFound)
+	}
+
+	return credential, nil
+}
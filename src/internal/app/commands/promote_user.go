@@ -46,7 +46,7 @@ func (c *PromoteUserCommand) Execute(ctx context.Context, r PromoteUserRequest)
 
 	err = organization.PromoteMember(*member)
 	if err != nil {
-		return "", fmt.Errorf("could not promote user")
+		return "", fmt.Errorf("could not promote user: %w", err)
 	}
 
 	err = c.orgRepo.Update(ctx, organization)
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"net/mail"
+)
+
+type ProvisionScimUserRequest struct {
+	OrganizationId string `json:"organization_id"`
+	Email          string `json:"email"`
+	Name           string `json:"name"`
+}
+
+type ProvisionScimUserCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+	ssoRepo  repositories.SsoConnectionRepository
+}
+
+func NewProvisionScimUserCommand(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+) *ProvisionScimUserCommand {
+	return &ProvisionScimUserCommand{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		ssoRepo:  ssoRepo,
+	}
+}
+
+// Execute makes the user with the email a member of the organization; a
+// user without an account gets one, and signs in once an identity with
+// that email is linked to it. Only emails in a domain the organization
+// verified for its SSO connection can be provisioned, since anyone else's
+// account is not the organization's to take; such users are invited
+// instead.
+func (c *ProvisionScimUserCommand) Execute(ctx context.Context, r ProvisionScimUserRequest) (userId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := mail.ParseAddress(r.Email)
+	if err != nil || address.Name != "" {
+		return "", fmt.Errorf("%w: invalid email %q", entities.ErrInvalid, r.Email)
+	}
+
+	email := entities.NormalizeEmail(r.Email)
+
+	connection, err := c.ssoRepo.FindByOrganization(ctx, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+	if connection == nil || !connection.AllowsEmail(email) {
+		return "", fmt.Errorf("%w: %s is not in a verified domain of the organization, invite the user instead", entities.ErrForbidden, email)
+	}
+
+	user, err := c.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil {
+		created := entities.NewUser(
+			valueobjects.GenerateUserId(),
+			r.Name,
+			email,
+			"",
+			make([]valueobjects.Identity, 0),
+			make([]entities.Membership, 0),
+		)
+
+		err = c.userRepo.Insert(ctx, &created)
+		if err != nil {
+			return "", fmt.Errorf("could not create user: %s", err)
+		}
+
+		user = &created
+	}
+
+	if organization.FindMemberByUserId(user.Id()) != nil {
+		return "", fmt.Errorf("%w: %s is already a member of the organization", entities.ErrConflict, user.Email())
+	}
+
+	organization.AddMember(entities.NewMembership(
+		valueobjects.GenerateMembershipId(),
+		user.Id(),
+		organization.Id(),
+		scimMemberLevel,
+		make([]valueobjects.UserRole, 0),
+		make([]valueobjects.UserGroup, 0),
+	))
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not add user to organization: %s", err)
+	}
+
+	return user.Id().Value(), nil
+}
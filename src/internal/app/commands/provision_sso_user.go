@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type ProvisionSsoUserRequest struct {
+	ConnectionId  string `json:"connection_id"`
+	Subject       string `json:"subject"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+// ProvisionSsoUserResponse holds the user signed in, or the token of a
+// link to confirm when the email belongs to an existing account.
+type ProvisionSsoUserResponse struct {
+	UserId    string
+	LinkToken string
+}
+
+type ProvisionSsoUserCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+	ssoRepo  repositories.SsoConnectionRepository
+	linkRepo repositories.IdentityLinkRepository
+}
+
+func NewProvisionSsoUserCommand(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+	linkRepo repositories.IdentityLinkRepository,
+) *ProvisionSsoUserCommand {
+	return &ProvisionSsoUserCommand{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		ssoRepo:  ssoRepo,
+		linkRepo: linkRepo,
+	}
+}
+
+// Execute runs on every login through an SSO connection. On the first
+// login the user is created and joins the organization as a member. The
+// email must be verified by the identity provider and be in a verified
+// domain of the connection.
+//
+// When a member of the organization already has the email, the identity
+// is not linked right away: the email alone does not prove the login and
+// the account belong to the same person. A link is requested instead,
+// which the member confirms after signing in with their existing method.
+// An account outside the organization is never linked.
+func (c *ProvisionSsoUserCommand) Execute(ctx context.Context, r ProvisionSsoUserRequest) (ProvisionSsoUserResponse, error) {
+
+	id, err := valueobjects.NewSsoConnectionId(r.ConnectionId)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	connection, err := c.ssoRepo.FindById(ctx, id)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, err
+	}
+	if connection == nil {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("could not find SSO connection: %w", entities.ErrNotFound)
+	}
+
+	idpId := connection.Subject(r.Subject)
+
+	user, err := c.userRepo.FindByIdentity(ctx, idpId)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, err
+	}
+	if user != nil {
+		return ProvisionSsoUserResponse{UserId: user.Id().Value()}, nil
+	}
+
+	organization, err := findOrganization(ctx, c.orgRepo, connection.OrganizationId().Value())
+	if err != nil {
+		return ProvisionSsoUserResponse{}, err
+	}
+
+	email := entities.NormalizeEmail(r.Email)
+	if !r.EmailVerified {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("%w: the identity provider did not verify %s", entities.ErrForbidden, email)
+	}
+	if !connection.AllowsEmail(email) {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("%w: %s is not in a verified domain of the SSO connection", entities.ErrForbidden, email)
+	}
+
+	user, err = c.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, err
+	}
+
+	if user != nil {
+		if organization.FindMemberByUserId(user.Id()) == nil {
+			return ProvisionSsoUserResponse{}, fmt.Errorf("%w: %s already has an account, sign in with it", entities.ErrConflict, email)
+		}
+
+		link, token, err := entities.RequestIdentityLink(user.Id(), organization.Id(), idpId)
+		if err != nil {
+			return ProvisionSsoUserResponse{}, err
+		}
+
+		err = c.linkRepo.Insert(ctx, &link)
+		if err != nil {
+			return ProvisionSsoUserResponse{}, fmt.Errorf("could not request identity link: %s", err)
+		}
+
+		return ProvisionSsoUserResponse{LinkToken: token}, nil
+	}
+
+	newId := valueobjects.GenerateUserId()
+
+	membership, err := connection.Provision(newId, email)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, err
+	}
+
+	created := entities.NewUser(
+		newId,
+		r.Name,
+		email,
+		r.Picture,
+		[]valueobjects.Identity{valueobjects.NewIdentity(idpId, newId)},
+		make([]entities.Membership, 0),
+	)
+
+	err = c.userRepo.Insert(ctx, &created)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("could not create user: %s", err)
+	}
+
+	organization.AddMember(membership)
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return ProvisionSsoUserResponse{}, fmt.Errorf("could not add user to organization: %s", err)
+	}
+
+	return ProvisionSsoUserResponse{UserId: newId.Value()}, nil
+}
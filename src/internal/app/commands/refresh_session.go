@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	IpAddress    string `json:"-"`
+}
+
+// RefreshSessionResponse carries the next refresh token and describes the
+// user, for the claims of their new access token.
+type RefreshSessionResponse struct {
+	SessionResponse
+	UserId  string
+	Email   string
+	Name    string
+	Picture string
+}
+
+type RefreshSessionCommand struct {
+	sessionRepo repositories.SessionRepository
+	userRepo    repositories.UserRepository
+	mfaRepo     repositories.MfaFactorRepository
+}
+
+func NewRefreshSessionCommand(
+	sessionRepo repositories.SessionRepository,
+	userRepo repositories.UserRepository,
+	mfaRepo repositories.MfaFactorRepository,
+) *RefreshSessionCommand {
+	return &RefreshSessionCommand{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		mfaRepo:     mfaRepo,
+	}
+}
+
+// Execute exchanges a refresh token for the next one. Unknown tokens and
+// tokens of ended sessions fail with entities.ErrInvalidRefreshToken, as
+// does a token that was already exchanged, even by a concurrent request,
+// which also revokes its session.
+// A session without a verified second factor becomes pending once one is
+// required, e.g. after an organization of the user started requiring it.
+func (c *RefreshSessionCommand) Execute(ctx context.Context, r RefreshSessionRequest) (RefreshSessionResponse, error) {
+	session, err := c.sessionRepo.FindByRefreshToken(ctx, valueobjects.NewTokenHash(r.RefreshToken))
+	if err != nil {
+		return RefreshSessionResponse{}, err
+	}
+	if session == nil {
+		return RefreshSessionResponse{}, entities.ErrInvalidRefreshToken
+	}
+
+	if session.MfaVerifiedAt() == nil && !session.MfaRequired() {
+		mfaRequired, err := c.mfaRepo.IsRequired(ctx, session.UserId())
+		if err != nil {
+			return RefreshSessionResponse{}, err
+		}
+		if mfaRequired {
+			session.RequireMfa()
+		}
+	}
+
+	tokenHash := valueobjects.NewTokenHash(r.RefreshToken)
+	refreshToken, err := session.Refresh(tokenHash, r.IpAddress, time.Now())
+
+	// A detected reuse revokes the session, which is stored.
+	if err != nil {
+		if len(session.Events()) > 0 {
+			if err := c.sessionRepo.Update(ctx, session); err != nil {
+				return RefreshSessionResponse{}, fmt.Errorf("could not revoke session: %s", err)
+			}
+		}
+
+		return RefreshSessionResponse{}, err
+	}
+
+	err = c.sessionRepo.Rotate(ctx, session, tokenHash)
+	if errors.Is(err, entities.ErrInvalidRefreshToken) {
+		return RefreshSessionResponse{}, c.revokeReused(ctx, session.Id())
+	}
+	if err != nil {
+		return RefreshSessionResponse{}, fmt.Errorf("could not refresh session: %s", err)
+	}
+
+	user, err := c.userRepo.FindById(ctx, session.UserId())
+	if err != nil {
+		return RefreshSessionResponse{}, err
+	}
+	if user == nil {
+		return RefreshSessionResponse{}, fmt.Errorf("could not find user: %w", entities.ErrNotFound)
+	}
+
+	return RefreshSessionResponse{
+		SessionResponse: newSessionResponse(session, refreshToken),
+		UserId:          user.Id().Value(),
+		Email:           user.Email(),
+		Name:            user.Name(),
+		Picture:         user.Picture(),
+	}, nil
+}
+
+// revokeReused revokes a session whose refresh token was exchanged by a
+// concurrent request in the meantime: the same token was used twice.
+func (c *RefreshSessionCommand) revokeReused(ctx context.Context, id valueobjects.SessionId) error {
+	session, err := c.sessionRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.Revoke(entities.SessionRefreshTokenReuse) != nil {
+		return entities.ErrInvalidRefreshToken
+	}
+
+	err = c.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return fmt.Errorf("could not revoke session: %s", err)
+	}
+
+	return entities.ErrInvalidRefreshToken
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"time"
+)
+
+type RegenerateRecoveryCodesRequest struct {
+	UserId string `json:"-"`
+	Code   string `json:"code"`
+}
+
+type RegenerateRecoveryCodesCommand struct {
+	mfaRepo repositories.MfaFactorRepository
+}
+
+func NewRegenerateRecoveryCodesCommand(
+	mfaRepo repositories.MfaFactorRepository,
+) *RegenerateRecoveryCodesCommand {
+	return &RegenerateRecoveryCodesCommand{
+		mfaRepo: mfaRepo,
+	}
+}
+
+// Execute replaces the recovery codes of the user, given a valid code.
+func (c *RegenerateRecoveryCodesCommand) Execute(ctx context.Context, r RegenerateRecoveryCodesRequest) (recoveryCodes []string, err error) {
+	factor, err := findMfaFactor(ctx, c.mfaRepo, r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err = factor.RegenerateRecoveryCodes(r.Code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.mfaRepo.Update(ctx, factor)
+	if errors.Is(err, entities.ErrInvalidMfaCode) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not regenerate recovery codes: %s", err)
+	}
+
+	return recoveryCodes, nil
+}
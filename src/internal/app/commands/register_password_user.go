@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"strings"
+)
+
+type RegisterPasswordUserRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type RegisterPasswordUserCommand struct {
+	userRepo repositories.UserRepository
+	credRepo repositories.CredentialRepository
+}
+
+func NewRegisterPasswordUserCommand(
+	userRepo repositories.UserRepository,
+	credRepo repositories.CredentialRepository,
+) *RegisterPasswordUserCommand {
+	return &RegisterPasswordUserCommand{
+		userRepo: userRepo,
+		credRepo: credRepo,
+	}
+}
+
+// Execute creates a user who signs in with a password instead of an
+// external identity provider.
+func (c *RegisterPasswordUserCommand) Execute(ctx context.Context, r RegisterPasswordUserRequest) (userId string, err error) {
+	email := entities.NormalizeEmail(r.Email)
+
+	existing, err := c.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("%w: a user with this email already exists", entities.ErrConflict)
+	}
+
+	id := valueobjects.GenerateUserId()
+
+	credential, err := entities.CreateCredential(id, email, r.Password)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		name = email
+	}
+
+	user := entities.NewUser(
+		id,
+		name,
+		email,
+		"",
+		[]valueobjects.Identity{valueobjects.NewIdentity(credential.Subject(), id)},
+		make([]entities.Membership, 0),
+	)
+
+	err = c.userRepo.Insert(ctx, &user)
+	if err != nil {
+		return "", fmt.Errorf("could not create user: %s", err)
+	}
+
+	err = c.credRepo.Insert(ctx, &credential)
+	if err != nil {
+		return "", fmt.Errorf("could not create password credential: %s", err)
+	}
+
+	return id.Value(), nil
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type RemoveMemberRequest struct {
+	MembershipId   string `json:"user_org_id"`
+	OrganizationId string `json:"organization_id"`
+	// ActorUserId is the user removing the member, if any; provisioning
+	// clients act without one.
+	ActorUserId string `json:"-"`
+}
+
+type RemoveMemberCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewRemoveMemberCommand(
+	orgRepo repositories.OrganizationRepository,
+) *RemoveMemberCommand {
+	return &RemoveMemberCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute removes the member from the organization together with its
+// roles and groups. Only owners can remove an owner.
+func (c *RemoveMemberCommand) Execute(ctx context.Context, r RemoveMemberRequest) (membershipId string, err error) {
+
+	organization, err := c.orgRepo.FindById(ctx, r.OrganizationId)
+	if err != nil || organization == nil {
+		return "", fmt.Errorf("could not find organization")
+	}
+
+	memberId, err := valueobjects.NewMembershipId(r.MembershipId)
+	if err != nil {
+		return "", err
+	}
+
+	var by valueobjects.MembershipId
+	if r.ActorUserId != "" {
+		actor, err := findActingMember(organization, r.ActorUserId)
+		if err != nil {
+			return "", err
+		}
+		by = actor.Id()
+	}
+
+	err = organization.RemoveMember(by, memberId)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not remove user: %s", err)
+	}
+
+	return memberId.Value(), nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type RemovePermissionRequest struct {
+	ApplicationId string `json:"application_id"`
+	PermissionId  string `json:"permission_id"`
+}
+
+type RemovePermissionCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewRemovePermissionCommand(
+	appRepo repositories.ApplicationRepository,
+) *RemovePermissionCommand {
+	return &RemovePermissionCommand{
+		appRepo: appRepo,
+	}
+}
+
+// Execute removes the permission from the application and from every role
+// it was attached to.
+func (c *RemovePermissionCommand) Execute(ctx context.Context, r RemovePermissionRequest) (permissionId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := parsePermissionId(r.PermissionId)
+	if err != nil {
+		return "", err
+	}
+
+	err = application.RemovePermission(id)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.appRepo.Update(ctx, application)
+	if err != nil {
+		return "", fmt.Errorf("could not update application: %s", err)
+	}
+
+	return id.Value(), nil
+}
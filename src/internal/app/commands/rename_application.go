@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type RenameApplicationRequest struct {
+	ApplicationId string `json:"application_id"`
+	Name          string `json:"name"`
+}
+
+type RenameApplicationCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewRenameApplicationCommand(
+	appRepo repositories.ApplicationRepository,
+) *RenameApplicationCommand {
+	return &RenameApplicationCommand{
+		appRepo: appRepo,
+	}
+}
+
+func (c *RenameApplicationCommand) Execute(ctx context.Context, r RenameApplicationRequest) (applicationId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	err = application.Rename(r.Name)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.appRepo.Update(ctx, application)
+	if err != nil {
+		return "", fmt.Errorf("could not update application: %s", err)
+	}
+
+	return application.Id().Value(), nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type RenamePermissionRequest struct {
+	ApplicationId string `json:"application_id"`
+	PermissionId  string `json:"permission_id"`
+	Name          string `json:"name"`
+}
+
+type RenamePermissionCommand struct {
+	appRepo repositories.ApplicationRepository
+}
+
+func NewRenamePermissionCommand(
+	appRepo repositories.ApplicationRepository,
+) *RenamePermissionCommand {
+	return &RenamePermissionCommand{
+		appRepo: appRepo,
+	}
+}
+
+func (c *RenamePermissionCommand) Execute(ctx context.Context, r RenamePermissionRequest) (permissionId string, err error) {
+	application, err := findApplication(ctx, c.appRepo, r.ApplicationId)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := parsePermissionId(r.PermissionId)
+	if err != nil {
+		return "", err
+	}
+
+	err = application.RenamePermission(id, r.Name)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.appRepo.Update(ctx, application)
+	if err != nil {
+		return "", fmt.Errorf("could not update application: %s", err)
+	}
+
+	return id.Value(), nil
+}
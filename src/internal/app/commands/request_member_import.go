@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+type RequestMemberImportRequest struct {
+	OrganizationId string                     `json:"organization_id"`
+	Rows           []entities.MemberImportRow `json:"rows"`
+	DryRun         bool                       `json:"dry_run"`
+	ActorUserId    string                     `json:"-"`
+}
+
+type RequestMemberImportCommand struct {
+	orgRepo    repositories.OrganizationRepository
+	importRepo repositories.MemberImportRepository
+}
+
+func NewRequestMemberImportCommand(
+	orgRepo repositories.OrganizationRepository,
+	importRepo repositories.MemberImportRepository,
+) *RequestMemberImportCommand {
+	return &RequestMemberImportCommand{
+		orgRepo:    orgRepo,
+		importRepo: importRepo,
+	}
+}
+
+// Execute queues the import and returns its id. The rows are checked when
+// the import runs, see RunMemberImportCommand.
+func (c *RequestMemberImportCommand) Execute(ctx context.Context, r RequestMemberImportRequest) (string, error) {
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	actor, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	memberImport, err := entities.RequestMemberImport(organization.Id(), actor.UserId(), r.Rows, r.DryRun)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.importRepo.Insert(ctx, &memberImport)
+	if err != nil {
+		return "", fmt.Errorf("could not queue member import: %s", err)
+	}
+
+	return memberImport.Id().Value(), nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type RequestOwnershipTransferRequest struct {
+	OrganizationId string `json:"organization_id"`
+	MembershipId   string `json:"user_org_id"`
+	ActorUserId    string `json:"-"`
+}
+
+type RequestOwnershipTransferCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewRequestOwnershipTransferCommand(
+	orgRepo repositories.OrganizationRepository,
+) *RequestOwnershipTransferCommand {
+	return &RequestOwnershipTransferCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute records a pending transfer of the acting owner's ownership to
+// another member and returns its id, which the owner uses to confirm it.
+func (c *RequestOwnershipTransferCommand) Execute(ctx context.Context, r RequestOwnershipTransferRequest) (transferId string, err error) {
+
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	actor, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	memberId, err := parseMembershipId(r.MembershipId)
+	if err != nil {
+		return "", err
+	}
+
+	transfer, err := organization.RequestOwnershipTransfer(actor.Id(), memberId, ownershipTransferTTL)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not request ownership transfer: %s", err)
+	}
+
+	return transfer.Id().Value(), nil
+}
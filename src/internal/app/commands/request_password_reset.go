@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/app/mail"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"net/url"
+	"strings"
+)
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type RequestPasswordResetCommand struct {
+	credRepo repositories.CredentialRepository
+	mailer   mail.Mailer
+	resetUrl string
+}
+
+// NewRequestPasswordResetCommand creates the command. resetUrl is the page
+// that receives the reset token in its "token" query parameter.
+func NewRequestPasswordResetCommand(
+	credRepo repositories.CredentialRepository,
+	mailer mail.Mailer,
+	resetUrl string,
+) *RequestPasswordResetCommand {
+	return &RequestPasswordResetCommand{
+		credRepo: credRepo,
+		mailer:   mailer,
+		resetUrl: resetUrl,
+	}
+}
+
+// Execute emails a password reset token to the owner of the email. It
+// returns no error for an email without a credential, so that callers
+// cannot find out who has an account; resetId is then empty.
+func (c *RequestPasswordResetCommand) Execute(ctx context.Context, r RequestPasswordResetRequest) (resetId string, err error) {
+	credential, err := c.credRepo.FindByEmail(ctx, r.Email)
+	if err != nil {
+		return "", err
+	}
+	if credential == nil {
+		return "", nil
+	}
+
+	reset, token, err := entities.RequestPasswordReset(credential, passwordResetTTL)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.credRepo.InsertReset(ctx, &reset)
+	if err != nil {
+		return "", fmt.Errorf("could not create password reset: %s", err)
+	}
+
+	err = c.mailer.Send(ctx, mail.Message{
+		To:      credential.Email(),
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your account. If it was you, choose a new password: %s\n\nThe link expires on %s. If you did not ask for it, ignore this email.\n",
+			c.link(token), reset.ExpiresAt().UTC().Format("January 2, 2006 15:04 MST"),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not send password reset: %s", err)
+	}
+
+	return reset.Id().Value(), nil
+}
+
+func (c *RequestPasswordResetCommand) link(token string) string {
+	separator := "?"
+	if strings.Contains(c.resetUrl, "?") {
+		separator = "&"
+	}
+
+	return c.resetUrl + separator + url.Values{"token": {token}}.Encode()
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ResetPasswordCommand struct {
+	credRepo    repositories.CredentialRepository
+	sessionRepo repositories.SessionRepository
+}
+
+func NewResetPasswordCommand(
+	credRepo repositories.CredentialRepository,
+	sessionRepo repositories.SessionRepository,
+) *ResetPasswordCommand {
+	return &ResetPasswordCommand{
+		credRepo:    credRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute sets a new password with the token of a password reset. Whoever
+// knew the old password may still be signed in, so every session of the
+// user ends.
+func (c *ResetPasswordCommand) Execute(ctx context.Context, r ResetPasswordRequest) (userId string, err error) {
+	reset, err := c.credRepo.FindResetByToken(ctx, valueobjects.NewTokenHash(r.Token))
+	if err != nil {
+		return "", err
+	}
+	if reset == nil {
+		return "", fmt.Errorf("could not find password reset: %w", entities.ErrNotFound)
+	}
+
+	credential, err := c.credRepo.FindById(ctx, reset.CredentialId())
+	if err != nil {
+		return "", err
+	}
+	if credential == nil {
+		return "", fmt.Errorf("could not find password credential: %w", entities.ErrNotFound)
+	}
+
+	err = credential.ResetPassword(reset, r.Password, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	err = c.credRepo.UpdateWithReset(ctx, credential, reset)
+	if err != nil {
+		return "", fmt.Errorf("could not reset password: %s", err)
+	}
+
+	_, err = revokeSessions(ctx, c.sessionRepo, credential.UserId(), "", entities.SessionPasswordReset)
+	if err != nil {
+		return "", err
+	}
+
+	return credential.UserId().Value(), nil
+}
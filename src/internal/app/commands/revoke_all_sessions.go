@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type RevokeAllSessionsRequest struct {
+	UserId string `json:"-"`
+	// ExceptSessionId keeps one session, usually the one making the
+	// request, signed in.
+	ExceptSessionId string `json:"-"`
+}
+
+type RevokeAllSessionsCommand struct {
+	sessionRepo repositories.SessionRepository
+}
+
+func NewRevokeAllSessionsCommand(
+	sessionRepo repositories.SessionRepository,
+) *RevokeAllSessionsCommand {
+	return &RevokeAllSessionsCommand{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute signs the user out on every device and returns the ids of the
+// sessions it ended.
+func (c *RevokeAllSessionsCommand) Execute(ctx context.Context, r RevokeAllSessionsRequest) (sessionIds []string, err error) {
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	return revokeSessions(ctx, c.sessionRepo, userId, r.ExceptSessionId, entities.SessionRevokedByUser)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type RevokeApiClientRequest struct {
+	ClientId string
+}
+
+type RevokeApiClientCommand struct {
+	clientRepo repositories.ApiClientRepository
+}
+
+func NewRevokeApiClientCommand(
+	clientRepo repositories.ApiClientRepository,
+) *RevokeApiClientCommand {
+	return &RevokeApiClientCommand{
+		clientRepo: clientRepo,
+	}
+}
+
+func (c *RevokeApiClientCommand) Execute(ctx context.Context, r RevokeApiClientRequest) (clientId string, err error) {
+	id, err := valueobjects.NewApiClientId(r.ClientId)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := c.clientRepo.FindById(ctx, id)
+	if err != nil || client == nil {
+		return "", fmt.Errorf("could not find client")
+	}
+
+	client.Revoke()
+
+	err = c.clientRepo.Update(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("could not revoke client")
+	}
+
+	return id.Value(), nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"strings"
+)
+
+type RevokeInvitationRequest struct {
+	OrganizationId string `json:"organization_id"`
+	InvitationId   string `json:"invitation_id"`
+}
+
+type RevokeInvitationCommand struct {
+	invRepo repositories.InvitationRepository
+}
+
+func NewRevokeInvitationCommand(
+	invRepo repositories.InvitationRepository,
+) *RevokeInvitationCommand {
+	return &RevokeInvitationCommand{
+		invRepo: invRepo,
+	}
+}
+
+func (c *RevokeInvitationCommand) Execute(ctx context.Context, r RevokeInvitationRequest) (invitationId string, err error) {
+
+	id, err := valueobjects.NewInvitationId(r.InvitationId)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	invitation, err := c.invRepo.FindById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if invitation == nil || !strings.EqualFold(invitation.OrganizationId().Value(), r.OrganizationId) {
+		return "", fmt.Errorf("could not find invitation: %w", entities.ErrNotFound)
+	}
+
+	err = invitation.Revoke()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.invRepo.Update(ctx, invitation)
+	if err != nil {
+		return "", fmt.Errorf("could not revoke invitation: %w", err)
+	}
+
+	return invitation.Id().Value(), nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type RevokePersonalAccessTokenRequest struct {
+	TokenId string
+	UserId  string
+}
+
+type RevokePersonalAccessTokenCommand struct {
+	patRepo repositories.PersonalAccessTokenRepository
+}
+
+func NewRevokePersonalAccessTokenCommand(
+	patRepo repositories.PersonalAccessTokenRepository,
+) *RevokePersonalAccessTokenCommand {
+	return &RevokePersonalAccessTokenCommand{
+		patRepo: patRepo,
+	}
+}
+
+// Execute revokes a personal access token of the user. Tokens of other
+// users are reported as not found.
+func (c *RevokePersonalAccessTokenCommand) Execute(ctx context.Context, r RevokePersonalAccessTokenRequest) (tokenId string, err error) {
+	id, err := valueobjects.NewPersonalAccessTokenId(r.TokenId)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	token, err := c.patRepo.FindById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.UserId().Value() != r.UserId {
+		return "", fmt.Errorf("could not find personal access token: %w", entities.ErrNotFound)
+	}
+
+	err = token.Revoke()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.patRepo.Update(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("could not revoke personal access token: %s", err)
+	}
+
+	return token.Id().Value(), nil
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type RevokeScimTokenRequest struct {
+	OrganizationId string `json:"organization_id"`
+	TokenId        string `json:"token_id"`
+}
+
+type RevokeScimTokenCommand struct {
+	scimRepo repositories.ScimTokenRepository
+}
+
+func NewRevokeScimTokenCommand(
+	scimRepo repositories.ScimTokenRepository,
+) *RevokeScimTokenCommand {
+	return &RevokeScimTokenCommand{
+		scimRepo: scimRepo,
+	}
+}
+
+func (c *RevokeScimTokenCommand) Execute(ctx context.Context, r RevokeScimTokenRequest) (tokenId string, err error) {
+
+	id, err := valueobjects.NewScimTokenId(r.TokenId)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entities.ErrInvalid, err)
+	}
+
+	token, err := c.scimRepo.FindById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.OrganizationId().Value() != r.OrganizationId {
+		return "", fmt.Errorf("could not find SCIM token: %w", entities.ErrNotFound)
+	}
+
+	err = token.Revoke()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.scimRepo.Update(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("could not revoke SCIM token: %s", err)
+	}
+
+	return token.Id().Value(), nil
+}
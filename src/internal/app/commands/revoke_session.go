@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type RevokeSessionRequest struct {
+	SessionId string `json:"-"`
+	UserId    string `json:"-"`
+	// Reason is recorded with the revocation, e.g. entities.SessionSignedOut.
+	Reason string `json:"-"`
+}
+
+type RevokeSessionCommand struct {
+	sessionRepo repositories.SessionRepository
+}
+
+func NewRevokeSessionCommand(
+	sessionRepo repositories.SessionRepository,
+) *RevokeSessionCommand {
+	return &RevokeSessionCommand{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute ends a session of the user. Its access tokens are refused from
+// then on and its refresh token can no longer be used.
+func (c *RevokeSessionCommand) Execute(ctx context.Context, r RevokeSessionRequest) (sessionId string, err error) {
+	session, err := findUserSession(ctx, c.sessionRepo, r.SessionId, r.UserId)
+	if err != nil {
+		return "", err
+	}
+
+	err = session.Revoke(r.Reason)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return "", fmt.Errorf("could not revoke session: %s", err)
+	}
+
+	return session.Id().Value(), nil
+}
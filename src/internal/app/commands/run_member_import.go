@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"strings"
+)
+
+type RunMemberImportCommand struct {
+	importRepo repositories.MemberImportRepository
+	orgRepo    repositories.OrganizationRepository
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+	groupRepo  repositories.GroupRepository
+}
+
+func NewRunMemberImportCommand(
+	importRepo repositories.MemberImportRepository,
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	groupRepo repositories.GroupRepository,
+) *RunMemberImportCommand {
+	return &RunMemberImportCommand{
+		importRepo: importRepo,
+		orgRepo:    orgRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		groupRepo:  groupRepo,
+	}
+}
+
+// memberImportProgressEvery is how many rows are applied between two
+// reports of progress.
+const memberImportProgressEvery = 100
+
+// Execute runs a pending import. Every row is applied to the organization
+// in turn, so later rows see the members added by earlier ones, and the
+// outcome of each is recorded. Rows that fail are skipped. The rows that
+// succeed are saved together, unless the import is a dry run.
+//
+// Execute may be repeated: an import that finished is left as is, and
+// one that was interrupted runs again, finding the members it already
+// saved unchanged. Failing to save returns the error without failing the
+// import, so that it can be retried.
+func (c *RunMemberImportCommand) Execute(ctx context.Context, importId string, progress func(done int, total int)) error {
+	memberImport, err := c.find(ctx, importId)
+	if err != nil {
+		return err
+	}
+	if memberImport.IsFinished() {
+		return nil
+	}
+
+	err = memberImport.Start()
+	if err != nil {
+		return err
+	}
+
+	err = c.importRepo.Update(ctx, memberImport)
+	if err != nil {
+		return fmt.Errorf("could not start member import: %s", err)
+	}
+
+	organization, err := c.orgRepo.FindById(ctx, memberImport.OrganizationId().Value())
+	if err != nil {
+		return fmt.Errorf("could not find organization: %s", err)
+	}
+	if organization == nil {
+		return c.fail(ctx, memberImport, fmt.Errorf("could not find organization: %w", entities.ErrNotFound))
+	}
+
+	catalog := memberImportCatalog{
+		roleRepo:  c.roleRepo,
+		groupRepo: c.groupRepo,
+		roles:     make(map[string]*entities.Role),
+		groups:    make(map[string]*entities.Group),
+	}
+
+	total := len(memberImport.Rows())
+	results := make([]entities.MemberImportResult, 0, total)
+	for i, row := range memberImport.Rows() {
+		result := entities.MemberImportResult{Row: i + 1, Email: row.Email}
+
+		membershipId, outcome, err := c.apply(ctx, organization, &catalog, row)
+		if err != nil {
+			result.Status = entities.MemberImportRowFailed
+			result.Error = err.Error()
+		} else {
+			result.Status = outcome
+			result.MembershipId = membershipId
+		}
+
+		results = append(results, result)
+
+		if progress != nil && (i+1)%memberImportProgressEvery == 0 {
+			progress(i+1, total)
+		}
+	}
+
+	if !memberImport.DryRun() {
+		err = c.orgRepo.Update(ctx, organization)
+		if err != nil {
+			return fmt.Errorf("could not save members: %s", err)
+		}
+	}
+
+	err = memberImport.Complete(results)
+	if err != nil {
+		return err
+	}
+
+	err = c.importRepo.Update(ctx, memberImport)
+	if err != nil {
+		return fmt.Errorf("could not complete member import: %s", err)
+	}
+
+	if progress != nil {
+		progress(total, total)
+	}
+
+	return nil
+}
+
+// Abandon fails an import that will not be run again, such as one whose
+// job ran out of attempts.
+func (c *RunMemberImportCommand) Abandon(ctx context.Context, importId string, reason string) error {
+	memberImport, err := c.find(ctx, importId)
+	if err != nil {
+		return err
+	}
+	if memberImport.IsFinished() {
+		return nil
+	}
+
+	memberImport.Fail(reason)
+
+	err = c.importRepo.Update(ctx, memberImport)
+	if err != nil {
+		return fmt.Errorf("could not fail member import: %s", err)
+	}
+
+	return nil
+}
+
+func (c *RunMemberImportCommand) find(ctx context.Context, importId string) (*entities.MemberImport, error) {
+	id, err := valueobjects.NewMemberImportId(importId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	memberImport, err := c.importRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find member import: %s", err)
+	}
+	if memberImport == nil {
+		return nil, fmt.Errorf("could not find member import: %w", entities.ErrNotFound)
+	}
+
+	return memberImport, nil
+}
+
+func (c *RunMemberImportCommand) fail(ctx context.Context, memberImport *entities.MemberImport, cause error) error {
+	memberImport.Fail(cause.Error())
+
+	err := c.importRepo.Update(ctx, memberImport)
+	if err != nil {
+		return fmt.Errorf("could not fail member import: %s", err)
+	}
+
+	return cause
+}
+
+// apply looks up the user, roles and groups of a row and imports it into
+// the organization, which checks the whole row before changing anything.
+func (c *RunMemberImportCommand) apply(ctx context.Context, organization *entities.Organization, catalog *memberImportCatalog, row entities.MemberImportRow) (string, string, error) {
+	if row.Email == "" {
+		return "", "", fmt.Errorf("%w: email is required", entities.ErrInvalid)
+	}
+
+	user, err := c.userRepo.FindByEmail(ctx, row.Email)
+	if err != nil {
+		return "", "", fmt.Errorf("could not find user: %s", err)
+	}
+	if user == nil {
+		return "", "", fmt.Errorf("%w: no user with email %s", entities.ErrNotFound, row.Email)
+	}
+
+	var tenantId *valueobjects.TenantId
+	if row.TenantId != "" {
+		id, err := valueobjects.NewTenantId(row.TenantId)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: invalid tenant %s", entities.ErrInvalid, row.TenantId)
+		}
+		tenantId = &id
+	}
+
+	roles := make([]*entities.Role, 0, len(row.Roles))
+	for _, id := range row.Roles {
+		role, err := catalog.role(ctx, id)
+		if err != nil {
+			return "", "", fmt.Errorf("role %s: %w", id, err)
+		}
+		roles = append(roles, role)
+	}
+
+	groups := make([]*entities.Group, 0, len(row.Groups))
+	for _, id := range row.Groups {
+		group, err := catalog.group(ctx, id)
+		if err != nil {
+			return "", "", fmt.Errorf("group %s: %w", id, err)
+		}
+		groups = append(groups, group)
+	}
+
+	membershipId, outcome, err := organization.ImportMember(user.Id(), valueobjects.MembershipLevel(row.Level), tenantId, roles, groups)
+	if err != nil {
+		return "", "", err
+	}
+
+	return membershipId.Value(), outcome, nil
+}
+
+// memberImportCatalog loads each role and group of an import once.
+type memberImportCatalog struct {
+	roleRepo  repositories.RoleRepository
+	groupRepo repositories.GroupRepository
+	roles     map[string]*entities.Role
+	groups    map[string]*entities.Group
+}
+
+func (m *memberImportCatalog) role(ctx context.Context, id string) (*entities.Role, error) {
+	id = strings.ToLower(id)
+	if role, ok := m.roles[id]; ok {
+		return role, nil
+	}
+
+	role, err := findRole(ctx, m.roleRepo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.roles[id] = role
+	return role, nil
+}
+
+func (m *memberImportCatalog) group(ctx context.Context, id string) (*entities.Group, error) {
+	id = strings.ToLower(id)
+	if group, ok := m.groups[id]; ok {
+		return group, nil
+	}
+
+	group, err := findGroup(ctx, m.groupRepo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.groups[id] = group
+	return group, nil
+}
@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"errors"
+	"iyaem/internal/domain/valueobjects"
+)
+
+// Helpers shared by the commands behind the SCIM API.
+
+// ErrInvalidScimToken is returned for a SCIM token that is unknown or
+// revoked.
+var ErrInvalidScimToken = errors.New("invalid_scim_token")
+
+// scimMemberLevel is the level of users provisioned through SCIM.
+const scimMemberLevel = valueobjects.MembershipLevel("member")
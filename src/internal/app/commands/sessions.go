@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// Helpers shared by the commands that manage sessions.
+
+// sessionTTL is how long a session lasts, however often it is refreshed.
+const sessionTTL = 30 * 24 * time.Hour
+
+// SessionResponse describes a session for the access token issued for it.
+type SessionResponse struct {
+	SessionId    string
+	RefreshToken string
+	AuthMethods  []string
+	Acr          string
+	AuthTime     time.Time
+	MfaPending   bool
+}
+
+func newSessionResponse(session *entities.Session, refreshToken string) SessionResponse {
+	return SessionResponse{
+		SessionId:    session.Id().Value(),
+		RefreshToken: refreshToken,
+		AuthMethods:  session.AuthMethods(),
+		Acr:          session.Acr(),
+		AuthTime:     session.AuthTime(),
+		MfaPending:   session.MfaPending(),
+	}
+}
+
+// findUserSession returns the session of the user. Sessions of other users
+// are reported as not found.
+func findUserSession(ctx context.Context, sessionRepo repositories.SessionRepository, sessionId string, userId string) (*entities.Session, error) {
+	id, err := valueobjects.NewSessionId(sessionId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	session, err := sessionRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.UserId().Value() != userId {
+		return nil, fmt.Errorf("could not find session: %w", entities.ErrNotFound)
+	}
+
+	return session, nil
+}
+
+// revokeSessions ends the active sessions of the user except one, and
+// returns the ids of the sessions it ended.
+func revokeSessions(ctx context.Context, sessionRepo repositories.SessionRepository, userId valueobjects.UserId, exceptSessionId string, reason string) ([]string, error) {
+	sessions, err := sessionRepo.FindActiveByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds := make([]string, 0, len(sessions))
+	for i := range sessions {
+		session := &sessions[i]
+		if session.Id().Value() == exceptSessionId {
+			continue
+		}
+
+		err = session.Revoke(reason)
+		if err != nil {
+			return nil, err
+		}
+
+		err = sessionRepo.Update(ctx, session)
+		if err != nil {
+			return nil, fmt.Errorf("could not revoke session: %s", err)
+		}
+
+		sessionIds = append(sessionIds, session.Id().Value())
+	}
+
+	return sessionIds, nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type SetOrganizationMfaRequest struct {
+	OrganizationId string `json:"organization_id"`
+	Required       bool   `json:"required"`
+	ActorUserId    string `json:"-"`
+}
+
+type SetOrganizationMfaCommand struct {
+	orgRepo repositories.OrganizationRepository
+}
+
+func NewSetOrganizationMfaCommand(
+	orgRepo repositories.OrganizationRepository,
+) *SetOrganizationMfaCommand {
+	return &SetOrganizationMfaCommand{
+		orgRepo: orgRepo,
+	}
+}
+
+// Execute decides whether the members of the organization must sign in
+// with a second factor. The acting user must be an owner. Members who
+// have not enrolled one are asked to when they next sign in or refresh
+// their session.
+func (c *SetOrganizationMfaCommand) Execute(ctx context.Context, r SetOrganizationMfaRequest) (organizationId string, err error) {
+	organization, err := findOrganization(ctx, c.orgRepo, r.OrganizationId)
+	if err != nil {
+		return "", err
+	}
+
+	actor, err := findActingMember(organization, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	err = organization.SetMfaRequired(actor.Id(), r.Required)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.orgRepo.Update(ctx, organization)
+	if err != nil {
+		return "", fmt.Errorf("could not change the multi-factor authentication policy: %s", err)
+	}
+
+	return organization.Id().Value(), nil
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+// Helpers shared by the commands that manage the SSO connection of an
+// organization.
+
+// requireOwningMember checks that the user making the request owns the
+// organization, as the connection decides who can sign in to it.
+func requireOwningMember(ctx context.Context, orgRepo repositories.OrganizationRepository, organizationId string, userId string) (*entities.Organization, error) {
+	organization, err := findOrganization(ctx, orgRepo, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := findActingMember(organization, userId)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsOwner() {
+		return nil, fmt.Errorf("%w: only owners can manage SSO", entities.ErrForbidden)
+	}
+
+	return organization, nil
+}
+
+func findSsoConnection(ctx context.Context, ssoRepo repositories.SsoConnectionRepository, organizationId string) (*entities.SsoConnection, error) {
+	connection, err := ssoRepo.FindByOrganization(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	if connection == nil {
+		return nil, fmt.Errorf("could not find SSO connection: %w", entities.ErrNotFound)
+	}
+
+	return connection, nil
+}
+
+// requireFreeDomains checks that no other organization verified one of
+// the domains, so that it routes logins to its own connection. Unverified
+// claims do not conflict.
+func requireFreeDomains(ctx context.Context, ssoRepo repositories.SsoConnectionRepository, connection *entities.SsoConnection) error {
+	for _, domain := range connection.Domains() {
+		other, err := ssoRepo.FindByDomain(ctx, domain)
+		if err != nil {
+			return err
+		}
+		if other != nil && !other.Id().Equals(connection.Id()) {
+			return fmt.Errorf("%w: %s is used by another SSO connection", entities.ErrConflict, domain)
+		}
+	}
+
+	return nil
+}
+
+// ResolveSsoConnection finds the connection a login is routed to, by the
+// organization identifier or else by the domain of the email.
+func ResolveSsoConnection(
+	ctx context.Context,
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+	organizationIdentifier string,
+	email string,
+) (*entities.SsoConnection, error) {
+	var connection *entities.SsoConnection
+
+	switch {
+	case organizationIdentifier != "":
+		organization, err := orgRepo.FindByIdentifier(ctx, organizationIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		if organization != nil {
+			connection, err = ssoRepo.FindByOrganization(ctx, organization.Id().Value())
+			if err != nil {
+				return nil, err
+			}
+		}
+	case email != "":
+		var err error
+		connection, err = ssoRepo.FindByDomain(ctx, entities.EmailDomain(email))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: an organization or an email is required", entities.ErrInvalid)
+	}
+
+	if connection == nil {
+		return nil, fmt.Errorf("could not find SSO connection: %w", entities.ErrNotFound)
+	}
+
+	return connection, nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/domain/valueobjects"
+)
+
+type StartSessionRequest struct {
+	UserId     string
+	UserAgent  string
+	IpAddress  string
+	AuthMethod string
+}
+
+type StartSessionCommand struct {
+	sessionRepo repositories.SessionRepository
+	mfaRepo     repositories.MfaFactorRepository
+}
+
+func NewStartSessionCommand(
+	sessionRepo repositories.SessionRepository,
+	mfaRepo repositories.MfaFactorRepository,
+) *StartSessionCommand {
+	return &StartSessionCommand{
+		sessionRepo: sessionRepo,
+		mfaRepo:     mfaRepo,
+	}
+}
+
+// Execute records a sign in of the user on a device. The session waits for
+// a second factor when the user enabled one or an organization of theirs
+// requires it.
+func (c *StartSessionCommand) Execute(ctx context.Context, r StartSessionRequest) (SessionResponse, error) {
+	userId, err := valueobjects.NewUserId(r.UserId)
+	if err != nil {
+		return SessionResponse{}, fmt.Errorf("%w: %s", entities.ErrInvalid, err)
+	}
+
+	mfaRequired, err := c.mfaRepo.IsRequired(ctx, userId)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	session, refreshToken, err := entities.StartSession(userId, r.UserAgent, r.IpAddress, r.AuthMethod, mfaRequired, sessionTTL)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	err = c.sessionRepo.Insert(ctx, &session)
+	if err != nil {
+		return SessionResponse{}, fmt.Errorf("could not start session: %s", err)
+	}
+
+	return newSessionResponse(&session, refreshToken), nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type UpdateGroupRequest struct {
+	GroupId     string `json:"group_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TenantId    string `json:"tenant_id"`
+}
+
+type UpdateGroupCommand struct {
+	groupRepo repositories.GroupRepository
+}
+
+func NewUpdateGroupCommand(
+	groupRepo repositories.GroupRepository,
+) *UpdateGroupCommand {
+	return &UpdateGroupCommand{
+		groupRepo: groupRepo,
+	}
+}
+
+func (c *UpdateGroupCommand) Execute(ctx context.Context, r UpdateGroupRequest) (groupId string, err error) {
+	group, err := findGroup(ctx, c.groupRepo, r.GroupId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("group", group.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	err = group.Update(r.Name, r.Description)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.groupRepo.Update(ctx, group)
+	if err != nil {
+		return "", fmt.Errorf("could not update group: %s", err)
+	}
+
+	return group.Id().Value(), nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type UpdateRoleRequest struct {
+	RoleId      string `json:"role_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TenantId    string `json:"tenant_id"`
+}
+
+type UpdateRoleCommand struct {
+	roleRepo repositories.RoleRepository
+}
+
+func NewUpdateRoleCommand(
+	roleRepo repositories.RoleRepository,
+) *UpdateRoleCommand {
+	return &UpdateRoleCommand{
+		roleRepo: roleRepo,
+	}
+}
+
+func (c *UpdateRoleCommand) Execute(ctx context.Context, r UpdateRoleRequest) (roleId string, err error) {
+	role, err := findRole(ctx, c.roleRepo, r.RoleId)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireOwner("role", role.TenantId(), r.TenantId)
+	if err != nil {
+		return "", err
+	}
+
+	err = role.Update(r.Name, r.Description)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.roleRepo.Update(ctx, role)
+	if err != nil {
+		return "", fmt.Errorf("could not update role: %s", err)
+	}
+
+	return role.Id().Value(), nil
+}
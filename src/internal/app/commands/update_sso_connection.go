@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/repositories"
+)
+
+type UpdateSsoConnectionRequest struct {
+	OrganizationId string   `json:"organization_id"`
+	IssuerUrl      string   `json:"issuer_url"`
+	ClientId       string   `json:"client_id"`
+	ClientSecret   string   `json:"client_secret"`
+	Domains        []string `json:"domains"`
+	ActorUserId    string   `json:"-"`
+}
+
+type UpdateSsoConnectionCommand struct {
+	orgRepo repositories.OrganizationRepository
+	ssoRepo repositories.SsoConnectionRepository
+}
+
+func NewUpdateSsoConnectionCommand(
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+) *UpdateSsoConnectionCommand {
+	return &UpdateSsoConnectionCommand{
+		orgRepo: orgRepo,
+		ssoRepo: ssoRepo,
+	}
+}
+
+// Execute changes the settings of the organization's connection. An empty
+// client secret keeps the current one.
+func (c *UpdateSsoConnectionCommand) Execute(ctx context.Context, r UpdateSsoConnectionRequest) (connectionId string, err error) {
+
+	organization, err := requireOwningMember(ctx, c.orgRepo, r.OrganizationId, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	connection, err := findSsoConnection(ctx, c.ssoRepo, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+
+	err = connection.Update(r.IssuerUrl, r.ClientId, r.ClientSecret, r.Domains)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireFreeDomains(ctx, c.ssoRepo, connection)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.ssoRepo.Update(ctx, connection)
+	if err != nil {
+		return "", fmt.Errorf("could not update SSO connection: %s", err)
+	}
+
+	return connection.Id().Value(), nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	"time"
+)
+
+type VerifyMfaRequest struct {
+	UserId    string `json:"-"`
+	SessionId string `json:"-"`
+	Code      string `json:"code"`
+}
+
+type VerifyMfaCommand struct {
+	mfaRepo     repositories.MfaFactorRepository
+	sessionRepo repositories.SessionRepository
+}
+
+func NewVerifyMfaCommand(
+	mfaRepo repositories.MfaFactorRepository,
+	sessionRepo repositories.SessionRepository,
+) *VerifyMfaCommand {
+	return &VerifyMfaCommand{
+		mfaRepo:     mfaRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute checks a password from the authenticator, or a recovery code,
+// for the session of the user. It completes a pending sign in, or steps
+// up an active session for a sensitive action.
+func (c *VerifyMfaCommand) Execute(ctx context.Context, r VerifyMfaRequest) (SessionResponse, error) {
+	factor, err := findMfaFactor(ctx, c.mfaRepo, r.UserId)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	err = factor.Verify(r.Code, time.Now())
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	// The accepted code is stored first so that it cannot be replayed
+	// whatever happens to the session.
+	err = c.mfaRepo.Update(ctx, factor)
+	if errors.Is(err, entities.ErrInvalidMfaCode) {
+		return SessionResponse{}, err
+	}
+	if err != nil {
+		return SessionResponse{}, fmt.Errorf("could not update authenticator: %s", err)
+	}
+
+	session, err := verifySession(ctx, c.sessionRepo, r.SessionId, r.UserId)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	return newSessionResponse(session, ""), nil
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+)
+
+// TxtResolver looks up the TXT records of a DNS name. net.DefaultResolver
+// satisfies it.
+type TxtResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type VerifySsoDomainRequest struct {
+	OrganizationId string `json:"organization_id"`
+	Domain         string `json:"domain"`
+	ActorUserId    string `json:"-"`
+}
+
+type VerifySsoDomainCommand struct {
+	orgRepo  repositories.OrganizationRepository
+	ssoRepo  repositories.SsoConnectionRepository
+	resolver TxtResolver
+}
+
+func NewVerifySsoDomainCommand(
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+	resolver TxtResolver,
+) *VerifySsoDomainCommand {
+	return &VerifySsoDomainCommand{
+		orgRepo:  orgRepo,
+		ssoRepo:  ssoRepo,
+		resolver: resolver,
+	}
+}
+
+// Execute verifies a domain of the organization's connection by looking up
+// the TXT record holding its verification token. Until then the domain
+// neither routes logins nor vouches for the emails of users.
+func (c *VerifySsoDomainCommand) Execute(ctx context.Context, r VerifySsoDomainRequest) (connectionId string, err error) {
+
+	organization, err := requireOwningMember(ctx, c.orgRepo, r.OrganizationId, r.ActorUserId)
+	if err != nil {
+		return "", err
+	}
+
+	connection, err := findSsoConnection(ctx, c.ssoRepo, organization.Id().Value())
+	if err != nil {
+		return "", err
+	}
+
+	domain, err := connection.Domain(r.Domain)
+	if err != nil {
+		return "", err
+	}
+
+	records, err := c.resolver.LookupTXT(ctx, domain.TxtRecordName())
+	if err != nil {
+		return "", fmt.Errorf("%w: could not look up %s: %v", entities.ErrForbidden, domain.TxtRecordName(), err)
+	}
+
+	err = connection.VerifyDomain(domain.Name, records)
+	if err != nil {
+		return "", err
+	}
+
+	err = requireFreeDomains(ctx, c.ssoRepo, connection)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.ssoRepo.Update(ctx, connection)
+	if err != nil {
+		return "", fmt.Errorf("could not verify domain: %s", err)
+	}
+
+	return connection.Id().Value(), nil
+}
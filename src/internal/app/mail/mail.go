@@ -0,0 +1,15 @@
+package mail
+
+import "context"
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. Implementations live in the providers package.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
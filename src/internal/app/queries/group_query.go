@@ -0,0 +1,27 @@
+package queries
+
+import "context"
+
+// TenantGroup is a group owned by a tenant, with the users who belong to
+// it in that tenant.
+type TenantGroup struct {
+	Id          string
+	Name        string
+	Description string
+	UserIds     []string
+}
+
+// Group is a group available in a tenant, either defined by the
+// application or custom to the tenant, with the names of its roles.
+type Group struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Custom      bool     `json:"custom"`
+	Roles       []string `json:"roles"`
+}
+
+type GroupQuery interface {
+	TenantGroups(ctx context.Context, tenantId string) ([]TenantGroup, error)
+	GroupsInTenant(ctx context.Context, tenantId string, list ListQuery) (Page[Group], error)
+}
@@ -0,0 +1,124 @@
+package queries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ErrInvalidList is returned for a list query with an unknown sort field
+// or a cursor that is malformed or does not belong to it.
+var ErrInvalidList = errors.New("invalid list query")
+
+// ListQuery selects a page of a list. Lists ignore the filters that do not
+// apply to them. A zero Limit returns the whole list, which only internal
+// callers should ask for.
+type ListQuery struct {
+	Cursor string
+	Limit  int
+
+	// Sort names the field to order by, descending when prefixed with a
+	// minus sign.
+	Sort string
+
+	Name       string
+	Email      string
+	Level      string
+	JoinedFrom time.Time
+	JoinedTo   time.Time
+	TenantId   string
+	RoleId     string
+	GroupId    string
+}
+
+// Page is a page of a list. NextCursor continues the list after its last
+// item, and is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// SortBy returns the field to order by and whether the order is
+// descending, defaulting to fallback in ascending order.
+func (q ListQuery) SortBy(fallback string, fields ...string) (string, bool, error) {
+	if q.Sort == "" {
+		return fallback, false, nil
+	}
+
+	field := strings.TrimPrefix(q.Sort, "-")
+	for _, f := range fields {
+		if f == field {
+			return field, field != q.Sort, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("%w: cannot sort by %s", ErrInvalidList, field)
+}
+
+// Cursor is the position of an item in a sorted list: the value of the
+// field the list is sorted by, and the id of the item, which breaks ties.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    string `json:"i"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reads the cursor of a list sorted as sort, which is the
+// Sort of the query that returned it.
+func DecodeCursor(s string, sort string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidList)
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidList)
+	}
+
+	// The database refuses ids that are not UUIDs and text holding a NUL, so
+	// such a cursor would fail the query rather than the request.
+	if _, err = uuid.Parse(c.Id); err != nil || strings.ContainsRune(c.Value, 0) {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidList)
+	}
+
+	if c.Sort != sort {
+		return Cursor{}, fmt.Errorf("%w: the cursor belongs to another sort order", ErrInvalidList)
+	}
+
+	return c, nil
+}
+
+// cursorTimeLayouts are the ways a timestamp is written as text by the
+// database, depending on the precision of the time and of its offset.
+var cursorTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+}
+
+// TimeValue reads the value of the cursor of a list sorted by a timestamp.
+func (c Cursor) TimeValue() (time.Time, error) {
+	for _, layout := range cursorTimeLayouts {
+		if t, err := time.Parse(layout, c.Value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: malformed cursor", ErrInvalidList)
+}
@@ -0,0 +1,94 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultSearchSize = 10
+	MaxSearchSize     = 50
+)
+
+// SearchMatch is a member matching a search term. Prefix is set when the
+// name, a word of the name or the email starts with the term, and
+// Similarity is how closely the name or email resembles it, from 0 to 1.
+type SearchMatch struct {
+	User
+	Prefix     bool
+	Similarity float64
+}
+
+// MemberSearch finds members of an organization by name or email, for the
+// members of that organization.
+type MemberSearch struct {
+	orgQuery OrganizationQuery
+}
+
+func NewMemberSearch(orgQuery OrganizationQuery) *MemberSearch {
+	return &MemberSearch{orgQuery}
+}
+
+// Search returns the members of the organization matching the term, best
+// matches first, filtered as UsersInOrganization filters them. The user
+// searching must be a member. Results are ranked rather than sorted, and
+// are not paged: list.Sort and list.Cursor are refused.
+func (s *MemberSearch) Search(ctx context.Context, userId string, organizationId string, term string, list ListQuery) ([]User, error) {
+	if list.Sort != "" || list.Cursor != "" {
+		return nil, fmt.Errorf("%w: search results are ranked and cannot be sorted or paged", ErrInvalidList)
+	}
+
+	member, err := s.orgQuery.FindMember(ctx, organizationId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("%w: only members can search the organization", entities.ErrForbidden)
+	}
+
+	if list.Limit <= 0 {
+		list.Limit = DefaultSearchSize
+	}
+	if list.Limit > MaxSearchSize {
+		list.Limit = MaxSearchSize
+	}
+
+	matches, err := s.orgQuery.SearchUsersInOrganization(ctx, organizationId, strings.ToLower(strings.TrimSpace(term)), list)
+	if err != nil {
+		return nil, err
+	}
+
+	return RankMatches(matches, list.Limit), nil
+}
+
+// RankMatches orders the matches with prefix matches first, then by
+// similarity, name and membership, and keeps the first limit.
+func RankMatches(matches []SearchMatch, limit int) []User {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Prefix != b.Prefix:
+			return a.Prefix
+		case a.Similarity != b.Similarity:
+			return a.Similarity > b.Similarity
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		default:
+			return a.UserOrgId < b.UserOrgId
+		}
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	users := make([]User, 0, len(matches))
+	for _, match := range matches {
+		users = append(users, match.User)
+	}
+
+	return users
+}
@@ -1,6 +1,9 @@
 package queries
 
-import "context"
+import (
+	"context"
+	"iyaem/internal/app/scim"
+)
 
 type Organization struct {
 	Id   string `json:"organization_id"`
@@ -17,10 +20,38 @@ type User struct {
 	JoinedAt  string `json:"joined_at"`
 }
 
+// MembershipRow is the level of a member and the roles and groups they
+// have in a tenant. Members without any role or group have a single row
+// without a tenant. Rows have the shape of member import rows, so that an
+// export can be imported again.
+type MembershipRow struct {
+	Email    string   `json:"email"`
+	Level    string   `json:"level"`
+	TenantId string   `json:"tenant_id,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
 type OrganizationQuery interface {
 	AllOrganizations(ctx context.Context) ([]Organization, error)
 	AllAffilatedOrganizations(ctx context.Context, userId string) ([]Organization, error)
-	UsersInOrganization(ctx context.Context, organizationId string) ([]User, error)
+	UsersInOrganization(ctx context.Context, organizationId string, list ListQuery) (Page[User], error)
+	// SearchUsersInOrganization returns the members matching the lowercased
+	// term, with the best list.Limit prefix matches and the best list.Limit
+	// other matches among them, for MemberSearch to rank.
+	SearchUsersInOrganization(ctx context.Context, organizationId string, term string, list ListQuery) ([]SearchMatch, error)
+	// FindMember returns the member of the organization with the user id,
+	// or nil.
+	FindMember(ctx context.Context, organizationId string, userId string) (*User, error)
+	// FindMemberByEmail returns the member of the organization with the
+	// email, or nil.
+	FindMemberByEmail(ctx context.Context, organizationId string, email string) (*User, error)
+	// ScimUsers returns at most count members matching the SCIM filter,
+	// starting at startIndex counted from 1, and how many match in total.
+	ScimUsers(ctx context.Context, organizationId string, filter scim.Filter, startIndex int, count int) ([]User, int, error)
 	RecentUsersInOrganization(ctx context.Context, organizationId string) ([]User, error)
 	FindById(ctx context.Context, organizationId string) (Organization, error)
+	// EachMembership calls fn for every row of the membership matrix of the
+	// organization, ordered by email and tenant.
+	EachMembership(ctx context.Context, organizationId string, fn func(MembershipRow) error) error
 }
@@ -0,0 +1,17 @@
+package queries
+
+import "context"
+
+// Role is a role available in a tenant, either defined by the application
+// or custom to the tenant, with the names of its permissions.
+type Role struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Custom      bool     `json:"custom"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleQuery interface {
+	RolesInTenant(ctx context.Context, tenantId string, list ListQuery) (Page[Role], error)
+}
@@ -0,0 +1,216 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Comparison is a single attribute expression of a filter, such as
+// userName eq "jane@example.com".
+type Comparison struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+// Filter selects the resources matching all of its comparisons. Only
+// comparisons joined with "and" are supported, which is what provisioning
+// clients send to look up resources.
+type Filter []Comparison
+
+var operators = map[string]bool{"eq": true, "ne": true, "co": true, "sw": true, "ew": true, "pr": true}
+
+// ParseFilter parses the filter query parameter. attributes are the
+// attributes that can be filtered by, in lower case. An empty filter
+// matches every resource.
+func ParseFilter(filter string, attributes ...string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(attributes))
+	for _, attribute := range attributes {
+		allowed[attribute] = true
+	}
+
+	parsed := make(Filter, 0)
+	for len(tokens) > 0 {
+		if len(parsed) > 0 {
+			if !strings.EqualFold(tokens[0], "and") || len(tokens) == 1 {
+				return nil, fmt.Errorf("%w: only \"and\" can join expressions", ErrInvalidFilter)
+			}
+			tokens = tokens[1:]
+		}
+
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("%w: incomplete expression", ErrInvalidFilter)
+		}
+
+		c := Comparison{Attribute: normalizeAttribute(tokens[0]), Operator: strings.ToLower(tokens[1])}
+		if !allowed[c.Attribute] {
+			return nil, fmt.Errorf("%w: cannot filter by %s", ErrInvalidFilter, tokens[0])
+		}
+		if !operators[c.Operator] {
+			return nil, fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, tokens[1])
+		}
+		tokens = tokens[2:]
+
+		if c.Operator != "pr" {
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("%w: missing value", ErrInvalidFilter)
+			}
+
+			c.Value, err = parseValue(tokens[0])
+			if err != nil {
+				return nil, err
+			}
+			tokens = tokens[1:]
+		}
+
+		parsed = append(parsed, c)
+	}
+
+	return parsed, nil
+}
+
+// Matches reports whether a resource with the given attribute values, as
+// returned by User.Attributes or Group.Attributes, matches the filter.
+// Values are compared without regard to case.
+func (f Filter) Matches(attributes map[string][]string) bool {
+	for _, c := range f {
+		if !c.matches(attributes[c.Attribute]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Lookup returns the value the filter compares one of the attributes to,
+// when it is a single "eq" comparison, as clients send to find a resource
+// by a unique attribute.
+func (f Filter) Lookup(attributes ...string) (string, bool) {
+	if len(f) != 1 || f[0].Operator != "eq" {
+		return "", false
+	}
+
+	for _, attribute := range attributes {
+		if f[0].Attribute == attribute {
+			return f[0].Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (c Comparison) matches(values []string) bool {
+	if c.Operator == "pr" {
+		for _, value := range values {
+			if value != "" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if c.Operator == "ne" {
+		return !Comparison{c.Attribute, "eq", c.Value}.matches(values)
+	}
+
+	expected := strings.ToLower(c.Value)
+	for _, value := range values {
+		value = strings.ToLower(value)
+
+		switch c.Operator {
+		case "eq":
+			if value == expected {
+				return true
+			}
+		case "co":
+			if strings.Contains(value, expected) {
+				return true
+			}
+		case "sw":
+			if strings.HasPrefix(value, expected) {
+				return true
+			}
+		case "ew":
+			if strings.HasSuffix(value, expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalizeAttribute lowers the attribute name and drops the schema URN
+// clients may prefix it with.
+func normalizeAttribute(attribute string) string {
+	attribute = strings.ToLower(attribute)
+
+	for _, schema := range []string{UserSchema, GroupSchema} {
+		prefix := strings.ToLower(schema) + ":"
+		if strings.HasPrefix(attribute, prefix) {
+			return attribute[len(prefix):]
+		}
+	}
+
+	return attribute
+}
+
+// parseValue parses a JSON string, boolean or null.
+func parseValue(token string) (string, error) {
+	switch strings.ToLower(token) {
+	case "true", "false":
+		return strings.ToLower(token), nil
+	case "null":
+		return "", nil
+	}
+
+	var value string
+	if err := json.Unmarshal([]byte(token), &value); err != nil {
+		return "", fmt.Errorf("%w: invalid value %s", ErrInvalidFilter, token)
+	}
+
+	return value, nil
+}
+
+// tokenize splits the filter on spaces outside of quoted strings.
+func tokenize(filter string) ([]string, error) {
+	tokens := make([]string, 0)
+	var current strings.Builder
+	quoted, escaped := false, false
+
+	for _, r := range filter {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == ' ':
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,247 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyToUser changes the user as the operations describe. Operations
+// without a path carry the attributes to change as their value.
+func (r PatchRequest) ApplyToUser(user *User) error {
+	for _, operation := range r.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return fmt.Errorf("%w: unsupported operation %q", ErrInvalidValue, operation.Op)
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return fmt.Errorf("%w: remove requires a path", ErrInvalidPath)
+			}
+
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				return fmt.Errorf("%w: expected an object of attributes", ErrInvalidValue)
+			}
+
+			for path, value := range values {
+				if err := setUserAttribute(user, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if op == "remove" {
+			if err := setUserAttribute(user, operation.Path, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := setUserAttribute(user, operation.Path, operation.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setUserAttribute sets the attribute at path, or clears it when value is
+// nil.
+func setUserAttribute(user *User, path string, value json.RawMessage) error {
+	if user.Name == nil {
+		user.Name = &Name{}
+	}
+
+	switch normalizeAttribute(path) {
+	case "active":
+		if value == nil {
+			return fmt.Errorf("%w: active cannot be removed", ErrInvalidPath)
+		}
+
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+	case "username":
+		if value == nil {
+			return fmt.Errorf("%w: userName cannot be removed", ErrInvalidPath)
+		}
+		return parseString(value, &user.UserName)
+	case "displayname":
+		return parseString(value, &user.DisplayName)
+	case "name.formatted":
+		return parseString(value, &user.Name.Formatted)
+	case "name.givenname":
+		return parseString(value, &user.Name.GivenName)
+	case "name.familyname":
+		return parseString(value, &user.Name.FamilyName)
+	case "name":
+		if value == nil {
+			user.Name = &Name{}
+			return nil
+		}
+		if err := json.Unmarshal(value, user.Name); err != nil {
+			return fmt.Errorf("%w: invalid name", ErrInvalidValue)
+		}
+	case "emails":
+		if value == nil {
+			user.Emails = nil
+			return nil
+		}
+		if err := json.Unmarshal(value, &user.Emails); err != nil {
+			return fmt.Errorf("%w: invalid emails", ErrInvalidValue)
+		}
+	default:
+		// Attributes that are not stored, like externalId or phone
+		// numbers, are ignored.
+	}
+
+	return nil
+}
+
+// ApplyToGroup changes the group as the operations describe. Members are
+// removed either by a path selecting them, e.g. members[value eq "id"], or
+// by listing them as the value.
+func (r PatchRequest) ApplyToGroup(group *Group) error {
+	for _, operation := range r.Operations {
+		op := strings.ToLower(operation.Op)
+		path := normalizeAttribute(strings.TrimSpace(operation.Path))
+
+		switch {
+		case path == "" && (op == "add" || op == "replace"):
+			var values struct {
+				DisplayName *string  `json:"displayName"`
+				Members     []Member `json:"members"`
+			}
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				return fmt.Errorf("%w: expected an object of attributes", ErrInvalidValue)
+			}
+
+			if values.DisplayName != nil {
+				group.DisplayName = *values.DisplayName
+			}
+			if values.Members != nil {
+				if op == "replace" {
+					group.Members = nil
+				}
+				group.Members = addMembers(group.Members, values.Members)
+			}
+		case path == "displayname" && (op == "add" || op == "replace"):
+			if err := parseString(operation.Value, &group.DisplayName); err != nil {
+				return err
+			}
+		case path == "members" && (op == "add" || op == "replace"):
+			var members []Member
+			if err := json.Unmarshal(operation.Value, &members); err != nil {
+				return fmt.Errorf("%w: expected a list of members", ErrInvalidValue)
+			}
+
+			if op == "replace" {
+				group.Members = nil
+			}
+			group.Members = addMembers(group.Members, members)
+		case path == "members" && op == "remove":
+			if len(operation.Value) == 0 || string(operation.Value) == "null" {
+				group.Members = nil
+				continue
+			}
+
+			var members []Member
+			if err := json.Unmarshal(operation.Value, &members); err != nil {
+				return fmt.Errorf("%w: expected a list of members", ErrInvalidValue)
+			}
+			group.Members = removeMembers(group.Members, members)
+		case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]") && op == "remove":
+			selection := strings.TrimSpace(operation.Path)
+			filter, err := ParseFilter(selection[len("members["):len(selection)-1], "value")
+			if err != nil || len(filter) != 1 || filter[0].Operator != "eq" {
+				return fmt.Errorf("%w: unsupported member selection %s", ErrInvalidPath, operation.Path)
+			}
+			group.Members = removeMembers(group.Members, []Member{{Value: filter[0].Value}})
+		default:
+			return fmt.Errorf("%w: unsupported operation %s %s", ErrInvalidPath, operation.Op, operation.Path)
+		}
+	}
+
+	return nil
+}
+
+func addMembers(members []Member, added []Member) []Member {
+	for _, member := range added {
+		if !containsMember(members, member.Value) {
+			members = append(members, Member{Value: member.Value})
+		}
+	}
+
+	return members
+}
+
+func removeMembers(members []Member, removed []Member) []Member {
+	kept := make([]Member, 0, len(members))
+	for _, member := range members {
+		if !containsMember(removed, member.Value) {
+			kept = append(kept, member)
+		}
+	}
+
+	return kept
+}
+
+func containsMember(members []Member, id string) bool {
+	for _, member := range members {
+		if member.Value == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseBool accepts booleans and, as some clients send them, the strings
+// "true" and "false".
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, fmt.Errorf("%w: expected a boolean", ErrInvalidValue)
+}
+
+// parseString sets target to the string value, or clears it when value is
+// nil.
+func parseString(value json.RawMessage, target *string) error {
+	if value == nil {
+		*target = ""
+		return nil
+	}
+
+	if err := json.Unmarshal(value, target); err != nil {
+		return fmt.Errorf("%w: expected a string", ErrInvalidValue)
+	}
+
+	return nil
+}
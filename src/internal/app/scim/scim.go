@@ -0,0 +1,213 @@
+// Package scim implements the parts of SCIM 2.0 (RFC 7643 and RFC 7644)
+// that do not depend on storage: the resources, filters and PATCH
+// operations.
+package scim
+
+import (
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"strings"
+)
+
+const (
+	UserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	ResourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// Errors of a request the provisioning client can fix. They map to the
+// scimType of the error response.
+var (
+	ErrInvalidFilter = fmt.Errorf("%w: invalid filter", entities.ErrInvalid)
+	ErrInvalidPath   = fmt.Errorf("%w: invalid path", entities.ErrInvalid)
+	ErrInvalidValue  = fmt.Errorf("%w: invalid value", entities.ErrInvalid)
+)
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type User struct {
+	Schemas     []string `json:"schemas"`
+	Id          string   `json:"id,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Email returns the address the user signs in with: the user name when it
+// is an email address, and otherwise the primary email.
+func (u User) Email() string {
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	return u.UserName
+}
+
+// FullName returns the name to display for the user.
+func (u User) FullName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+
+	if u.Name == nil {
+		return ""
+	}
+
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// IsActive reports whether the user should be a member. Users are active
+// unless told otherwise.
+func (u User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Attributes returns the values of the attributes users can be filtered
+// by.
+func (u User) Attributes() map[string][]string {
+	attributes := map[string][]string{
+		"username":    {u.UserName},
+		"displayname": {u.FullName()},
+		"active":      {fmt.Sprint(u.IsActive())},
+	}
+
+	for _, email := range u.Emails {
+		attributes["emails.value"] = append(attributes["emails.value"], email.Value)
+	}
+	attributes["emails"] = attributes["emails.value"]
+
+	return attributes
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	Id          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// MemberIds returns the ids of the members of the group.
+func (g Group) MemberIds() []string {
+	ids := make([]string, 0, len(g.Members))
+	for _, member := range g.Members {
+		ids = append(ids, member.Value)
+	}
+
+	return ids
+}
+
+// Attributes returns the values of the attributes groups can be filtered
+// by.
+func (g Group) Attributes() map[string][]string {
+	return map[string][]string{
+		"displayname":   {g.DisplayName},
+		"members.value": g.MemberIds(),
+		"members":       g.MemberIds(),
+	}
+}
+
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// NewListResponse returns the page of resources starting at startIndex,
+// counted from 1, with at most count resources.
+func NewListResponse(resources []interface{}, startIndex int, count int) ListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	from := startIndex - 1
+	if from > len(resources) {
+		from = len(resources)
+	}
+
+	to := from + count
+	if to > len(resources) {
+		to = len(resources)
+	}
+
+	return ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: to - from,
+		Resources:    resources[from:to],
+	}
+}
+
+// NewPagedListResponse returns a page of resources selected by the
+// caller, out of total resources, starting at startIndex counted from 1.
+func NewPagedListResponse(resources []interface{}, total int, startIndex int) ListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	return ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+func NewError(status int, scimType string, detail string) Error {
+	return Error{[]string{ErrorSchema}, fmt.Sprint(status), scimType, detail}
+}
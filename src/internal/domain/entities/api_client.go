@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"fmt"
+	vo "iyaem/internal/domain/valueobjects"
+	"strings"
+	"time"
+)
+
+// ApiClient is a machine client that authenticates with the OAuth2 client
+// credentials grant.
+type ApiClient struct {
+	id         vo.ApiClientId
+	name       string
+	secretHash vo.SecretHash
+	scopes     []string
+	createdAt  time.Time
+	revokedAt  *time.Time
+}
+
+func NewApiClient(
+	id vo.ApiClientId,
+	name string,
+	secretHash vo.SecretHash,
+	scopes []string,
+	createdAt time.Time,
+	revokedAt *time.Time,
+) ApiClient {
+	return ApiClient{id, name, secretHash, scopes, createdAt, revokedAt}
+}
+
+func (c ApiClient) String() string {
+	return c.id.Value() + " " + c.name + " " + strings.Join(c.scopes, " ")
+}
+
+func (c *ApiClient) Id() vo.ApiClientId {
+	return c.id
+}
+
+func (c *ApiClient) Name() string {
+	return c.name
+}
+
+func (c *ApiClient) SecretHash() vo.SecretHash {
+	return c.secretHash
+}
+
+func (c *ApiClient) Scopes() []string {
+	return c.scopes
+}
+
+func (c *ApiClient) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+func (c *ApiClient) RevokedAt() *time.Time {
+	return c.revokedAt
+}
+
+func (c *ApiClient) IsRevoked() bool {
+	return c.revokedAt != nil
+}
+
+// Authenticate checks the secret presented by the client.
+func (c *ApiClient) Authenticate(secret string) error {
+	if c.IsRevoked() {
+		return fmt.Errorf("client is revoked")
+	}
+
+	if !c.secretHash.Matches(secret) {
+		return fmt.Errorf("invalid client secret")
+	}
+
+	return nil
+}
+
+// GrantScopes returns the scopes the client may receive for a token
+// request. An empty request grants every scope registered for the client.
+func (c *ApiClient) GrantScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return c.scopes, nil
+	}
+
+	for _, scope := range requested {
+		allowed := false
+		for _, s := range c.scopes {
+			if s == scope {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return nil, fmt.Errorf("scope %s is not allowed", scope)
+		}
+	}
+
+	return requested, nil
+}
+
+func (c *ApiClient) Revoke() {
+	now := time.Now()
+	c.revokedAt = &now
+}
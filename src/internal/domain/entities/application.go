@@ -2,17 +2,33 @@ package entities
 
 import (
 	"fmt"
+	"iyaem/internal/domain/events"
 	vo "iyaem/internal/domain/valueobjects"
+	"strings"
 )
 
 type Application struct {
 	id          vo.ApplicationId
 	name        string
 	permissions []Permission
+
+	events []events.Event
 }
 
 func NewApplication(id vo.ApplicationId, name string, permissions []Permission) Application {
-	return Application{id, name, permissions}
+	return Application{id, name, permissions, make([]events.Event, 0)}
+}
+
+// CreateApplication registers a new application without permissions.
+func CreateApplication(name string) (Application, error) {
+	if err := requireName("application", name); err != nil {
+		return Application{}, err
+	}
+
+	u := NewApplication(vo.GenerateApplicationId(), name, make([]Permission, 0))
+	u.events = append(u.events, events.NewApplicationCreated(u.id.Value(), name))
+
+	return u, nil
 }
 
 func (u Application) String() string {
@@ -31,6 +47,87 @@ func (u *Application) Permissions() []Permission {
 	return u.permissions
 }
 
-func (u *Application) AddPermission(p Permission) {
-	u.permissions = append(u.permissions, p)
+func (u *Application) Events() []events.Event {
+	return u.events
+}
+
+func (u *Application) Rename(name string) error {
+	if err := requireName("application", name); err != nil {
+		return err
+	}
+
+	previous := u.name
+	u.name = name
+	u.events = append(u.events, events.NewApplicationRenamed(u.id.Value(), name, previous))
+	return nil
+}
+
+func (u *Application) FindPermissionById(permissionId vo.PermissionId) *Permission {
+	for _, permission := range u.permissions {
+		if permission.id.Equals(permissionId) {
+			return &permission
+		}
+	}
+
+	return nil
+}
+
+// AddPermission defines a new permission. Permission names are unique
+// within an application.
+func (u *Application) AddPermission(name string) (Permission, error) {
+	if err := u.checkPermissionName(vo.PermissionId{}, name); err != nil {
+		return Permission{}, err
+	}
+
+	permission := NewPermission(vo.GeneratePermissionId(), u.id, name)
+
+	u.permissions = append(u.permissions, permission)
+	u.events = append(u.events, events.NewPermissionAdded(u.id.Value(), permission.id.Value(), name))
+	return permission, nil
+}
+
+func (u *Application) RenamePermission(permissionId vo.PermissionId, name string) error {
+	if err := u.checkPermissionName(permissionId, name); err != nil {
+		return err
+	}
+
+	for i, permission := range u.permissions {
+		if permission.id.Equals(permissionId) {
+			u.permissions[i].name = name
+			u.events = append(u.events, events.NewPermissionRenamed(u.id.Value(), permissionId.Value(), name, permission.name))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("permission %v %w", permissionId.Value(), ErrNotFound)
+}
+
+func (u *Application) RemovePermission(permissionId vo.PermissionId) error {
+	for i, permission := range u.permissions {
+		if permission.id.Equals(permissionId) {
+			u.permissions = append(u.permissions[:i], u.permissions[i+1:]...)
+			u.events = append(u.events, events.NewPermissionRemoved(u.id.Value(), permissionId.Value()))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("permission %v %w", permissionId.Value(), ErrNotFound)
+}
+
+func (u *Application) Delete() {
+	u.events = append(u.events, events.NewApplicationDeleted(u.id.Value()))
+}
+
+func (u *Application) checkPermissionName(permissionId vo.PermissionId, name string) error {
+	if err := requireName("permission", name); err != nil {
+		return err
+	}
+
+	for _, permission := range u.permissions {
+		if strings.EqualFold(permission.name, name) && !permission.id.Equals(permissionId) {
+			return fmt.Errorf("%w: permission %s already exists", ErrConflict, name)
+		}
+	}
+
+	return nil
 }
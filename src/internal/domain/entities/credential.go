@@ -0,0 +1,245 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MinPasswordLength = 12
+	MaxPasswordLength = 128
+
+	// MaxFailedLogins is how many wrong passwords in a row lock the
+	// credential for LockoutDuration.
+	MaxFailedLogins = 5
+	LockoutDuration = 15 * time.Minute
+)
+
+// ErrInvalidPassword is returned for a wrong password and for a locked
+// credential alike, so that callers cannot tell the two apart.
+var ErrInvalidPassword = fmt.Errorf("%w: invalid email or password", ErrForbidden)
+
+// commonPasswords are rejected even though they are long enough.
+var commonPasswords = map[string]bool{
+	"123456789012":     true,
+	"1234567890123":    true,
+	"password1234":     true,
+	"passwordpassword": true,
+	"qwertyuiopasdf":   true,
+	"qwerty123456":     true,
+	"iloveyou1234":     true,
+	"letmeinletmein":   true,
+	"administrator":    true,
+	"changemechangeme": true,
+	"welcome12345":     true,
+	"aaaaaaaaaaaa":     true,
+}
+
+// ValidatePassword checks a new password against the password policy:
+// between MinPasswordLength and MaxPasswordLength characters, not a
+// commonly used password and not containing the user's email.
+func ValidatePassword(password string, email string) error {
+	length := utf8.RuneCountInString(password)
+	if length < MinPasswordLength {
+		return fmt.Errorf("%w: the password must have at least %d characters", ErrInvalid, MinPasswordLength)
+	}
+
+	if length > MaxPasswordLength {
+		return fmt.Errorf("%w: the password must have at most %d characters", ErrInvalid, MaxPasswordLength)
+	}
+
+	lowered := strings.ToLower(password)
+	if commonPasswords[lowered] {
+		return fmt.Errorf("%w: the password is too common", ErrInvalid)
+	}
+
+	local, _, _ := strings.Cut(NormalizeEmail(email), "@")
+	if len(local) >= 4 && strings.Contains(lowered, local) {
+		return fmt.Errorf("%w: the password must not contain the email", ErrInvalid)
+	}
+
+	return nil
+}
+
+// Credential is a password a user signs in with directly, without an
+// external identity provider.
+type Credential struct {
+	id                vo.CredentialId
+	userId            vo.UserId
+	email             string
+	hash              vo.PasswordHash
+	failedAttempts    int
+	lockedUntil       *time.Time
+	passwordChangedAt time.Time
+	createdAt         time.Time
+
+	events []events.Event
+}
+
+func NewCredential(
+	id vo.CredentialId,
+	userId vo.UserId,
+	email string,
+	hash vo.PasswordHash,
+	failedAttempts int,
+	lockedUntil *time.Time,
+	passwordChangedAt time.Time,
+	createdAt time.Time,
+) Credential {
+	return Credential{id, userId, email, hash, failedAttempts, lockedUntil, passwordChangedAt, createdAt, make([]events.Event, 0)}
+}
+
+func CreateCredential(userId vo.UserId, email string, password string) (Credential, error) {
+	email = NormalizeEmail(email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return Credential{}, fmt.Errorf("%w: a valid email is required", ErrInvalid)
+	}
+
+	if err := ValidatePassword(password, email); err != nil {
+		return Credential{}, err
+	}
+
+	hash, err := vo.NewPasswordHash(password)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	now := time.Now()
+	c := NewCredential(vo.GenerateCredentialId(), userId, email, hash, 0, nil, now, now)
+	c.events = append(c.events, events.NewCredentialCreated(c.id.Value(), userId.Value(), email))
+
+	return c, nil
+}
+
+// Subject returns the identity provider id of users signing in with the
+// credential.
+func (c *Credential) Subject() string {
+	return "password|" + c.userId.Value()
+}
+
+func (c *Credential) Id() vo.CredentialId {
+	return c.id
+}
+
+func (c *Credential) UserId() vo.UserId {
+	return c.userId
+}
+
+func (c *Credential) Email() string {
+	return c.email
+}
+
+func (c *Credential) Hash() vo.PasswordHash {
+	return c.hash
+}
+
+func (c *Credential) FailedAttempts() int {
+	return c.failedAttempts
+}
+
+func (c *Credential) LockedUntil() *time.Time {
+	return c.lockedUntil
+}
+
+func (c *Credential) PasswordChangedAt() time.Time {
+	return c.passwordChangedAt
+}
+
+func (c *Credential) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+func (c *Credential) IsLocked(now time.Time) bool {
+	return c.lockedUntil != nil && now.Before(*c.lockedUntil)
+}
+
+func (c *Credential) Events() []events.Event {
+	return c.events
+}
+
+// Authenticate checks the password. Wrong passwords are counted, and after
+// MaxFailedLogins of them the credential is locked for LockoutDuration,
+// during which even the right password is refused. A hash made with
+// outdated parameters is replaced once the password is known to be right.
+func (c *Credential) Authenticate(password string, now time.Time) error {
+	if c.IsLocked(now) {
+		return ErrInvalidPassword
+	}
+
+	if !c.hash.Matches(password) {
+		c.failedAttempts++
+		if c.failedAttempts >= MaxFailedLogins {
+			lockedUntil := now.Add(LockoutDuration)
+			c.lockedUntil = &lockedUntil
+			c.failedAttempts = 0
+			c.events = append(c.events, events.NewCredentialLocked(c.id.Value(), c.userId.Value(), lockedUntil))
+		}
+
+		return ErrInvalidPassword
+	}
+
+	c.failedAttempts = 0
+	c.lockedUntil = nil
+
+	if c.hash.NeedsRehash() {
+		hash, err := vo.NewPasswordHash(password)
+		if err != nil {
+			return err
+		}
+		c.hash = hash
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password of a user who knows the current
+// one.
+func (c *Credential) ChangePassword(current string, password string, now time.Time) error {
+	if err := c.Authenticate(current, now); err != nil {
+		return err
+	}
+
+	return c.setPassword(password, now, "changed", "")
+}
+
+// ResetPassword replaces the password using a reset token sent to the
+// user's email. It also lifts a lockout.
+func (c *Credential) ResetPassword(reset *PasswordReset, password string, now time.Time) error {
+	if !reset.CredentialId().Equals(c.id) {
+		return fmt.Errorf("%w: the reset was requested for another credential", ErrForbidden)
+	}
+
+	if err := reset.use(now); err != nil {
+		return err
+	}
+
+	return c.setPassword(password, now, "reset", reset.Id().Value())
+}
+
+func (c *Credential) setPassword(password string, now time.Time, reason string, resetId string) error {
+	if err := ValidatePassword(password, c.email); err != nil {
+		return err
+	}
+
+	if c.hash.Matches(password) {
+		return fmt.Errorf("%w: the new password must differ from the current one", ErrInvalid)
+	}
+
+	hash, err := vo.NewPasswordHash(password)
+	if err != nil {
+		return err
+	}
+
+	c.hash = hash
+	c.failedAttempts = 0
+	c.lockedUntil = nil
+	c.passwordChangedAt = now
+	c.events = append(c.events, events.NewPasswordChanged(c.id.Value(), c.userId.Value(), reason, resetId))
+
+	return nil
+}
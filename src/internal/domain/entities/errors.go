@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned when a change would leave an entity in an
+	// invalid state.
+	ErrInvalid = errors.New("invalid")
+
+	// ErrConflict is returned when a change conflicts with the current
+	// state of an entity, for example a duplicate name or link.
+	ErrConflict = errors.New("conflict")
+
+	// ErrNotFound is returned when a change refers to something that does
+	// not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is returned when the member making a change is not
+	// allowed to make it.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrLastOwner is returned when a change would leave an organization
+	// without an owner.
+	ErrLastOwner = fmt.Errorf("%w: an organization must keep at least one owner", ErrConflict)
+)
+
+func requireName(kind string, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: %s name is required", ErrInvalid, kind)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"fmt"
+	"iyaem/internal/domain/events"
 	vo "iyaem/internal/domain/valueobjects"
 )
 
@@ -10,11 +11,49 @@ type Group struct {
 	name          string
 	description   string
 	applicationId vo.ApplicationId
+	tenantId      *vo.TenantId
 	roles         []Role
+
+	events []events.Event
+}
+
+func NewGroup(
+	id vo.GroupId,
+	name string,
+	description string,
+	applicationId vo.ApplicationId,
+	tenantId *vo.TenantId,
+	roles []Role,
+) Group {
+	return Group{id, name, description, applicationId, tenantId, roles, make([]events.Event, 0)}
+}
+
+// CreateGroup defines a new group without roles for an application.
+func CreateGroup(name string, description string, applicationId vo.ApplicationId) (Group, error) {
+	return createGroup(name, description, applicationId, nil)
 }
 
-func NewGroup(id vo.GroupId, name string, description string, applicationId vo.ApplicationId, roles []Role) Group {
-	return Group{id, name, description, applicationId, roles}
+// CreateTenantGroup defines a new group owned by a tenant. It can only be
+// granted in that tenant and is managed by the tenant's organization.
+func CreateTenantGroup(name string, description string, tenant Tenant) (Group, error) {
+	tenantId := tenant.id
+	return createGroup(name, description, tenant.applicationId, &tenantId)
+}
+
+func createGroup(name string, description string, applicationId vo.ApplicationId, tenantId *vo.TenantId) (Group, error) {
+	if err := requireName("group", name); err != nil {
+		return Group{}, err
+	}
+
+	owner := ""
+	if tenantId != nil {
+		owner = tenantId.Value()
+	}
+
+	u := NewGroup(vo.GenerateGroupId(), name, description, applicationId, tenantId, make([]Role, 0))
+	u.events = append(u.events, events.NewGroupCreated(u.id.Value(), applicationId.Value(), owner, name))
+
+	return u, nil
 }
 
 func (u Group) String() string {
@@ -29,6 +68,84 @@ func (u *Group) Name() string {
 	return u.name
 }
 
-func (u *Group) Roles() []Permission {
-	return u.Roles()
+func (u *Group) Description() string {
+	return u.description
+}
+
+func (u *Group) ApplicationId() vo.ApplicationId {
+	return u.applicationId
+}
+
+// TenantId returns the tenant owning the group, or nil for groups defined
+// by the application.
+func (u *Group) TenantId() *vo.TenantId {
+	return u.tenantId
+}
+
+// IsAvailableIn reports whether the group can be granted in the tenant.
+func (u *Group) IsAvailableIn(t Tenant) bool {
+	if !u.applicationId.Equals(t.applicationId) {
+		return false
+	}
+
+	return u.tenantId == nil || u.tenantId.Equals(t.id)
+}
+
+func (u *Group) Roles() []Role {
+	return u.roles
+}
+
+func (u *Group) Events() []events.Event {
+	return u.events
+}
+
+func (u *Group) Update(name string, description string) error {
+	if err := requireName("group", name); err != nil {
+		return err
+	}
+
+	previousName, previousDescription := u.name, u.description
+	u.name = name
+	u.description = description
+	u.events = append(u.events, events.NewGroupUpdated(u.id.Value(), name, description, previousName, previousDescription))
+	return nil
+}
+
+// AttachRole grants a role through the group. Only roles of the group's
+// own application can be attached, and a tenant role only to a group of
+// the same tenant.
+func (u *Group) AttachRole(r Role) error {
+	if !r.applicationId.Equals(u.applicationId) {
+		return fmt.Errorf("%w: role %s belongs to another application", ErrInvalid, r.id.Value())
+	}
+
+	if r.tenantId != nil && (u.tenantId == nil || !u.tenantId.Equals(*r.tenantId)) {
+		return fmt.Errorf("%w: role %s belongs to another tenant", ErrInvalid, r.id.Value())
+	}
+
+	for _, role := range u.roles {
+		if role.id.Equals(r.id) {
+			return fmt.Errorf("%w: role already attached", ErrConflict)
+		}
+	}
+
+	u.roles = append(u.roles, r)
+	u.events = append(u.events, events.NewRoleAttachedToGroup(u.id.Value(), r.id.Value()))
+	return nil
+}
+
+func (u *Group) DetachRole(roleId vo.RoleId) error {
+	for i, role := range u.roles {
+		if role.id.Equals(roleId) {
+			u.roles = append(u.roles[:i], u.roles[i+1:]...)
+			u.events = append(u.events, events.NewRoleDetachedFromGroup(u.id.Value(), roleId.Value()))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("role %v %w", roleId.Value(), ErrNotFound)
+}
+
+func (u *Group) Delete() {
+	u.events = append(u.events, events.NewGroupDeleted(u.id.Value()))
 }
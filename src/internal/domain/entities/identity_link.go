@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// IdentityLinkTtl is how long a user has to confirm a link.
+const IdentityLinkTtl = 15 * time.Minute
+
+// IdentityLink is a pending link between an identity of an SSO connection
+// and an existing account with the same email. An email alone does not
+// prove the two belong to the same person, so the link is only made once
+// the owner of the account signs in with it and confirms.
+type IdentityLink struct {
+	tokenHash      vo.TokenHash
+	userId         vo.UserId
+	organizationId vo.OrganizationId
+	idpId          string
+	expiresAt      time.Time
+
+	events []events.Event
+}
+
+func NewIdentityLink(
+	tokenHash vo.TokenHash,
+	userId vo.UserId,
+	organizationId vo.OrganizationId,
+	idpId string,
+	expiresAt time.Time,
+) IdentityLink {
+	return IdentityLink{tokenHash, userId, organizationId, idpId, expiresAt, make([]events.Event, 0)}
+}
+
+// RequestIdentityLink returns a link of the identity to the account and
+// the plaintext token that confirms it, which is only known at this point.
+func RequestIdentityLink(userId vo.UserId, organizationId vo.OrganizationId, idpId string) (IdentityLink, string, error) {
+	token, tokenHash, err := vo.GenerateToken()
+	if err != nil {
+		return IdentityLink{}, "", err
+	}
+
+	l := NewIdentityLink(tokenHash, userId, organizationId, idpId, time.Now().Add(IdentityLinkTtl))
+	l.events = append(l.events, events.NewIdentityLinkRequested(userId.Value(), organizationId.Value(), idpId))
+
+	return l, token, nil
+}
+
+func (l *IdentityLink) TokenHash() vo.TokenHash {
+	return l.tokenHash
+}
+
+func (l *IdentityLink) UserId() vo.UserId {
+	return l.userId
+}
+
+func (l *IdentityLink) OrganizationId() vo.OrganizationId {
+	return l.organizationId
+}
+
+func (l *IdentityLink) IdpId() string {
+	return l.idpId
+}
+
+func (l *IdentityLink) ExpiresAt() time.Time {
+	return l.expiresAt
+}
+
+func (l *IdentityLink) Events() []events.Event {
+	return l.events
+}
+
+// Confirm links the identity, when the user signed in to the account the
+// link was requested for.
+func (l *IdentityLink) Confirm(userId vo.UserId) (vo.Identity, error) {
+	if !l.userId.Equals(userId) {
+		return vo.Identity{}, fmt.Errorf("%w: the link was requested for another account", ErrForbidden)
+	}
+	if time.Now().After(l.expiresAt) {
+		return vo.Identity{}, fmt.Errorf("%w: the link expired, sign in with SSO again", ErrInvalid)
+	}
+
+	l.events = append(l.events, events.NewIdentityLinked(l.userId.Value(), l.organizationId.Value(), l.idpId))
+
+	return vo.NewIdentity(l.idpId, l.userId), nil
+}
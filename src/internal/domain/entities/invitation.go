@@ -0,0 +1,184 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation asks a person, known only by email, to join an organization.
+// The invitee proves they received it with the token sent by email.
+type Invitation struct {
+	id             vo.InvitationId
+	organizationId vo.OrganizationId
+	email          string
+	level          vo.MembershipLevel
+	tokenHash      vo.TokenHash
+	status         InvitationStatus
+	invitedBy      string
+	expiresAt      time.Time
+	createdAt      time.Time
+
+	events []events.Event
+}
+
+func NewInvitation(
+	id vo.InvitationId,
+	organizationId vo.OrganizationId,
+	email string,
+	level vo.MembershipLevel,
+	tokenHash vo.TokenHash,
+	status InvitationStatus,
+	invitedBy string,
+	expiresAt time.Time,
+	createdAt time.Time,
+) Invitation {
+	return Invitation{id, organizationId, email, level, tokenHash, status, invitedBy, expiresAt, createdAt, make([]events.Event, 0)}
+}
+
+// CreateInvitation invites the owner of an email address to join the
+// organization at the given level. It returns the invitation together with
+// the plaintext token, which is only known at this point.
+func CreateInvitation(organizationId vo.OrganizationId, email string, level vo.MembershipLevel, invitedBy string, ttl time.Duration) (Invitation, string, error) {
+	email = NormalizeEmail(email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return Invitation{}, "", fmt.Errorf("%w: a valid email is required", ErrInvalid)
+	}
+
+	if level != "member" && level != "manager" {
+		return Invitation{}, "", fmt.Errorf("%w: invitations can only be for members or managers", ErrInvalid)
+	}
+
+	token, tokenHash, err := vo.GenerateToken()
+	if err != nil {
+		return Invitation{}, "", err
+	}
+
+	now := time.Now()
+	i := NewInvitation(vo.GenerateInvitationId(), organizationId, email, level, tokenHash, InvitationPending, invitedBy, now.Add(ttl), now)
+	i.events = append(i.events, events.NewInvitationCreated(i.id.Value(), organizationId.Value(), email, string(level), invitedBy, i.expiresAt))
+
+	return i, token, nil
+}
+
+// NormalizeEmail returns the form of an email address used to match
+// invitations.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (i *Invitation) Id() vo.InvitationId {
+	return i.id
+}
+
+func (i *Invitation) OrganizationId() vo.OrganizationId {
+	return i.organizationId
+}
+
+func (i *Invitation) Email() string {
+	return i.email
+}
+
+func (i *Invitation) Level() vo.MembershipLevel {
+	return i.level
+}
+
+func (i *Invitation) TokenHash() vo.TokenHash {
+	return i.tokenHash
+}
+
+func (i *Invitation) Status() InvitationStatus {
+	return i.status
+}
+
+func (i *Invitation) InvitedBy() string {
+	return i.invitedBy
+}
+
+func (i *Invitation) ExpiresAt() time.Time {
+	return i.expiresAt
+}
+
+func (i *Invitation) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.expiresAt)
+}
+
+func (i *Invitation) Events() []events.Event {
+	return i.events
+}
+
+// Accept turns the invitation into a membership for the user, who must own
+// the invited email address.
+func (i *Invitation) Accept(userId vo.UserId, email string) (Membership, error) {
+	if err := i.requirePending(); err != nil {
+		return Membership{}, err
+	}
+
+	if NormalizeEmail(email) != i.email {
+		return Membership{}, fmt.Errorf("%w: the invitation was sent to another email", ErrForbidden)
+	}
+
+	m := NewMembership(
+		vo.GenerateMembershipId(),
+		userId,
+		i.organizationId,
+		i.level,
+		make([]vo.UserRole, 0),
+		make([]vo.UserGroup, 0),
+	)
+
+	i.status = InvitationAccepted
+	i.events = append(i.events, events.NewInvitationAccepted(i.id.Value(), i.organizationId.Value(), i.email, m.id.Value()))
+
+	return m, nil
+}
+
+func (i *Invitation) Decline() error {
+	if err := i.requirePending(); err != nil {
+		return err
+	}
+
+	i.status = InvitationDeclined
+	i.events = append(i.events, events.NewInvitationDeclined(i.id.Value(), i.organizationId.Value(), i.email))
+	return nil
+}
+
+// Revoke withdraws a pending invitation. Expired invitations can be
+// revoked too, to clear them from the list.
+func (i *Invitation) Revoke() error {
+	if i.status != InvitationPending {
+		return fmt.Errorf("%w: invitation is already %s", ErrConflict, i.status)
+	}
+
+	i.status = InvitationRevoked
+	i.events = append(i.events, events.NewInvitationRevoked(i.id.Value(), i.organizationId.Value(), i.email))
+	return nil
+}
+
+func (i *Invitation) requirePending() error {
+	if i.status != InvitationPending {
+		return fmt.Errorf("%w: invitation is already %s", ErrConflict, i.status)
+	}
+
+	if i.IsExpired() {
+		return fmt.Errorf("%w: invitation expired", ErrConflict)
+	}
+
+	return nil
+}
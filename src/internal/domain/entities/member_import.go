@@ -0,0 +1,194 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"strings"
+	"time"
+)
+
+// MaxMemberImportRows is the largest file a member import accepts.
+const MaxMemberImportRows = 10000
+
+// Statuses of a member import.
+const (
+	MemberImportPending   = "pending"
+	MemberImportRunning   = "running"
+	MemberImportCompleted = "completed"
+	MemberImportFailed    = "failed"
+)
+
+// Outcomes of a row of a member import.
+const (
+	MemberImportRowCreated   = "created"
+	MemberImportRowUpdated   = "updated"
+	MemberImportRowUnchanged = "unchanged"
+	MemberImportRowFailed    = "failed"
+)
+
+// MemberImportRow is a line of an import file: a user, identified by
+// email, with an optional level and the roles and groups to grant them in
+// a tenant. A user granted access to several tenants has a row for each.
+type MemberImportRow struct {
+	Email    string   `json:"email"`
+	Level    string   `json:"level,omitempty"`
+	TenantId string   `json:"tenant_id,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// MemberImportResult is the outcome of a row, numbered from 1.
+type MemberImportResult struct {
+	Row          int    `json:"row"`
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+	MembershipId string `json:"membership_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// MemberImport adds members to an organization in bulk. It runs as a job
+// that applies every row it can and records the outcome of each. A dry
+// run checks the rows the same way without changing the organization.
+type MemberImport struct {
+	id             vo.MemberImportId
+	organizationId vo.OrganizationId
+	requestedBy    vo.UserId
+	dryRun         bool
+	rows           []MemberImportRow
+	status         string
+	results        []MemberImportResult
+	failure        string
+	createdAt      time.Time
+	finishedAt     *time.Time
+
+	events []events.Event
+}
+
+func NewMemberImport(
+	id vo.MemberImportId,
+	organizationId vo.OrganizationId,
+	requestedBy vo.UserId,
+	dryRun bool,
+	rows []MemberImportRow,
+	status string,
+	results []MemberImportResult,
+	failure string,
+	createdAt time.Time,
+	finishedAt *time.Time,
+) MemberImport {
+	return MemberImport{id, organizationId, requestedBy, dryRun, rows, status, results, failure, createdAt, finishedAt, make([]events.Event, 0)}
+}
+
+// RequestMemberImport queues the import of the rows into the organization.
+func RequestMemberImport(organizationId vo.OrganizationId, requestedBy vo.UserId, rows []MemberImportRow, dryRun bool) (MemberImport, error) {
+	if len(rows) == 0 {
+		return MemberImport{}, fmt.Errorf("%w: the file has no rows", ErrInvalid)
+	}
+	if len(rows) > MaxMemberImportRows {
+		return MemberImport{}, fmt.Errorf("%w: imports are limited to %d rows", ErrInvalid, MaxMemberImportRows)
+	}
+
+	for i := range rows {
+		rows[i].Email = strings.ToLower(strings.TrimSpace(rows[i].Email))
+		rows[i].Level = strings.ToLower(strings.TrimSpace(rows[i].Level))
+		rows[i].TenantId = strings.TrimSpace(rows[i].TenantId)
+	}
+
+	i := NewMemberImport(vo.GenerateMemberImportId(), organizationId, requestedBy, dryRun, rows, MemberImportPending, nil, "", time.Now(), nil)
+	i.events = append(i.events, events.NewMemberImportRequested(i.id.Value(), organizationId.Value(), requestedBy.Value(), dryRun, len(rows)))
+
+	return i, nil
+}
+
+func (i *MemberImport) Id() vo.MemberImportId {
+	return i.id
+}
+
+func (i *MemberImport) OrganizationId() vo.OrganizationId {
+	return i.organizationId
+}
+
+func (i *MemberImport) RequestedBy() vo.UserId {
+	return i.requestedBy
+}
+
+func (i *MemberImport) DryRun() bool {
+	return i.dryRun
+}
+
+func (i *MemberImport) Rows() []MemberImportRow {
+	return i.rows
+}
+
+func (i *MemberImport) Status() string {
+	return i.status
+}
+
+func (i *MemberImport) Results() []MemberImportResult {
+	return i.results
+}
+
+// IsFinished reports whether the import completed or failed.
+func (i *MemberImport) IsFinished() bool {
+	return i.status == MemberImportCompleted || i.status == MemberImportFailed
+}
+
+// Failure is why the import as a whole failed, if it did.
+func (i *MemberImport) Failure() string {
+	return i.failure
+}
+
+func (i *MemberImport) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *MemberImport) FinishedAt() *time.Time {
+	return i.finishedAt
+}
+
+func (i *MemberImport) Events() []events.Event {
+	return i.events
+}
+
+// Start marks the import as running. A running import may be started
+// again, when the job that ran it stopped before it finished, but a
+// finished one is not run twice.
+func (i *MemberImport) Start() error {
+	if i.status != MemberImportPending && i.status != MemberImportRunning {
+		return fmt.Errorf("%w: the import is %s", ErrConflict, i.status)
+	}
+
+	i.status = MemberImportRunning
+	return nil
+}
+
+// Complete records the outcome of every row.
+func (i *MemberImport) Complete(results []MemberImportResult) error {
+	if i.status != MemberImportRunning {
+		return fmt.Errorf("%w: the import is %s", ErrConflict, i.status)
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	now := time.Now()
+	i.status = MemberImportCompleted
+	i.results = results
+	i.finishedAt = &now
+	i.events = append(i.events, events.NewMemberImportCompleted(i.id.Value(), i.organizationId.Value(), i.dryRun,
+		counts[MemberImportRowCreated], counts[MemberImportRowUpdated], counts[MemberImportRowUnchanged], counts[MemberImportRowFailed]))
+	return nil
+}
+
+// Fail records that the import could not be applied at all, in which case
+// none of its rows were.
+func (i *MemberImport) Fail(reason string) {
+	now := time.Now()
+	i.status = MemberImportFailed
+	i.failure = reason
+	i.finishedAt = &now
+	i.events = append(i.events, events.NewMemberImportFailed(i.id.Value(), i.organizationId.Value(), reason))
+}
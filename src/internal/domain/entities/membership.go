@@ -44,6 +44,10 @@ func (u Membership) Level() vo.MembershipLevel {
 	return u.level
 }
 
+func (u Membership) IsOwner() bool {
+	return u.level == ownerLevel
+}
+
 func (u Membership) Roles() []vo.UserRole {
 	return u.roles
 }
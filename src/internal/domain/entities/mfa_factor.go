@@ -0,0 +1,282 @@
+package entities
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"strings"
+	"time"
+)
+
+// Authentication methods recorded in the "amr" claim of tokens (RFC 8176).
+// AuthMethodExternal stands for a sign in at an external identity
+// provider, which does not say how the user authenticated there.
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodExternal = "ext"
+	AuthMethodOtp      = "otp"
+	AuthMethodMfa      = "mfa"
+)
+
+// Authentication assurance levels recorded in the "acr" claim of tokens.
+const (
+	AssuranceSingleFactor = "aal1"
+	AssuranceMultiFactor  = "aal2"
+)
+
+// RecoveryCodeCount is how many recovery codes are issued at a time.
+const RecoveryCodeCount = 10
+
+// ErrInvalidMfaCode is returned for a one-time password or recovery code
+// that does not match, or was already used.
+var ErrInvalidMfaCode = fmt.Errorf("%w: invalid code", ErrForbidden)
+
+// RecoveryCode is a one-time code that replaces the authenticator app, e.g.
+// when the phone is lost. Only its hash is kept.
+type RecoveryCode struct {
+	hash   vo.TokenHash
+	usedAt *time.Time
+}
+
+func NewRecoveryCode(hash vo.TokenHash, usedAt *time.Time) RecoveryCode {
+	return RecoveryCode{hash, usedAt}
+}
+
+func (c RecoveryCode) Hash() vo.TokenHash {
+	return c.hash
+}
+
+func (c RecoveryCode) UsedAt() *time.Time {
+	return c.usedAt
+}
+
+// MfaFactor is the authenticator app a user proves a second factor with.
+// It is pending until the user confirms it with a first code.
+type MfaFactor struct {
+	id            vo.MfaFactorId
+	userId        vo.UserId
+	secret        vo.TotpSecret
+	lastCounter   int64
+	recoveryCodes []RecoveryCode
+	confirmedAt   *time.Time
+	createdAt     time.Time
+
+	// What was accepted since the factor was loaded, which the repository
+	// stores only if it was not accepted concurrently.
+	totpAccepted          bool
+	usedRecoveryCode      *RecoveryCode
+	recoveryCodesReplaced bool
+
+	events []events.Event
+}
+
+func NewMfaFactor(
+	id vo.MfaFactorId,
+	userId vo.UserId,
+	secret vo.TotpSecret,
+	lastCounter int64,
+	recoveryCodes []RecoveryCode,
+	confirmedAt *time.Time,
+	createdAt time.Time,
+) MfaFactor {
+	return MfaFactor{
+		id:            id,
+		userId:        userId,
+		secret:        secret,
+		lastCounter:   lastCounter,
+		recoveryCodes: recoveryCodes,
+		confirmedAt:   confirmedAt,
+		createdAt:     createdAt,
+		events:        make([]events.Event, 0),
+	}
+}
+
+// EnrollTotp starts enrolling an authenticator app for the user.
+func EnrollTotp(userId vo.UserId) (MfaFactor, error) {
+	secret, err := vo.GenerateTotpSecret()
+	if err != nil {
+		return MfaFactor{}, err
+	}
+
+	return NewMfaFactor(vo.GenerateMfaFactorId(), userId, secret, 0, make([]RecoveryCode, 0), nil, time.Now()), nil
+}
+
+func (f *MfaFactor) Id() vo.MfaFactorId {
+	return f.id
+}
+
+func (f *MfaFactor) UserId() vo.UserId {
+	return f.userId
+}
+
+func (f *MfaFactor) Secret() vo.TotpSecret {
+	return f.secret
+}
+
+// LastCounter is the time step of the last accepted password, which
+// cannot be used again.
+func (f *MfaFactor) LastCounter() int64 {
+	return f.lastCounter
+}
+
+// TotpAccepted reports whether a password from the app was accepted since
+// the factor was loaded, which moved LastCounter forward.
+func (f *MfaFactor) TotpAccepted() bool {
+	return f.totpAccepted
+}
+
+func (f *MfaFactor) RecoveryCodes() []RecoveryCode {
+	return f.recoveryCodes
+}
+
+// UsedRecoveryCode is the recovery code used since the factor was loaded,
+// if any.
+func (f *MfaFactor) UsedRecoveryCode() *RecoveryCode {
+	return f.usedRecoveryCode
+}
+
+// RecoveryCodesReplaced reports whether new recovery codes were issued
+// since the factor was loaded.
+func (f *MfaFactor) RecoveryCodesReplaced() bool {
+	return f.recoveryCodesReplaced
+}
+
+// RemainingRecoveryCodes counts the recovery codes that were not used.
+func (f *MfaFactor) RemainingRecoveryCodes() int {
+	remaining := 0
+	for _, code := range f.recoveryCodes {
+		if code.usedAt == nil {
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+func (f *MfaFactor) ConfirmedAt() *time.Time {
+	return f.confirmedAt
+}
+
+func (f *MfaFactor) IsConfirmed() bool {
+	return f.confirmedAt != nil
+}
+
+func (f *MfaFactor) CreatedAt() time.Time {
+	return f.createdAt
+}
+
+func (f *MfaFactor) Events() []events.Event {
+	return f.events
+}
+
+// Confirm enables the factor once the user entered a password from the
+// app, and returns the first recovery codes.
+func (f *MfaFactor) Confirm(code string, now time.Time) ([]string, error) {
+	if f.IsConfirmed() {
+		return nil, fmt.Errorf("%w: multi-factor authentication is already enabled", ErrConflict)
+	}
+
+	if !f.verifyTotp(code, now) {
+		return nil, ErrInvalidMfaCode
+	}
+
+	codes, err := f.replaceRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	f.confirmedAt = &now
+	f.events = append(f.events, events.NewMfaEnabled(f.id.Value(), f.userId.Value()))
+
+	return codes, nil
+}
+
+// Verify checks a password from the app or an unused recovery code. Each
+// of them is accepted once.
+func (f *MfaFactor) Verify(code string, now time.Time) error {
+	if !f.IsConfirmed() {
+		return fmt.Errorf("%w: multi-factor authentication is not enabled", ErrConflict)
+	}
+
+	code = normalizeMfaCode(code)
+	if f.verifyTotp(code, now) {
+		return nil
+	}
+
+	hash := vo.NewTokenHash(code)
+	for i, recoveryCode := range f.recoveryCodes {
+		if recoveryCode.usedAt == nil && recoveryCode.hash == hash {
+			f.recoveryCodes[i].usedAt = &now
+			f.usedRecoveryCode = &f.recoveryCodes[i]
+			f.events = append(f.events, events.NewRecoveryCodeUsed(f.id.Value(), f.userId.Value(), f.RemainingRecoveryCodes()))
+			return nil
+		}
+	}
+
+	return ErrInvalidMfaCode
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func (f *MfaFactor) RegenerateRecoveryCodes(code string, now time.Time) ([]string, error) {
+	if err := f.Verify(code, now); err != nil {
+		return nil, err
+	}
+
+	codes, err := f.replaceRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	f.events = append(f.events, events.NewRecoveryCodesRegenerated(f.id.Value(), f.userId.Value()))
+	return codes, nil
+}
+
+// Disable turns multi-factor authentication off, which takes a valid code
+// so that a stolen session alone cannot do it.
+func (f *MfaFactor) Disable(code string, now time.Time) error {
+	if err := f.Verify(code, now); err != nil {
+		return err
+	}
+
+	f.events = append(f.events, events.NewMfaDisabled(f.id.Value(), f.userId.Value()))
+	return nil
+}
+
+func (f *MfaFactor) verifyTotp(code string, now time.Time) bool {
+	counter, ok := f.secret.Verify(normalizeMfaCode(code), now)
+	if !ok || counter <= f.lastCounter {
+		return false
+	}
+
+	f.lastCounter = counter
+	f.totpAccepted = true
+	return true
+}
+
+func (f *MfaFactor) replaceRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	recoveryCodes := make([]RecoveryCode, 0, RecoveryCodeCount)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := base32.StdEncoding.EncodeToString(b)
+		codes = append(codes, code[:4]+"-"+code[4:])
+		recoveryCodes = append(recoveryCodes, RecoveryCode{hash: vo.NewTokenHash(code)})
+	}
+
+	f.recoveryCodes = recoveryCodes
+	f.recoveryCodesReplaced = true
+	return codes, nil
+}
+
+// normalizeMfaCode drops the separators users may type and uppercases
+// recovery codes.
+func normalizeMfaCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"iyaem/internal/domain/events"
 	vo "iyaem/internal/domain/valueobjects"
+	"time"
 )
 
+const ownerLevel = vo.MembershipLevel("owner")
+
 type Organization struct {
 	id         vo.OrganizationId
 	name       string
@@ -26,6 +29,16 @@ func NewOrganization(
 	return Organization{id, name, identifier, tenants, members, make([]events.Event, 0)}
 }
 
+// CreateOrganization starts a new organization with its owner as the only
+// member.
+func CreateOrganization(id vo.OrganizationId, name string, identifier string, owner Membership) Organization {
+	o := NewOrganization(id, name, identifier, make([]Membership, 0), make([]Tenant, 0))
+	o.events = append(o.events, events.NewOrganizationCreated(id.Value(), name, identifier))
+	o.AddMember(owner)
+
+	return o
+}
+
 func (o Organization) String() string {
 	return fmt.Sprint(o.id.Value(), " ", o.name, "\nTenants: ", o.tenants)
 }
@@ -74,11 +87,36 @@ func (o *Organization) FindMemberById(membershipId vo.MembershipId) *Membership
 	return nil
 }
 
+func (o *Organization) FindMemberByUserId(userId vo.UserId) *Membership {
+	for _, member := range o.members {
+		if member.userId.Equals(userId) {
+			return &member
+		}
+	}
+
+	return nil
+}
+
+func (o *Organization) FindTenantById(tenantId vo.TenantId) *Tenant {
+	for _, tenant := range o.tenants {
+		if tenant.id.Equals(tenantId) {
+			return &tenant
+		}
+	}
+
+	return nil
+}
+
 func (o *Organization) PromoteMember(m Membership) error {
 	for i, member := range o.members {
 		if member.id == m.id {
+			if member.level == ownerLevel {
+				return fmt.Errorf("%w: member is already an owner", ErrConflict)
+			}
+
+			previous := member.level
 			o.members[i].level = vo.MembershipLevel("manager")
-			o.events = append(o.events, events.NewMemberPromoted(m.id.Value()))
+			o.events = append(o.events, events.NewMemberPromoted(m.id.Value(), string(previous)))
 			return nil
 		}
 	}
@@ -86,11 +124,22 @@ func (o *Organization) PromoteMember(m Membership) error {
 	return fmt.Errorf("could not find member with id %v", m.id)
 }
 
-func (o *Organization) DemoteMember(m Membership) error {
+// DemoteMember lowers the level of a member by one: owners become
+// managers and managers members. Only an owner can demote an owner, so by
+// is the member acting, or the zero id when no member is.
+func (o *Organization) DemoteMember(by vo.MembershipId, m Membership) error {
 	for i, member := range o.members {
 		if member.id == m.id {
+			if err := o.requireOwnerFor(by, member); err != nil {
+				return err
+			}
+
+			previous := member.level
 			o.members[i].level = vo.MembershipLevel("member")
-			o.events = append(o.events, events.NewMemberDemoted(m.id.Value()))
+			if previous == ownerLevel {
+				o.members[i].level = vo.MembershipLevel("manager")
+			}
+			o.events = append(o.events, events.NewMemberDemoted(m.id.Value(), string(previous)))
 			return nil
 		}
 	}
@@ -172,14 +221,275 @@ func (o *Organization) RemoveGroupFromMember(membershipId vo.MembershipId, group
 	return fmt.Errorf("could not find member with id %v", membershipId)
 }
 
-func (o *Organization) RemoveMember(membershipId vo.MembershipId) error {
+// RemoveMember removes a member from the organization. Only an owner can
+// remove an owner, so by is the member acting, or the zero id when no
+// member is.
+func (o *Organization) RemoveMember(by vo.MembershipId, membershipId vo.MembershipId) error {
 	for i, member := range o.members {
 		if member.id == membershipId {
+			if err := o.requireOwnerFor(by, member); err != nil {
+				return err
+			}
+
 			o.members = append(o.members[:i], o.members[i+1:]...)
-			o.events = append(o.events, events.NewMemberRemoved(membershipId.Value()))
+			o.events = append(o.events, events.NewMemberRemoved(membershipId.Value(), member.userId.Value(), string(member.level)))
 			return nil
 		}
 	}
 
 	return fmt.Errorf("could not find member with id %v", membershipId)
 }
+
+// AddOwner makes a member an owner alongside the existing ones. Only an
+// owner can add another owner.
+func (o *Organization) AddOwner(by vo.MembershipId, membershipId vo.MembershipId) error {
+	if err := o.requireOwner(by); err != nil {
+		return err
+	}
+
+	for i, member := range o.members {
+		if member.id == membershipId {
+			if member.level == ownerLevel {
+				return fmt.Errorf("%w: member is already an owner", ErrConflict)
+			}
+
+			previous := member.level
+			o.members[i].level = ownerLevel
+			o.events = append(o.events, events.NewOwnerAdded(membershipId.Value(), string(previous)))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("member %v %w", membershipId.Value(), ErrNotFound)
+}
+
+// RequestOwnershipTransfer starts handing the ownership of an owner over to
+// another member. Nothing changes until the owner confirms the transfer
+// with TransferOwnership.
+func (o *Organization) RequestOwnershipTransfer(from vo.MembershipId, to vo.MembershipId, ttl time.Duration) (OwnershipTransfer, error) {
+	if err := o.requireOwner(from); err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	if from.Equals(to) {
+		return OwnershipTransfer{}, fmt.Errorf("%w: ownership cannot be transferred to the current owner", ErrInvalid)
+	}
+
+	member := o.FindMemberById(to)
+	if member == nil {
+		return OwnershipTransfer{}, fmt.Errorf("member %v %w", to.Value(), ErrNotFound)
+	}
+
+	if member.level == ownerLevel {
+		return OwnershipTransfer{}, fmt.Errorf("%w: member is already an owner", ErrConflict)
+	}
+
+	t := NewOwnershipTransfer(vo.GenerateOwnershipTransferId(), o.id, from, to, time.Now().Add(ttl), nil)
+	o.events = append(o.events, events.NewOwnershipTransferRequested(t.id.Value(), from.Value(), to.Value(), t.expiresAt))
+
+	return t, nil
+}
+
+// TransferOwnership completes a pending transfer. It must be confirmed by
+// the owner who requested it, who then stays on as a manager.
+func (o *Organization) TransferOwnership(t OwnershipTransfer, confirmedBy vo.MembershipId) error {
+	if !t.organizationId.Equals(o.id) {
+		return fmt.Errorf("ownership transfer %v %w", t.id.Value(), ErrNotFound)
+	}
+
+	if t.confirmedAt != nil {
+		return fmt.Errorf("%w: ownership transfer already confirmed", ErrConflict)
+	}
+
+	if time.Now().After(t.expiresAt) {
+		return fmt.Errorf("%w: ownership transfer expired", ErrConflict)
+	}
+
+	if !t.from.Equals(confirmedBy) {
+		return fmt.Errorf("%w: only the owner who requested the transfer can confirm it", ErrForbidden)
+	}
+
+	if err := o.requireOwner(t.from); err != nil {
+		return err
+	}
+
+	to := -1
+	for i, member := range o.members {
+		if member.id.Equals(t.to) {
+			to = i
+		}
+	}
+
+	if to < 0 {
+		return fmt.Errorf("member %v %w", t.to.Value(), ErrNotFound)
+	}
+
+	if o.members[to].level == ownerLevel {
+		return fmt.Errorf("%w: member is already an owner", ErrConflict)
+	}
+
+	previous := o.members[to].level
+	for i, member := range o.members {
+		if member.id.Equals(t.from) {
+			o.members[i].level = vo.MembershipLevel("manager")
+		}
+	}
+	o.members[to].level = ownerLevel
+
+	o.events = append(o.events, events.NewOwnershipTransferred(t.id.Value(), t.from.Value(), t.to.Value(), string(previous)))
+	return nil
+}
+
+// SetMfaRequired decides whether members must sign in with a second
+// factor. Only an owner can change it.
+func (o *Organization) SetMfaRequired(by vo.MembershipId, required bool) error {
+	member := o.FindMemberById(by)
+	if member == nil || member.level != ownerLevel {
+		return fmt.Errorf("%w: only an owner can change the multi-factor authentication policy", ErrForbidden)
+	}
+
+	o.events = append(o.events, events.NewOrganizationMfaRequirementChanged(o.id.Value(), required))
+	return nil
+}
+
+func (o *Organization) requireOwner(membershipId vo.MembershipId) error {
+	member := o.FindMemberById(membershipId)
+	if member == nil || member.level != ownerLevel {
+		return fmt.Errorf("%w: only an owner can manage the ownership of the organization", ErrForbidden)
+	}
+
+	return nil
+}
+
+// requireOwnerFor checks a change to the member: an owner can only be
+// changed by an owner, and not when they are the last one.
+func (o *Organization) requireOwnerFor(by vo.MembershipId, m Membership) error {
+	if m.level != ownerLevel {
+		return nil
+	}
+
+	if err := o.requireOwner(by); err != nil {
+		return err
+	}
+
+	return o.requireAnotherOwner(m)
+}
+
+// requireAnotherOwner rejects changes that take away the last owner.
+func (o *Organization) requireAnotherOwner(m Membership) error {
+	if m.level != ownerLevel {
+		return nil
+	}
+
+	for _, member := range o.members {
+		if member.level == ownerLevel && !member.id.Equals(m.id) {
+			return nil
+		}
+	}
+
+	return ErrLastOwner
+}
+
+// ImportMember applies a row of a member import. It adds the user as a
+// member, of the level when one is given, or changes the level of the
+// member they already are, then grants the roles and groups in the tenant.
+// Roles and groups the member already has are left alone. The whole row,
+// including whether the roles and groups are available in the tenant, is
+// checked before anything changes, so an invalid row changes nothing. An
+// import cannot change the level of an owner.
+func (o *Organization) ImportMember(userId vo.UserId, level vo.MembershipLevel, tenantId *vo.TenantId, roles []*Role, groups []*Group) (vo.MembershipId, string, error) {
+	if level != "" && level != "member" && level != "manager" {
+		return vo.MembershipId{}, "", fmt.Errorf("%w: level must be member or manager", ErrInvalid)
+	}
+
+	if tenantId == nil && (len(roles) > 0 || len(groups) > 0) {
+		return vo.MembershipId{}, "", fmt.Errorf("%w: roles and groups are granted in a tenant", ErrInvalid)
+	}
+
+	var tenant *Tenant
+	if tenantId != nil {
+		tenant = o.FindTenantById(*tenantId)
+		if tenant == nil {
+			return vo.MembershipId{}, "", fmt.Errorf("%w: tenant %s is not part of the organization", ErrInvalid, tenantId.Value())
+		}
+	}
+
+	for _, role := range roles {
+		if !role.IsAvailableIn(*tenant) {
+			return vo.MembershipId{}, "", fmt.Errorf("%w: role %s is not available in tenant", ErrInvalid, role.Id().Value())
+		}
+	}
+	for _, group := range groups {
+		if !group.IsAvailableIn(*tenant) {
+			return vo.MembershipId{}, "", fmt.Errorf("%w: group %s is not available in tenant", ErrInvalid, group.Id().Value())
+		}
+	}
+
+	member := o.FindMemberByUserId(userId)
+	if member != nil && member.level == ownerLevel && level != "" {
+		return vo.MembershipId{}, "", fmt.Errorf("%w: owners are changed through ownership transfers", ErrConflict)
+	}
+
+	// Nothing below fails: the member exists and is not an owner, and
+	// the roles and groups it already has are skipped.
+	outcome := MemberImportRowUnchanged
+	if member == nil {
+		if level == "" {
+			level = "member"
+		}
+
+		m := NewMembership(vo.GenerateMembershipId(), userId, o.id, level, make([]vo.UserRole, 0), make([]vo.UserGroup, 0))
+		o.AddMember(m)
+		member = &m
+		outcome = MemberImportRowCreated
+	} else if level != "" && level != member.level {
+		if level == "manager" {
+			o.PromoteMember(*member)
+		} else {
+			o.DemoteMember(vo.MembershipId{}, *member)
+		}
+		outcome = MemberImportRowUpdated
+	}
+
+	for _, role := range roles {
+		if hasRole(*o.FindMemberById(member.id), role.Id(), *tenantId) {
+			continue
+		}
+		o.AddRoleToMember(member.id, role.Id(), *tenantId)
+		if outcome == MemberImportRowUnchanged {
+			outcome = MemberImportRowUpdated
+		}
+	}
+
+	for _, group := range groups {
+		if hasGroup(*o.FindMemberById(member.id), group.Id(), *tenantId) {
+			continue
+		}
+		o.AddGroupToMember(member.id, group.Id(), *tenantId)
+		if outcome == MemberImportRowUnchanged {
+			outcome = MemberImportRowUpdated
+		}
+	}
+
+	return member.id, outcome, nil
+}
+
+func hasRole(m Membership, roleId vo.RoleId, tenantId vo.TenantId) bool {
+	for _, role := range m.roles {
+		if role.RoleId() == roleId && role.TenantId() == tenantId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasGroup(m Membership, groupId vo.GroupId, tenantId vo.TenantId) bool {
+	for _, group := range m.groups {
+		if group.GroupId() == groupId && group.TenantId() == tenantId {
+			return true
+		}
+	}
+
+	return false
+}
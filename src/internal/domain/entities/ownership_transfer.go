@@ -0,0 +1,53 @@
+package entities
+
+import (
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// OwnershipTransfer is a request by an owner to hand the organization over
+// to another member. It takes effect once the same owner confirms it,
+// before it expires.
+type OwnershipTransfer struct {
+	id             vo.OwnershipTransferId
+	organizationId vo.OrganizationId
+	from           vo.MembershipId
+	to             vo.MembershipId
+	expiresAt      time.Time
+	confirmedAt    *time.Time
+}
+
+func NewOwnershipTransfer(
+	id vo.OwnershipTransferId,
+	organizationId vo.OrganizationId,
+	from vo.MembershipId,
+	to vo.MembershipId,
+	expiresAt time.Time,
+	confirmedAt *time.Time,
+) OwnershipTransfer {
+	return OwnershipTransfer{id, organizationId, from, to, expiresAt, confirmedAt}
+}
+
+func (t OwnershipTransfer) Id() vo.OwnershipTransferId {
+	return t.id
+}
+
+func (t OwnershipTransfer) OrganizationId() vo.OrganizationId {
+	return t.organizationId
+}
+
+func (t OwnershipTransfer) From() vo.MembershipId {
+	return t.from
+}
+
+func (t OwnershipTransfer) To() vo.MembershipId {
+	return t.to
+}
+
+func (t OwnershipTransfer) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func (t OwnershipTransfer) ConfirmedAt() *time.Time {
+	return t.confirmedAt
+}
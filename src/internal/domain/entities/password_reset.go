@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// PasswordReset lets the owner of a credential's email choose a new
+// password. The owner proves they received it with the token sent by
+// email, which can be used once.
+type PasswordReset struct {
+	id           vo.PasswordResetId
+	credentialId vo.CredentialId
+	tokenHash    vo.TokenHash
+	expiresAt    time.Time
+	usedAt       *time.Time
+	createdAt    time.Time
+
+	events []events.Event
+}
+
+func NewPasswordReset(
+	id vo.PasswordResetId,
+	credentialId vo.CredentialId,
+	tokenHash vo.TokenHash,
+	expiresAt time.Time,
+	usedAt *time.Time,
+	createdAt time.Time,
+) PasswordReset {
+	return PasswordReset{id, credentialId, tokenHash, expiresAt, usedAt, createdAt, make([]events.Event, 0)}
+}
+
+// RequestPasswordReset returns a reset for the credential together with
+// the plaintext token, which is only known at this point.
+func RequestPasswordReset(credential *Credential, ttl time.Duration) (PasswordReset, string, error) {
+	token, tokenHash, err := vo.GenerateToken()
+	if err != nil {
+		return PasswordReset{}, "", err
+	}
+
+	now := time.Now()
+	r := NewPasswordReset(vo.GeneratePasswordResetId(), credential.Id(), tokenHash, now.Add(ttl), nil, now)
+	r.events = append(r.events, events.NewPasswordResetRequested(r.id.Value(), credential.Id().Value(), credential.UserId().Value(), r.expiresAt))
+
+	return r, token, nil
+}
+
+func (r *PasswordReset) Id() vo.PasswordResetId {
+	return r.id
+}
+
+func (r *PasswordReset) CredentialId() vo.CredentialId {
+	return r.credentialId
+}
+
+func (r *PasswordReset) TokenHash() vo.TokenHash {
+	return r.tokenHash
+}
+
+func (r *PasswordReset) ExpiresAt() time.Time {
+	return r.expiresAt
+}
+
+func (r *PasswordReset) UsedAt() *time.Time {
+	return r.usedAt
+}
+
+func (r *PasswordReset) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *PasswordReset) Events() []events.Event {
+	return r.events
+}
+
+func (r *PasswordReset) use(now time.Time) error {
+	if r.usedAt != nil {
+		return fmt.Errorf("%w: the reset was already used", ErrConflict)
+	}
+
+	if now.After(r.expiresAt) {
+		return fmt.Errorf("%w: the reset expired", ErrConflict)
+	}
+
+	r.usedAt = &now
+	return nil
+}
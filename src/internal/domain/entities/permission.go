@@ -22,6 +22,10 @@ func (u *Permission) Id() vo.PermissionId {
 	return u.id
 }
 
+func (u *Permission) ApplicationId() vo.ApplicationId {
+	return u.applicationId
+}
+
 func (u *Permission) Name() string {
 	return u.name
 }
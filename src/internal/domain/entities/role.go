@@ -2,6 +2,7 @@ package entities
 
 import (
 	"fmt"
+	"iyaem/internal/domain/events"
 	vo "iyaem/internal/domain/valueobjects"
 )
 
@@ -10,11 +11,49 @@ type Role struct {
 	name          string
 	description   string
 	applicationId vo.ApplicationId
+	tenantId      *vo.TenantId
 	permissions   []Permission
+
+	events []events.Event
+}
+
+func NewRole(
+	id vo.RoleId,
+	name string,
+	description string,
+	applicationId vo.ApplicationId,
+	tenantId *vo.TenantId,
+	permissions []Permission,
+) Role {
+	return Role{id, name, description, applicationId, tenantId, permissions, make([]events.Event, 0)}
+}
+
+// CreateRole defines a new role without permissions for an application.
+func CreateRole(name string, description string, applicationId vo.ApplicationId) (Role, error) {
+	return createRole(name, description, applicationId, nil)
+}
+
+// CreateTenantRole defines a new role owned by a tenant. It can only be
+// granted in that tenant and is managed by the tenant's organization.
+func CreateTenantRole(name string, description string, tenant Tenant) (Role, error) {
+	tenantId := tenant.id
+	return createRole(name, description, tenant.applicationId, &tenantId)
 }
 
-func NewRole(id vo.RoleId, name string, description string, applicationId vo.ApplicationId, permissions []Permission) Role {
-	return Role{id, name, description, applicationId, permissions}
+func createRole(name string, description string, applicationId vo.ApplicationId, tenantId *vo.TenantId) (Role, error) {
+	if err := requireName("role", name); err != nil {
+		return Role{}, err
+	}
+
+	owner := ""
+	if tenantId != nil {
+		owner = tenantId.Value()
+	}
+
+	u := NewRole(vo.GenerateRoleId(), name, description, applicationId, tenantId, make([]Permission, 0))
+	u.events = append(u.events, events.NewRoleCreated(u.id.Value(), applicationId.Value(), owner, name))
+
+	return u, nil
 }
 
 func (u Role) String() string {
@@ -29,10 +68,79 @@ func (u *Role) Name() string {
 	return u.name
 }
 
+func (u *Role) Description() string {
+	return u.description
+}
+
+func (u *Role) ApplicationId() vo.ApplicationId {
+	return u.applicationId
+}
+
+// TenantId returns the tenant owning the role, or nil for roles defined
+// by the application.
+func (u *Role) TenantId() *vo.TenantId {
+	return u.tenantId
+}
+
+// IsAvailableIn reports whether the role can be granted in the tenant.
+func (u *Role) IsAvailableIn(t Tenant) bool {
+	if !u.applicationId.Equals(t.applicationId) {
+		return false
+	}
+
+	return u.tenantId == nil || u.tenantId.Equals(t.id)
+}
+
 func (u *Role) Permissions() []Permission {
 	return u.permissions
 }
 
-func (u *Role) AddPermission(p Permission) {
+func (u *Role) Events() []events.Event {
+	return u.events
+}
+
+func (u *Role) Update(name string, description string) error {
+	if err := requireName("role", name); err != nil {
+		return err
+	}
+
+	previousName, previousDescription := u.name, u.description
+	u.name = name
+	u.description = description
+	u.events = append(u.events, events.NewRoleUpdated(u.id.Value(), name, description, previousName, previousDescription))
+	return nil
+}
+
+// AttachPermission grants a permission through the role. Only permissions
+// of the role's own application can be attached.
+func (u *Role) AttachPermission(p Permission) error {
+	if !p.applicationId.Equals(u.applicationId) {
+		return fmt.Errorf("%w: permission %s belongs to another application", ErrInvalid, p.id.Value())
+	}
+
+	for _, permission := range u.permissions {
+		if permission.id.Equals(p.id) {
+			return fmt.Errorf("%w: permission already attached", ErrConflict)
+		}
+	}
+
 	u.permissions = append(u.permissions, p)
+	u.events = append(u.events, events.NewPermissionAttachedToRole(u.id.Value(), p.id.Value()))
+	return nil
+}
+
+func (u *Role) DetachPermission(permissionId vo.PermissionId) error {
+	for i, permission := range u.permissions {
+		if permission.id.Equals(permissionId) {
+			u.permissions = append(u.permissions[:i], u.permissions[i+1:]...)
+			u.events = append(u.events, events.NewPermissionDetachedFromRole(u.id.Value(), permissionId.Value()))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("permission %v %w", permissionId.Value(), ErrNotFound)
+}
+
+func (u *Role) Delete() {
+	u.events = append(u.events, events.NewRoleDeleted(u.id.Value()))
 }
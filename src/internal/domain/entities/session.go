@@ -0,0 +1,228 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+// Reasons a session ends, recorded with SessionRevoked.
+const (
+	SessionSignedOut         = "signed_out"
+	SessionRevokedByUser     = "revoked"
+	SessionRefreshTokenReuse = "refresh_token_reuse"
+	SessionPasswordReset     = "password_reset"
+)
+
+// ErrInvalidRefreshToken is returned for a refresh token of a session that
+// expired or was revoked.
+var ErrInvalidRefreshToken = fmt.Errorf("%w: invalid refresh token", ErrForbidden)
+
+// Session is a user signed in on one device. Access tokens carry its id,
+// and a refresh token, replaced on every use, issues new ones until the
+// session expires or is revoked.
+//
+// A session of a user who must prove a second factor is pending until
+// they do; its tokens are only good for verifying or enrolling one.
+type Session struct {
+	id               vo.SessionId
+	userId           vo.UserId
+	userAgent        string
+	ipAddress        string
+	authMethod       string
+	mfaRequired      bool
+	mfaVerifiedAt    *time.Time
+	refreshTokenHash vo.TokenHash
+	createdAt        time.Time
+	lastUsedAt       time.Time
+	expiresAt        time.Time
+	revokedAt        *time.Time
+
+	events []events.Event
+}
+
+func NewSession(
+	id vo.SessionId,
+	userId vo.UserId,
+	userAgent string,
+	ipAddress string,
+	authMethod string,
+	mfaRequired bool,
+	mfaVerifiedAt *time.Time,
+	refreshTokenHash vo.TokenHash,
+	createdAt time.Time,
+	lastUsedAt time.Time,
+	expiresAt time.Time,
+	revokedAt *time.Time,
+) Session {
+	return Session{id, userId, userAgent, ipAddress, authMethod, mfaRequired, mfaVerifiedAt, refreshTokenHash, createdAt, lastUsedAt, expiresAt, revokedAt, make([]events.Event, 0)}
+}
+
+// StartSession signs in the user on a device for at most ttl, after they
+// authenticated with authMethod. It returns the session together with its
+// first refresh token, which is only known at this point.
+func StartSession(userId vo.UserId, userAgent string, ipAddress string, authMethod string, mfaRequired bool, ttl time.Duration) (Session, string, error) {
+	token, tokenHash, err := vo.GenerateToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	now := time.Now()
+	s := NewSession(vo.GenerateSessionId(), userId, userAgent, ipAddress, authMethod, mfaRequired, nil, tokenHash, now, now, now.Add(ttl), nil)
+	s.events = append(s.events, events.NewSessionStarted(s.id.Value(), userId.Value(), userAgent, ipAddress))
+
+	return s, token, nil
+}
+
+func (s *Session) Id() vo.SessionId {
+	return s.id
+}
+
+func (s *Session) UserId() vo.UserId {
+	return s.userId
+}
+
+func (s *Session) UserAgent() string {
+	return s.userAgent
+}
+
+func (s *Session) IpAddress() string {
+	return s.ipAddress
+}
+
+// AuthMethod is how the user authenticated when the session started, one
+// of the AuthMethod constants.
+func (s *Session) AuthMethod() string {
+	return s.authMethod
+}
+
+func (s *Session) MfaRequired() bool {
+	return s.mfaRequired
+}
+
+func (s *Session) MfaVerifiedAt() *time.Time {
+	return s.mfaVerifiedAt
+}
+
+// MfaPending reports whether the user still has to prove a second factor.
+func (s *Session) MfaPending() bool {
+	return s.mfaRequired && s.mfaVerifiedAt == nil
+}
+
+// AuthMethods lists the methods the user authenticated with, for the
+// "amr" claim.
+func (s *Session) AuthMethods() []string {
+	if s.mfaVerifiedAt == nil {
+		return []string{s.authMethod}
+	}
+
+	return []string{s.authMethod, AuthMethodOtp, AuthMethodMfa}
+}
+
+// Acr is the assurance level of the session, for the "acr" claim.
+func (s *Session) Acr() string {
+	if s.mfaVerifiedAt == nil {
+		return AssuranceSingleFactor
+	}
+
+	return AssuranceMultiFactor
+}
+
+// AuthTime is when the user last authenticated, which a second factor
+// verified as a step-up moves forward.
+func (s *Session) AuthTime() time.Time {
+	if s.mfaVerifiedAt == nil {
+		return s.createdAt
+	}
+
+	return *s.mfaVerifiedAt
+}
+
+// RequireMfa asks for a second factor before the session can be used any
+// further, unless one was already verified.
+func (s *Session) RequireMfa() {
+	s.mfaRequired = true
+}
+
+// VerifyMfa records that the user proved a second factor, either to
+// complete the sign in or to step up for a sensitive action.
+func (s *Session) VerifyMfa(now time.Time) error {
+	if !s.IsActive(now) {
+		return fmt.Errorf("%w: the session has ended", ErrConflict)
+	}
+
+	s.mfaVerifiedAt = &now
+	s.events = append(s.events, events.NewSessionMfaVerified(s.id.Value(), s.userId.Value()))
+	return nil
+}
+
+// RefreshTokenHash is the hash of the refresh token that can be used next.
+func (s *Session) RefreshTokenHash() vo.TokenHash {
+	return s.refreshTokenHash
+}
+
+func (s *Session) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Session) LastUsedAt() time.Time {
+	return s.lastUsedAt
+}
+
+func (s *Session) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+func (s *Session) RevokedAt() *time.Time {
+	return s.revokedAt
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.revokedAt == nil && now.Before(s.expiresAt)
+}
+
+func (s *Session) Events() []events.Event {
+	return s.events
+}
+
+// Refresh exchanges a refresh token of the session for the next one.
+// Presenting a token that was already exchanged means it was copied, so
+// the session is revoked before anyone can use it any further.
+func (s *Session) Refresh(tokenHash vo.TokenHash, ipAddress string, now time.Time) (string, error) {
+	if !s.IsActive(now) {
+		return "", ErrInvalidRefreshToken
+	}
+
+	if tokenHash != s.refreshTokenHash {
+		s.revoke(SessionRefreshTokenReuse, now)
+		return "", ErrInvalidRefreshToken
+	}
+
+	token, next, err := vo.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.refreshTokenHash = next
+	s.lastUsedAt = now
+	if ipAddress != "" {
+		s.ipAddress = ipAddress
+	}
+
+	return token, nil
+}
+
+func (s *Session) Revoke(reason string) error {
+	if s.revokedAt != nil {
+		return fmt.Errorf("%w: the session is already revoked", ErrConflict)
+	}
+
+	s.revoke(reason, time.Now())
+	return nil
+}
+
+func (s *Session) revoke(reason string, now time.Time) {
+	s.revokedAt = &now
+	s.events = append(s.events, events.NewSessionRevoked(s.id.Value(), s.userId.Value(), reason))
+}
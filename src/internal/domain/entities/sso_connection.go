@@ -0,0 +1,313 @@
+package entities
+
+import (
+	"fmt"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	SsoProtocolOIDC = "oidc"
+	SsoProtocolSAML = "saml"
+
+	// ssoMemberLevel is the level of users provisioned by a connection.
+	ssoMemberLevel = vo.MembershipLevel("member")
+)
+
+// SsoDomain is an email domain of a connection. Claiming a domain proves
+// nothing, so a domain only routes logins and vouches for emails once the
+// organization published its verification token in a DNS TXT record.
+type SsoDomain struct {
+	Name              string
+	VerificationToken string
+	VerifiedAt        *time.Time
+}
+
+func (d SsoDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// TxtRecordName is the DNS name the verification record is published at.
+func (d SsoDomain) TxtRecordName() string {
+	return "_iyaem-verification." + d.Name
+}
+
+// TxtRecordValue is the content of the verification record.
+func (d SsoDomain) TxtRecordValue() string {
+	return "iyaem-verification=" + d.VerificationToken
+}
+
+// SsoConnection lets the members of an organization sign in with the
+// organization's own identity provider. Logins are routed to it by the
+// organization identifier or by the domain of the user's email.
+type SsoConnection struct {
+	id             vo.SsoConnectionId
+	organizationId vo.OrganizationId
+	protocol       string
+	issuerUrl      string
+	clientId       string
+	clientSecret   string
+	domains        []SsoDomain
+
+	events []events.Event
+}
+
+func NewSsoConnection(
+	id vo.SsoConnectionId,
+	organizationId vo.OrganizationId,
+	protocol string,
+	issuerUrl string,
+	clientId string,
+	clientSecret string,
+	domains []SsoDomain,
+) SsoConnection {
+	return SsoConnection{id, organizationId, protocol, issuerUrl, clientId, clientSecret, domains, make([]events.Event, 0)}
+}
+
+// CreateSsoConnection registers an identity provider for the
+// organization. Only OpenID Connect is supported for now.
+func CreateSsoConnection(organizationId vo.OrganizationId, protocol string, issuerUrl string, clientId string, clientSecret string, domains []string) (SsoConnection, error) {
+	if protocol == SsoProtocolSAML {
+		return SsoConnection{}, fmt.Errorf("%w: SAML connections are not supported yet", ErrInvalid)
+	}
+	if protocol != SsoProtocolOIDC {
+		return SsoConnection{}, fmt.Errorf("%w: unknown protocol %q", ErrInvalid, protocol)
+	}
+
+	c := NewSsoConnection(vo.GenerateSsoConnectionId(), organizationId, protocol, "", "", "", nil)
+	if err := c.configure(issuerUrl, clientId, clientSecret, domains); err != nil {
+		return SsoConnection{}, err
+	}
+
+	c.events = append(c.events, events.NewSsoConnectionCreated(
+		c.id.Value(), organizationId.Value(), protocol, c.issuerUrl, c.clientId, strings.Join(c.Domains(), ","),
+	))
+
+	return c, nil
+}
+
+func (c *SsoConnection) Id() vo.SsoConnectionId {
+	return c.id
+}
+
+func (c *SsoConnection) OrganizationId() vo.OrganizationId {
+	return c.organizationId
+}
+
+func (c *SsoConnection) Protocol() string {
+	return c.protocol
+}
+
+func (c *SsoConnection) IssuerUrl() string {
+	return c.issuerUrl
+}
+
+func (c *SsoConnection) ClientId() string {
+	return c.clientId
+}
+
+func (c *SsoConnection) ClientSecret() string {
+	return c.clientSecret
+}
+
+// Domains returns the names of the domains of the connection, verified or
+// not.
+func (c *SsoConnection) Domains() []string {
+	names := make([]string, 0, len(c.domains))
+	for _, d := range c.domains {
+		names = append(names, d.Name)
+	}
+
+	return names
+}
+
+func (c *SsoConnection) DomainVerifications() []SsoDomain {
+	return c.domains
+}
+
+// VerifiedDomains returns the names of the domains the organization proved
+// it owns.
+func (c *SsoConnection) VerifiedDomains() []string {
+	names := make([]string, 0, len(c.domains))
+	for _, d := range c.domains {
+		if d.IsVerified() {
+			names = append(names, d.Name)
+		}
+	}
+
+	return names
+}
+
+// Domain returns the domain of the connection with the name.
+func (c *SsoConnection) Domain(name string) (SsoDomain, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for _, d := range c.domains {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+
+	return SsoDomain{}, fmt.Errorf("could not find domain %s: %w", name, ErrNotFound)
+}
+
+// VerifyDomain marks the domain as verified when one of the TXT records
+// published at its TxtRecordName holds its verification token.
+func (c *SsoConnection) VerifyDomain(name string, records []string) error {
+	d, err := c.Domain(name)
+	if err != nil {
+		return err
+	}
+	if d.IsVerified() {
+		return nil
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) != d.TxtRecordValue() {
+			continue
+		}
+
+		for i := range c.domains {
+			if c.domains[i].Name == d.Name {
+				now := time.Now()
+				c.domains[i].VerifiedAt = &now
+			}
+		}
+		c.events = append(c.events, events.NewSsoDomainVerified(c.id.Value(), c.organizationId.Value(), d.Name))
+		return nil
+	}
+
+	return fmt.Errorf("%w: no TXT record %s at %s", ErrForbidden, d.TxtRecordValue(), d.TxtRecordName())
+}
+
+func (c *SsoConnection) Events() []events.Event {
+	return c.events
+}
+
+// Update changes the identity provider settings. An empty secret keeps
+// the current one.
+func (c *SsoConnection) Update(issuerUrl string, clientId string, clientSecret string, domains []string) error {
+	previousIssuerUrl, previousClientId, previousDomains := c.issuerUrl, c.clientId, strings.Join(c.Domains(), ",")
+
+	if clientSecret == "" {
+		clientSecret = c.clientSecret
+	}
+
+	if err := c.configure(issuerUrl, clientId, clientSecret, domains); err != nil {
+		return err
+	}
+
+	c.events = append(c.events, events.NewSsoConnectionUpdated(
+		c.id.Value(), c.organizationId.Value(), c.issuerUrl, c.clientId, strings.Join(c.Domains(), ","),
+		previousIssuerUrl, previousClientId, previousDomains,
+	))
+	return nil
+}
+
+func (c *SsoConnection) Delete() {
+	c.events = append(c.events, events.NewSsoConnectionDeleted(c.id.Value(), c.organizationId.Value()))
+}
+
+// Subject returns the identity a user of this connection is known by, so
+// that subjects of different identity providers cannot collide.
+func (c *SsoConnection) Subject(subject string) string {
+	return "sso|" + c.id.Value() + "|" + subject
+}
+
+// AllowsEmail reports whether the identity provider is trusted for the
+// email, i.e. whether the email belongs to one of the verified domains of
+// the connection.
+func (c *SsoConnection) AllowsEmail(email string) bool {
+	domain := EmailDomain(email)
+	for _, d := range c.domains {
+		if d.Name == domain && d.IsVerified() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Provision returns the default membership of a user who signed in with
+// the connection for the first time.
+func (c *SsoConnection) Provision(userId vo.UserId, email string) (Membership, error) {
+	if !c.AllowsEmail(email) {
+		return Membership{}, fmt.Errorf("%w: %s is not in a verified domain of the SSO connection", ErrForbidden, email)
+	}
+
+	return NewMembership(
+		vo.GenerateMembershipId(),
+		userId,
+		c.organizationId,
+		ssoMemberLevel,
+		make([]vo.UserRole, 0),
+		make([]vo.UserGroup, 0),
+	), nil
+}
+
+// EmailDomain returns the normalized domain of an email address.
+func EmailDomain(email string) string {
+	email = NormalizeEmail(email)
+	return email[strings.LastIndex(email, "@")+1:]
+}
+
+func (c *SsoConnection) configure(issuerUrl string, clientId string, clientSecret string, domains []string) error {
+	issuer, err := url.Parse(strings.TrimSpace(issuerUrl))
+	if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+		return fmt.Errorf("%w: the issuer must be an https URL", ErrInvalid)
+	}
+
+	if strings.TrimSpace(clientId) == "" || clientSecret == "" {
+		return fmt.Errorf("%w: client id and secret are required", ErrInvalid)
+	}
+
+	if len(domains) == 0 {
+		return fmt.Errorf("%w: at least one email domain is required", ErrInvalid)
+	}
+
+	normalized := make([]SsoDomain, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || strings.ContainsAny(domain, "@/ ") || !strings.Contains(domain, ".") {
+			return fmt.Errorf("%w: invalid email domain %q", ErrInvalid, domain)
+		}
+
+		var err error
+		normalized, err = c.appendDomain(normalized, domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.issuerUrl = issuer.String()
+	c.clientId = strings.TrimSpace(clientId)
+	c.clientSecret = clientSecret
+	c.domains = normalized
+	return nil
+}
+
+// appendDomain adds the domain unless it is listed already. A domain the
+// connection already had keeps its verification.
+func (c *SsoConnection) appendDomain(domains []SsoDomain, domain string) ([]SsoDomain, error) {
+	for _, d := range domains {
+		if d.Name == domain {
+			return domains, nil
+		}
+	}
+
+	for _, d := range c.domains {
+		if d.Name == domain {
+			return append(domains, d), nil
+		}
+	}
+
+	token, _, err := vo.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(domains, SsoDomain{Name: domain, VerificationToken: token}), nil
+}
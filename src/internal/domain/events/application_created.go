@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApplicationCreated struct {
+	ApplicationId   string    `json:"application_id"`
+	ApplicationName string    `json:"name"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func NewApplicationCreated(applicationId, applicationName string) ApplicationCreated {
+	return ApplicationCreated{ApplicationId: applicationId, ApplicationName: applicationName, Timestamp: time.Now()}
+}
+
+func (k ApplicationCreated) Name() string {
+	return "application_created"
+}
+
+func (k ApplicationCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k ApplicationCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApplicationDeleted struct {
+	ApplicationId string    `json:"application_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewApplicationDeleted(applicationId string) ApplicationDeleted {
+	return ApplicationDeleted{ApplicationId: applicationId, Timestamp: time.Now()}
+}
+
+func (k ApplicationDeleted) Name() string {
+	return "application_deleted"
+}
+
+func (k ApplicationDeleted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k ApplicationDeleted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApplicationRenamed struct {
+	ApplicationId   string    `json:"application_id"`
+	ApplicationName string    `json:"name"`
+	PreviousName    string    `json:"previous_name"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func NewApplicationRenamed(applicationId, applicationName, previousName string) ApplicationRenamed {
+	return ApplicationRenamed{ApplicationId: applicationId, ApplicationName: applicationName, PreviousName: previousName, Timestamp: time.Now()}
+}
+
+func (k ApplicationRenamed) Name() string {
+	return "application_renamed"
+}
+
+func (k ApplicationRenamed) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k ApplicationRenamed) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
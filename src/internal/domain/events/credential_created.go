@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CredentialCreated struct {
+	CredentialId string    `json:"credential_id"`
+	UserId       string    `json:"user_id"`
+	Email        string    `json:"email"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewCredentialCreated(credentialId, userId, email string) CredentialCreated {
+	return CredentialCreated{CredentialId: credentialId, UserId: userId, Email: email, Timestamp: time.Now()}
+}
+
+func (k CredentialCreated) Name() string {
+	return "credential_created"
+}
+
+func (k CredentialCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k CredentialCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CredentialLocked struct {
+	CredentialId string    `json:"credential_id"`
+	UserId       string    `json:"user_id"`
+	LockedUntil  time.Time `json:"locked_until"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewCredentialLocked(credentialId, userId string, lockedUntil time.Time) CredentialLocked {
+	return CredentialLocked{CredentialId: credentialId, UserId: userId, LockedUntil: lockedUntil, Timestamp: time.Now()}
+}
+
+func (k CredentialLocked) Name() string {
+	return "credential_locked"
+}
+
+func (k CredentialLocked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k CredentialLocked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -6,6 +6,7 @@ import (
 )
 
 type Event interface {
+	Name() string
 	OccuredOn() time.Time
 	JSON() ([]byte, error)
 }
@@ -13,7 +13,11 @@ type GroupAddedToMember struct {
 }
 
 func NewGroupAddedToMember(membershipId, groupId, tenantId string) GroupAddedToMember {
-	return GroupAddedToMember{MembershipId: membershipId, GroupId: groupId, TenantId: tenantId}
+	return GroupAddedToMember{MembershipId: membershipId, GroupId: groupId, TenantId: tenantId, Timestamp: time.Now()}
+}
+
+func (k GroupAddedToMember) Name() string {
+	return "group_added_to_member"
 }
 
 func (k GroupAddedToMember) OccuredOn() time.Time {
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GroupCreated struct {
+	GroupId       string    `json:"group_id"`
+	ApplicationId string    `json:"application_id"`
+	TenantId      string    `json:"tenant_id,omitempty"`
+	GroupName     string    `json:"name"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewGroupCreated(groupId, applicationId, tenantId, groupName string) GroupCreated {
+	return GroupCreated{GroupId: groupId, ApplicationId: applicationId, TenantId: tenantId, GroupName: groupName, Timestamp: time.Now()}
+}
+
+func (k GroupCreated) Name() string {
+	return "group_created"
+}
+
+func (k GroupCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k GroupCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
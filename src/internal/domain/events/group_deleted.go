@@ -0,0 +1,27 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GroupDeleted struct {
+	GroupId   string    `json:"group_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewGroupDeleted(groupId string) GroupDeleted {
+	return GroupDeleted{GroupId: groupId, Timestamp: time.Now()}
+}
+
+func (k GroupDeleted) Name() string {
+	return "group_deleted"
+}
+
+func (k GroupDeleted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k GroupDeleted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
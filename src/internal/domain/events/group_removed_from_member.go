@@ -13,7 +13,11 @@ type GroupRemovedFromMember struct {
 }
 
 func NewGroupRemovedFromMember(membershipId, groupId, tenantId string) GroupRemovedFromMember {
-	return GroupRemovedFromMember{MembershipId: membershipId, GroupId: groupId, TenantId: tenantId}
+	return GroupRemovedFromMember{MembershipId: membershipId, GroupId: groupId, TenantId: tenantId, Timestamp: time.Now()}
+}
+
+func (k GroupRemovedFromMember) Name() string {
+	return "group_removed_from_member"
 }
 
 func (k GroupRemovedFromMember) OccuredOn() time.Time {
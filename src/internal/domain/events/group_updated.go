@@ -0,0 +1,31 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GroupUpdated struct {
+	GroupId             string    `json:"group_id"`
+	GroupName           string    `json:"name"`
+	Description         string    `json:"description"`
+	PreviousName        string    `json:"previous_name"`
+	PreviousDescription string    `json:"previous_description"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+func NewGroupUpdated(groupId, groupName, description, previousName, previousDescription string) GroupUpdated {
+	return GroupUpdated{GroupId: groupId, GroupName: groupName, Description: description, PreviousName: previousName, PreviousDescription: previousDescription, Timestamp: time.Now()}
+}
+
+func (k GroupUpdated) Name() string {
+	return "group_updated"
+}
+
+func (k GroupUpdated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k GroupUpdated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type IdentityLinkRequested struct {
+	UserId         string    `json:"user_id"`
+	OrganizationId string    `json:"organization_id"`
+	IdpId          string    `json:"idp_id"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewIdentityLinkRequested(userId, organizationId, idpId string) IdentityLinkRequested {
+	return IdentityLinkRequested{UserId: userId, OrganizationId: organizationId, IdpId: idpId, Timestamp: time.Now()}
+}
+
+func (k IdentityLinkRequested) Name() string {
+	return "identity_link_requested"
+}
+
+func (k IdentityLinkRequested) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k IdentityLinkRequested) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
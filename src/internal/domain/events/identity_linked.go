@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type IdentityLinked struct {
+	UserId         string    `json:"user_id"`
+	OrganizationId string    `json:"organization_id"`
+	IdpId          string    `json:"idp_id"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewIdentityLinked(userId, organizationId, idpId string) IdentityLinked {
+	return IdentityLinked{UserId: userId, OrganizationId: organizationId, IdpId: idpId, Timestamp: time.Now()}
+}
+
+func (k IdentityLinked) Name() string {
+	return "identity_linked"
+}
+
+func (k IdentityLinked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k IdentityLinked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type InvitationAccepted struct {
+	InvitationId   string    `json:"invitation_id"`
+	OrganizationId string    `json:"organization_id"`
+	Email          string    `json:"email"`
+	MembershipId   string    `json:"membership_id"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewInvitationAccepted(invitationId, organizationId, email, membershipId string) InvitationAccepted {
+	return InvitationAccepted{InvitationId: invitationId, OrganizationId: organizationId, Email: email, MembershipId: membershipId, Timestamp: time.Now()}
+}
+
+func (k InvitationAccepted) Name() string {
+	return "invitation_accepted"
+}
+
+func (k InvitationAccepted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k InvitationAccepted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,32 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type InvitationCreated struct {
+	InvitationId   string    `json:"invitation_id"`
+	OrganizationId string    `json:"organization_id"`
+	Email          string    `json:"email"`
+	Level          string    `json:"level"`
+	InvitedBy      string    `json:"invited_by"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewInvitationCreated(invitationId, organizationId, email, level, invitedBy string, expiresAt time.Time) InvitationCreated {
+	return InvitationCreated{InvitationId: invitationId, OrganizationId: organizationId, Email: email, Level: level, InvitedBy: invitedBy, ExpiresAt: expiresAt, Timestamp: time.Now()}
+}
+
+func (k InvitationCreated) Name() string {
+	return "invitation_created"
+}
+
+func (k InvitationCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k InvitationCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type InvitationDeclined struct {
+	InvitationId   string    `json:"invitation_id"`
+	OrganizationId string    `json:"organization_id"`
+	Email          string    `json:"email"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewInvitationDeclined(invitationId, organizationId, email string) InvitationDeclined {
+	return InvitationDeclined{InvitationId: invitationId, OrganizationId: organizationId, Email: email, Timestamp: time.Now()}
+}
+
+func (k InvitationDeclined) Name() string {
+	return "invitation_declined"
+}
+
+func (k InvitationDeclined) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k InvitationDeclined) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
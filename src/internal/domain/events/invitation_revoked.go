@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type InvitationRevoked struct {
+	InvitationId   string    `json:"invitation_id"`
+	OrganizationId string    `json:"organization_id"`
+	Email          string    `json:"email"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewInvitationRevoked(invitationId, organizationId, email string) InvitationRevoked {
+	return InvitationRevoked{InvitationId: invitationId, OrganizationId: organizationId, Email: email, Timestamp: time.Now()}
+}
+
+func (k InvitationRevoked) Name() string {
+	return "invitation_revoked"
+}
+
+func (k InvitationRevoked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k InvitationRevoked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -13,7 +13,11 @@ type MemberAdded struct {
 }
 
 func NewMemberAdded(membershipId, userId, level string) MemberAdded {
-	return MemberAdded{MembershipId: membershipId, UserId: userId, Level: level}
+	return MemberAdded{MembershipId: membershipId, UserId: userId, Level: level, Timestamp: time.Now()}
+}
+
+func (k MemberAdded) Name() string {
+	return "member_added"
 }
 
 func (k MemberAdded) OccuredOn() time.Time {
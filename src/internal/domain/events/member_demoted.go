@@ -6,12 +6,17 @@ import (
 )
 
 type MemberDemoted struct {
-	MembershipId string    `json:"membership_id"`
-	Timestamp    time.Time `json:"timestamp"`
+	MembershipId  string    `json:"membership_id"`
+	PreviousLevel string    `json:"previous_level"`
+	Timestamp     time.Time `json:"timestamp"`
 }
 
-func NewMemberDemoted(membershipId string) MemberDemoted {
-	return MemberDemoted{MembershipId: membershipId}
+func NewMemberDemoted(membershipId, previousLevel string) MemberDemoted {
+	return MemberDemoted{MembershipId: membershipId, PreviousLevel: previousLevel, Timestamp: time.Now()}
+}
+
+func (k MemberDemoted) Name() string {
+	return "member_demoted"
 }
 
 func (k MemberDemoted) OccuredOn() time.Time {
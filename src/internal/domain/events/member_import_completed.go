@@ -0,0 +1,33 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MemberImportCompleted struct {
+	ImportId       string    `json:"import_id"`
+	OrganizationId string    `json:"organization_id"`
+	DryRun         bool      `json:"dry_run"`
+	Created        int       `json:"created"`
+	Updated        int       `json:"updated"`
+	Unchanged      int       `json:"unchanged"`
+	Failed         int       `json:"failed"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewMemberImportCompleted(importId, organizationId string, dryRun bool, created, updated, unchanged, failed int) MemberImportCompleted {
+	return MemberImportCompleted{ImportId: importId, OrganizationId: organizationId, DryRun: dryRun, Created: created, Updated: updated, Unchanged: unchanged, Failed: failed, Timestamp: time.Now()}
+}
+
+func (k MemberImportCompleted) Name() string {
+	return "member_import_completed"
+}
+
+func (k MemberImportCompleted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k MemberImportCompleted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
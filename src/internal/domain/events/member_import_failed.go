@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MemberImportFailed struct {
+	ImportId       string    `json:"import_id"`
+	OrganizationId string    `json:"organization_id"`
+	Reason         string    `json:"reason"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewMemberImportFailed(importId, organizationId, reason string) MemberImportFailed {
+	return MemberImportFailed{ImportId: importId, OrganizationId: organizationId, Reason: reason, Timestamp: time.Now()}
+}
+
+func (k MemberImportFailed) Name() string {
+	return "member_import_failed"
+}
+
+func (k MemberImportFailed) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k MemberImportFailed) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MemberImportRequested struct {
+	ImportId       string    `json:"import_id"`
+	OrganizationId string    `json:"organization_id"`
+	RequestedBy    string    `json:"requested_by"`
+	DryRun         bool      `json:"dry_run"`
+	Rows           int       `json:"rows"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewMemberImportRequested(importId, organizationId, requestedBy string, dryRun bool, rows int) MemberImportRequested {
+	return MemberImportRequested{ImportId: importId, OrganizationId: organizationId, RequestedBy: requestedBy, DryRun: dryRun, Rows: rows, Timestamp: time.Now()}
+}
+
+func (k MemberImportRequested) Name() string {
+	return "member_import_requested"
+}
+
+func (k MemberImportRequested) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k MemberImportRequested) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
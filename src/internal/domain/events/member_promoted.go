@@ -6,12 +6,17 @@ import (
 )
 
 type MemberPromoted struct {
-	MembershipId string    `json:"membership_id"`
-	Timestamp    time.Time `json:"timestamp"`
+	MembershipId  string    `json:"membership_id"`
+	PreviousLevel string    `json:"previous_level"`
+	Timestamp     time.Time `json:"timestamp"`
 }
 
-func NewMemberPromoted(membershipId string) MemberPromoted {
-	return MemberPromoted{MembershipId: membershipId}
+func NewMemberPromoted(membershipId, previousLevel string) MemberPromoted {
+	return MemberPromoted{MembershipId: membershipId, PreviousLevel: previousLevel, Timestamp: time.Now()}
+}
+
+func (k MemberPromoted) Name() string {
+	return "member_promoted"
 }
 
 func (k MemberPromoted) OccuredOn() time.Time {
@@ -7,11 +7,17 @@ import (
 
 type MemberRemoved struct {
 	MembershipId string    `json:"membership_id"`
+	UserId       string    `json:"user_id"`
+	Level        string    `json:"level"`
 	Timestamp    time.Time `json:"timestamp"`
 }
 
-func NewMemberRemoved(membershipId string) MemberRemoved {
-	return MemberRemoved{MembershipId: membershipId}
+func NewMemberRemoved(membershipId, userId, level string) MemberRemoved {
+	return MemberRemoved{MembershipId: membershipId, UserId: userId, Level: level, Timestamp: time.Now()}
+}
+
+func (k MemberRemoved) Name() string {
+	return "member_removed"
 }
 
 func (k MemberRemoved) OccuredOn() time.Time {
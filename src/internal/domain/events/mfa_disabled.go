@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MfaDisabled struct {
+	FactorId  string    `json:"factor_id"`
+	UserId    string    `json:"user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewMfaDisabled(factorId, userId string) MfaDisabled {
+	return MfaDisabled{FactorId: factorId, UserId: userId, Timestamp: time.Now()}
+}
+
+func (k MfaDisabled) Name() string {
+	return "mfa_disabled"
+}
+
+func (k MfaDisabled) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k MfaDisabled) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MfaEnabled struct {
+	FactorId  string    `json:"factor_id"`
+	UserId    string    `json:"user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewMfaEnabled(factorId, userId string) MfaEnabled {
+	return MfaEnabled{FactorId: factorId, UserId: userId, Timestamp: time.Now()}
+}
+
+func (k MfaEnabled) Name() string {
+	return "mfa_enabled"
+}
+
+func (k MfaEnabled) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k MfaEnabled) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
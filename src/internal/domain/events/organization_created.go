@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OrganizationCreated struct {
+	OrganizationId   string    `json:"organization_id"`
+	OrganizationName string    `json:"name"`
+	Identifier       string    `json:"identifier"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+func NewOrganizationCreated(organizationId, organizationName, identifier string) OrganizationCreated {
+	return OrganizationCreated{OrganizationId: organizationId, OrganizationName: organizationName, Identifier: identifier, Timestamp: time.Now()}
+}
+
+func (k OrganizationCreated) Name() string {
+	return "organization_created"
+}
+
+func (k OrganizationCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k OrganizationCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OrganizationMfaRequirementChanged struct {
+	OrganizationId string    `json:"organization_id"`
+	Required       bool      `json:"required"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewOrganizationMfaRequirementChanged(organizationId string, required bool) OrganizationMfaRequirementChanged {
+	return OrganizationMfaRequirementChanged{OrganizationId: organizationId, Required: required, Timestamp: time.Now()}
+}
+
+func (k OrganizationMfaRequirementChanged) Name() string {
+	return "organization_mfa_requirement_changed"
+}
+
+func (k OrganizationMfaRequirementChanged) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k OrganizationMfaRequirementChanged) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
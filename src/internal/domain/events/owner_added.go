@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OwnerAdded struct {
+	MembershipId  string    `json:"membership_id"`
+	PreviousLevel string    `json:"previous_level"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewOwnerAdded(membershipId, previousLevel string) OwnerAdded {
+	return OwnerAdded{MembershipId: membershipId, PreviousLevel: previousLevel, Timestamp: time.Now()}
+}
+
+func (k OwnerAdded) Name() string {
+	return "owner_added"
+}
+
+func (k OwnerAdded) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k OwnerAdded) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OwnershipTransferRequested struct {
+	TransferId       string    `json:"transfer_id"`
+	FromMembershipId string    `json:"from_membership_id"`
+	ToMembershipId   string    `json:"to_membership_id"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+func NewOwnershipTransferRequested(transferId, fromMembershipId, toMembershipId string, expiresAt time.Time) OwnershipTransferRequested {
+	return OwnershipTransferRequested{TransferId: transferId, FromMembershipId: fromMembershipId, ToMembershipId: toMembershipId, ExpiresAt: expiresAt, Timestamp: time.Now()}
+}
+
+func (k OwnershipTransferRequested) Name() string {
+	return "ownership_transfer_requested"
+}
+
+func (k OwnershipTransferRequested) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k OwnershipTransferRequested) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OwnershipTransferred struct {
+	TransferId       string    `json:"transfer_id"`
+	FromMembershipId string    `json:"from_membership_id"`
+	ToMembershipId   string    `json:"to_membership_id"`
+	PreviousLevel    string    `json:"previous_level"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+func NewOwnershipTransferred(transferId, fromMembershipId, toMembershipId, previousLevel string) OwnershipTransferred {
+	return OwnershipTransferred{TransferId: transferId, FromMembershipId: fromMembershipId, ToMembershipId: toMembershipId, PreviousLevel: previousLevel, Timestamp: time.Now()}
+}
+
+func (k OwnershipTransferred) Name() string {
+	return "ownership_transferred"
+}
+
+func (k OwnershipTransferred) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k OwnershipTransferred) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PasswordChanged struct {
+	CredentialId string    `json:"credential_id"`
+	UserId       string    `json:"user_id"`
+	Reason       string    `json:"reason"`
+	ResetId      string    `json:"reset_id"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewPasswordChanged(credentialId, userId, reason, resetId string) PasswordChanged {
+	return PasswordChanged{CredentialId: credentialId, UserId: userId, Reason: reason, ResetId: resetId, Timestamp: time.Now()}
+}
+
+func (k PasswordChanged) Name() string {
+	return "password_changed"
+}
+
+func (k PasswordChanged) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PasswordChanged) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
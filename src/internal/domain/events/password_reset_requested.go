@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PasswordResetRequested struct {
+	ResetId      string    `json:"reset_id"`
+	CredentialId string    `json:"credential_id"`
+	UserId       string    `json:"user_id"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewPasswordResetRequested(resetId, credentialId, userId string, expiresAt time.Time) PasswordResetRequested {
+	return PasswordResetRequested{ResetId: resetId, CredentialId: credentialId, UserId: userId, ExpiresAt: expiresAt, Timestamp: time.Now()}
+}
+
+func (k PasswordResetRequested) Name() string {
+	return "password_reset_requested"
+}
+
+func (k PasswordResetRequested) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PasswordResetRequested) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
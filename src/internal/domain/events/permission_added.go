@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PermissionAdded struct {
+	ApplicationId  string    `json:"application_id"`
+	PermissionId   string    `json:"permission_id"`
+	PermissionName string    `json:"name"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewPermissionAdded(applicationId, permissionId, permissionName string) PermissionAdded {
+	return PermissionAdded{ApplicationId: applicationId, PermissionId: permissionId, PermissionName: permissionName, Timestamp: time.Now()}
+}
+
+func (k PermissionAdded) Name() string {
+	return "permission_added"
+}
+
+func (k PermissionAdded) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PermissionAdded) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PermissionAttachedToRole struct {
+	RoleId       string    `json:"role_id"`
+	PermissionId string    `json:"permission_id"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewPermissionAttachedToRole(roleId, permissionId string) PermissionAttachedToRole {
+	return PermissionAttachedToRole{RoleId: roleId, PermissionId: permissionId, Timestamp: time.Now()}
+}
+
+func (k PermissionAttachedToRole) Name() string {
+	return "permission_attached_to_role"
+}
+
+func (k PermissionAttachedToRole) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PermissionAttachedToRole) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
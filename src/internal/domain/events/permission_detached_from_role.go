@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PermissionDetachedFromRole struct {
+	RoleId       string    `json:"role_id"`
+	PermissionId string    `json:"permission_id"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewPermissionDetachedFromRole(roleId, permissionId string) PermissionDetachedFromRole {
+	return PermissionDetachedFromRole{RoleId: roleId, PermissionId: permissionId, Timestamp: time.Now()}
+}
+
+func (k PermissionDetachedFromRole) Name() string {
+	return "permission_detached_from_role"
+}
+
+func (k PermissionDetachedFromRole) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PermissionDetachedFromRole) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
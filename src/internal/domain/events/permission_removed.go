@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PermissionRemoved struct {
+	ApplicationId string    `json:"application_id"`
+	PermissionId  string    `json:"permission_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewPermissionRemoved(applicationId, permissionId string) PermissionRemoved {
+	return PermissionRemoved{ApplicationId: applicationId, PermissionId: permissionId, Timestamp: time.Now()}
+}
+
+func (k PermissionRemoved) Name() string {
+	return "permission_removed"
+}
+
+func (k PermissionRemoved) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PermissionRemoved) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PermissionRenamed struct {
+	ApplicationId  string    `json:"application_id"`
+	PermissionId   string    `json:"permission_id"`
+	PermissionName string    `json:"name"`
+	PreviousName   string    `json:"previous_name"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewPermissionRenamed(applicationId, permissionId, permissionName, previousName string) PermissionRenamed {
+	return PermissionRenamed{ApplicationId: applicationId, PermissionId: permissionId, PermissionName: permissionName, PreviousName: previousName, Timestamp: time.Now()}
+}
+
+func (k PermissionRenamed) Name() string {
+	return "permission_renamed"
+}
+
+func (k PermissionRenamed) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PermissionRenamed) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
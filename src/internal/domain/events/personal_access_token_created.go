@@ -0,0 +1,32 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PersonalAccessTokenCreated struct {
+	TokenId         string    `json:"token_id"`
+	UserId          string    `json:"user_id"`
+	TokenName       string    `json:"token_name"`
+	Scopes          []string  `json:"scopes"`
+	OrganizationIds []string  `json:"organization_ids"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func NewPersonalAccessTokenCreated(tokenId, userId, tokenName string, scopes, organizationIds []string, expiresAt time.Time) PersonalAccessTokenCreated {
+	return PersonalAccessTokenCreated{TokenId: tokenId, UserId: userId, TokenName: tokenName, Scopes: scopes, OrganizationIds: organizationIds, ExpiresAt: expiresAt, Timestamp: time.Now()}
+}
+
+func (k PersonalAccessTokenCreated) Name() string {
+	return "personal_access_token_created"
+}
+
+func (k PersonalAccessTokenCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PersonalAccessTokenCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PersonalAccessTokenRevoked struct {
+	TokenId   string    `json:"token_id"`
+	UserId    string    `json:"user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewPersonalAccessTokenRevoked(tokenId, userId string) PersonalAccessTokenRevoked {
+	return PersonalAccessTokenRevoked{TokenId: tokenId, UserId: userId, Timestamp: time.Now()}
+}
+
+func (k PersonalAccessTokenRevoked) Name() string {
+	return "personal_access_token_revoked"
+}
+
+func (k PersonalAccessTokenRevoked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k PersonalAccessTokenRevoked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
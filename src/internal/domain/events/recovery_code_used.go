@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RecoveryCodeUsed struct {
+	FactorId  string    `json:"factor_id"`
+	UserId    string    `json:"user_id"`
+	Remaining int       `json:"remaining"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewRecoveryCodeUsed(factorId, userId string, remaining int) RecoveryCodeUsed {
+	return RecoveryCodeUsed{FactorId: factorId, UserId: userId, Remaining: remaining, Timestamp: time.Now()}
+}
+
+func (k RecoveryCodeUsed) Name() string {
+	return "recovery_code_used"
+}
+
+func (k RecoveryCodeUsed) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RecoveryCodeUsed) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
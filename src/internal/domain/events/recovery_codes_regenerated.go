@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RecoveryCodesRegenerated struct {
+	FactorId  string    `json:"factor_id"`
+	UserId    string    `json:"user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewRecoveryCodesRegenerated(factorId, userId string) RecoveryCodesRegenerated {
+	return RecoveryCodesRegenerated{FactorId: factorId, UserId: userId, Timestamp: time.Now()}
+}
+
+func (k RecoveryCodesRegenerated) Name() string {
+	return "recovery_codes_regenerated"
+}
+
+func (k RecoveryCodesRegenerated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RecoveryCodesRegenerated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -13,7 +13,11 @@ type RoleAddedToMember struct {
 }
 
 func NewRoleAddedToMember(membershipId, roleId, tenantId string) RoleAddedToMember {
-	return RoleAddedToMember{MembershipId: membershipId, RoleId: roleId, TenantId: tenantId}
+	return RoleAddedToMember{MembershipId: membershipId, RoleId: roleId, TenantId: tenantId, Timestamp: time.Now()}
+}
+
+func (k RoleAddedToMember) Name() string {
+	return "role_added_to_member"
 }
 
 func (k RoleAddedToMember) OccuredOn() time.Time {
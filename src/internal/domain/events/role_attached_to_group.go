@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RoleAttachedToGroup struct {
+	GroupId   string    `json:"group_id"`
+	RoleId    string    `json:"role_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewRoleAttachedToGroup(groupId, roleId string) RoleAttachedToGroup {
+	return RoleAttachedToGroup{GroupId: groupId, RoleId: roleId, Timestamp: time.Now()}
+}
+
+func (k RoleAttachedToGroup) Name() string {
+	return "role_attached_to_group"
+}
+
+func (k RoleAttachedToGroup) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RoleAttachedToGroup) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RoleCreated struct {
+	RoleId        string    `json:"role_id"`
+	ApplicationId string    `json:"application_id"`
+	TenantId      string    `json:"tenant_id,omitempty"`
+	RoleName      string    `json:"name"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func NewRoleCreated(roleId, applicationId, tenantId, roleName string) RoleCreated {
+	return RoleCreated{RoleId: roleId, ApplicationId: applicationId, TenantId: tenantId, RoleName: roleName, Timestamp: time.Now()}
+}
+
+func (k RoleCreated) Name() string {
+	return "role_created"
+}
+
+func (k RoleCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RoleCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RoleDeleted struct {
+	RoleId    string    `json:"role_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewRoleDeleted(roleId string) RoleDeleted {
+	return RoleDeleted{RoleId: roleId, Timestamp: time.Now()}
+}
+
+func (k RoleDeleted) Name() string {
+	return "role_deleted"
+}
+
+func (k RoleDeleted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RoleDeleted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RoleDetachedFromGroup struct {
+	GroupId   string    `json:"group_id"`
+	RoleId    string    `json:"role_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewRoleDetachedFromGroup(groupId, roleId string) RoleDetachedFromGroup {
+	return RoleDetachedFromGroup{GroupId: groupId, RoleId: roleId, Timestamp: time.Now()}
+}
+
+func (k RoleDetachedFromGroup) Name() string {
+	return "role_detached_from_group"
+}
+
+func (k RoleDetachedFromGroup) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RoleDetachedFromGroup) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -13,7 +13,11 @@ type RoleRemovedFromMember struct {
 }
 
 func NewRoleRemovedFromMember(membershipId, roleId, tenantId string) RoleRemovedFromMember {
-	return RoleRemovedFromMember{MembershipId: membershipId, RoleId: roleId, TenantId: tenantId}
+	return RoleRemovedFromMember{MembershipId: membershipId, RoleId: roleId, TenantId: tenantId, Timestamp: time.Now()}
+}
+
+func (k RoleRemovedFromMember) Name() string {
+	return "role_removed_from_member"
 }
 
 func (k RoleRemovedFromMember) OccuredOn() time.Time {
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RoleUpdated struct {
+	RoleId              string    `json:"role_id"`
+	RoleName            string    `json:"name"`
+	Description         string    `json:"description"`
+	PreviousName        string    `json:"previous_name"`
+	PreviousDescription string    `json:"previous_description"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+func NewRoleUpdated(roleId, roleName, description, previousName, previousDescription string) RoleUpdated {
+	return RoleUpdated{RoleId: roleId, RoleName: roleName, Description: description, PreviousName: previousName, PreviousDescription: previousDescription, Timestamp: time.Now()}
+}
+
+func (k RoleUpdated) Name() string {
+	return "role_updated"
+}
+
+func (k RoleUpdated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k RoleUpdated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
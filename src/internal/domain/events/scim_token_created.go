@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ScimTokenCreated struct {
+	TokenId        string    `json:"token_id"`
+	OrganizationId string    `json:"organization_id"`
+	TenantId       string    `json:"tenant_id"`
+	Description    string    `json:"description"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewScimTokenCreated(tokenId, organizationId, tenantId, description string) ScimTokenCreated {
+	return ScimTokenCreated{TokenId: tokenId, OrganizationId: organizationId, TenantId: tenantId, Description: description, Timestamp: time.Now()}
+}
+
+func (k ScimTokenCreated) Name() string {
+	return "scim_token_created"
+}
+
+func (k ScimTokenCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k ScimTokenCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
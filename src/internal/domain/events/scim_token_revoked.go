@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ScimTokenRevoked struct {
+	TokenId        string    `json:"token_id"`
+	OrganizationId string    `json:"organization_id"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewScimTokenRevoked(tokenId, organizationId string) ScimTokenRevoked {
+	return ScimTokenRevoked{TokenId: tokenId, OrganizationId: organizationId, Timestamp: time.Now()}
+}
+
+func (k ScimTokenRevoked) Name() string {
+	return "scim_token_revoked"
+}
+
+func (k ScimTokenRevoked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k ScimTokenRevoked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
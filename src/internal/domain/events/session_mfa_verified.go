@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SessionMfaVerified struct {
+	SessionId string    `json:"session_id"`
+	UserId    string    `json:"user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewSessionMfaVerified(sessionId, userId string) SessionMfaVerified {
+	return SessionMfaVerified{SessionId: sessionId, UserId: userId, Timestamp: time.Now()}
+}
+
+func (k SessionMfaVerified) Name() string {
+	return "session_mfa_verified"
+}
+
+func (k SessionMfaVerified) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SessionMfaVerified) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
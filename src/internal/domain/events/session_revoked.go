@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SessionRevoked struct {
+	SessionId string    `json:"session_id"`
+	UserId    string    `json:"user_id"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewSessionRevoked(sessionId, userId, reason string) SessionRevoked {
+	return SessionRevoked{SessionId: sessionId, UserId: userId, Reason: reason, Timestamp: time.Now()}
+}
+
+func (k SessionRevoked) Name() string {
+	return "session_revoked"
+}
+
+func (k SessionRevoked) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SessionRevoked) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
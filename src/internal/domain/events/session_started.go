@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SessionStarted struct {
+	SessionId string    `json:"session_id"`
+	UserId    string    `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	IpAddress string    `json:"ip_address"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewSessionStarted(sessionId, userId, userAgent, ipAddress string) SessionStarted {
+	return SessionStarted{SessionId: sessionId, UserId: userId, UserAgent: userAgent, IpAddress: ipAddress, Timestamp: time.Now()}
+}
+
+func (k SessionStarted) Name() string {
+	return "session_started"
+}
+
+func (k SessionStarted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SessionStarted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
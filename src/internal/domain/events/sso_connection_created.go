@@ -0,0 +1,32 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SsoConnectionCreated struct {
+	ConnectionId   string    `json:"connection_id"`
+	OrganizationId string    `json:"organization_id"`
+	Protocol       string    `json:"protocol"`
+	IssuerUrl      string    `json:"issuer_url"`
+	ClientId       string    `json:"client_id"`
+	Domains        string    `json:"domains"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewSsoConnectionCreated(connectionId, organizationId, protocol, issuerUrl, clientId, domains string) SsoConnectionCreated {
+	return SsoConnectionCreated{ConnectionId: connectionId, OrganizationId: organizationId, Protocol: protocol, IssuerUrl: issuerUrl, ClientId: clientId, Domains: domains, Timestamp: time.Now()}
+}
+
+func (k SsoConnectionCreated) Name() string {
+	return "sso_connection_created"
+}
+
+func (k SsoConnectionCreated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SsoConnectionCreated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SsoConnectionDeleted struct {
+	ConnectionId   string    `json:"connection_id"`
+	OrganizationId string    `json:"organization_id"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewSsoConnectionDeleted(connectionId, organizationId string) SsoConnectionDeleted {
+	return SsoConnectionDeleted{ConnectionId: connectionId, OrganizationId: organizationId, Timestamp: time.Now()}
+}
+
+func (k SsoConnectionDeleted) Name() string {
+	return "sso_connection_deleted"
+}
+
+func (k SsoConnectionDeleted) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SsoConnectionDeleted) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
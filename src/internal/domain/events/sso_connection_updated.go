@@ -0,0 +1,34 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SsoConnectionUpdated struct {
+	ConnectionId      string    `json:"connection_id"`
+	OrganizationId    string    `json:"organization_id"`
+	IssuerUrl         string    `json:"issuer_url"`
+	ClientId          string    `json:"client_id"`
+	Domains           string    `json:"domains"`
+	PreviousIssuerUrl string    `json:"previous_issuer_url"`
+	PreviousClientId  string    `json:"previous_client_id"`
+	PreviousDomains   string    `json:"previous_domains"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+func NewSsoConnectionUpdated(connectionId, organizationId, issuerUrl, clientId, domains, previousIssuerUrl, previousClientId, previousDomains string) SsoConnectionUpdated {
+	return SsoConnectionUpdated{ConnectionId: connectionId, OrganizationId: organizationId, IssuerUrl: issuerUrl, ClientId: clientId, Domains: domains, PreviousIssuerUrl: previousIssuerUrl, PreviousClientId: previousClientId, PreviousDomains: previousDomains, Timestamp: time.Now()}
+}
+
+func (k SsoConnectionUpdated) Name() string {
+	return "sso_connection_updated"
+}
+
+func (k SsoConnectionUpdated) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SsoConnectionUpdated) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SsoDomainVerified struct {
+	ConnectionId   string    `json:"connection_id"`
+	OrganizationId string    `json:"organization_id"`
+	Domain         string    `json:"domain"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func NewSsoDomainVerified(connectionId, organizationId, domain string) SsoDomainVerified {
+	return SsoDomainVerified{ConnectionId: connectionId, OrganizationId: organizationId, Domain: domain, Timestamp: time.Now()}
+}
+
+func (k SsoDomainVerified) Name() string {
+	return "sso_domain_verified"
+}
+
+func (k SsoDomainVerified) OccuredOn() time.Time {
+	return k.Timestamp
+}
+
+func (k SsoDomainVerified) JSON() ([]byte, error) {
+	return json.Marshal(k)
+}
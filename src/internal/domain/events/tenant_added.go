@@ -13,7 +13,11 @@ type TenantAdded struct {
 }
 
 func NewTenantAdded(tenantId, orgId, appId string) TenantAdded {
-	return TenantAdded{TenantId: tenantId, OrganizationId: orgId, ApplicationId: appId}
+	return TenantAdded{TenantId: tenantId, OrganizationId: orgId, ApplicationId: appId, Timestamp: time.Now()}
+}
+
+func (k TenantAdded) Name() string {
+	return "tenant_added"
 }
 
 func (k TenantAdded) OccuredOn() time.Time {
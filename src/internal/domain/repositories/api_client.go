@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type ApiClientRepository interface {
+	Insert(ctx context.Context, client *entities.ApiClient) error
+	FindById(ctx context.Context, id vo.ApiClientId) (*entities.ApiClient, error)
+	Update(ctx context.Context, client *entities.ApiClient) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type ApplicationRepository interface {
+	FindById(ctx context.Context, id vo.ApplicationId) (*entities.Application, error)
+	HasTenants(ctx context.Context, id vo.ApplicationId) (bool, error)
+	Insert(ctx context.Context, application *entities.Application) error
+	Update(ctx context.Context, application *entities.Application) error
+	Delete(ctx context.Context, application *entities.Application) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type CredentialRepository interface {
+	Insert(ctx context.Context, credential *entities.Credential) error
+	Update(ctx context.Context, credential *entities.Credential) error
+	FindById(ctx context.Context, id vo.CredentialId) (*entities.Credential, error)
+	FindByEmail(ctx context.Context, email string) (*entities.Credential, error)
+	FindByUserId(ctx context.Context, userId vo.UserId) (*entities.Credential, error)
+	// InsertReset stores a requested password reset.
+	InsertReset(ctx context.Context, reset *entities.PasswordReset) error
+	FindResetByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.PasswordReset, error)
+	// UpdateWithReset stores the credential and marks the reset used in one
+	// transaction.
+	UpdateWithReset(ctx context.Context, credential *entities.Credential, reset *entities.PasswordReset) error
+}
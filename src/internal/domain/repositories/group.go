@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type GroupRepository interface {
+	FindById(ctx context.Context, id vo.GroupId) (*entities.Group, error)
+	Insert(ctx context.Context, group *entities.Group) error
+	Update(ctx context.Context, group *entities.Group) error
+	Delete(ctx context.Context, group *entities.Group) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type IdentityLinkRepository interface {
+	Insert(ctx context.Context, link *entities.IdentityLink) error
+	FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.IdentityLink, error)
+	// Confirm adds the identity to the user and removes the link, together.
+	Confirm(ctx context.Context, link *entities.IdentityLink, identity vo.Identity) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type InvitationRepository interface {
+	Insert(ctx context.Context, invitation *entities.Invitation) error
+	// Update saves the response to a pending invitation, failing with
+	// ErrConflict when it is no longer pending.
+	Update(ctx context.Context, invitation *entities.Invitation) error
+	// Accept saves the accepted invitation together with the organization
+	// the user joined.
+	Accept(ctx context.Context, invitation *entities.Invitation, organization *entities.Organization) error
+	FindById(ctx context.Context, id vo.InvitationId) (*entities.Invitation, error)
+	FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.Invitation, error)
+	FindPendingByOrganization(ctx context.Context, organizationId string) ([]entities.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string) ([]entities.Invitation, error)
+}
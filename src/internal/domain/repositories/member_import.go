@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type MemberImportRepository interface {
+	Insert(ctx context.Context, memberImport *entities.MemberImport) error
+	Update(ctx context.Context, memberImport *entities.MemberImport) error
+	FindById(ctx context.Context, id vo.MemberImportId) (*entities.MemberImport, error)
+}
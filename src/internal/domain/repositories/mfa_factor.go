@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type MfaFactorRepository interface {
+	Insert(ctx context.Context, factor *entities.MfaFactor) error
+	// Update stores the factor together with its recovery codes. It fails
+	// with ErrInvalidMfaCode when the password or recovery code accepted
+	// since the factor was loaded was used concurrently.
+	Update(ctx context.Context, factor *entities.MfaFactor) error
+	// Delete removes the factor, recording its events.
+	Delete(ctx context.Context, factor *entities.MfaFactor) error
+	FindByUserId(ctx context.Context, userId vo.UserId) (*entities.MfaFactor, error)
+	// IsRequired reports whether the user must prove a second factor to
+	// sign in, because they enabled one or belong to an organization that
+	// requires it.
+	IsRequired(ctx context.Context, userId vo.UserId) (bool, error)
+}
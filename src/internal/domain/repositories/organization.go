@@ -10,4 +10,5 @@ type OrganizationRepository interface {
 	FindByIdentifier(ctx context.Context, identifier string) (*entities.Organization, error)
 	Insert(ctx context.Context, organization *entities.Organization) error
 	Update(ctx context.Context, organization *entities.Organization) error
+	FindOwnershipTransfer(ctx context.Context, organizationId string, id string) (*entities.OwnershipTransfer, error)
 }
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type PersonalAccessTokenRepository interface {
+	Insert(ctx context.Context, token *entities.PersonalAccessToken) error
+	Update(ctx context.Context, token *entities.PersonalAccessToken) error
+	FindById(ctx context.Context, id vo.PersonalAccessTokenId) (*entities.PersonalAccessToken, error)
+	FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.PersonalAccessToken, error)
+	// FindByUser returns the tokens of the user that were not revoked,
+	// expired ones included.
+	FindByUser(ctx context.Context, userId vo.UserId) ([]entities.PersonalAccessToken, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type RoleRepository interface {
+	FindById(ctx context.Context, id vo.RoleId) (*entities.Role, error)
+	Insert(ctx context.Context, role *entities.Role) error
+	Update(ctx context.Context, role *entities.Role) error
+	Delete(ctx context.Context, role *entities.Role) error
+}
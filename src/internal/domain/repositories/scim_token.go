@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type ScimTokenRepository interface {
+	Insert(ctx context.Context, token *entities.ScimToken) error
+	Update(ctx context.Context, token *entities.ScimToken) error
+	FindById(ctx context.Context, id vo.ScimTokenId) (*entities.ScimToken, error)
+	FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.ScimToken, error)
+	FindByOrganization(ctx context.Context, organizationId string) ([]entities.ScimToken, error)
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type SessionRepository interface {
+	Insert(ctx context.Context, session *entities.Session) error
+	// Update stores the session except its refresh token, which only
+	// Rotate changes.
+	Update(ctx context.Context, session *entities.Session) error
+	// Rotate stores a session whose refresh token was exchanged, unless
+	// the token it was exchanged for is no longer current, in which case
+	// it fails with entities.ErrInvalidRefreshToken and stores nothing.
+	Rotate(ctx context.Context, session *entities.Session, previous vo.TokenHash) error
+	FindById(ctx context.Context, id vo.SessionId) (*entities.Session, error)
+	// FindByRefreshToken returns the session a refresh token was issued
+	// for, including tokens that were already exchanged.
+	FindByRefreshToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.Session, error)
+	FindActiveByUser(ctx context.Context, userId vo.UserId) ([]entities.Session, error)
+	IsActive(ctx context.Context, id string) (bool, error)
+}
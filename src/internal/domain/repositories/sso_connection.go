@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+)
+
+type SsoConnectionRepository interface {
+	FindById(ctx context.Context, id vo.SsoConnectionId) (*entities.SsoConnection, error)
+	FindByOrganization(ctx context.Context, organizationId string) (*entities.SsoConnection, error)
+	FindByDomain(ctx context.Context, domain string) (*entities.SsoConnection, error)
+	Insert(ctx context.Context, connection *entities.SsoConnection) error
+	Update(ctx context.Context, connection *entities.SsoConnection) error
+	Delete(ctx context.Context, connection *entities.SsoConnection) error
+}
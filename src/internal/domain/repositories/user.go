@@ -10,5 +10,7 @@ type UserRepository interface {
 	Insert(ctx context.Context, user *entities.User) error
 	FindById(ctx context.Context, userId vo.UserId) (*entities.User, error)
 	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	FindByIdentity(ctx context.Context, idpId string) (*entities.User, error)
+	AddIdentity(ctx context.Context, identity vo.Identity) error
 	// Update(ctx context.Context, user *entities.User) error
 }
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type ApiClientId struct {
+	id string
+}
+
+func NewApiClientId(id string) (ApiClientId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return ApiClientId{}, errors.New("invalid_api_client_id")
+	}
+
+	return ApiClientId{id}, nil
+}
+
+func GenerateApiClientId() ApiClientId {
+	return ApiClientId{uuid.NewString()}
+}
+
+func (d ApiClientId) Value() string {
+	return d.id
+}
+
+func (d ApiClientId) Equals(other ApiClientId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
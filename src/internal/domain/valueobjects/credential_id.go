@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type CredentialId struct {
+	id string
+}
+
+func NewCredentialId(id string) (CredentialId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return CredentialId{}, errors.New("invalid_credential_id")
+	}
+
+	return CredentialId{id}, nil
+}
+
+func GenerateCredentialId() CredentialId {
+	return CredentialId{uuid.NewString()}
+}
+
+func (d CredentialId) Value() string {
+	return d.id
+}
+
+func (d CredentialId) Equals(other CredentialId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
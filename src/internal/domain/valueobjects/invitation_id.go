@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type InvitationId struct {
+	id string
+}
+
+func NewInvitationId(id string) (InvitationId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return InvitationId{}, errors.New("invalid_invitation_id")
+	}
+
+	return InvitationId{id}, nil
+}
+
+func GenerateInvitationId() InvitationId {
+	return InvitationId{uuid.NewString()}
+}
+
+func (d InvitationId) Value() string {
+	return d.id
+}
+
+func (d InvitationId) Equals(other InvitationId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type MemberImportId struct {
+	id string
+}
+
+func NewMemberImportId(id string) (MemberImportId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return MemberImportId{}, errors.New("invalid_member_import_id")
+	}
+
+	return MemberImportId{id}, nil
+}
+
+func GenerateMemberImportId() MemberImportId {
+	return MemberImportId{uuid.NewString()}
+}
+
+func (d MemberImportId) Value() string {
+	return d.id
+}
+
+func (d MemberImportId) Equals(other MemberImportId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type MfaFactorId struct {
+	id string
+}
+
+func NewMfaFactorId(id string) (MfaFactorId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return MfaFactorId{}, errors.New("invalid_mfa_factor_id")
+	}
+
+	return MfaFactorId{id}, nil
+}
+
+func GenerateMfaFactorId() MfaFactorId {
+	return MfaFactorId{uuid.NewString()}
+}
+
+func (d MfaFactorId) Value() string {
+	return d.id
+}
+
+func (d MfaFactorId) Equals(other MfaFactorId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type OwnershipTransferId struct {
+	id string
+}
+
+func NewOwnershipTransferId(id string) (OwnershipTransferId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return OwnershipTransferId{}, errors.New("invalid_ownership_transfer_id")
+	}
+
+	return OwnershipTransferId{id}, nil
+}
+
+func GenerateOwnershipTransferId() OwnershipTransferId {
+	return OwnershipTransferId{uuid.NewString()}
+}
+
+func (d OwnershipTransferId) Value() string {
+	return d.id
+}
+
+func (d OwnershipTransferId) Equals(other OwnershipTransferId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,120 @@
+package valueobjects
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Parameters of new argon2id hashes, following the OWASP recommendation of
+// 19 MiB of memory and two iterations.
+const (
+	argon2Memory  uint32 = 19 * 1024
+	argon2Time    uint32 = 2
+	argon2Threads uint8  = 1
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen        = 16
+)
+
+// PasswordHash is the hash of a user's password. New hashes use argon2id
+// in the PHC string format; bcrypt hashes, as imported from other
+// identity providers, are verified too and should be rehashed on the next
+// successful login.
+type PasswordHash struct {
+	hash string
+}
+
+func NewPasswordHash(password string) (PasswordHash, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return PasswordHash{}, err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	hash := fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argon2Memory,
+		argon2Time,
+		argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return PasswordHash{hash}, nil
+}
+
+// PasswordHashFromString wraps a hash that was loaded from storage.
+func PasswordHashFromString(hash string) PasswordHash {
+	return PasswordHash{hash}
+}
+
+func (h PasswordHash) Value() string {
+	return h.hash
+}
+
+func (h PasswordHash) Matches(password string) bool {
+	if strings.HasPrefix(h.hash, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(h.hash), []byte(password)) == nil
+	}
+
+	params, salt, key, err := decodeArgon2id(h.hash)
+	if err != nil {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or
+// weaker parameters than new hashes are.
+func (h PasswordHash) NeedsRehash() bool {
+	params, _, key, err := decodeArgon2id(h.hash)
+	if err != nil {
+		return true
+	}
+
+	return params.memory < argon2Memory || params.time < argon2Time || uint32(len(key)) < argon2KeyLen
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argon2Params{}, nil, nil, errors.New("invalid_password_hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, errors.New("invalid_password_hash")
+	}
+
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return argon2Params{}, nil, nil, errors.New("invalid_password_hash")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, errors.New("invalid_password_hash")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return argon2Params{}, nil, nil, errors.New("invalid_password_hash")
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetId struct {
+	id string
+}
+
+func NewPasswordResetId(id string) (PasswordResetId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return PasswordResetId{}, errors.New("invalid_password_reset_id")
+	}
+
+	return PasswordResetId{id}, nil
+}
+
+func GeneratePasswordResetId() PasswordResetId {
+	return PasswordResetId{uuid.NewString()}
+}
+
+func (d PasswordResetId) Value() string {
+	return d.id
+}
+
+func (d PasswordResetId) Equals(other PasswordResetId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
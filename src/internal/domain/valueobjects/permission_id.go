@@ -20,6 +20,10 @@ func NewPermissionId(id string) (PermissionId, error) {
 	return PermissionId{id}, nil
 }
 
+func GeneratePermissionId() PermissionId {
+	return PermissionId{uuid.NewString()}
+}
+
 func (d PermissionId) Value() string {
 	return d.id
 }
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type PersonalAccessTokenId struct {
+	id string
+}
+
+func NewPersonalAccessTokenId(id string) (PersonalAccessTokenId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return PersonalAccessTokenId{}, errors.New("invalid_personal_access_token_id")
+	}
+
+	return PersonalAccessTokenId{id}, nil
+}
+
+func GeneratePersonalAccessTokenId() PersonalAccessTokenId {
+	return PersonalAccessTokenId{uuid.NewString()}
+}
+
+func (d PersonalAccessTokenId) Value() string {
+	return d.id
+}
+
+func (d PersonalAccessTokenId) Equals(other PersonalAccessTokenId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type ScimTokenId struct {
+	id string
+}
+
+func NewScimTokenId(id string) (ScimTokenId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return ScimTokenId{}, errors.New("invalid_scim_token_id")
+	}
+
+	return ScimTokenId{id}, nil
+}
+
+func GenerateScimTokenId() ScimTokenId {
+	return ScimTokenId{uuid.NewString()}
+}
+
+func (d ScimTokenId) Value() string {
+	return d.id
+}
+
+func (d ScimTokenId) Equals(other ScimTokenId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,39 @@
+package valueobjects
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SecretHash is the bcrypt hash of a client secret. The plaintext secret is
+// only known when it is generated.
+type SecretHash struct {
+	hash string
+}
+
+func NewSecretHash(secret string) (SecretHash, error) {
+	if len(secret) < 32 {
+		return SecretHash{}, errors.New("secret_too_short")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return SecretHash{}, err
+	}
+
+	return SecretHash{string(hash)}, nil
+}
+
+// SecretHashFromString wraps a hash that was loaded from storage.
+func SecretHashFromString(hash string) SecretHash {
+	return SecretHash{hash}
+}
+
+func (h SecretHash) Value() string {
+	return h.hash
+}
+
+func (h SecretHash) Matches(secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(h.hash), []byte(secret)) == nil
+}
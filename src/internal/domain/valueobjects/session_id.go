@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type SessionId struct {
+	id string
+}
+
+func NewSessionId(id string) (SessionId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return SessionId{}, errors.New("invalid_session_id")
+	}
+
+	return SessionId{id}, nil
+}
+
+func GenerateSessionId() SessionId {
+	return SessionId{uuid.NewString()}
+}
+
+func (d SessionId) Value() string {
+	return d.id
+}
+
+func (d SessionId) Equals(other SessionId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,33 @@
+package valueobjects
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type SsoConnectionId struct {
+	id string
+}
+
+func NewSsoConnectionId(id string) (SsoConnectionId, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return SsoConnectionId{}, errors.New("invalid_sso_connection_id")
+	}
+
+	return SsoConnectionId{id}, nil
+}
+
+func GenerateSsoConnectionId() SsoConnectionId {
+	return SsoConnectionId{uuid.NewString()}
+}
+
+func (d SsoConnectionId) Value() string {
+	return d.id
+}
+
+func (d SsoConnectionId) Equals(other SsoConnectionId) bool {
+	return strings.EqualFold(d.id, other.id)
+}
@@ -0,0 +1,41 @@
+package valueobjects
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// TokenHash is the SHA-256 hash of a random bearer token. Unlike a
+// SecretHash it can be looked up, so the token alone identifies what it
+// was issued for. The plaintext token is only known when it is generated.
+type TokenHash struct {
+	hash string
+}
+
+// GenerateToken returns a new random token and its hash.
+func GenerateToken() (string, TokenHash, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", TokenHash{}, err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, NewTokenHash(token), nil
+}
+
+func NewTokenHash(token string) TokenHash {
+	sum := sha256.Sum256([]byte(token))
+	return TokenHash{hex.EncodeToString(sum[:])}
+}
+
+// TokenHashFromString wraps a hash that was loaded from storage.
+func TokenHashFromString(hash string) TokenHash {
+	return TokenHash{hash}
+}
+
+func (h TokenHash) Value() string {
+	return h.hash
+}
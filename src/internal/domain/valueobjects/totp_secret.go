@@ -0,0 +1,107 @@
+package valueobjects
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters, which are the defaults of authenticator apps.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is how many periods before and after the current one are
+	// accepted, to allow for clock drift.
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TotpSecret is the shared key of a time-based one-time password
+// authenticator (RFC 6238).
+type TotpSecret struct {
+	key []byte
+}
+
+func GenerateTotpSecret() (TotpSecret, error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return TotpSecret{}, err
+	}
+
+	return TotpSecret{key}, nil
+}
+
+// TotpSecretFromString decodes a base32 secret, as loaded from storage.
+func TotpSecretFromString(secret string) (TotpSecret, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return TotpSecret{}, errors.New("invalid_totp_secret")
+	}
+
+	return TotpSecret{key}, nil
+}
+
+// Value returns the base32 secret users can type into authenticator apps.
+func (s TotpSecret) Value() string {
+	return totpEncoding.EncodeToString(s.key)
+}
+
+// URI returns the otpauth URI authenticator apps scan as a QR code.
+func (s TotpSecret) URI(issuer string, account string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{
+		"secret":    {s.Value()},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TotpCounter returns the time step of t.
+func TotpCounter(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// Code returns the password of a time step.
+func (s TotpSecret) Code(counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, s.key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// Verify checks a password against the time steps around now and returns
+// the step it belongs to.
+func (s TotpSecret) Verify(code string, now time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := TotpCounter(now)
+	for counter := current - totpSkew; counter <= current+totpSkew; counter++ {
+		if subtle.ConstantTimeCompare([]byte(s.Code(counter)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+
+	return 0, false
+}
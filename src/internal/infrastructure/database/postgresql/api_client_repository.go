@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type ApiClientRepository struct {
+	db *sql.DB
+}
+
+func NewApiClientRepository(db *sql.DB) repositories.ApiClientRepository {
+	return &ApiClientRepository{
+		db: db,
+	}
+}
+
+func (r *ApiClientRepository) Insert(ctx context.Context, client *entities.ApiClient) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_client (id, name, secret_hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5);`,
+		client.Id().Value(), client.Name(), client.SecretHash().Value(), pq.StringArray(client.Scopes()), client.CreatedAt(),
+	)
+
+	return err
+}
+
+func (r *ApiClientRepository) FindById(ctx context.Context, id vo.ApiClientId) (*entities.ApiClient, error) {
+	var clientRecord struct {
+		Id         string
+		Name       string
+		SecretHash string
+		Scopes     pq.StringArray
+		CreatedAt  time.Time
+		RevokedAt  sql.NullTime
+	}
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, secret_hash, scopes, created_at, revoked_at FROM api_client WHERE id=$1;`, id.Value(),
+	)
+
+	err := row.Scan(&clientRecord.Id, &clientRecord.Name, &clientRecord.SecretHash, &clientRecord.Scopes, &clientRecord.CreatedAt, &clientRecord.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var revokedAt *time.Time
+	if clientRecord.RevokedAt.Valid {
+		revokedAt = &clientRecord.RevokedAt.Time
+	}
+
+	client := entities.NewApiClient(
+		id,
+		clientRecord.Name,
+		vo.SecretHashFromString(clientRecord.SecretHash),
+		clientRecord.Scopes,
+		clientRecord.CreatedAt,
+		revokedAt,
+	)
+
+	return &client, nil
+}
+
+func (r *ApiClientRepository) Update(ctx context.Context, client *entities.ApiClient) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_client SET name=$2, scopes=$3, revoked_at=$4 WHERE id=$1;`,
+		client.Id().Value(), client.Name(), pq.StringArray(client.Scopes()), client.RevokedAt(),
+	)
+
+	return err
+}
@@ -0,0 +1,232 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"log"
+)
+
+type ApplicationRepository struct {
+	db *sql.DB
+}
+
+func NewApplicationRepository(db *sql.DB) repositories.ApplicationRepository {
+	return &ApplicationRepository{
+		db: db,
+	}
+}
+
+func (r *ApplicationRepository) FindById(ctx context.Context, id vo.ApplicationId) (*entities.Application, error) {
+	var name string
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT "name" FROM application WHERE id=$1;`, id.Value(),
+	).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, "name" FROM "permission" WHERE application_id=$1 ORDER BY "name";`, id.Value(),
+	)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make([]entities.Permission, 0)
+
+	for rows.Next() {
+		var permissionRecord struct {
+			Id   string
+			Name string
+		}
+
+		err = rows.Scan(&permissionRecord.Id, &permissionRecord.Name)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		permissionId, err := vo.NewPermissionId(permissionRecord.Id)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		permissions = append(permissions, entities.NewPermission(permissionId, id, permissionRecord.Name))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	application := entities.NewApplication(id, name, permissions)
+
+	return &application, nil
+}
+
+func (r *ApplicationRepository) HasTenants(ctx context.Context, id vo.ApplicationId) (bool, error) {
+	var exists bool
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM tenant WHERE app_id=$1);`, id.Value(),
+	).Scan(&exists)
+
+	return exists, err
+}
+
+func (r *ApplicationRepository) Insert(ctx context.Context, application *entities.Application) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO application (id, "name") VALUES ($1, $2);`,
+		application.Id().Value(), application.Name(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range application.Permissions() {
+		_, err = tx.Exec(`
+			INSERT INTO "permission" (id, application_id, "name") VALUES ($1, $2, $3);`,
+			permission.Id().Value(), application.Id().Value(), permission.Name(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{}, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ApplicationRepository) Update(ctx context.Context, application *entities.Application) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE application SET "name"=$2 WHERE id=$1;`,
+		application.Id().Value(), application.Name(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range application.Events() {
+		switch e := event.(type) {
+		case events.PermissionAdded:
+			_, err = tx.Exec(`
+				INSERT INTO "permission" (id, application_id, "name") VALUES ($1, $2, $3);`,
+				e.PermissionId, e.ApplicationId, e.PermissionName,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.PermissionRenamed:
+			_, err = tx.Exec(`
+				UPDATE "permission" SET "name"=$2 WHERE id=$1;`,
+				e.PermissionId, e.PermissionName,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.PermissionRemoved:
+			_, err = tx.Exec(`
+				DELETE FROM role_permission WHERE permission_id=$1;`,
+				e.PermissionId,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(`
+				DELETE FROM "permission" WHERE id=$1;`,
+				e.PermissionId,
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = insertOutboxEvents(tx, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{}, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the application together with its permissions, roles and
+// groups. Callers must make sure no tenant uses the application anymore.
+func (r *ApplicationRepository) Delete(ctx context.Context, application *entities.Application) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`DELETE FROM role_permission WHERE role_id IN (SELECT id FROM "role" WHERE application_id=$1);`,
+		`DELETE FROM group_role WHERE group_id IN (SELECT id FROM "group" WHERE application_id=$1);`,
+		`DELETE FROM "group" WHERE application_id=$1;`,
+		`DELETE FROM "role" WHERE application_id=$1;`,
+		`DELETE FROM "permission" WHERE application_id=$1;`,
+		`DELETE FROM application WHERE id=$1;`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, application.Id().Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{}, "application", application.Id().Value(), application.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,170 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/events"
+	"strings"
+)
+
+// writeUserEvents records the events of an aggregate that belongs to a
+// user, such as a session, a credential or an MFA factor, in the outbox and
+// the audit log. A user is not part of any one organization, so the audit
+// entries have no organization either.
+func writeUserEvents(ctx context.Context, tx *sql.Tx, aggregateType string, aggregateId string, evts []events.Event) error {
+	err := insertOutboxEvents(tx, aggregateType, aggregateId, evts)
+	if err != nil {
+		return err
+	}
+
+	return insertAuditEntries(ctx, tx, audit.Scope{}, aggregateType, aggregateId, evts)
+}
+
+// insertAuditEntries records the events of an aggregate in the audit log,
+// in the same transaction as the change itself. When only the tenant is
+// known, the organization is taken from the tenant.
+func insertAuditEntries(ctx context.Context, tx *sql.Tx, scope audit.Scope, aggregateType string, aggregateId string, evts []events.Event) error {
+	for _, entry := range audit.Entries(ctx, scope, aggregateType, aggregateId, evts) {
+		before, err := json.Marshal(entry.Before)
+		if err != nil {
+			return err
+		}
+
+		after, err := json.Marshal(entry.After)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO audit_log (
+				id, organization_id, tenant_id, actor_id, actor_email, actor_client_id,
+				action, target_type, target_id, before, after,
+				ip_address, user_agent, request_id, created_at
+			) VALUES (
+				$1, coalesce(nullif($2, '')::uuid, (SELECT org_id FROM tenant WHERE id = nullif($3, '')::uuid)),
+				nullif($3, '')::uuid, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			);`,
+			entry.Id, entry.OrganizationId, entry.TenantId,
+			entry.Actor.UserId, entry.Actor.Email, entry.Actor.ClientId,
+			entry.Action, entry.TargetType, entry.TargetId, before, after,
+			entry.Metadata.IpAddress, entry.Metadata.UserAgent, entry.Metadata.RequestId, entry.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert audit entry %s: %w", entry.Action, err)
+		}
+	}
+
+	return nil
+}
+
+type AuditLogQuery struct {
+	db *sql.DB
+}
+
+func NewAuditLogQuery(db *sql.DB) *AuditLogQuery {
+	return &AuditLogQuery{db}
+}
+
+func (q *AuditLogQuery) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	where, args := auditLogWhere(filter)
+
+	query := auditLogSelect + where + " ORDER BY created_at DESC, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	entries := make([]audit.Entry, 0)
+	err := q.scan(ctx, query, args, func(entry audit.Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+
+	return entries, err
+}
+
+func (q *AuditLogQuery) Each(ctx context.Context, filter audit.Filter, fn func(audit.Entry) error) error {
+	where, args := auditLogWhere(filter)
+
+	return q.scan(ctx, auditLogSelect+where+" ORDER BY created_at, id", args, fn)
+}
+
+const auditLogSelect = `
+	SELECT id, coalesce(organization_id::text, ''), coalesce(tenant_id::text, ''),
+		actor_id, actor_email, actor_client_id, action, target_type, target_id, before, after,
+		ip_address, user_agent, request_id, created_at
+	FROM audit_log`
+
+func auditLogWhere(filter audit.Filter) (string, []interface{}) {
+	conditions := []string{"organization_id = $1"}
+	args := []interface{}{filter.OrganizationId}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.TenantId != "" {
+		add("tenant_id = $%d", filter.TenantId)
+	}
+	if filter.ActorId != "" {
+		add("(actor_id = $%[1]d OR actor_email = $%[1]d OR actor_client_id = $%[1]d)", filter.ActorId)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TargetId != "" {
+		add("target_id = $%d", filter.TargetId)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (q *AuditLogQuery) scan(ctx context.Context, query string, args []interface{}, fn func(audit.Entry) error) error {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry audit.Entry
+		var before, after []byte
+
+		err = rows.Scan(
+			&entry.Id, &entry.OrganizationId, &entry.TenantId,
+			&entry.Actor.UserId, &entry.Actor.Email, &entry.Actor.ClientId,
+			&entry.Action, &entry.TargetType, &entry.TargetId, &before, &after,
+			&entry.Metadata.IpAddress, &entry.Metadata.UserAgent, &entry.Metadata.RequestId, &entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err = json.Unmarshal(before, &entry.Before); err != nil {
+			return err
+		}
+		if err = json.Unmarshal(after, &entry.After); err != nil {
+			return err
+		}
+
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
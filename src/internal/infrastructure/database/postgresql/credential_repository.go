@@ -0,0 +1,229 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type CredentialRepository struct {
+	db *sql.DB
+}
+
+func NewCredentialRepository(db *sql.DB) repositories.CredentialRepository {
+	return &CredentialRepository{
+		db: db,
+	}
+}
+
+func (r *CredentialRepository) Insert(ctx context.Context, credential *entities.Credential) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO credential (id, user_id, email, password_hash, password_changed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6);`,
+		credential.Id().Value(), credential.UserId().Value(), credential.Email(),
+		credential.Hash().Value(), credential.PasswordChangedAt(), credential.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = writeUserEvents(ctx, tx, "credential", credential.Id().Value(), credential.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CredentialRepository) Update(ctx context.Context, credential *entities.Credential) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.update(ctx, tx, credential)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CredentialRepository) UpdateWithReset(ctx context.Context, credential *entities.Credential, reset *entities.PasswordReset) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE password_reset SET used_at=$2 WHERE id=$1;`, reset.Id().Value(), reset.UsedAt())
+	if err != nil {
+		return err
+	}
+
+	err = r.update(ctx, tx, credential)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CredentialRepository) update(ctx context.Context, tx *sql.Tx, credential *entities.Credential) error {
+	_, err := tx.Exec(`
+		UPDATE credential
+		SET password_hash=$2, failed_attempts=$3, locked_until=$4, password_changed_at=$5
+		WHERE id=$1;`,
+		credential.Id().Value(), credential.Hash().Value(), credential.FailedAttempts(),
+		credential.LockedUntil(), credential.PasswordChangedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return writeUserEvents(ctx, tx, "credential", credential.Id().Value(), credential.Events())
+}
+
+func (r *CredentialRepository) InsertReset(ctx context.Context, reset *entities.PasswordReset) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO password_reset (id, credential_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5);`,
+		reset.Id().Value(), reset.CredentialId().Value(), reset.TokenHash().Value(),
+		reset.ExpiresAt(), reset.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = writeUserEvents(ctx, tx, "password_reset", reset.Id().Value(), reset.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const credentialSelect = `
+	SELECT id, user_id, email, password_hash, failed_attempts, locked_until, password_changed_at, created_at
+	FROM credential`
+
+func (r *CredentialRepository) FindById(ctx context.Context, id vo.CredentialId) (*entities.Credential, error) {
+	return r.findOne(ctx, credentialSelect+` WHERE id=$1;`, id.Value())
+}
+
+func (r *CredentialRepository) FindByEmail(ctx context.Context, email string) (*entities.Credential, error) {
+	return r.findOne(ctx, credentialSelect+` WHERE email=$1;`, entities.NormalizeEmail(email))
+}
+
+func (r *CredentialRepository) FindByUserId(ctx context.Context, userId vo.UserId) (*entities.Credential, error) {
+	return r.findOne(ctx, credentialSelect+` WHERE user_id=$1;`, userId.Value())
+}
+
+func (r *CredentialRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.Credential, error) {
+	var record struct {
+		Id                string
+		UserId            string
+		Email             string
+		PasswordHash      string
+		FailedAttempts    int
+		LockedUntil       sql.NullTime
+		PasswordChangedAt time.Time
+		CreatedAt         time.Time
+	}
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&record.Id, &record.UserId, &record.Email, &record.PasswordHash,
+		&record.FailedAttempts, &record.LockedUntil, &record.PasswordChangedAt, &record.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := vo.NewCredentialId(record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := vo.NewUserId(record.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var lockedUntil *time.Time
+	if record.LockedUntil.Valid {
+		lockedUntil = &record.LockedUntil.Time
+	}
+
+	credential := entities.NewCredential(
+		id,
+		userId,
+		record.Email,
+		vo.PasswordHashFromString(record.PasswordHash),
+		record.FailedAttempts,
+		lockedUntil,
+		record.PasswordChangedAt,
+		record.CreatedAt,
+	)
+
+	return &credential, nil
+}
+
+func (r *CredentialRepository) FindResetByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.PasswordReset, error) {
+	var record struct {
+		Id           string
+		CredentialId string
+		ExpiresAt    time.Time
+		UsedAt       sql.NullTime
+		CreatedAt    time.Time
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, credential_id, expires_at, used_at, created_at
+		FROM password_reset
+		WHERE token_hash=$1;`,
+		tokenHash.Value(),
+	).Scan(&record.Id, &record.CredentialId, &record.ExpiresAt, &record.UsedAt, &record.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := vo.NewPasswordResetId(record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialId, err := vo.NewCredentialId(record.CredentialId)
+	if err != nil {
+		return nil, err
+	}
+
+	var usedAt *time.Time
+	if record.UsedAt.Valid {
+		usedAt = &record.UsedAt.Time
+	}
+
+	reset := entities.NewPasswordReset(id, credentialId, tokenHash, record.ExpiresAt, usedAt, record.CreatedAt)
+	return &reset, nil
+}
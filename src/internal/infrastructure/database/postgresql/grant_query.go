@@ -0,0 +1,111 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/authorization"
+)
+
+type GrantQuery struct {
+	db *sql.DB
+}
+
+func NewGrantQuery(db *sql.DB) *GrantQuery {
+	return &GrantQuery{db}
+}
+
+func (q *GrantQuery) IsMemberOfTenant(ctx context.Context, membershipId string, tenantId string) (bool, error) {
+	var exists bool
+
+	err := q.db.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM user_organization uo
+			JOIN tenant t ON t.org_id = uo.organization_id
+			WHERE uo.id=$1 AND t.id=$2);`, membershipId, tenantId,
+	).Scan(&exists)
+
+	return exists, err
+}
+
+func (q *GrantQuery) Grants(ctx context.Context, membershipId string, tenantId string) ([]authorization.Grant, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT p.id, p."name", r.id, r."name", '' group_id, '' group_name
+		FROM user_role ur
+		JOIN "role" r ON r.id = ur.role_id
+		JOIN role_permission rp ON rp.role_id = r.id
+		JOIN "permission" p ON p.id = rp.permission_id
+		WHERE ur.user_org_id=$1 AND ur.tenant_id=$2
+			AND (r.tenant_id IS NULL OR r.tenant_id = ur.tenant_id)
+		UNION ALL
+		SELECT p.id, p."name", r.id, r."name", g.id::text, g."name"
+		FROM user_group ug
+		JOIN "group" g ON g.id = ug.group_id
+		JOIN group_role gr ON gr.group_id = g.id
+		JOIN "role" r ON r.id = gr.role_id
+		JOIN role_permission rp ON rp.role_id = r.id
+		JOIN "permission" p ON p.id = rp.permission_id
+		WHERE ug.user_org_id=$1 AND ug.tenant_id=$2
+			AND (g.tenant_id IS NULL OR g.tenant_id = ug.tenant_id)
+			AND (r.tenant_id IS NULL OR r.tenant_id = ug.tenant_id);`, membershipId, tenantId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]authorization.Grant, 0)
+
+	for rows.Next() {
+		g := authorization.Grant{}
+		err := rows.Scan(&g.PermissionId, &g.PermissionName, &g.RoleId, &g.RoleName, &g.GroupId, &g.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+func (q *GrantQuery) UserGrants(ctx context.Context, userId string) ([]authorization.Grant, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT uo.organization_id, ur.tenant_id, coalesce(p.id::text, ''), coalesce(p."name", ''),
+			r.id, r."name", '' group_id, '' group_name
+		FROM user_organization uo
+		JOIN user_role ur ON ur.user_org_id = uo.id
+		JOIN "role" r ON r.id = ur.role_id
+		LEFT JOIN role_permission rp ON rp.role_id = r.id
+		LEFT JOIN "permission" p ON p.id = rp.permission_id
+		WHERE uo.user_id=$1 AND (r.tenant_id IS NULL OR r.tenant_id = ur.tenant_id)
+		UNION ALL
+		SELECT uo.organization_id, ug.tenant_id, coalesce(p.id::text, ''), coalesce(p."name", ''),
+			r.id, r."name", g.id::text, g."name"
+		FROM user_organization uo
+		JOIN user_group ug ON ug.user_org_id = uo.id
+		JOIN "group" g ON g.id = ug.group_id
+		JOIN group_role gr ON gr.group_id = g.id
+		JOIN "role" r ON r.id = gr.role_id
+		LEFT JOIN role_permission rp ON rp.role_id = r.id
+		LEFT JOIN "permission" p ON p.id = rp.permission_id
+		WHERE uo.user_id=$1
+			AND (g.tenant_id IS NULL OR g.tenant_id = ug.tenant_id)
+			AND (r.tenant_id IS NULL OR r.tenant_id = ug.tenant_id);`, userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]authorization.Grant, 0)
+
+	for rows.Next() {
+		g := authorization.Grant{}
+		err := rows.Scan(&g.OrganizationId, &g.TenantId, &g.PermissionId, &g.PermissionName, &g.RoleId, &g.RoleName, &g.GroupId, &g.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/queries"
+
+	"github.com/lib/pq"
+)
+
+type GroupQuery struct {
+	db *sql.DB
+}
+
+func NewGroupQuery(db *sql.DB) *GroupQuery {
+	return &GroupQuery{db}
+}
+
+func (q *GroupQuery) TenantGroups(ctx context.Context, tenantId string) ([]queries.TenantGroup, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT g.id, g."name", g.description,
+			array(SELECT uo.user_id FROM user_group ug
+				JOIN user_organization uo ON uo.id = ug.user_org_id
+				WHERE ug.group_id = g.id AND ug.tenant_id = g.tenant_id
+				ORDER BY uo.created_at)
+		FROM "group" g
+		WHERE g.tenant_id=$1
+		ORDER BY g."name";`, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]queries.TenantGroup, 0)
+	for rows.Next() {
+		var group queries.TenantGroup
+		var userIds pq.StringArray
+
+		err := rows.Scan(&group.Id, &group.Name, &group.Description, &userIds)
+		if err != nil {
+			return nil, err
+		}
+
+		group.UserIds = userIds
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+var groupSortColumns = map[string]sortColumn{
+	"name": {`g."name"`, "text"},
+}
+
+// GroupsInTenant returns a page of the groups of the tenant's application
+// and of the tenant itself, sorted by name and filtered on it.
+func (q *GroupQuery) GroupsInTenant(ctx context.Context, tenantId string, list queries.ListQuery) (queries.Page[queries.Group], error) {
+	k, err := newKeyset(list, groupSortColumns, "name", "g.id")
+	if err != nil {
+		return queries.Page[queries.Group]{}, err
+	}
+
+	query := `
+		SELECT g.id, g."name", g.description, g.tenant_id IS NOT NULL,
+			array(SELECT r."name" FROM group_role gr
+				JOIN "role" r ON r.id = gr.role_id
+				WHERE gr.group_id = g.id
+				ORDER BY r."name"),
+			` + k.value() + `
+		FROM "group" g
+		JOIN tenant t ON g.application_id = t.app_id
+		WHERE t.id = $1 AND (g.tenant_id IS NULL OR g.tenant_id = t.id)`
+	args := []interface{}{tenantId}
+
+	if list.Name != "" {
+		args = append(args, escapeLike(list.Name))
+		query += ` AND g."name" ILIKE '%' || $2 || '%'`
+	}
+
+	keysetWhere, args := k.where(args)
+	orderBy, args := k.orderBy(args)
+
+	rows, err := q.db.QueryContext(ctx, query+keysetWhere+orderBy+";", args...)
+	if err != nil {
+		return queries.Page[queries.Group]{}, err
+	}
+	defer rows.Close()
+
+	groups := make([]queries.Group, 0)
+	values := make([]string, 0)
+	ids := make([]string, 0)
+
+	for rows.Next() {
+		var group queries.Group
+		var roles pq.StringArray
+		var value string
+
+		err := rows.Scan(&group.Id, &group.Name, &group.Description, &group.Custom, &roles, &value)
+		if err != nil {
+			return queries.Page[queries.Group]{}, err
+		}
+
+		group.Roles = roles
+		groups = append(groups, group)
+		values = append(values, value)
+		ids = append(ids, group.Id)
+	}
+	if err = rows.Err(); err != nil {
+		return queries.Page[queries.Group]{}, err
+	}
+
+	return keysetPage(k, groups, values, ids), nil
+}
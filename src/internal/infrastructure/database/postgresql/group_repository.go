@@ -0,0 +1,229 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"log"
+)
+
+type GroupRepository struct {
+	db *sql.DB
+}
+
+func NewGroupRepository(db *sql.DB) repositories.GroupRepository {
+	return &GroupRepository{
+		db: db,
+	}
+}
+
+// FindById loads the group with its roles. The permissions of those roles
+// are not loaded.
+func (r *GroupRepository) FindById(ctx context.Context, id vo.GroupId) (*entities.Group, error) {
+	var groupRecord struct {
+		Name          string
+		Description   string
+		ApplicationId string
+		TenantId      sql.NullString
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT "name", coalesce(description, ''), application_id, tenant_id FROM "group" WHERE id=$1;`, id.Value(),
+	).Scan(&groupRecord.Name, &groupRecord.Description, &groupRecord.ApplicationId, &groupRecord.TenantId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	applicationId, err := vo.NewApplicationId(groupRecord.ApplicationId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	tenantId, err := nullTenantId(groupRecord.TenantId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r."name", coalesce(r.description, ''), r.tenant_id FROM group_role gr
+		JOIN "role" r ON r.id = gr.role_id
+		WHERE gr.group_id=$1 ORDER BY r."name";`, id.Value(),
+	)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]entities.Role, 0)
+
+	for rows.Next() {
+		var roleRecord struct {
+			Id          string
+			Name        string
+			Description string
+			TenantId    sql.NullString
+		}
+
+		err = rows.Scan(&roleRecord.Id, &roleRecord.Name, &roleRecord.Description, &roleRecord.TenantId)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		roleId, err := vo.NewRoleId(roleRecord.Id)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		roleTenantId, err := nullTenantId(roleRecord.TenantId)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		roles = append(roles, entities.NewRole(roleId, roleRecord.Name, roleRecord.Description, applicationId, roleTenantId, make([]entities.Permission, 0)))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	group := entities.NewGroup(id, groupRecord.Name, groupRecord.Description, applicationId, tenantId, roles)
+
+	return &group, nil
+}
+
+func (r *GroupRepository) Insert(ctx context.Context, group *entities.Group) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO "group" (id, "name", description, application_id, tenant_id) VALUES ($1, $2, $3, $4, $5);`,
+		group.Id().Value(), group.Name(), group.Description(), group.ApplicationId().Value(), tenantIdValue(group.TenantId()),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range group.Roles() {
+		_, err = tx.Exec(`
+			INSERT INTO group_role (group_id, role_id) VALUES ($1, $2);`,
+			group.Id().Value(), role.Id().Value(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(group.TenantId()).String}, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *GroupRepository) Update(ctx context.Context, group *entities.Group) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE "group" SET "name"=$2, description=$3 WHERE id=$1;`,
+		group.Id().Value(), group.Name(), group.Description(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range group.Events() {
+		switch e := event.(type) {
+		case events.RoleAttachedToGroup:
+			_, err = tx.Exec(`
+				INSERT INTO group_role (group_id, role_id) VALUES ($1, $2);`,
+				e.GroupId, e.RoleId,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.RoleDetachedFromGroup:
+			_, err = tx.Exec(`
+				DELETE FROM group_role WHERE group_id=$1 AND role_id=$2;`,
+				e.GroupId, e.RoleId,
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = insertOutboxEvents(tx, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(group.TenantId()).String}, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the group and every membership of it.
+func (r *GroupRepository) Delete(ctx context.Context, group *entities.Group) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`DELETE FROM group_role WHERE group_id=$1;`,
+		`DELETE FROM user_group WHERE group_id=$1;`,
+		`DELETE FROM "group" WHERE id=$1;`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, group.Id().Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(group.TenantId()).String}, "group", group.Id().Value(), group.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type IdentityLinkRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityLinkRepository(db *sql.DB) repositories.IdentityLinkRepository {
+	return &IdentityLinkRepository{
+		db: db,
+	}
+}
+
+func (r *IdentityLinkRepository) Insert(ctx context.Context, link *entities.IdentityLink) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO identity_link (token_hash, user_id, organization_id, idp_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5);`,
+		link.TokenHash().Value(), link.UserId().Value(), link.OrganizationId().Value(), link.IdpId(), link.ExpiresAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, link)
+}
+
+func (r *IdentityLinkRepository) Confirm(ctx context.Context, link *entities.IdentityLink, identity vo.Identity) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM identity_link WHERE token_hash=$1;`, link.TokenHash().Value())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identity (idp_id, user_id) VALUES ($1, $2);`,
+		identity.IdpId(), identity.UserId().Value(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, link)
+}
+
+func (r *IdentityLinkRepository) commit(ctx context.Context, tx *sql.Tx, link *entities.IdentityLink) error {
+	err := insertOutboxEvents(tx, "user", link.UserId().Value(), link.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{OrganizationId: link.OrganizationId().Value()}, "user", link.UserId().Value(), link.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *IdentityLinkRepository) FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.IdentityLink, error) {
+	var record struct {
+		UserId         string
+		OrganizationId string
+		IdpId          string
+		ExpiresAt      time.Time
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, organization_id, idp_id, expires_at FROM identity_link WHERE token_hash=$1;`,
+		tokenHash.Value(),
+	).Scan(&record.UserId, &record.OrganizationId, &record.IdpId, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := vo.NewUserId(record.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	orgId, err := vo.NewOrganizationId(record.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	link := entities.NewIdentityLink(tokenHash, userId, orgId, record.IdpId, record.ExpiresAt)
+	return &link, nil
+}
@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type InboxRepository struct {
+	db *sql.DB
+}
+
+func NewInboxRepository(db *sql.DB) *InboxRepository {
+	return &InboxRepository{
+		db: db,
+	}
+}
+
+// Process runs handle unless the consumer already processed the message.
+// The message is marked as processed in a transaction that handle writes
+// through, so the mark and the changes commit together or not at all. A
+// concurrent delivery of the message waits on the mark, and is skipped
+// once it commits.
+func (r *InboxRepository) Process(ctx context.Context, consumer string, messageId string, handle func(ctx context.Context) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO inbox (consumer, message_id) VALUES ($1, $2)
+		ON CONFLICT (consumer, message_id) DO NOTHING;`,
+		consumer, messageId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return err
+	}
+
+	if err := handle(withTx(ctx, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,200 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+func NewInvitationRepository(db *sql.DB) repositories.InvitationRepository {
+	return &InvitationRepository{
+		db: db,
+	}
+}
+
+func (r *InvitationRepository) Insert(ctx context.Context, invitation *entities.Invitation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO invitation
+			(id, organization_id, email, level, token_hash, status, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+		invitation.Id().Value(), invitation.OrganizationId().Value(), invitation.Email(), invitation.Level(),
+		invitation.TokenHash().Value(), invitation.Status(), invitation.InvitedBy(), invitation.ExpiresAt(), invitation.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, invitation)
+}
+
+func (r *InvitationRepository) Update(ctx context.Context, invitation *entities.Invitation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = respondToInvitation(tx, invitation)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, invitation)
+}
+
+// Accept records the invitation as accepted and saves the organization the
+// user joined in the same transaction.
+func (r *InvitationRepository) Accept(ctx context.Context, invitation *entities.Invitation, org *entities.Organization) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = respondToInvitation(tx, invitation)
+	if err != nil {
+		return err
+	}
+
+	err = updateOrganization(ctx, tx, org)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, invitation)
+}
+
+// respondToInvitation saves the new status of the invitation. Only pending
+// invitations can change status, so of two concurrent responses only the
+// first one is saved.
+func respondToInvitation(tx *sql.Tx, invitation *entities.Invitation) error {
+	result, err := tx.Exec(`
+		UPDATE invitation SET status=$2, responded_at=now() WHERE id=$1 AND status='pending';`,
+		invitation.Id().Value(), invitation.Status(),
+	)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("%w: invitation is no longer pending", entities.ErrConflict)
+	}
+
+	return nil
+}
+
+func (r *InvitationRepository) commit(ctx context.Context, tx *sql.Tx, invitation *entities.Invitation) error {
+	err := insertOutboxEvents(tx, "invitation", invitation.Id().Value(), invitation.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{OrganizationId: invitation.OrganizationId().Value()}, "invitation", invitation.Id().Value(), invitation.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const invitationSelect = `
+	SELECT id, organization_id, email, level, token_hash, status, invited_by, expires_at, created_at
+	FROM invitation`
+
+func (r *InvitationRepository) FindById(ctx context.Context, id vo.InvitationId) (*entities.Invitation, error) {
+	return r.findOne(ctx, invitationSelect+` WHERE id=$1;`, id.Value())
+}
+
+func (r *InvitationRepository) FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.Invitation, error) {
+	return r.findOne(ctx, invitationSelect+` WHERE token_hash=$1;`, tokenHash.Value())
+}
+
+func (r *InvitationRepository) FindPendingByOrganization(ctx context.Context, organizationId string) ([]entities.Invitation, error) {
+	return r.find(ctx, invitationSelect+` WHERE organization_id=$1 AND status='pending' ORDER BY created_at DESC;`, organizationId)
+}
+
+func (r *InvitationRepository) FindPendingByEmail(ctx context.Context, email string) ([]entities.Invitation, error) {
+	return r.find(ctx, invitationSelect+` WHERE email=$1 AND status='pending' AND expires_at > now() ORDER BY created_at;`, entities.NormalizeEmail(email))
+}
+
+func (r *InvitationRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.Invitation, error) {
+	invitations, err := r.find(ctx, query, args...)
+	if err != nil || len(invitations) == 0 {
+		return nil, err
+	}
+
+	return &invitations[0], nil
+}
+
+func (r *InvitationRepository) find(ctx context.Context, query string, args ...interface{}) ([]entities.Invitation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]entities.Invitation, 0)
+	for rows.Next() {
+		var record struct {
+			Id             string
+			OrganizationId string
+			Email          string
+			Level          string
+			TokenHash      string
+			Status         string
+			InvitedBy      string
+			ExpiresAt      time.Time
+			CreatedAt      time.Time
+		}
+
+		err = rows.Scan(&record.Id, &record.OrganizationId, &record.Email, &record.Level, &record.TokenHash,
+			&record.Status, &record.InvitedBy, &record.ExpiresAt, &record.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := vo.NewInvitationId(record.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		orgId, err := vo.NewOrganizationId(record.OrganizationId)
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, entities.NewInvitation(
+			id,
+			orgId,
+			record.Email,
+			vo.MembershipLevel(record.Level),
+			vo.TokenHashFromString(record.TokenHash),
+			entities.InvitationStatus(record.Status),
+			record.InvitedBy,
+			record.ExpiresAt,
+			record.CreatedAt,
+		))
+	}
+
+	return invitations, rows.Err()
+}
@@ -0,0 +1,196 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"iyaem/internal/infrastructure/jobs"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// Enqueue joins the transaction of the context, if any, so that a job is
+// only queued when the work that queues it commits.
+func (r *JobRepository) Enqueue(ctx context.Context, job jobs.Job) (string, error) {
+	actor, err := json.Marshal(job.Actor)
+	if err != nil {
+		return "", err
+	}
+
+	conn := connFrom(ctx, r.db)
+
+	var id string
+	err = conn.QueryRowContext(ctx, `
+		INSERT INTO job (id, type, payload, idempotency_key, organization_id, actor, status, max_attempts, run_at, created_at)
+		VALUES ($1, $2, $3, nullif($4, ''), nullif($5, '')::uuid, $6, $7, $8, $9, $10)
+		ON CONFLICT (idempotency_key) DO NOTHING
+		RETURNING id;`,
+		job.Id, job.Type, job.Payload, job.IdempotencyKey, job.OrganizationId, actor,
+		job.Status, job.MaxAttempts, job.RunAt, job.CreatedAt,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = conn.QueryRowContext(ctx, `SELECT id FROM job WHERE idempotency_key = $1;`, job.IdempotencyKey).Scan(&id)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *JobRepository) FetchPending(ctx context.Context, types []string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	return r.find(ctx, `
+		UPDATE job SET status = 'running', attempts = attempts + 1,
+			locked_until = now() + $3 * interval '1 millisecond',
+			started_at = coalesce(started_at, now())
+		WHERE id IN (
+			SELECT id FROM job
+			WHERE status IN ('pending', 'running') AND type = ANY($1) AND run_at <= now()
+				AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns+`;`,
+		pq.StringArray(types), limit, lease.Milliseconds(),
+	)
+}
+
+func (r *JobRepository) ExtendLease(ctx context.Context, id string, lease time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET locked_until = now() + $2 * interval '1 millisecond'
+		WHERE id = $1 AND status = 'running';`, id, lease.Milliseconds(),
+	)
+
+	return err
+}
+
+func (r *JobRepository) SetProgress(ctx context.Context, id string, done int, total int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET progress_done = $2, progress_total = $3 WHERE id = $1;`, id, done, total,
+	)
+
+	return err
+}
+
+func (r *JobRepository) MarkSucceeded(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET status = 'succeeded', finished_at = now(), locked_until = NULL WHERE id = $1;`, id,
+	)
+
+	return err
+}
+
+// MarkFailed puts the job back in the queue, to run again at retryAt.
+func (r *JobRepository) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET status = 'pending', last_error = $2, run_at = $3, locked_until = NULL
+		WHERE id = $1;`, id, cause.Error(), retryAt,
+	)
+
+	return err
+}
+
+// Postpone gives back the attempt counted when the job was fetched.
+func (r *JobRepository) Postpone(ctx context.Context, id string, runAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET status = 'pending', attempts = attempts - 1, run_at = $2, locked_until = NULL
+		WHERE id = $1;`, id, runAt,
+	)
+
+	return err
+}
+
+func (r *JobRepository) MarkDead(ctx context.Context, id string, cause error) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE job SET status = 'dead', last_error = $2, finished_at = now(), locked_until = NULL
+		WHERE id = $1;`, id, cause.Error(),
+	)
+
+	return err
+}
+
+func (r *JobRepository) FindById(ctx context.Context, id string) (*jobs.Job, error) {
+	found, err := r.find(ctx, `SELECT `+jobColumns+` FROM job WHERE id = $1;`, id)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+
+	return &found[0], nil
+}
+
+const jobColumns = `id, type, payload, coalesce(idempotency_key, ''), coalesce(organization_id::text, ''), actor,
+	status, attempts, max_attempts, progress_done, progress_total, last_error, run_at, created_at, started_at, finished_at`
+
+// TryLock takes a session advisory lock, on a connection of its own that
+// is held until the lock is released. It does not wait for the lock, so
+// no connection is held by a runner waiting for one.
+func (r *JobRepository) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1));`, key).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+
+	return func() {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1));`, key)
+		if err != nil {
+			log.Printf("Error: unlock %s: %v", key, err)
+		}
+		conn.Close()
+	}, true, nil
+}
+
+func (r *JobRepository) find(ctx context.Context, query string, args ...interface{}) ([]jobs.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make([]jobs.Job, 0)
+	for rows.Next() {
+		var job jobs.Job
+		var actor []byte
+		var startedAt, finishedAt sql.NullTime
+
+		err = rows.Scan(&job.Id, &job.Type, &job.Payload, &job.IdempotencyKey, &job.OrganizationId, &actor,
+			&job.Status, &job.Attempts, &job.MaxAttempts, &job.ProgressDone, &job.ProgressTotal, &job.LastError,
+			&job.RunAt, &job.CreatedAt, &startedAt, &finishedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(actor, &job.Actor); err != nil {
+			return nil, err
+		}
+		if startedAt.Valid {
+			job.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			job.FinishedAt = &finishedAt.Time
+		}
+
+		found = append(found, job)
+	}
+
+	return found, rows.Err()
+}
@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"fmt"
+	"iyaem/internal/app/queries"
+	"strings"
+)
+
+// sortColumn is a column a list can be sorted by, with the type its
+// cursor values are read back as.
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// keyset pages through a list ordered by one of its columns and then by
+// id, so that a page starts right after the last item of the previous one
+// however the list changed in between.
+type keyset struct {
+	sort   string
+	column sortColumn
+	id     string
+	desc   bool
+	after  *queries.Cursor
+	limit  int
+
+	// afterValue is the sort value of the cursor, decoded for its column.
+	afterValue interface{}
+}
+
+func newKeyset(list queries.ListQuery, columns map[string]sortColumn, fallback string, id string) (keyset, error) {
+	fields := make([]string, 0, len(columns))
+	for field := range columns {
+		fields = append(fields, field)
+	}
+
+	field, desc, err := list.SortBy(fallback, fields...)
+	if err != nil {
+		return keyset{}, err
+	}
+
+	k := keyset{sort: field, column: columns[field], id: id, desc: desc, limit: list.Limit}
+	if desc {
+		k.sort = "-" + field
+	}
+
+	if list.Cursor != "" {
+		cursor, err := queries.DecodeCursor(list.Cursor, k.sort)
+		if err != nil {
+			return keyset{}, err
+		}
+		k.after = &cursor
+		k.afterValue = cursor.Value
+
+		if k.column.cast == "timestamptz" {
+			if k.afterValue, err = cursor.TimeValue(); err != nil {
+				return keyset{}, err
+			}
+		}
+	}
+
+	return k, nil
+}
+
+// value selects the sort column as text, to build the next cursor from.
+func (k keyset) value() string {
+	return k.column.expr + "::text"
+}
+
+// where returns the condition that skips the items up to the cursor, if
+// there is one, adding its arguments to args.
+func (k keyset) where(args []interface{}) (string, []interface{}) {
+	if k.after == nil {
+		return "", args
+	}
+
+	op := ">"
+	if k.desc {
+		op = "<"
+	}
+
+	args = append(args, k.afterValue, k.after.Id)
+	return fmt.Sprintf(" AND (%s, %s) %s ($%d::%s, $%d::uuid)", k.column.expr, k.id, op, len(args)-1, k.column.cast, len(args)), args
+}
+
+// orderBy orders the list and fetches one item more than the page, which
+// tells whether there is a next page.
+func (k keyset) orderBy(args []interface{}) (string, []interface{}) {
+	order := "ASC"
+	if k.desc {
+		order = "DESC"
+	}
+
+	clause := fmt.Sprintf(" ORDER BY %s %s, %s %s", k.column.expr, order, k.id, order)
+	if k.limit > 0 {
+		args = append(args, k.limit+1)
+		clause += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return clause, args
+}
+
+// page trims the items fetched with orderBy to the page, given the sort
+// value and id of each item.
+func keysetPage[T any](k keyset, items []T, values []string, ids []string) queries.Page[T] {
+	if k.limit <= 0 || len(items) <= k.limit {
+		return queries.Page[T]{Items: items}
+	}
+
+	last := k.limit - 1
+	return queries.Page[T]{
+		Items:      items[:k.limit],
+		NextCursor: queries.Cursor{Sort: k.sort, Value: values[last], Id: ids[last]}.Encode(),
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so that user input
+// only matches itself.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/infrastructure/jobs"
+	"time"
+)
+
+type MemberImportRepository struct {
+	db    *sql.DB
+	queue *jobs.Queue
+}
+
+func NewMemberImportRepository(db *sql.DB, queue *jobs.Queue) repositories.MemberImportRepository {
+	return &MemberImportRepository{
+		db:    db,
+		queue: queue,
+	}
+}
+
+func (r *MemberImportRepository) Insert(ctx context.Context, memberImport *entities.MemberImport) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := json.Marshal(memberImport.Rows())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO member_import (id, organization_id, requested_by, dry_run, rows, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`,
+		memberImport.Id().Value(), memberImport.OrganizationId().Value(), memberImport.RequestedBy().Value(),
+		memberImport.DryRun(), rows, memberImport.Status(), memberImport.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The job is queued in the transaction, so an import is never left
+	// pending without a job to run it. It shares the id of the import.
+	_, err = jobs.Enqueue(withTx(ctx, tx), r.queue, jobs.MemberImport, jobs.MemberImportPayload{ImportId: memberImport.Id().Value()}, jobs.Options{
+		Id:             memberImport.Id().Value(),
+		IdempotencyKey: "member_import:" + memberImport.Id().Value(),
+		OrganizationId: memberImport.OrganizationId().Value(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, memberImport)
+}
+
+func (r *MemberImportRepository) Update(ctx context.Context, memberImport *entities.MemberImport) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	results, err := json.Marshal(memberImport.Results())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE member_import SET status=$2, results=$3, failure=$4, finished_at=$5 WHERE id=$1;`,
+		memberImport.Id().Value(), memberImport.Status(), results, memberImport.Failure(), memberImport.FinishedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, memberImport)
+}
+
+func (r *MemberImportRepository) commit(ctx context.Context, tx *sql.Tx, memberImport *entities.MemberImport) error {
+	err := insertOutboxEvents(tx, "member_import", memberImport.Id().Value(), memberImport.Events())
+	if err != nil {
+		return err
+	}
+
+	scope := audit.Scope{OrganizationId: memberImport.OrganizationId().Value()}
+	err = insertAuditEntries(ctx, tx, scope, "member_import", memberImport.Id().Value(), memberImport.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *MemberImportRepository) FindById(ctx context.Context, id vo.MemberImportId) (*entities.MemberImport, error) {
+	var record struct {
+		Id             string
+		OrganizationId string
+		RequestedBy    string
+		DryRun         bool
+		Rows           []byte
+		Status         string
+		Results        []byte
+		Failure        string
+		CreatedAt      time.Time
+		FinishedAt     sql.NullTime
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, organization_id, requested_by, dry_run, rows, status, results, failure, created_at, finished_at
+		FROM member_import WHERE id=$1;`, id.Value()).Scan(
+		&record.Id, &record.OrganizationId, &record.RequestedBy, &record.DryRun, &record.Rows,
+		&record.Status, &record.Results, &record.Failure, &record.CreatedAt, &record.FinishedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orgId, err := vo.NewOrganizationId(record.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedBy, err := vo.NewUserId(record.RequestedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []entities.MemberImportRow
+	if err = json.Unmarshal(record.Rows, &rows); err != nil {
+		return nil, err
+	}
+
+	var results []entities.MemberImportResult
+	if err = json.Unmarshal(record.Results, &results); err != nil {
+		return nil, err
+	}
+
+	var finishedAt *time.Time
+	if record.FinishedAt.Valid {
+		finishedAt = &record.FinishedAt.Time
+	}
+
+	memberImport := entities.NewMemberImport(id, orgId, requestedBy, record.DryRun, rows,
+		record.Status, results, record.Failure, record.CreatedAt, finishedAt)
+
+	return &memberImport, nil
+}
@@ -0,0 +1,259 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"time"
+)
+
+type MfaFactorRepository struct {
+	db  *sql.DB
+	box *providers.SecretBox
+}
+
+// NewMfaFactorRepository stores the secrets of the factors sealed in the
+// box.
+func NewMfaFactorRepository(db *sql.DB, box *providers.SecretBox) repositories.MfaFactorRepository {
+	return &MfaFactorRepository{
+		db:  db,
+		box: box,
+	}
+}
+
+func (r *MfaFactorRepository) Insert(ctx context.Context, factor *entities.MfaFactor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	secret, err := r.box.Seal(factor.Secret().Value(), mfaSecretBinding(factor.Id().Value()))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO mfa_factor (id, user_id, secret, last_counter, confirmed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6);`,
+		factor.Id().Value(), factor.UserId().Value(), secret,
+		factor.LastCounter(), factor.ConfirmedAt(), factor.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = r.insertRecoveryCodes(tx, factor)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, factor)
+}
+
+// Update compares and swaps what was accepted since the factor was loaded,
+// so that of two concurrent uses of a password or a recovery code only one
+// succeeds. The other fails with ErrInvalidMfaCode.
+func (r *MfaFactorRepository) Update(ctx context.Context, factor *entities.MfaFactor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if factor.TotpAccepted() {
+		result, err := tx.Exec(`
+			UPDATE mfa_factor SET last_counter=$2, confirmed_at=$3 WHERE id=$1 AND last_counter < $2;`,
+			factor.Id().Value(), factor.LastCounter(), factor.ConfirmedAt(),
+		)
+		if err := requireUnusedMfaCode(result, err); err != nil {
+			return err
+		}
+	}
+
+	if code := factor.UsedRecoveryCode(); code != nil {
+		result, err := tx.Exec(`
+			UPDATE mfa_recovery_code SET used_at=$3 WHERE factor_id=$1 AND code_hash=$2 AND used_at IS NULL;`,
+			factor.Id().Value(), code.Hash().Value(), code.UsedAt(),
+		)
+		if err := requireUnusedMfaCode(result, err); err != nil {
+			return err
+		}
+	}
+
+	if factor.RecoveryCodesReplaced() {
+		_, err = tx.Exec(`DELETE FROM mfa_recovery_code WHERE factor_id=$1;`, factor.Id().Value())
+		if err != nil {
+			return err
+		}
+
+		err = r.insertRecoveryCodes(tx, factor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.commit(ctx, tx, factor)
+}
+
+func (r *MfaFactorRepository) Delete(ctx context.Context, factor *entities.MfaFactor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM mfa_factor WHERE id=$1;`, factor.Id().Value())
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, factor)
+}
+
+func (r *MfaFactorRepository) insertRecoveryCodes(tx *sql.Tx, factor *entities.MfaFactor) error {
+	for _, code := range factor.RecoveryCodes() {
+		_, err := tx.Exec(`
+			INSERT INTO mfa_recovery_code (factor_id, code_hash, used_at) VALUES ($1, $2, $3);`,
+			factor.Id().Value(), code.Hash().Value(), code.UsedAt(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commit records the events of the factor.
+func (r *MfaFactorRepository) commit(ctx context.Context, tx *sql.Tx, factor *entities.MfaFactor) error {
+	err := writeUserEvents(ctx, tx, "mfa_factor", factor.Id().Value(), factor.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// requireUnusedMfaCode fails with ErrInvalidMfaCode unless the statement
+// changed a row, i.e. when the code was used concurrently.
+func requireUnusedMfaCode(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return entities.ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+func (r *MfaFactorRepository) FindByUserId(ctx context.Context, userId vo.UserId) (*entities.MfaFactor, error) {
+	var record struct {
+		Id          string
+		UserId      string
+		Secret      string
+		LastCounter int64
+		ConfirmedAt sql.NullTime
+		CreatedAt   time.Time
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, secret, last_counter, confirmed_at, created_at
+		FROM mfa_factor WHERE user_id=$1;`,
+		userId.Value(),
+	).Scan(&record.Id, &record.UserId, &record.Secret, &record.LastCounter, &record.ConfirmedAt, &record.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := vo.NewMfaFactorId(record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := r.box.Open(record.Secret, mfaSecretBinding(record.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := vo.TotpSecretFromString(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := r.findRecoveryCodes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmedAt *time.Time
+	if record.ConfirmedAt.Valid {
+		confirmedAt = &record.ConfirmedAt.Time
+	}
+
+	factor := entities.NewMfaFactor(id, userId, secret, record.LastCounter, recoveryCodes, confirmedAt, record.CreatedAt)
+	return &factor, nil
+}
+
+func (r *MfaFactorRepository) findRecoveryCodes(ctx context.Context, id vo.MfaFactorId) ([]entities.RecoveryCode, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT code_hash, used_at FROM mfa_recovery_code WHERE factor_id=$1;`,
+		id.Value(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := make([]entities.RecoveryCode, 0)
+	for rows.Next() {
+		var hash string
+		var usedAt sql.NullTime
+
+		err = rows.Scan(&hash, &usedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		var used *time.Time
+		if usedAt.Valid {
+			used = &usedAt.Time
+		}
+
+		codes = append(codes, entities.NewRecoveryCode(vo.TokenHashFromString(hash), used))
+	}
+
+	return codes, rows.Err()
+}
+
+func (r *MfaFactorRepository) IsRequired(ctx context.Context, userId vo.UserId) (bool, error) {
+	var required bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM mfa_factor WHERE user_id=$1 AND confirmed_at IS NOT NULL
+		) OR EXISTS(
+			SELECT 1 FROM user_organization uo
+			JOIN organization o ON o.id = uo.organization_id
+			WHERE uo.user_id=$1 AND o.require_mfa
+		);`,
+		userId.Value(),
+	).Scan(&required)
+
+	return required, err
+}
+
+func mfaSecretBinding(id string) string {
+	return "mfa_factor.secret:" + id
+}
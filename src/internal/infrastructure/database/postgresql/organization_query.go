@@ -3,7 +3,12 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"iyaem/internal/app/queries"
+	"iyaem/internal/app/scim"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type OrganizationQuery struct {
@@ -60,30 +65,278 @@ func (q *OrganizationQuery) AllAffilatedOrganizations(ctx context.Context, userI
 	return orgs, nil
 }
 
-func (q *OrganizationQuery) UsersInOrganization(ctx context.Context, organizationId string) ([]queries.User, error) {
-	rows, err := q.db.Query(`
-		SELECT uo.id, uo.user_id, u."picture", u."name", u."email", uo."level", uo.created_at as joined_at FROM user_organization uo 
+// memberSortColumns are the fields members can be sorted by.
+var memberSortColumns = map[string]sortColumn{
+	"name":      {`coalesce(u."name", '')`, "text"},
+	"email":     {`coalesce(u."email", '')`, "text"},
+	"level":     {`uo."level"::text`, "text"},
+	"joined_at": {`uo.created_at`, "timestamptz"},
+}
+
+// UsersInOrganization returns a page of the members of the organization,
+// sorted by name unless the query says otherwise. Members can be filtered
+// on their name, email, level and joining date, and on a role or group
+// they have, in the tenant of the query when it names one.
+func (q *OrganizationQuery) UsersInOrganization(ctx context.Context, organizationId string, list queries.ListQuery) (queries.Page[queries.User], error) {
+	k, err := newKeyset(list, memberSortColumns, "name", "uo.id")
+	if err != nil {
+		return queries.Page[queries.User]{}, err
+	}
+
+	conditions, args := memberConditions(organizationId, list)
+
+	query := `
+		SELECT uo.id, uo.user_id, u."picture", u."name", u."email", uo."level", uo.created_at as joined_at, ` + k.value() + ` FROM user_organization uo 
 		LEFT JOIN public."user" u ON u.id = uo.user_id 
-		WHERE uo.organization_id=$1;`, organizationId)
+		WHERE ` + strings.Join(conditions, " AND ")
 
+	keysetWhere, args := k.where(args)
+	orderBy, args := k.orderBy(args)
+
+	rows, err := q.db.QueryContext(ctx, query+keysetWhere+orderBy+";", args...)
 	if err != nil {
-		return nil, err
+		return queries.Page[queries.User]{}, err
 	}
 
 	defer rows.Close()
 
 	users := make([]queries.User, 0)
+	values := make([]string, 0)
+	ids := make([]string, 0)
 
 	for rows.Next() {
 		user := queries.User{}
-		err := rows.Scan(&user.UserOrgId, &user.UserId, &user.Picture, &user.Name, &user.Email, &user.Level, &user.JoinedAt)
+		var value string
+		err := rows.Scan(&user.UserOrgId, &user.UserId, &user.Picture, &user.Name, &user.Email, &user.Level, &user.JoinedAt, &value)
+		if err != nil {
+			return queries.Page[queries.User]{}, err
+		}
+		users = append(users, user)
+		values = append(values, value)
+		ids = append(ids, user.UserOrgId)
+	}
+	if err = rows.Err(); err != nil {
+		return queries.Page[queries.User]{}, err
+	}
+
+	return keysetPage(k, users, values, ids), nil
+}
+
+// memberConditions filters the members of the organization on the name,
+// email, level, joining date, role and group of the query. Roles and
+// groups are assigned per tenant; without one, an assignment in any tenant
+// matches.
+func memberConditions(organizationId string, list queries.ListQuery) ([]string, []interface{}) {
+	conditions := []string{"uo.organization_id = $1"}
+	args := []interface{}{organizationId}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if list.Name != "" {
+		add(`u."name" ILIKE '%%' || $%d || '%%'`, escapeLike(list.Name))
+	}
+	if list.Email != "" {
+		add(`u."email" ILIKE '%%' || $%d || '%%'`, escapeLike(list.Email))
+	}
+	if list.Level != "" {
+		add(`uo."level"::text = $%d`, list.Level)
+	}
+	if !list.JoinedFrom.IsZero() {
+		add("uo.created_at >= $%d", list.JoinedFrom)
+	}
+	if !list.JoinedTo.IsZero() {
+		add("uo.created_at < $%d", list.JoinedTo)
+	}
+
+	assigned := func(table string, column string, id string) {
+		args = append(args, id)
+		condition := fmt.Sprintf("EXISTS (SELECT 1 FROM %s a WHERE a.user_org_id = uo.id AND a.%s = $%d", table, column, len(args))
+		if list.TenantId != "" {
+			args = append(args, list.TenantId)
+			condition += fmt.Sprintf(" AND a.tenant_id = $%d", len(args))
+		}
+		conditions = append(conditions, condition+")")
+	}
+
+	if list.RoleId != "" {
+		assigned("user_role", "role_id", list.RoleId)
+	}
+	if list.GroupId != "" {
+		assigned("user_group", "group_id", list.GroupId)
+	}
+
+	return conditions, args
+}
+
+// SearchUsersInOrganization returns the members of the organization
+// whose name or email matches the term. Members whose name, a word of
+// their name or their email starts with the term are prefix matches, the
+// others only resemble it. The best list.Limit of each are returned, which
+// holds the best list.Limit of all whichever way MemberSearch ranks them.
+// Members can be filtered as in UsersInOrganization.
+func (q *OrganizationQuery) SearchUsersInOrganization(ctx context.Context, organizationId string, term string, list queries.ListQuery) ([]queries.SearchMatch, error) {
+	conditions, args := memberConditions(organizationId, list)
+
+	args = append(args, term, escapeLike(term), list.Limit)
+	t, prefix, limit := len(args)-2, len(args)-1, len(args)
+
+	query := fmt.Sprintf(`
+		WITH candidates AS (
+			SELECT uo.id, uo.user_id, u."picture", u."name", u."email", uo."level", uo.created_at as joined_at,
+				coalesce(lower(u."name") LIKE $%[3]d || '%%' OR lower(u."name") LIKE '%% ' || $%[3]d || '%%' OR lower(u."email") LIKE $%[3]d || '%%', false) AS prefix,
+				coalesce(greatest(word_similarity($%[2]d, lower(u."name")), word_similarity($%[2]d, lower(u."email"))), 0) AS similarity
+			FROM user_organization uo 
+			LEFT JOIN public."user" u ON u.id = uo.user_id 
+			WHERE %[1]s AND (
+				lower(u."name") LIKE $%[3]d || '%%' OR lower(u."email") LIKE $%[3]d || '%%'
+				OR $%[2]d <%% lower(u."name") OR $%[2]d <%% lower(u."email")
+			)
+		)
+		(SELECT * FROM candidates WHERE prefix ORDER BY similarity DESC, "name", id LIMIT $%[4]d)
+		UNION ALL
+		(SELECT * FROM candidates WHERE NOT prefix ORDER BY similarity DESC, "name", id LIMIT $%[4]d);`,
+		strings.Join(conditions, " AND "), t, prefix, limit)
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	matches := make([]queries.SearchMatch, 0)
+
+	for rows.Next() {
+		match := queries.SearchMatch{}
+		err := rows.Scan(&match.UserOrgId, &match.UserId, &match.Picture, &match.Name, &match.Email, &match.Level, &match.JoinedAt,
+			&match.Prefix, &match.Similarity)
 		if err != nil {
 			return nil, err
 		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+const memberSelect = `
+	SELECT uo.id, uo.user_id, u."picture", u."name", u."email", uo."level", uo.created_at as joined_at FROM user_organization uo 
+	LEFT JOIN public."user" u ON u.id = uo.user_id `
+
+func (q *OrganizationQuery) FindMember(ctx context.Context, organizationId string, userId string) (*queries.User, error) {
+	return q.findMember(ctx, memberSelect+`WHERE uo.organization_id = $1 AND uo.user_id = $2;`, organizationId, userId)
+}
+
+func (q *OrganizationQuery) FindMemberByEmail(ctx context.Context, organizationId string, email string) (*queries.User, error) {
+	return q.findMember(ctx, memberSelect+`WHERE uo.organization_id = $1 AND lower(u."email") = lower($2);`, organizationId, email)
+}
+
+func (q *OrganizationQuery) findMember(ctx context.Context, query string, args ...interface{}) (*queries.User, error) {
+	user := queries.User{}
+	err := q.db.QueryRowContext(ctx, query, args...).Scan(&user.UserOrgId, &user.UserId, &user.Picture, &user.Name, &user.Email, &user.Level, &user.JoinedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// scimUserColumns are the columns of the SCIM user attributes. Every
+// member is active, as deprovisioned users are no longer members.
+var scimUserColumns = map[string]string{
+	"username":     `coalesce(u."email", '')`,
+	"emails":       `coalesce(u."email", '')`,
+	"emails.value": `coalesce(u."email", '')`,
+	"displayname":  `coalesce(u."name", '')`,
+	"active":       `'true'`,
+}
+
+// ScimUsers pages through the members matching the filter in the order
+// they joined, comparing values without regard to case as
+// scim.Filter.Matches does.
+func (q *OrganizationQuery) ScimUsers(ctx context.Context, organizationId string, filter scim.Filter, startIndex int, count int) ([]queries.User, int, error) {
+	conditions := []string{"uo.organization_id = $1"}
+	args := []interface{}{organizationId}
+
+	for _, c := range filter {
+		column, ok := scimUserColumns[c.Attribute]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: cannot filter by %s", scim.ErrInvalidFilter, c.Attribute)
+		}
+
+		if c.Operator == "pr" {
+			conditions = append(conditions, column+" <> ''")
+			continue
+		}
+
+		value := strings.ToLower(c.Value)
+		switch c.Operator {
+		case "co":
+			value = "%" + escapeLike(value) + "%"
+		case "sw":
+			value = escapeLike(value) + "%"
+		case "ew":
+			value = "%" + escapeLike(value)
+		}
+		args = append(args, value)
+
+		switch c.Operator {
+		case "eq":
+			conditions = append(conditions, fmt.Sprintf("lower(%s) = $%d", column, len(args)))
+		case "ne":
+			conditions = append(conditions, fmt.Sprintf("lower(%s) <> $%d", column, len(args)))
+		default:
+			conditions = append(conditions, fmt.Sprintf("lower(%s) LIKE $%d", column, len(args)))
+		}
+	}
+
+	args = append(args, count, startIndex-1)
+	query := fmt.Sprintf(`
+		SELECT uo.id, uo.user_id, u."picture", u."name", u."email", uo."level", uo.created_at as joined_at, count(*) OVER () FROM user_organization uo 
+		LEFT JOIN public."user" u ON u.id = uo.user_id 
+		WHERE %s
+		ORDER BY uo.created_at, uo.id LIMIT $%d OFFSET $%d;`, strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	users := make([]queries.User, 0)
+	total := 0
+
+	for rows.Next() {
+		user := queries.User{}
+		err := rows.Scan(&user.UserOrgId, &user.UserId, &user.Picture, &user.Name, &user.Email, &user.Level, &user.JoinedAt, &total)
+		if err != nil {
+			return nil, 0, err
+		}
 		users = append(users, user)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	return users, nil
+	// Past the last page no row carries the total, which is counted apart.
+	if len(users) == 0 && startIndex > 1 {
+		err = q.db.QueryRowContext(ctx, `
+			SELECT count(*) FROM user_organization uo 
+			LEFT JOIN public."user" u ON u.id = uo.user_id 
+			WHERE `+strings.Join(conditions, " AND ")+`;`, args[:len(args)-2]...).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return users, total, nil
 }
 
 func (q *OrganizationQuery) RecentUsersInOrganization(ctx context.Context, organizationId string) ([]queries.User, error) {
@@ -125,3 +378,45 @@ func (q *OrganizationQuery) FindById(ctx context.Context, organizationId string)
 
 	return org, nil
 }
+
+func (q *OrganizationQuery) EachMembership(ctx context.Context, organizationId string, fn func(queries.MembershipRow) error) error {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT u."email", uo."level"::text, coalesce(t.tenant_id::text, ''),
+			array(SELECT ur.role_id::text FROM user_role ur
+				WHERE ur.user_org_id = uo.id AND ur.tenant_id = t.tenant_id
+				ORDER BY ur.role_id),
+			array(SELECT ug.group_id::text FROM user_group ug
+				WHERE ug.user_org_id = uo.id AND ug.tenant_id = t.tenant_id
+				ORDER BY ug.group_id)
+		FROM user_organization uo
+		JOIN public."user" u ON u.id = uo.user_id
+		LEFT JOIN LATERAL (
+			SELECT tenant_id FROM user_role WHERE user_org_id = uo.id
+			UNION
+			SELECT tenant_id FROM user_group WHERE user_org_id = uo.id
+		) t ON true
+		WHERE uo.organization_id = $1
+		ORDER BY u."email", t.tenant_id NULLS FIRST;`, organizationId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row queries.MembershipRow
+		var roles, groups pq.StringArray
+
+		err = rows.Scan(&row.Email, &row.Level, &row.TenantId, &roles, &groups)
+		if err != nil {
+			return err
+		}
+
+		row.Roles = roles
+		row.Groups = groups
+		if err = fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"iyaem/internal/app/audit"
 	"iyaem/internal/domain/entities"
 	"iyaem/internal/domain/events"
 	"iyaem/internal/domain/repositories"
 	"iyaem/internal/domain/valueobjects"
 	"log"
+	"time"
 )
 
 type OrganizationRepository struct {
@@ -381,6 +383,7 @@ func (r *OrganizationRepository) Insert(ctx context.Context, org *entities.Organ
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
 	_, err = tx.Exec(`
 		INSERT INTO organization (id, name, identifier) VALUES ($1, $2, $3);`,
@@ -393,8 +396,8 @@ func (r *OrganizationRepository) Insert(ctx context.Context, org *entities.Organ
 
 	for _, member := range org.Members() {
 		_, err = tx.Exec(
-			`INSERT INTO user_organization (organization_id, user_id, level) VALUES ($1, $2, $3);`,
-			org.Id().Value(), member.UserId().Value(), member.Level(),
+			`INSERT INTO user_organization (id, organization_id, user_id, level) VALUES ($1, $2, $3, $4);`,
+			member.Id().Value(), org.Id().Value(), member.UserId().Value(), member.Level(),
 		)
 
 		if err != nil {
@@ -402,6 +405,16 @@ func (r *OrganizationRepository) Insert(ctx context.Context, org *entities.Organ
 		}
 	}
 
+	err = insertOutboxEvents(tx, "organization", org.Id().Value(), org.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{OrganizationId: org.Id().Value()}, "organization", org.Id().Value(), org.Events())
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -417,7 +430,20 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	err = updateOrganization(ctx, tx, org)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
+// updateOrganization writes the changes of the organization, with its
+// outbox events and audit entries, without committing them, so other
+// repositories can save an organization together with their aggregate.
+func updateOrganization(ctx context.Context, tx *sql.Tx, org *entities.Organization) error {
 	managerCount := 0
 	for _, member := range org.Members() {
 		if member.Level() == "manager" {
@@ -425,7 +451,7 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 		}
 	}
 
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
 		UPDATE organization SET name=$1, identifier=$2, tenant_count=$4, member_count=$5, manager_count=$6 WHERE id=$3;`,
 		org.Name(), org.Identifier(), org.Id().Value(), len(org.Tenants()), len(org.Members()), managerCount,
 	)
@@ -457,6 +483,37 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 			if err != nil {
 				return err
 			}
+		case events.OwnerAdded:
+			_, err = tx.Exec(`
+				UPDATE user_organization SET level='owner' WHERE id=$1;`,
+				e.MembershipId,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.OwnershipTransferRequested:
+			_, err = tx.Exec(`
+				INSERT INTO ownership_transfer 
+					(id, organization_id, from_membership_id, to_membership_id, expires_at) 
+				VALUES ($1, $2, $3, $4, $5);`,
+				e.TransferId, org.Id().Value(), e.FromMembershipId, e.ToMembershipId, e.ExpiresAt,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.OwnershipTransferred:
+			for _, statement := range []string{
+				`UPDATE user_organization SET level='manager' WHERE id=$2;`,
+				`UPDATE user_organization SET level='owner' WHERE id=$3;`,
+				`UPDATE ownership_transfer SET confirmed_at=$4 WHERE id=$1;`,
+			} {
+				_, err = tx.Exec(statement, e.TransferId, e.FromMembershipId, e.ToMembershipId, e.Timestamp)
+				if err != nil {
+					return err
+				}
+			}
 		case events.MemberDemoted:
 			_, err = tx.Exec(`
 				UPDATE user_organization SET level='member' WHERE id=$1;`,
@@ -466,6 +523,17 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 			if err != nil {
 				return err
 			}
+		case events.MemberRemoved:
+			for _, statement := range []string{
+				`DELETE FROM user_role WHERE user_org_id=$1;`,
+				`DELETE FROM user_group WHERE user_org_id=$1;`,
+				`DELETE FROM user_organization WHERE id=$1;`,
+			} {
+				_, err = tx.Exec(statement, e.MembershipId)
+				if err != nil {
+					return err
+				}
+			}
 		case events.RoleAddedToMember:
 			_, err = tx.Exec(`
 				INSERT INTO user_role (user_org_id, role_id, tenant_id) VALUES ($1, $2, $3);`,
@@ -503,6 +571,15 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 
 			log.Printf("Id: %v, GroupId: %v, TenantId: %v", e.MembershipId, e.GroupId, e.TenantId)
 
+			if err != nil {
+				return err
+			}
+		case events.OrganizationMfaRequirementChanged:
+			_, err = tx.Exec(`
+				UPDATE organization SET require_mfa=$2 WHERE id=$1;`,
+				org.Id().Value(), e.Required,
+			)
+
 			if err != nil {
 				return err
 			}
@@ -520,10 +597,60 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *entities.Organ
 		}
 	}
 
-	err = tx.Commit()
+	err = insertOutboxEvents(tx, "organization", org.Id().Value(), org.Events())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return insertAuditEntries(ctx, tx, audit.Scope{OrganizationId: org.Id().Value()}, "organization", org.Id().Value(), org.Events())
+}
+
+func (r *OrganizationRepository) FindOwnershipTransfer(ctx context.Context, organizationId string, id string) (*entities.OwnershipTransfer, error) {
+	var record struct {
+		Id             string
+		OrganizationId string
+		From           string
+		To             string
+		ExpiresAt      time.Time
+		ConfirmedAt    sql.NullTime
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, organization_id, from_membership_id, to_membership_id, expires_at, confirmed_at
+		FROM ownership_transfer WHERE id=$1 AND organization_id=$2;`, id, organizationId,
+	).Scan(&record.Id, &record.OrganizationId, &record.From, &record.To, &record.ExpiresAt, &record.ConfirmedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transferId, err := valueobjects.NewOwnershipTransferId(record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	orgId, err := valueobjects.NewOrganizationId(record.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := valueobjects.NewMembershipId(record.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := valueobjects.NewMembershipId(record.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmedAt *time.Time
+	if record.ConfirmedAt.Valid {
+		confirmedAt = &record.ConfirmedAt.Time
+	}
+
+	transfer := entities.NewOwnershipTransfer(transferId, orgId, from, to, record.ExpiresAt, confirmedAt)
+	return &transfer, nil
 }
@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iyaem/internal/domain/events"
+	"iyaem/internal/infrastructure/outbox"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// insertOutboxEvents writes the events raised by an aggregate to the outbox
+// using the caller's transaction, so they are only published if the
+// aggregate changes are committed.
+func insertOutboxEvents(tx *sql.Tx, aggregateType string, aggregateId string, evts []events.Event) error {
+	for _, event := range evts {
+		payload, err := event.JSON()
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", event.Name(), err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6);`,
+			uuid.NewString(), aggregateType, aggregateId, event.Name(), payload, event.OccuredOn(),
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) FetchPending(ctx context.Context, limit int, lease time.Duration) ([]outbox.Message, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE outbox SET locked_until = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = 'pending' AND next_attempt_at <= now()
+				AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, aggregate_type, aggregate_id, event_type, payload, attempts, created_at;`,
+		limit, lease.Milliseconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]outbox.Message, 0)
+
+	for rows.Next() {
+		m := outbox.Message{}
+		err := rows.Scan(&m.Id, &m.AggregateType, &m.AggregateId, &m.EventType, &m.Payload, &m.Attempts, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox SET status = 'sent', sent_at = now(), locked_until = NULL WHERE id = $1;`, id,
+	)
+
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, locked_until = NULL
+		WHERE id = $1;`, id, cause.Error(), retryAt,
+	)
+
+	return err
+}
+
+func (r *OutboxRepository) MarkDead(ctx context.Context, id string, cause error) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox SET status = 'dead', attempts = attempts + 1, last_error = $2, locked_until = NULL
+		WHERE id = $1;`, id, cause.Error(),
+	)
+
+	return err
+}
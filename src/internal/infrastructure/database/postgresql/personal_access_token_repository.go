@@ -0,0 +1,182 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalAccessTokenRepository(db *sql.DB) repositories.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		db: db,
+	}
+}
+
+func (r *PersonalAccessTokenRepository) Insert(ctx context.Context, token *entities.PersonalAccessToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	organizationIds := make([]string, 0, len(token.OrganizationIds()))
+	for _, id := range token.OrganizationIds() {
+		organizationIds = append(organizationIds, id.Value())
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO personal_access_token (id, user_id, name, token_hash, scopes, organization_ids, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`,
+		token.Id().Value(), token.UserId().Value(), token.Name(), token.TokenHash().Value(),
+		pq.StringArray(token.Scopes()), pq.StringArray(organizationIds), token.CreatedAt(), token.ExpiresAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, token)
+}
+
+func (r *PersonalAccessTokenRepository) Update(ctx context.Context, token *entities.PersonalAccessToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE personal_access_token SET last_used_at=$2, last_used_ip=$3, revoked_at=$4 WHERE id=$1;`,
+		token.Id().Value(), token.LastUsedAt(), token.LastUsedIp(), token.RevokedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, token)
+}
+
+func (r *PersonalAccessTokenRepository) commit(ctx context.Context, tx *sql.Tx, token *entities.PersonalAccessToken) error {
+	err := writeUserEvents(ctx, tx, "personal_access_token", token.Id().Value(), token.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const personalAccessTokenSelect = `
+	SELECT id, user_id, name, token_hash, scopes, organization_ids, created_at, expires_at,
+		last_used_at, last_used_ip, revoked_at
+	FROM personal_access_token`
+
+func (r *PersonalAccessTokenRepository) FindById(ctx context.Context, id vo.PersonalAccessTokenId) (*entities.PersonalAccessToken, error) {
+	return r.findOne(ctx, personalAccessTokenSelect+` WHERE id=$1;`, id.Value())
+}
+
+func (r *PersonalAccessTokenRepository) FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.PersonalAccessToken, error) {
+	return r.findOne(ctx, personalAccessTokenSelect+` WHERE token_hash=$1;`, tokenHash.Value())
+}
+
+func (r *PersonalAccessTokenRepository) FindByUser(ctx context.Context, userId vo.UserId) ([]entities.PersonalAccessToken, error) {
+	return r.find(ctx, personalAccessTokenSelect+`
+		WHERE user_id=$1 AND revoked_at IS NULL
+		ORDER BY created_at DESC;`,
+		userId.Value(),
+	)
+}
+
+func (r *PersonalAccessTokenRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.PersonalAccessToken, error) {
+	tokens, err := r.find(ctx, query, args...)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	return &tokens[0], nil
+}
+
+func (r *PersonalAccessTokenRepository) find(ctx context.Context, query string, args ...interface{}) ([]entities.PersonalAccessToken, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]entities.PersonalAccessToken, 0)
+	for rows.Next() {
+		var record struct {
+			Id              string
+			UserId          string
+			Name            string
+			TokenHash       string
+			Scopes          pq.StringArray
+			OrganizationIds pq.StringArray
+			CreatedAt       time.Time
+			ExpiresAt       time.Time
+			LastUsedAt      sql.NullTime
+			LastUsedIp      string
+			RevokedAt       sql.NullTime
+		}
+
+		err = rows.Scan(&record.Id, &record.UserId, &record.Name, &record.TokenHash, &record.Scopes,
+			&record.OrganizationIds, &record.CreatedAt, &record.ExpiresAt, &record.LastUsedAt,
+			&record.LastUsedIp, &record.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := vo.NewPersonalAccessTokenId(record.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		userId, err := vo.NewUserId(record.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		organizationIds := make([]vo.OrganizationId, 0, len(record.OrganizationIds))
+		for _, value := range record.OrganizationIds {
+			organizationId, err := vo.NewOrganizationId(value)
+			if err != nil {
+				return nil, err
+			}
+
+			organizationIds = append(organizationIds, organizationId)
+		}
+
+		var lastUsedAt *time.Time
+		if record.LastUsedAt.Valid {
+			lastUsedAt = &record.LastUsedAt.Time
+		}
+
+		var revokedAt *time.Time
+		if record.RevokedAt.Valid {
+			revokedAt = &record.RevokedAt.Time
+		}
+
+		tokens = append(tokens, entities.NewPersonalAccessToken(
+			id,
+			userId,
+			record.Name,
+			vo.TokenHashFromString(record.TokenHash),
+			record.Scopes,
+			organizationIds,
+			record.CreatedAt,
+			record.ExpiresAt,
+			lastUsedAt,
+			record.LastUsedIp,
+			revokedAt,
+		))
+	}
+
+	return tokens, rows.Err()
+}
@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/queries"
+
+	"github.com/lib/pq"
+)
+
+type RoleQuery struct {
+	db *sql.DB
+}
+
+func NewRoleQuery(db *sql.DB) *RoleQuery {
+	return &RoleQuery{db}
+}
+
+var roleSortColumns = map[string]sortColumn{
+	"name": {`r."name"`, "text"},
+}
+
+// RolesInTenant returns a page of the roles of the tenant's application
+// and of the tenant itself, sorted by name and filtered on it.
+func (q *RoleQuery) RolesInTenant(ctx context.Context, tenantId string, list queries.ListQuery) (queries.Page[queries.Role], error) {
+	k, err := newKeyset(list, roleSortColumns, "name", "r.id")
+	if err != nil {
+		return queries.Page[queries.Role]{}, err
+	}
+
+	query := `
+		SELECT r.id, r."name", r.description, r.tenant_id IS NOT NULL,
+			array(SELECT p."name" FROM role_permission rp
+				JOIN "permission" p ON p.id = rp.permission_id
+				WHERE rp.role_id = r.id
+				ORDER BY p."name"),
+			` + k.value() + `
+		FROM "role" r
+		JOIN tenant t ON r.application_id = t.app_id
+		WHERE t.id = $1 AND (r.tenant_id IS NULL OR r.tenant_id = t.id)`
+	args := []interface{}{tenantId}
+
+	if list.Name != "" {
+		args = append(args, escapeLike(list.Name))
+		query += ` AND r."name" ILIKE '%' || $2 || '%'`
+	}
+
+	keysetWhere, args := k.where(args)
+	orderBy, args := k.orderBy(args)
+
+	rows, err := q.db.QueryContext(ctx, query+keysetWhere+orderBy+";", args...)
+	if err != nil {
+		return queries.Page[queries.Role]{}, err
+	}
+	defer rows.Close()
+
+	roles := make([]queries.Role, 0)
+	values := make([]string, 0)
+	ids := make([]string, 0)
+
+	for rows.Next() {
+		var role queries.Role
+		var permissions pq.StringArray
+		var value string
+
+		err := rows.Scan(&role.Id, &role.Name, &role.Description, &role.Custom, &permissions, &value)
+		if err != nil {
+			return queries.Page[queries.Role]{}, err
+		}
+
+		role.Permissions = permissions
+		roles = append(roles, role)
+		values = append(values, value)
+		ids = append(ids, role.Id)
+	}
+	if err = rows.Err(); err != nil {
+		return queries.Page[queries.Role]{}, err
+	}
+
+	return keysetPage(k, roles, values, ids), nil
+}
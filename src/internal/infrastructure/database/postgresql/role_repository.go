@@ -0,0 +1,242 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"log"
+)
+
+type RoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) repositories.RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (r *RoleRepository) FindById(ctx context.Context, id vo.RoleId) (*entities.Role, error) {
+	var roleRecord struct {
+		Name          string
+		Description   string
+		ApplicationId string
+		TenantId      sql.NullString
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT "name", coalesce(description, ''), application_id, tenant_id FROM "role" WHERE id=$1;`, id.Value(),
+	).Scan(&roleRecord.Name, &roleRecord.Description, &roleRecord.ApplicationId, &roleRecord.TenantId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	applicationId, err := vo.NewApplicationId(roleRecord.ApplicationId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	tenantId, err := nullTenantId(roleRecord.TenantId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p."name" FROM role_permission rp
+		JOIN "permission" p ON p.id = rp.permission_id
+		WHERE rp.role_id=$1 ORDER BY p."name";`, id.Value(),
+	)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make([]entities.Permission, 0)
+
+	for rows.Next() {
+		var permissionRecord struct {
+			Id   string
+			Name string
+		}
+
+		err = rows.Scan(&permissionRecord.Id, &permissionRecord.Name)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		permissionId, err := vo.NewPermissionId(permissionRecord.Id)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+
+		permissions = append(permissions, entities.NewPermission(permissionId, applicationId, permissionRecord.Name))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	role := entities.NewRole(id, roleRecord.Name, roleRecord.Description, applicationId, tenantId, permissions)
+
+	return &role, nil
+}
+
+func (r *RoleRepository) Insert(ctx context.Context, role *entities.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO "role" (id, "name", description, application_id, tenant_id) VALUES ($1, $2, $3, $4, $5);`,
+		role.Id().Value(), role.Name(), role.Description(), role.ApplicationId().Value(), tenantIdValue(role.TenantId()),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range role.Permissions() {
+		_, err = tx.Exec(`
+			INSERT INTO role_permission (role_id, permission_id) VALUES ($1, $2);`,
+			role.Id().Value(), permission.Id().Value(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(role.TenantId()).String}, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RoleRepository) Update(ctx context.Context, role *entities.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE "role" SET "name"=$2, description=$3 WHERE id=$1;`,
+		role.Id().Value(), role.Name(), role.Description(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range role.Events() {
+		switch e := event.(type) {
+		case events.PermissionAttachedToRole:
+			_, err = tx.Exec(`
+				INSERT INTO role_permission (role_id, permission_id) VALUES ($1, $2);`,
+				e.RoleId, e.PermissionId,
+			)
+
+			if err != nil {
+				return err
+			}
+		case events.PermissionDetachedFromRole:
+			_, err = tx.Exec(`
+				DELETE FROM role_permission WHERE role_id=$1 AND permission_id=$2;`,
+				e.RoleId, e.PermissionId,
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = insertOutboxEvents(tx, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(role.TenantId()).String}, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the role and every assignment of it, whether direct or
+// through a group.
+func (r *RoleRepository) Delete(ctx context.Context, role *entities.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`DELETE FROM role_permission WHERE role_id=$1;`,
+		`DELETE FROM group_role WHERE role_id=$1;`,
+		`DELETE FROM user_role WHERE role_id=$1;`,
+		`DELETE FROM "role" WHERE id=$1;`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, role.Id().Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOutboxEvents(tx, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{TenantId: tenantIdValue(role.TenantId()).String}, "role", role.Id().Value(), role.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func nullTenantId(value sql.NullString) (*vo.TenantId, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	tenantId, err := vo.NewTenantId(value.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenantId, nil
+}
+
+func tenantIdValue(tenantId *vo.TenantId) sql.NullString {
+	if tenantId == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: tenantId.Value(), Valid: true}
+}
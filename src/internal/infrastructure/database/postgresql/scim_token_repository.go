@@ -0,0 +1,179 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type ScimTokenRepository struct {
+	db *sql.DB
+}
+
+func NewScimTokenRepository(db *sql.DB) repositories.ScimTokenRepository {
+	return &ScimTokenRepository{
+		db: db,
+	}
+}
+
+func (r *ScimTokenRepository) Insert(ctx context.Context, token *entities.ScimToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var tenantId sql.NullString
+	if token.TenantId() != nil {
+		tenantId = sql.NullString{String: token.TenantId().Value(), Valid: true}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO scim_token (id, organization_id, tenant_id, description, token_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6);`,
+		token.Id().Value(), token.OrganizationId().Value(), tenantId, token.Description(),
+		token.TokenHash().Value(), token.CreatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, token)
+}
+
+func (r *ScimTokenRepository) Update(ctx context.Context, token *entities.ScimToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE scim_token SET revoked_at=$2 WHERE id=$1;`, token.Id().Value(), token.RevokedAt())
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, token)
+}
+
+func (r *ScimTokenRepository) commit(ctx context.Context, tx *sql.Tx, token *entities.ScimToken) error {
+	err := insertOutboxEvents(tx, "scim_token", token.Id().Value(), token.Events())
+	if err != nil {
+		return err
+	}
+
+	scope := audit.Scope{OrganizationId: token.OrganizationId().Value()}
+	if token.TenantId() != nil {
+		scope.TenantId = token.TenantId().Value()
+	}
+
+	err = insertAuditEntries(ctx, tx, scope, "scim_token", token.Id().Value(), token.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const scimTokenSelect = `
+	SELECT id, organization_id, tenant_id, description, token_hash, created_at, revoked_at
+	FROM scim_token`
+
+func (r *ScimTokenRepository) FindById(ctx context.Context, id vo.ScimTokenId) (*entities.ScimToken, error) {
+	return r.findOne(ctx, scimTokenSelect+` WHERE id=$1;`, id.Value())
+}
+
+// FindByToken also records that the token was used.
+func (r *ScimTokenRepository) FindByToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.ScimToken, error) {
+	token, err := r.findOne(ctx, scimTokenSelect+` WHERE token_hash=$1;`, tokenHash.Value())
+	if err != nil || token == nil {
+		return token, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `UPDATE scim_token SET last_used_at=now() WHERE id=$1;`, token.Id().Value())
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *ScimTokenRepository) FindByOrganization(ctx context.Context, organizationId string) ([]entities.ScimToken, error) {
+	return r.find(ctx, scimTokenSelect+` WHERE organization_id=$1 ORDER BY created_at DESC;`, organizationId)
+}
+
+func (r *ScimTokenRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.ScimToken, error) {
+	tokens, err := r.find(ctx, query, args...)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	return &tokens[0], nil
+}
+
+func (r *ScimTokenRepository) find(ctx context.Context, query string, args ...interface{}) ([]entities.ScimToken, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]entities.ScimToken, 0)
+	for rows.Next() {
+		var record struct {
+			Id             string
+			OrganizationId string
+			TenantId       sql.NullString
+			Description    string
+			TokenHash      string
+			CreatedAt      time.Time
+			RevokedAt      sql.NullTime
+		}
+
+		err = rows.Scan(&record.Id, &record.OrganizationId, &record.TenantId, &record.Description,
+			&record.TokenHash, &record.CreatedAt, &record.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := vo.NewScimTokenId(record.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		orgId, err := vo.NewOrganizationId(record.OrganizationId)
+		if err != nil {
+			return nil, err
+		}
+
+		var tenantId *vo.TenantId
+		if record.TenantId.Valid {
+			id, err := vo.NewTenantId(record.TenantId.String)
+			if err != nil {
+				return nil, err
+			}
+			tenantId = &id
+		}
+
+		var revokedAt *time.Time
+		if record.RevokedAt.Valid {
+			revokedAt = &record.RevokedAt.Time
+		}
+
+		tokens = append(tokens, entities.NewScimToken(
+			id,
+			orgId,
+			tenantId,
+			record.Description,
+			vo.TokenHashFromString(record.TokenHash),
+			record.CreatedAt,
+			revokedAt,
+		))
+	}
+
+	return tokens, rows.Err()
+}
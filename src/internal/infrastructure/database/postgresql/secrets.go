@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/providers"
+)
+
+// SealSecrets seals the secrets stored before they were encrypted, and
+// returns how many it sealed. Those are still read as they are, so it can
+// run at any time after the upgrade.
+func SealSecrets(ctx context.Context, db *sql.DB, box *providers.SecretBox) (int, error) {
+	sealed := 0
+
+	for _, column := range []struct {
+		table   string
+		column  string
+		binding func(id string) string
+	}{
+		{"mfa_factor", "secret", mfaSecretBinding},
+		{"sso_connection", "client_secret", ssoClientSecretBinding},
+		{"signing_key", "private_key", signingKeyBinding},
+	} {
+		n, err := sealColumn(ctx, db, box, column.table, column.column, column.binding)
+		sealed += n
+		if err != nil {
+			return sealed, err
+		}
+	}
+
+	return sealed, nil
+}
+
+func sealColumn(ctx context.Context, db *sql.DB, box *providers.SecretBox, table string, column string, binding func(id string) string) (int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, `+column+` FROM `+table+` WHERE `+column+` NOT LIKE 'sealed:%';`)
+	if err != nil {
+		return 0, err
+	}
+
+	plain := make(map[string]string)
+	for rows.Next() {
+		var id, secret string
+		if err := rows.Scan(&id, &secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		plain[id] = secret
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sealed := 0
+	for id, secret := range plain {
+		value, err := box.Seal(secret, binding(id))
+		if err != nil {
+			return sealed, err
+		}
+
+		// A secret changed in the meantime is sealed already.
+		result, err := db.ExecContext(ctx, `UPDATE `+table+` SET `+column+`=$2 WHERE id=$1 AND `+column+`=$3;`, id, value, secret)
+		if err != nil {
+			return sealed, err
+		}
+
+		if n, _ := result.RowsAffected(); n == 1 {
+			sealed++
+		}
+	}
+
+	return sealed, nil
+}
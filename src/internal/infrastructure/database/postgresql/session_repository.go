@@ -0,0 +1,234 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"time"
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) repositories.SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (r *SessionRepository) Insert(ctx context.Context, session *entities.Session) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO session (id, user_id, user_agent, ip_address, auth_method, mfa_required,
+			refresh_token_hash, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`,
+		session.Id().Value(), session.UserId().Value(), session.UserAgent(), session.IpAddress(),
+		session.AuthMethod(), session.MfaRequired(),
+		session.RefreshTokenHash().Value(), session.CreatedAt(), session.LastUsedAt(), session.ExpiresAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, session)
+}
+
+// Update leaves the refresh token alone: the session may have been loaded
+// before a concurrent Rotate, whose token it would bring back.
+func (r *SessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE session
+		SET ip_address=$2, last_used_at=$3, revoked_at=$4, mfa_required=$5, mfa_verified_at=$6
+		WHERE id=$1;`,
+		session.Id().Value(), session.IpAddress(),
+		session.LastUsedAt(), session.RevokedAt(), session.MfaRequired(), session.MfaVerifiedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, session)
+}
+
+// Rotate compares and swaps the refresh token, so that of two concurrent
+// exchanges of the same token only one succeeds.
+func (r *SessionRepository) Rotate(ctx context.Context, session *entities.Session, previous vo.TokenHash) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE session
+		SET ip_address=$2, refresh_token_hash=$3, last_used_at=$4, mfa_required=$5
+		WHERE id=$1 AND refresh_token_hash=$6 AND revoked_at IS NULL;`,
+		session.Id().Value(), session.IpAddress(), session.RefreshTokenHash().Value(),
+		session.LastUsedAt(), session.MfaRequired(), previous.Value(),
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return entities.ErrInvalidRefreshToken
+	}
+
+	return r.commit(ctx, tx, session)
+}
+
+// commit records the current refresh token among those issued for the
+// session, and the events.
+func (r *SessionRepository) commit(ctx context.Context, tx *sql.Tx, session *entities.Session) error {
+	_, err := tx.Exec(`
+		INSERT INTO session_refresh_token (token_hash, session_id) VALUES ($1, $2)
+		ON CONFLICT (token_hash) DO NOTHING;`,
+		session.RefreshTokenHash().Value(), session.Id().Value(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = writeUserEvents(ctx, tx, "session", session.Id().Value(), session.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const sessionSelect = `
+	SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.auth_method, s.mfa_required,
+		s.mfa_verified_at, s.refresh_token_hash,
+		s.created_at, s.last_used_at, s.expires_at, s.revoked_at
+	FROM session s`
+
+func (r *SessionRepository) FindById(ctx context.Context, id vo.SessionId) (*entities.Session, error) {
+	return r.findOne(ctx, sessionSelect+` WHERE s.id=$1;`, id.Value())
+}
+
+func (r *SessionRepository) FindByRefreshToken(ctx context.Context, tokenHash vo.TokenHash) (*entities.Session, error) {
+	return r.findOne(ctx, sessionSelect+`
+		JOIN session_refresh_token t ON t.session_id = s.id
+		WHERE t.token_hash=$1;`,
+		tokenHash.Value(),
+	)
+}
+
+func (r *SessionRepository) FindActiveByUser(ctx context.Context, userId vo.UserId) ([]entities.Session, error) {
+	return r.find(ctx, sessionSelect+`
+		WHERE s.user_id=$1 AND s.revoked_at IS NULL AND s.expires_at > now()
+		ORDER BY s.last_used_at DESC;`,
+		userId.Value(),
+	)
+}
+
+func (r *SessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM session
+			WHERE id::text=$1 AND revoked_at IS NULL AND expires_at > now()
+		);`,
+		id,
+	).Scan(&active)
+
+	return active, err
+}
+
+func (r *SessionRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.Session, error) {
+	sessions, err := r.find(ctx, query, args...)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+
+	return &sessions[0], nil
+}
+
+func (r *SessionRepository) find(ctx context.Context, query string, args ...interface{}) ([]entities.Session, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]entities.Session, 0)
+	for rows.Next() {
+		var record struct {
+			Id               string
+			UserId           string
+			UserAgent        string
+			IpAddress        string
+			AuthMethod       string
+			MfaRequired      bool
+			MfaVerifiedAt    sql.NullTime
+			RefreshTokenHash string
+			CreatedAt        time.Time
+			LastUsedAt       time.Time
+			ExpiresAt        time.Time
+			RevokedAt        sql.NullTime
+		}
+
+		err = rows.Scan(&record.Id, &record.UserId, &record.UserAgent, &record.IpAddress,
+			&record.AuthMethod, &record.MfaRequired, &record.MfaVerifiedAt, &record.RefreshTokenHash,
+			&record.CreatedAt, &record.LastUsedAt, &record.ExpiresAt, &record.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := vo.NewSessionId(record.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		userId, err := vo.NewUserId(record.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		var revokedAt *time.Time
+		if record.RevokedAt.Valid {
+			revokedAt = &record.RevokedAt.Time
+		}
+
+		var mfaVerifiedAt *time.Time
+		if record.MfaVerifiedAt.Valid {
+			mfaVerifiedAt = &record.MfaVerifiedAt.Time
+		}
+
+		sessions = append(sessions, entities.NewSession(
+			id,
+			userId,
+			record.UserAgent,
+			record.IpAddress,
+			record.AuthMethod,
+			record.MfaRequired,
+			mfaVerifiedAt,
+			vo.TokenHashFromString(record.RefreshTokenHash),
+			record.CreatedAt,
+			record.LastUsedAt,
+			record.ExpiresAt,
+			revokedAt,
+		))
+	}
+
+	return sessions, rows.Err()
+}
@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"iyaem/internal/providers"
+	"time"
+)
+
+type SigningKeyRepository struct {
+	db  *sql.DB
+	box *providers.SecretBox
+}
+
+// NewSigningKeyRepository stores the private keys sealed in the box, so
+// that a copy of the database cannot sign tokens.
+func NewSigningKeyRepository(db *sql.DB, box *providers.SecretBox) providers.KeyRepository {
+	return &SigningKeyRepository{
+		db:  db,
+		box: box,
+	}
+}
+
+func (r *SigningKeyRepository) All(ctx context.Context) ([]providers.SigningKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, algorithm, private_key, status, created_at, rotated_at FROM signing_key;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]providers.SigningKey, 0)
+
+	for rows.Next() {
+		var key providers.SigningKey
+		var privateKey string
+		var rotatedAt sql.NullTime
+
+		err := rows.Scan(&key.Id, &key.Algorithm, &privateKey, &key.Status, &key.CreatedAt, &rotatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err = r.box.Open(privateKey, signingKeyBinding(key.Id))
+		if err != nil {
+			return nil, err
+		}
+
+		key.PrivateKey, err = decodePrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if rotatedAt.Valid {
+			key.RotatedAt = &rotatedAt.Time
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *SigningKeyRepository) Insert(ctx context.Context, key providers.SigningKey) error {
+	privateKey, err := r.sealPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO signing_key (id, algorithm, private_key, status, created_at) VALUES ($1, $2, $3, $4, $5);`,
+		key.Id, key.Algorithm, privateKey, key.Status, key.CreatedAt,
+	)
+
+	return err
+}
+
+// InsertFirst takes an advisory lock for the transaction, so instances
+// starting together on an empty table insert a single active key.
+func (r *SigningKeyRepository) InsertFirst(ctx context.Context, key providers.SigningKey) (bool, error) {
+	privateKey, err := r.sealPrivateKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('signing_key'));`)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO signing_key (id, algorithm, private_key, status, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (SELECT 1 FROM signing_key WHERE status=$6);`,
+		key.Id, key.Algorithm, privateKey, key.Status, key.CreatedAt, providers.KeyActive,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return inserted == 1, tx.Commit()
+}
+
+func (r *SigningKeyRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	var rotatedAt interface{}
+	if status == providers.KeyInactive {
+		rotatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE signing_key SET status=$2, rotated_at=coalesce(rotated_at, $3) WHERE id=$1;`,
+		id, status, rotatedAt,
+	)
+
+	return err
+}
+
+// sealPrivateKey encodes the private key as PKCS#8 PEM and seals it.
+func (r *SigningKeyRepository) sealPrivateKey(key providers.SigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	return r.box.Seal(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), signingKeyBinding(key.Id))
+}
+
+func decodePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid signing key pem")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key is not a crypto.Signer")
+	}
+
+	return signer, nil
+}
+
+func signingKeyBinding(id string) string {
+	return "signing_key.private_key:" + id
+}
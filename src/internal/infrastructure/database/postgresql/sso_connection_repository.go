@@ -0,0 +1,224 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+)
+
+type SsoConnectionRepository struct {
+	db  *sql.DB
+	box *providers.SecretBox
+}
+
+// NewSsoConnectionRepository stores the client secrets of the connections
+// sealed in the box.
+func NewSsoConnectionRepository(db *sql.DB, box *providers.SecretBox) repositories.SsoConnectionRepository {
+	return &SsoConnectionRepository{
+		db:  db,
+		box: box,
+	}
+}
+
+func (r *SsoConnectionRepository) Insert(ctx context.Context, connection *entities.SsoConnection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	secret, err := r.box.Seal(connection.ClientSecret(), ssoClientSecretBinding(connection.Id().Value()))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO sso_connection (id, organization_id, protocol, issuer_url, client_id, client_secret)
+		VALUES ($1, $2, $3, $4, $5, $6);`,
+		connection.Id().Value(), connection.OrganizationId().Value(), connection.Protocol(),
+		connection.IssuerUrl(), connection.ClientId(), secret,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = insertDomains(tx, connection)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, connection)
+}
+
+func (r *SsoConnectionRepository) Update(ctx context.Context, connection *entities.SsoConnection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	secret, err := r.box.Seal(connection.ClientSecret(), ssoClientSecretBinding(connection.Id().Value()))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sso_connection SET issuer_url=$2, client_id=$3, client_secret=$4, updated_at=now() WHERE id=$1;`,
+		connection.Id().Value(), connection.IssuerUrl(), connection.ClientId(), secret,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM sso_connection_domain WHERE connection_id=$1;`, connection.Id().Value())
+	if err != nil {
+		return err
+	}
+
+	err = insertDomains(tx, connection)
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, connection)
+}
+
+func (r *SsoConnectionRepository) Delete(ctx context.Context, connection *entities.SsoConnection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM sso_connection WHERE id=$1;`, connection.Id().Value())
+	if err != nil {
+		return err
+	}
+
+	return r.commit(ctx, tx, connection)
+}
+
+func insertDomains(tx *sql.Tx, connection *entities.SsoConnection) error {
+	for _, domain := range connection.DomainVerifications() {
+		_, err := tx.Exec(`
+			INSERT INTO sso_connection_domain (domain, connection_id, verification_token, verified_at)
+			VALUES ($1, $2, $3, $4);`,
+			domain.Name, connection.Id().Value(), domain.VerificationToken, domain.VerifiedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SsoConnectionRepository) commit(ctx context.Context, tx *sql.Tx, connection *entities.SsoConnection) error {
+	err := insertOutboxEvents(tx, "sso_connection", connection.Id().Value(), connection.Events())
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEntries(ctx, tx, audit.Scope{OrganizationId: connection.OrganizationId().Value()}, "sso_connection", connection.Id().Value(), connection.Events())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const ssoConnectionSelect = `
+	SELECT c.id, c.organization_id, c.protocol, c.issuer_url, c.client_id, c.client_secret
+	FROM sso_connection c`
+
+func (r *SsoConnectionRepository) FindById(ctx context.Context, id vo.SsoConnectionId) (*entities.SsoConnection, error) {
+	return r.findOne(ctx, ssoConnectionSelect+` WHERE c.id=$1;`, id.Value())
+}
+
+func (r *SsoConnectionRepository) FindByOrganization(ctx context.Context, organizationId string) (*entities.SsoConnection, error) {
+	return r.findOne(ctx, ssoConnectionSelect+` WHERE c.organization_id=$1;`, organizationId)
+}
+
+// FindByDomain finds the connection that verified the domain. Unverified
+// claims never route logins.
+func (r *SsoConnectionRepository) FindByDomain(ctx context.Context, domain string) (*entities.SsoConnection, error) {
+	return r.findOne(ctx, ssoConnectionSelect+`
+		WHERE c.id = (SELECT connection_id FROM sso_connection_domain WHERE domain=$1 AND verified_at IS NOT NULL);`, domain)
+}
+
+func (r *SsoConnectionRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entities.SsoConnection, error) {
+	var record struct {
+		Id             string
+		OrganizationId string
+		Protocol       string
+		IssuerUrl      string
+		ClientId       string
+		ClientSecret   string
+	}
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&record.Id, &record.OrganizationId, &record.Protocol,
+		&record.IssuerUrl, &record.ClientId, &record.ClientSecret)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := vo.NewSsoConnectionId(record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	orgId, err := vo.NewOrganizationId(record.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := r.findDomains(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := r.box.Open(record.ClientSecret, ssoClientSecretBinding(record.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	connection := entities.NewSsoConnection(id, orgId, record.Protocol, record.IssuerUrl, record.ClientId, secret, domains)
+	return &connection, nil
+}
+
+func (r *SsoConnectionRepository) findDomains(ctx context.Context, id vo.SsoConnectionId) ([]entities.SsoDomain, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT domain, verification_token, verified_at FROM sso_connection_domain
+		WHERE connection_id=$1 ORDER BY domain;`, id.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := make([]entities.SsoDomain, 0)
+	for rows.Next() {
+		var domain entities.SsoDomain
+		var verifiedAt sql.NullTime
+		if err := rows.Scan(&domain.Name, &domain.VerificationToken, &verifiedAt); err != nil {
+			return nil, err
+		}
+		if verifiedAt.Valid {
+			domain.VerifiedAt = &verifiedAt.Time
+		}
+
+		domains = append(domains, domain)
+	}
+
+	return domains, rows.Err()
+}
+
+func ssoClientSecretBinding(id string) string {
+	return "sso_connection.client_secret:" + id
+}
@@ -0,0 +1,30 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// withTx makes the repositories that support it write through the
+// transaction, so that their changes commit with it.
+func withTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// connFrom returns the transaction of the context, or the database when
+// there is none.
+func connFrom(ctx context.Context, db *sql.DB) conn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
@@ -55,7 +55,18 @@ func (r *UserRepository) Insert(ctx context.Context, user *entities.User) error
 }
 
 func (r *UserRepository) FindById(ctx context.Context, userId vo.UserId) (*entities.User, error) {
-	return nil, nil
+	var email string
+
+	row := r.db.QueryRowContext(ctx, `SELECT email FROM public.user WHERE id=$1`, userId.Value())
+	err := row.Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByEmail(ctx, email)
 }
 
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
@@ -74,6 +85,9 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entiti
 		WHERE email=$1`, email,
 	)
 	err := row.Scan(&userRecord.Id, &userRecord.Name, &userRecord.Email, &userRecord.Picture)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		log.Printf("Error: %v", err)
 		return nil, err
@@ -121,6 +135,37 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entiti
 	return &user, nil
 }
 
+// FindByIdentity returns the user who signs in with the identity, or nil
+// when no user does.
+func (r *UserRepository) FindByIdentity(ctx context.Context, idpId string) (*entities.User, error) {
+	var email string
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT u.email
+		FROM public.user u
+		JOIN user_identity ui ON u.id = ui.user_id
+		WHERE ui.idp_id=$1`, idpId,
+	)
+	err := row.Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByEmail(ctx, email)
+}
+
+func (r *UserRepository) AddIdentity(ctx context.Context, identity vo.Identity) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_identity (idp_id, user_id) VALUES ($1, $2);`,
+		identity.IdpId(), identity.UserId().Value(),
+	)
+
+	return err
+}
+
 func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
 	return nil
 }
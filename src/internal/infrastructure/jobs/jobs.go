@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/app/audit"
+	"time"
+)
+
+// Statuses of a job. A running job whose lease expired, because its
+// worker stopped, is picked up again like a pending one.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Job is a unit of background work, persisted so that it survives
+// restarts and is retried when it fails.
+type Job struct {
+	Id             string
+	Type           string
+	Payload        []byte
+	IdempotencyKey string
+	OrganizationId string
+	// Actor enqueued the job, and is recorded as the actor of the changes
+	// it makes.
+	Actor  audit.Actor
+	Status string
+	// Attempts counts the runs of the job, the current one included.
+	Attempts      int
+	MaxAttempts   int
+	ProgressDone  int
+	ProgressTotal int
+	LastError     string
+	RunAt         time.Time
+	CreatedAt     time.Time
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+}
+
+// Store is the persistence side of the job queue.
+type Store interface {
+	// Enqueue adds the job, unless a job with the same idempotency key
+	// exists, and returns the id of the job that will do the work.
+	Enqueue(ctx context.Context, job Job) (string, error)
+	// FetchPending leases up to limit jobs of the given types that are due,
+	// counting an attempt for each, so that no other runner picks them up
+	// until the lease expires.
+	FetchPending(ctx context.Context, types []string, limit int, lease time.Duration) ([]Job, error)
+	ExtendLease(ctx context.Context, id string, lease time.Duration) error
+	SetProgress(ctx context.Context, id string, done int, total int) error
+	MarkSucceeded(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error
+	// Postpone puts a job that could not start back in the queue, to run
+	// at runAt, without counting the attempt.
+	Postpone(ctx context.Context, id string, runAt time.Time) error
+	MarkDead(ctx context.Context, id string, cause error) error
+	FindById(ctx context.Context, id string) (*Job, error)
+	// TryLock takes the lock of the key unless another runner holds it,
+	// returning the function that releases it, or false without waiting.
+	TryLock(ctx context.Context, key string) (func(), bool, error)
+}
+
+// Type names a kind of job and the type of its payload, which ties the
+// code that enqueues jobs to the handler that runs them.
+type Type[T any] struct {
+	Name string
+}
+
+// permanentError is a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of a handler as final, so that the job is
+// dead-lettered without further attempts.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iyaem/internal/app/audit"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxAttempts is how often a job runs before it is dead-lettered,
+// unless it is enqueued with another limit.
+const DefaultMaxAttempts = 8
+
+// Options describe how a job is enqueued. Zero values fall back to the
+// defaults: a random id, no idempotency key, DefaultMaxAttempts and running
+// right away.
+type Options struct {
+	// Id is the id of the job, a random one when empty.
+	Id string
+	// IdempotencyKey makes enqueueing the same work twice return the job
+	// enqueued first.
+	IdempotencyKey string
+	OrganizationId string
+	MaxAttempts    int
+	RunAt          time.Time
+}
+
+// Queue enqueues jobs for a Runner.
+type Queue struct {
+	store Store
+}
+
+func NewQueue(store Store) *Queue {
+	return &Queue{store}
+}
+
+// Enqueue adds a job of the type and returns its id. The actor of the
+// context is recorded as the one of the job.
+func Enqueue[T any](ctx context.Context, q *Queue, t Type[T], payload T, options Options) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal %s payload: %w", t.Name, err)
+	}
+
+	actor, _ := audit.FromContext(ctx)
+
+	job := Job{
+		Id:             uuid.NewString(),
+		Type:           t.Name,
+		Payload:        data,
+		IdempotencyKey: options.IdempotencyKey,
+		OrganizationId: options.OrganizationId,
+		Actor:          actor,
+		Status:         StatusPending,
+		MaxAttempts:    options.MaxAttempts,
+		RunAt:          options.RunAt,
+		CreatedAt:      time.Now(),
+	}
+	if options.Id != "" {
+		job.Id = options.Id
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+
+	return q.store.Enqueue(ctx, job)
+}
+
+// Find returns the job, or nil when there is none with the id.
+func (q *Queue) Find(ctx context.Context, id string) (*Job, error) {
+	return q.store.FindById(ctx, id)
+}
@@ -0,0 +1,276 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iyaem/internal/app/audit"
+	"log"
+	"sync"
+	"time"
+)
+
+type RunnerConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+func DefaultRunnerConfig() RunnerConfig {
+	return RunnerConfig{
+		Workers:      4,
+		PollInterval: time.Second,
+		Lease:        time.Minute,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   30 * time.Minute,
+	}
+}
+
+// Run is a job being handled.
+type Run struct {
+	Job
+	store Store
+}
+
+// Progress records how much of the job is done, for the UI to show.
+func (r *Run) Progress(ctx context.Context, done int, total int) error {
+	return r.store.SetProgress(ctx, r.Id, done, total)
+}
+
+// LastAttempt reports whether the job is dead-lettered if this run fails.
+func (r *Run) LastAttempt() bool {
+	return r.Attempts >= r.MaxAttempts
+}
+
+type handler func(ctx context.Context, run *Run) error
+
+// Runner runs the jobs of the types it has handlers for. A job is run at
+// least once: one whose worker stops before it finished runs again once
+// its lease expires, so handlers must be safe to repeat.
+type Runner struct {
+	store    Store
+	config   RunnerConfig
+	handlers map[string]handler
+	serial   map[string]bool
+}
+
+func NewRunner(store Store, config RunnerConfig) *Runner {
+	return &Runner{
+		store:    store,
+		config:   config,
+		handlers: make(map[string]handler),
+		serial:   make(map[string]bool),
+	}
+}
+
+// Handle registers the handler of a job type.
+func Handle[T any](r *Runner, t Type[T], handle func(ctx context.Context, run *Run, payload T) error) {
+	r.handlers[t.Name] = func(ctx context.Context, run *Run) error {
+		var payload T
+		if err := json.Unmarshal(run.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("unmarshal %s payload: %w", t.Name, err))
+		}
+
+		return handle(ctx, run, payload)
+	}
+}
+
+// HandleSerially registers the handler of a job type whose jobs must not
+// run concurrently, on this runner or any other, such as jobs that read
+// and write back the same remote resource. A job fetched while another
+// runs is postponed rather than waited for, so that it does not hold a
+// worker.
+func HandleSerially[T any](r *Runner, t Type[T], handle func(ctx context.Context, run *Run, payload T) error) {
+	Handle(r, t, handle)
+	r.serial[t.Name] = true
+}
+
+// Run polls for jobs until the context is cancelled, then waits for the
+// jobs it started to finish.
+func (r *Runner) Run(ctx context.Context) {
+	types := r.types()
+
+	slots := make(chan struct{}, r.config.Workers)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := r.config.Workers - len(slots)
+		if free > 0 {
+			jobs, err := r.store.FetchPending(ctx, types, free, r.config.Lease)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error: job runner: %v", err)
+			}
+
+			for _, job := range jobs {
+				slots <- struct{}{}
+				running.Add(1)
+
+				go func(job Job) {
+					defer func() {
+						<-slots
+						running.Done()
+					}()
+
+					// Jobs keep running through shutdown, so that they are
+					// not abandoned half way.
+					r.run(context.WithoutCancel(ctx), job)
+				}(job)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush runs the jobs that are due, up to one per worker, and returns how
+// many it ran.
+func (r *Runner) Flush(ctx context.Context) (int, error) {
+	jobs, err := r.store.FetchPending(ctx, r.types(), r.config.Workers, r.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var running sync.WaitGroup
+	for _, job := range jobs {
+		running.Add(1)
+		go func(job Job) {
+			defer running.Done()
+			r.run(ctx, job)
+		}(job)
+	}
+	running.Wait()
+
+	return len(jobs), nil
+}
+
+func (r *Runner) types() []string {
+	types := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		types = append(types, name)
+	}
+
+	return types
+}
+
+func (r *Runner) run(ctx context.Context, job Job) {
+	handle, ok := r.handlers[job.Type]
+	if !ok {
+		r.fail(ctx, job, Permanent(fmt.Errorf("no handler for %s jobs", job.Type)))
+		return
+	}
+
+	if job.Attempts > job.MaxAttempts {
+		r.fail(ctx, job, Permanent(fmt.Errorf("gave up after %d attempts", job.MaxAttempts)))
+		return
+	}
+
+	stop := r.keepLease(ctx, job.Id)
+	defer stop()
+
+	if r.serial[job.Type] {
+		unlock, ok, err := r.store.TryLock(ctx, "job:"+job.Type)
+		if err != nil {
+			r.fail(ctx, job, err)
+			return
+		}
+		if !ok {
+			r.postpone(ctx, job)
+			return
+		}
+		defer unlock()
+	}
+
+	// Changes made by the job are recorded as made by whoever enqueued it.
+	ctx = audit.WithActor(ctx, job.Actor, audit.Metadata{RequestId: "job:" + job.Id})
+
+	err := r.call(ctx, handle, &Run{job, r.store})
+	if err != nil {
+		r.fail(ctx, job, err)
+		return
+	}
+
+	err = r.store.MarkSucceeded(ctx, job.Id)
+	if err != nil {
+		log.Printf("Error: job %s: %v", job.Id, err)
+	}
+}
+
+// call runs the handler, turning a panic into a failure of the job.
+func (r *Runner) call(ctx context.Context, handle handler, run *Run) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return handle(ctx, run)
+}
+
+// keepLease extends the lease of a job while it runs, so that long jobs
+// are not picked up by another runner.
+func (r *Runner) keepLease(ctx context.Context, id string) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(r.config.Lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := r.store.ExtendLease(ctx, id, r.config.Lease); err != nil {
+					log.Printf("Error: job %s: %v", id, err)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (r *Runner) fail(ctx context.Context, job Job, cause error) {
+	var err error
+	if isPermanent(cause) || job.Attempts >= job.MaxAttempts {
+		log.Printf("Error: job %s (%s) dead after %d attempts: %v", job.Id, job.Type, job.Attempts, cause)
+		err = r.store.MarkDead(ctx, job.Id, cause)
+	} else {
+		err = r.store.MarkFailed(ctx, job.Id, cause, time.Now().Add(r.backoff(job.Attempts)))
+	}
+
+	if err != nil {
+		log.Printf("Error: job %s: %v", job.Id, err)
+	}
+}
+
+// postpone runs the job again once the one holding its lock is likely
+// done.
+func (r *Runner) postpone(ctx context.Context, job Job) {
+	err := r.store.Postpone(ctx, job.Id, time.Now().Add(r.config.BaseBackoff))
+	if err != nil {
+		log.Printf("Error: job %s: %v", job.Id, err)
+	}
+}
+
+func (r *Runner) backoff(attempts int) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.config.MaxBackoff {
+			return r.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,26 @@
+package jobs
+
+// MemberImport runs a member import that was requested.
+var MemberImport = Type[MemberImportPayload]{"member_import"}
+
+type MemberImportPayload struct {
+	ImportId string `json:"import_id"`
+}
+
+// AddTenant provisions a tenant persisted by the tenant service.
+var AddTenant = Type[AddTenantPayload]{"add_tenant"}
+
+type AddTenantPayload struct {
+	OrganizationId string `json:"organization_id"`
+	TenantId       string `json:"tenant_id"`
+	ApplicationId  string `json:"application_id"`
+}
+
+// AddCallbackUrl registers the callback of a domain with the identity
+// provider.
+var AddCallbackUrl = Type[AddCallbackUrlPayload]{"add_callback_url"}
+
+type AddCallbackUrlPayload struct {
+	OrganizationId string `json:"organization_id"`
+	DomainUrl      string `json:"domain_url"`
+}
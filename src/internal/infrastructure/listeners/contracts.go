@@ -0,0 +1,63 @@
+package listeners
+
+import (
+	"errors"
+	vo "iyaem/internal/domain/valueobjects"
+	"net/url"
+	"strings"
+)
+
+// DomainRegistered is sent when an organization registers the domain its
+// application runs on.
+var DomainRegistered = Contract[DomainRegisteredData]{
+	Type:     "domain_registered",
+	Versions: []int{1},
+}
+
+type DomainRegisteredData struct {
+	OrganizationId string `json:"organization_id"`
+	Url            string `json:"url"`
+}
+
+// Validate accepts only the https origin of the domain, optionally with a
+// path: the callback URL registered with the identity provider is derived
+// from it, so credentials, a query or a fragment have no place in it.
+func (d DomainRegisteredData) Validate() error {
+	if _, err := vo.NewOrganizationId(d.OrganizationId); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(d.Url)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil ||
+		u.RawQuery != "" || u.ForceQuery || u.Fragment != "" || strings.Contains(d.Url, "#") {
+		return errors.New("invalid url")
+	}
+
+	return nil
+}
+
+// TenantPersisted is sent when a tenant of an application was created.
+var TenantPersisted = Contract[TenantPersistedData]{
+	Type:     "tenant_persisted",
+	Versions: []int{1},
+}
+
+type TenantPersistedData struct {
+	OrganizationId string `json:"organization_id"`
+	TenantId       string `json:"tenant_id"`
+	ApplicationId  string `json:"application_id"`
+}
+
+func (d TenantPersistedData) Validate() error {
+	if _, err := vo.NewOrganizationId(d.OrganizationId); err != nil {
+		return err
+	}
+	if _, err := vo.NewTenantId(d.TenantId); err != nil {
+		return err
+	}
+	if _, err := vo.NewApplicationId(d.ApplicationId); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package listeners
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iyaem/internal/providers"
+	"time"
+)
+
+// Payload is the data of an inbound event, which checks itself before it
+// is handled.
+type Payload interface {
+	Validate() error
+}
+
+// Contract describes an inbound event: its type and the versions of its
+// schema the listeners understand.
+type Contract[T Payload] struct {
+	Type     string
+	Versions []int
+}
+
+// Event is a decoded and validated inbound event.
+type Event[T Payload] struct {
+	Id         string
+	Type       string
+	Version    int
+	OccurredAt time.Time
+	Data       T
+}
+
+// envelope is how inbound events are sent:
+//
+//	{"event_id": "...", "event_type": "tenant_persisted", "version": 1,
+//	 "occurred_at": "2024-01-01T00:00:00Z", "data": {...}}
+//
+// Messages without an envelope, from publishers that predate it, are read
+// as the data of the first version, with the id of the message.
+type envelope struct {
+	EventId    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Handle decodes the messages of a subscription into events of the
+// contract for the handler. Messages that are malformed, of another type,
+// of an unknown version or invalid are rejected, which dead-letters them
+// for inspection.
+func Handle[T Payload](contract Contract[T], handle func(ctx context.Context, event Event[T]) error) providers.Handler {
+	return func(ctx context.Context, msg providers.Message) error {
+		event, err := decode(contract, msg)
+		if err != nil {
+			return providers.Reject(fmt.Errorf("%s message %s: %w", contract.Type, msg.Id, err))
+		}
+
+		return handle(ctx, event)
+	}
+}
+
+func decode[T Payload](contract Contract[T], msg providers.Message) (Event[T], error) {
+	var e envelope
+	if err := json.Unmarshal(msg.Data, &e); err != nil {
+		return Event[T]{}, fmt.Errorf("malformed: %s", err)
+	}
+
+	if e.Version == 0 && e.Data == nil {
+		e = envelope{
+			EventId:    msg.Id,
+			EventType:  contract.Type,
+			Version:    1,
+			OccurredAt: time.Now(),
+			Data:       msg.Data,
+		}
+	}
+
+	switch {
+	case e.EventId == "":
+		return Event[T]{}, fmt.Errorf("missing event_id")
+	case e.EventType != contract.Type:
+		return Event[T]{}, fmt.Errorf("unexpected event type %q", e.EventType)
+	case !supports(contract.Versions, e.Version):
+		return Event[T]{}, fmt.Errorf("unsupported version %d", e.Version)
+	case e.OccurredAt.IsZero():
+		return Event[T]{}, fmt.Errorf("missing occurred_at")
+	case e.Data == nil || bytes.Equal(e.Data, []byte("null")):
+		return Event[T]{}, fmt.Errorf("missing data")
+	}
+
+	var data T
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return Event[T]{}, fmt.Errorf("malformed data: %s", err)
+	}
+
+	if err := data.Validate(); err != nil {
+		return Event[T]{}, fmt.Errorf("invalid data: %s", err)
+	}
+
+	return Event[T]{
+		Id:         e.EventId,
+		Type:       e.EventType,
+		Version:    e.Version,
+		OccurredAt: e.OccurredAt,
+		Data:       data,
+	}, nil
+}
+
+func supports(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
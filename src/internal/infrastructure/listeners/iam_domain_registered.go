@@ -2,104 +2,45 @@ package listeners
 
 import (
 	"context"
-	"encoding/json"
-	"io"
-	"iyaem/internal/domain/repositories"
+	"fmt"
+	"iyaem/internal/infrastructure/jobs"
 	"iyaem/internal/providers"
-	"log"
-	"net/http"
-	"os"
-	"strings"
 )
 
 type IamDomainRegisteredHandlers struct {
-	organizationRepo repositories.OrganizationRepository
+	queue *jobs.Queue
+	inbox Inbox
 }
 
 func NewIamDomainRegisteredHandlers(
-	organizationRepo repositories.OrganizationRepository,
+	queue *jobs.Queue,
+	inbox Inbox,
 ) *IamDomainRegisteredHandlers {
 	return &IamDomainRegisteredHandlers{
-		organizationRepo: organizationRepo,
+		queue: queue,
+		inbox: inbox,
 	}
 }
 
-func (l *IamDomainRegisteredHandlers) GetHandlers() []providers.Callback {
-	return []providers.Callback{
-		l.AddCallbackUrl,
-	}
+func (l *IamDomainRegisteredHandlers) Handler() providers.Handler {
+	return Handle(DomainRegistered, Once(l.inbox, "domain_registered.add_callback_url", l.AddCallbackUrl))
 }
 
-func (l *IamDomainRegisteredHandlers) AddCallbackUrl(ctx context.Context, payload map[string]interface{}) {
-
-	if payload["organization_id"] == nil {
-		log.Printf("Error: missing required fields")
-		return
-	}
-
-	apiToken := providers.GetTokenSingleton().Token
-
-	url := "https://saasiam.us.auth0.com/api/v2/clients/" + os.Getenv("AUTH0_CLIENT_ID")
-
-	req, _ := http.NewRequest("GET", url, nil)
-
-	req.Header.Add("content-type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+apiToken)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("Error: %v", err)
-		return
-	}
-
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	clientJson := make(map[string]interface{})
-	err = json.Unmarshal(bodyBytes, &clientJson)
-	if err != nil {
-		log.Printf("Error: %v", err)
-	}
-
-	IlogoutUrls := clientJson["allowed_logout_urls"].([]interface{})
-	Icallbacks := clientJson["callbacks"].([]interface{})
-
-	newLogoutUrl := payload["url"].(string)
-	newCallbackUrl := payload["url"].(string) + "/callback"
-
-	logoutUrls := make([]string, 0)
-	callbacks := make([]string, 0)
-
-	for _, url := range IlogoutUrls {
-		logoutUrls = append(logoutUrls, url.(string))
-	}
-
-	for _, url := range Icallbacks {
-		callbacks = append(callbacks, url.(string))
-	}
-
-	logoutUrls = append(logoutUrls, newLogoutUrl)
-	callbacks = append(callbacks, newCallbackUrl)
-
-	reqPayload := strings.NewReader(`{
-		"allowed_logout_urls": ["` + strings.Join(logoutUrls, "\",\"") + `"],
-		"callbacks": ["` + strings.Join(callbacks, "\",\"") + `"]
-	}`)
-
-	log.Printf("Payload: %v", reqPayload)
-
-	req, _ = http.NewRequest("PATCH", url, reqPayload)
-
-	req.Header.Add("content-type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+apiToken)
-
-	_, err = http.DefaultClient.Do(req)
+// AddCallbackUrl queues the registration of the callback of the domain
+// with the identity provider, which is retried until it succeeds. The job
+// is keyed by the event, so a domain registered again after its job died
+// is queued again.
+func (l *IamDomainRegisteredHandlers) AddCallbackUrl(ctx context.Context, event Event[DomainRegisteredData]) error {
+	_, err := jobs.Enqueue(ctx, l.queue, jobs.AddCallbackUrl, jobs.AddCallbackUrlPayload{
+		OrganizationId: event.Data.OrganizationId,
+		DomainUrl:      event.Data.Url,
+	}, jobs.Options{
+		IdempotencyKey: "add_callback_url:" + event.Id,
+		OrganizationId: event.Data.OrganizationId,
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
-		return
+		return fmt.Errorf("could not queue job: %s", err)
 	}
 
-	log.Printf("Added callback url %s", newCallbackUrl)
+	return nil
 }
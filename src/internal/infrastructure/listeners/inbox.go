@@ -0,0 +1,24 @@
+package listeners
+
+import (
+	"context"
+)
+
+// Inbox records the messages each consumer processed, so that a message
+// delivered again is not processed twice.
+type Inbox interface {
+	// Process runs handle unless the consumer already processed the
+	// message, and marks it as processed when handle succeeds. The mark
+	// commits with the changes handle makes through its context.
+	Process(ctx context.Context, consumer string, messageId string, handle func(ctx context.Context) error) error
+}
+
+// Once makes the handler process each event once for the consumer, by the
+// id of the event.
+func Once[T Payload](inbox Inbox, consumer string, handle func(ctx context.Context, event Event[T]) error) func(ctx context.Context, event Event[T]) error {
+	return func(ctx context.Context, event Event[T]) error {
+		return inbox.Process(ctx, consumer, event.Id, func(ctx context.Context) error {
+			return handle(ctx, event)
+		})
+	}
+}
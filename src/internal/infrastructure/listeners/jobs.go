@@ -0,0 +1,97 @@
+package listeners
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/infrastructure/jobs"
+	"iyaem/internal/providers"
+	"log"
+)
+
+// JobHandlers run the background jobs of the service.
+type JobHandlers struct {
+	runMemberImportCommand *commands.RunMemberImportCommand
+	addTenantCommand       *commands.AddTenantCommand
+	idp                    providers.IdentityProvider
+}
+
+func NewJobHandlers(
+	runMemberImportCommand *commands.RunMemberImportCommand,
+	addTenantCommand *commands.AddTenantCommand,
+	idp providers.IdentityProvider,
+) *JobHandlers {
+	return &JobHandlers{
+		runMemberImportCommand: runMemberImportCommand,
+		addTenantCommand:       addTenantCommand,
+		idp:                    idp,
+	}
+}
+
+// Register adds the handlers to the runner.
+func (h *JobHandlers) Register(r *jobs.Runner) {
+	jobs.Handle(r, jobs.MemberImport, h.RunMemberImport)
+	jobs.Handle(r, jobs.AddTenant, h.AddTenant)
+	// Adding a callback reads the allowed URLs and writes them back, so
+	// concurrent jobs would overwrite each other's URL.
+	jobs.HandleSerially(r, jobs.AddCallbackUrl, h.AddCallbackUrl)
+}
+
+func (h *JobHandlers) RunMemberImport(ctx context.Context, run *jobs.Run, payload jobs.MemberImportPayload) error {
+	err := h.runMemberImportCommand.Execute(ctx, payload.ImportId, func(done int, total int) {
+		if err := run.Progress(ctx, done, total); err != nil {
+			log.Printf("Error: member import %s: %v", payload.ImportId, err)
+		}
+	})
+	if err == nil {
+		return nil
+	}
+
+	err = permanent(err)
+	if run.LastAttempt() {
+		if err := h.runMemberImportCommand.Abandon(ctx, payload.ImportId, err.Error()); err != nil {
+			log.Printf("Error: member import %s: %v", payload.ImportId, err)
+		}
+	}
+
+	return err
+}
+
+func (h *JobHandlers) AddTenant(ctx context.Context, run *jobs.Run, payload jobs.AddTenantPayload) error {
+	_, err := h.addTenantCommand.Execute(ctx, commands.AddTenantRequest{
+		OrganizationId: payload.OrganizationId,
+		TenantId:       payload.TenantId,
+		ApplicationId:  payload.ApplicationId,
+	})
+
+	return permanent(err)
+}
+
+func (h *JobHandlers) AddCallbackUrl(ctx context.Context, run *jobs.Run, payload jobs.AddCallbackUrlPayload) error {
+	callbackUrl := payload.DomainUrl + "/callback"
+
+	err := h.idp.AddCallbackURL(ctx, callbackUrl, payload.DomainUrl)
+	if errors.Is(err, providers.ErrNotSupported) {
+		return jobs.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Added callback url %s", callbackUrl)
+	return nil
+}
+
+// permanent marks the errors of commands that retrying cannot fix.
+func permanent(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, entities.ErrInvalid), errors.Is(err, entities.ErrNotFound),
+		errors.Is(err, entities.ErrConflict), errors.Is(err, entities.ErrForbidden):
+		return jobs.Permanent(err)
+	}
+
+	return err
+}
@@ -2,46 +2,44 @@ package listeners
 
 import (
 	"context"
-	"iyaem/internal/app/commands"
+	"fmt"
+	"iyaem/internal/infrastructure/jobs"
 	"iyaem/internal/providers"
-	"log"
 )
 
 type TenantPersistedHandlers struct {
-	addTenantCommand *commands.AddTenantCommand
+	queue *jobs.Queue
+	inbox Inbox
 }
 
 func NewTenantPersistedHandlers(
-	addTenantCommand *commands.AddTenantCommand,
+	queue *jobs.Queue,
+	inbox Inbox,
 ) *TenantPersistedHandlers {
 	return &TenantPersistedHandlers{
-		addTenantCommand: addTenantCommand,
+		queue: queue,
+		inbox: inbox,
 	}
 }
 
-func (l *TenantPersistedHandlers) GetHandlers() []providers.Callback {
-	return []providers.Callback{
-		l.AddCallbackUrl,
-	}
+func (l *TenantPersistedHandlers) Handler() providers.Handler {
+	return Handle(TenantPersisted, Once(l.inbox, "tenant_persisted.add_tenant", l.AddCallbackUrl))
 }
 
-func (l *TenantPersistedHandlers) AddCallbackUrl(ctx context.Context, payload map[string]interface{}) {
-
-	if payload["organization_id"] == nil || payload["tenant_id"] == nil || payload["application_id"] == nil {
-		log.Printf("Error: missing required fields")
-		return
-	}
-
-	req := commands.AddTenantRequest{
-		OrganizationId: payload["organization_id"].(string),
-		TenantId:       payload["tenant_id"].(string),
-		ApplicationId:  payload["application_id"].(string),
-	}
-
-	_, err := l.addTenantCommand.Execute(ctx, req)
+// AddCallbackUrl queues the provisioning of the tenant, which is retried
+// until it succeeds. A tenant persisted twice is provisioned once.
+func (l *TenantPersistedHandlers) AddCallbackUrl(ctx context.Context, event Event[TenantPersistedData]) error {
+	_, err := jobs.Enqueue(ctx, l.queue, jobs.AddTenant, jobs.AddTenantPayload{
+		OrganizationId: event.Data.OrganizationId,
+		TenantId:       event.Data.TenantId,
+		ApplicationId:  event.Data.ApplicationId,
+	}, jobs.Options{
+		IdempotencyKey: "add_tenant:" + event.Data.TenantId,
+		OrganizationId: event.Data.OrganizationId,
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
-		return
+		return fmt.Errorf("could not queue job: %s", err)
 	}
 
+	return nil
 }
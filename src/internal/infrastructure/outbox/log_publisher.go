@@ -0,0 +1,19 @@
+package outbox
+
+import (
+	"context"
+	"log"
+)
+
+// LogPublisher writes messages to the application log. It is used when no
+// broker is configured, e.g. when running locally.
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error {
+	log.Printf("Publish %s %v: %s", topic, attributes, string(data))
+	return nil
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Message is a domain event that has been persisted to the outbox table
+// and is waiting to be delivered to other services.
+type Message struct {
+	Id            string
+	AggregateType string
+	AggregateId   string
+	EventType     string
+	Payload       []byte
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+// Store is the persistence side of the outbox. Messages are written to it
+// by the repositories, in the same transaction as the aggregate changes.
+type Store interface {
+	// FetchPending leases up to limit deliverable messages so that no other
+	// relay picks them up until the lease expires.
+	FetchPending(ctx context.Context, limit int, lease time.Duration) ([]Message, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error
+	MarkDead(ctx context.Context, id string, cause error) error
+}
+
+// Publisher delivers outbox messages to a message broker.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error
+}
@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type RelayConfig struct {
+	TopicPrefix  string
+	BatchSize    int
+	PollInterval time.Duration
+	Lease        time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		TopicPrefix:  "iam_",
+		BatchSize:    50,
+		PollInterval: 2 * time.Second,
+		Lease:        30 * time.Second,
+		MaxAttempts:  10,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+	}
+}
+
+// Relay polls the outbox and publishes pending messages. Delivery is
+// at-least-once: a message is only marked as sent after the publisher
+// acknowledged it, so consumers must tolerate duplicates.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	config    RelayConfig
+}
+
+func NewRelay(store Store, publisher Publisher, config RelayConfig) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := r.Flush(ctx)
+		if err != nil {
+			log.Printf("Error: outbox relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes a single batch of pending messages and returns how many
+// of them were delivered.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	messages, err := r.store.FetchPending(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("fetch pending: %w", err)
+	}
+
+	sent := 0
+	for _, m := range messages {
+		attributes := map[string]string{
+			"message_id":     m.Id,
+			"event_type":     m.EventType,
+			"aggregate_type": m.AggregateType,
+			"aggregate_id":   m.AggregateId,
+		}
+
+		err = r.publisher.Publish(ctx, r.config.TopicPrefix+m.EventType, m.Payload, attributes)
+		if err != nil {
+			r.fail(ctx, m, err)
+			continue
+		}
+
+		err = r.store.MarkSent(ctx, m.Id)
+		if err != nil {
+			return sent, fmt.Errorf("mark sent %s: %w", m.Id, err)
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (r *Relay) fail(ctx context.Context, m Message, cause error) {
+	attempts := m.Attempts + 1
+
+	var err error
+	if attempts >= r.config.MaxAttempts {
+		log.Printf("Error: outbox message %s gave up after %d attempts: %v", m.Id, attempts, cause)
+		err = r.store.MarkDead(ctx, m.Id, cause)
+	} else {
+		err = r.store.MarkFailed(ctx, m.Id, cause, time.Now().Add(r.backoff(attempts)))
+	}
+
+	if err != nil {
+		log.Printf("Error: outbox message %s: %v", m.Id, err)
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.config.MaxBackoff {
+			return r.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type ApplicationController struct {
+	createApplicationCommand *commands.CreateApplicationCommand
+	renameApplicationCommand *commands.RenameApplicationCommand
+	deleteApplicationCommand *commands.DeleteApplicationCommand
+	addPermissionCommand     *commands.AddPermissionCommand
+	renamePermissionCommand  *commands.RenamePermissionCommand
+	removePermissionCommand  *commands.RemovePermissionCommand
+}
+
+func NewApplicationController(
+	createApplicationCommand *commands.CreateApplicationCommand,
+	renameApplicationCommand *commands.RenameApplicationCommand,
+	deleteApplicationCommand *commands.DeleteApplicationCommand,
+	addPermissionCommand *commands.AddPermissionCommand,
+	renamePermissionCommand *commands.RenamePermissionCommand,
+	removePermissionCommand *commands.RemovePermissionCommand,
+) *ApplicationController {
+	return &ApplicationController{
+		createApplicationCommand,
+		renameApplicationCommand,
+		deleteApplicationCommand,
+		addPermissionCommand,
+		renamePermissionCommand,
+		removePermissionCommand,
+	}
+}
+
+func (c *ApplicationController) Create(ctx *gin.Context) {
+	var params struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1701: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	applicationId, err := c.createApplicationCommand.Execute(ctx, commands.CreateApplicationRequest{
+		Name: params.Name,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create application")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    applicationId,
+	})
+}
+
+func (c *ApplicationController) Rename(ctx *gin.Context) {
+	var params struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1702: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	applicationId, err := c.renameApplicationCommand.Execute(ctx, commands.RenameApplicationRequest{
+		ApplicationId: ctx.Param("id"),
+		Name:          params.Name,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to rename application")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    applicationId,
+	})
+}
+
+func (c *ApplicationController) Delete(ctx *gin.Context) {
+	applicationId, err := c.deleteApplicationCommand.Execute(ctx, commands.DeleteApplicationRequest{
+		ApplicationId: ctx.Param("id"),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to delete application")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    applicationId,
+	})
+}
+
+func (c *ApplicationController) AddPermission(ctx *gin.Context) {
+	var params struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1703: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	permissionId, err := c.addPermissionCommand.Execute(ctx, commands.AddPermissionRequest{
+		ApplicationId: ctx.Param("id"),
+		Name:          params.Name,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to add permission")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    permissionId,
+	})
+}
+
+func (c *ApplicationController) RenamePermission(ctx *gin.Context) {
+	var params struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1704: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	permissionId, err := c.renamePermissionCommand.Execute(ctx, commands.RenamePermissionRequest{
+		ApplicationId: ctx.Param("id"),
+		PermissionId:  ctx.Param("permission_id"),
+		Name:          params.Name,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to rename permission")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    permissionId,
+	})
+}
+
+func (c *ApplicationController) RemovePermission(ctx *gin.Context) {
+	permissionId, err := c.removePermissionCommand.Execute(ctx, commands.RemovePermissionRequest{
+		ApplicationId: ctx.Param("id"),
+		PermissionId:  ctx.Param("permission_id"),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to remove permission")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    permissionId,
+	})
+}
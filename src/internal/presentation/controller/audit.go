@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"iyaem/internal/app/audit"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+type AuditController struct {
+	auditQuery audit.Query
+}
+
+func NewAuditController(auditQuery audit.Query) *AuditController {
+	return &AuditController{auditQuery}
+}
+
+type auditLogParams struct {
+	OrganizationId string    `form:"organization_id" binding:"required"`
+	TenantId       string    `form:"tenant_id"`
+	Actor          string    `form:"actor"`
+	Action         string    `form:"action"`
+	TargetId       string    `form:"target_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To             time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit          int       `form:"limit" binding:"omitempty,min=1"`
+	Offset         int       `form:"offset" binding:"omitempty,min=0"`
+	Format         string    `form:"format" binding:"omitempty,oneof=csv json"`
+}
+
+func (p auditLogParams) filter() audit.Filter {
+	return audit.Filter{
+		OrganizationId: p.OrganizationId,
+		TenantId:       p.TenantId,
+		ActorId:        p.Actor,
+		Action:         p.Action,
+		TargetId:       p.TargetId,
+		From:           p.From,
+		To:             p.To,
+		Limit:          p.Limit,
+		Offset:         p.Offset,
+	}
+}
+
+// AuditLog returns a page of the organization's audit log, newest first.
+func (c *AuditController) AuditLog(ctx *gin.Context) {
+	var params auditLogParams
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 1801: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultAuditPageSize
+	}
+	if params.Limit > maxAuditPageSize {
+		params.Limit = maxAuditPageSize
+	}
+
+	filter := params.filter()
+	filter.Limit = params.Limit + 1
+
+	entries, err := c.auditQuery.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get audit log",
+		})
+		return
+	}
+
+	hasMore := len(entries) > params.Limit
+	if hasMore {
+		entries = entries[:params.Limit]
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"entries":  entries,
+		"limit":    params.Limit,
+		"offset":   params.Offset,
+		"has_more": hasMore,
+	})
+}
+
+// Export streams every matching entry, oldest first, as CSV or as a JSON
+// array.
+func (c *AuditController) Export(ctx *gin.Context) {
+	var params auditLogParams
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 1802: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	filter := params.filter()
+	filename := "audit-log-" + time.Now().UTC().Format("20060102T150405Z")
+
+	if params.Format == "csv" {
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		err = writeAuditCSV(ctx, c.auditQuery, filter)
+	} else {
+		ctx.Header("Content-Type", "application/json")
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		err = writeAuditJSON(ctx, c.auditQuery, filter)
+	}
+
+	// The status is already sent once streaming started, so a failure can
+	// only be logged.
+	if err != nil {
+		log.Printf("Error 1803: %v", err)
+	}
+}
+
+var auditCSVHeader = []string{
+	"id", "created_at", "organization_id", "tenant_id",
+	"actor_id", "actor_email", "actor_client_id",
+	"action", "target_type", "target_id", "before", "after",
+	"ip_address", "user_agent", "request_id",
+}
+
+func writeAuditCSV(ctx *gin.Context, query audit.Query, filter audit.Filter) error {
+	w := csv.NewWriter(ctx.Writer)
+
+	err := w.Write(auditCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	err = query.Each(ctx, filter, func(e audit.Entry) error {
+		before, _ := json.Marshal(e.Before)
+		after, _ := json.Marshal(e.After)
+
+		return w.Write([]string{
+			e.Id, e.CreatedAt.UTC().Format(time.RFC3339), e.OrganizationId, e.TenantId,
+			e.Actor.UserId, e.Actor.Email, e.Actor.ClientId,
+			e.Action, e.TargetType, e.TargetId, string(before), string(after),
+			e.Metadata.IpAddress, e.Metadata.UserAgent, e.Metadata.RequestId,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeAuditJSON(ctx *gin.Context, query audit.Query, filter audit.Filter) error {
+	_, err := ctx.Writer.WriteString("[")
+	if err != nil {
+		return err
+	}
+
+	first := true
+	err = query.Each(ctx, filter, func(e audit.Entry) error {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		if !first {
+			ctx.Writer.WriteString(",")
+		}
+		first = false
+
+		_, err = ctx.Writer.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.Writer.WriteString("]")
+	return err
+}
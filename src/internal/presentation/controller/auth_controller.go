@@ -8,30 +8,96 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"net/url"
-	"os"
-
+	"strconv"
+	"strings"
+	"time"
+
+	"iyaem/internal/app/authorization"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
 	"iyaem/internal/providers"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/golang-jwt/jwt"
 )
 
 type AuthController struct {
-	auth *providers.Authenticator
-	db   *sql.DB
+	idp      providers.IdentityProvider
+	keys     *providers.KeyStore
+	verifier *providers.TokenVerifier
+	db       *sql.DB
+
+	// enricher is optional; when set, issued tokens carry the effective
+	// roles and permissions of the user.
+	enricher *authorization.TokenEnricher
+
+	joinInvitedOrganizationsCommand *commands.JoinInvitedOrganizationsCommand
+	provisionSsoUserCommand         *commands.ProvisionSsoUserCommand
+	passwordLoginCommand            *commands.PasswordLoginCommand
+	startSessionCommand             *commands.StartSessionCommand
+	refreshSessionCommand           *commands.RefreshSessionCommand
+	revokeSessionCommand            *commands.RevokeSessionCommand
+	verifyMfaCommand                *commands.VerifyMfaCommand
+
+	orgRepo      repositories.OrganizationRepository
+	ssoRepo      repositories.SsoConnectionRepository
+	ssoProviders *providers.SsoProviders
+
+	loginThrottle *providers.LoginThrottle
+	mfaThrottle   *providers.LoginThrottle
 }
 
-var (
-	key []byte
-	t   *jwt.Token
-	s   string
-)
-
-func NewAuthController(auth *providers.Authenticator, db *sql.DB) *AuthController {
-	return &AuthController{auth, db}
+func NewAuthController(
+	idp providers.IdentityProvider,
+	keys *providers.KeyStore,
+	verifier *providers.TokenVerifier,
+	db *sql.DB,
+	enricher *authorization.TokenEnricher,
+	joinInvitedOrganizationsCommand *commands.JoinInvitedOrganizationsCommand,
+	provisionSsoUserCommand *commands.ProvisionSsoUserCommand,
+	passwordLoginCommand *commands.PasswordLoginCommand,
+	startSessionCommand *commands.StartSessionCommand,
+	refreshSessionCommand *commands.RefreshSessionCommand,
+	revokeSessionCommand *commands.RevokeSessionCommand,
+	verifyMfaCommand *commands.VerifyMfaCommand,
+	orgRepo repositories.OrganizationRepository,
+	ssoRepo repositories.SsoConnectionRepository,
+	ssoProviders *providers.SsoProviders,
+	loginThrottle *providers.LoginThrottle,
+	mfaThrottle *providers.LoginThrottle,
+) *AuthController {
+	return &AuthController{
+		idp,
+		keys,
+		verifier,
+		db,
+		enricher,
+		joinInvitedOrganizationsCommand,
+		provisionSsoUserCommand,
+		passwordLoginCommand,
+		startSessionCommand,
+		refreshSessionCommand,
+		revokeSessionCommand,
+		verifyMfaCommand,
+		orgRepo,
+		ssoRepo,
+		ssoProviders,
+		loginThrottle,
+		mfaThrottle,
+	}
 }
 
+// ssoStatePrefix marks the state of a login through the SSO connection of
+// an organization, followed by the connection id.
+const ssoStatePrefix = "sso:"
+
+// ssoStateCookie keeps the state of an SSO login in the browser that
+// started it.
+const ssoStateCookie = "sso_login"
+
 func (c *AuthController) Login(ctx *gin.Context) {
 	state, err := generateRandomState()
 	if err != nil {
@@ -41,17 +107,13 @@ func (c *AuthController) Login(ctx *gin.Context) {
 
 	log.Printf("Client req: %v", ctx.Request.Header.Get("Origin"))
 
-	if ctx.Request.Header.Get("Origin") == "" {
-		c.auth.Config.RedirectURL = "https://" + os.Getenv("AUTH0_CALLBACK_URL")
-		authorizationURL := c.auth.AuthCodeURL(state)
-
-		ctx.Redirect(http.StatusTemporaryRedirect, authorizationURL)
+	origin := ctx.Request.Header.Get("Origin")
+	if origin == "" {
+		ctx.Redirect(http.StatusTemporaryRedirect, c.idp.AuthCodeURL(state, "", providers.LoginChallenge{}))
 		return
-	} else {
-		c.auth.Config.RedirectURL = ctx.Request.Header.Get("origin") + "/callback"
 	}
 
-	authorizationURL := c.auth.AuthCodeURL(state) + "&app=" + ctx.Request.Header.Get("Origin")
+	authorizationURL := c.idp.AuthCodeURL(state, callbackURL(origin), providers.LoginChallenge{}) + "&app=" + origin
 
 	ctx.JSON(http.StatusTemporaryRedirect, gin.H{
 		"url": authorizationURL,
@@ -59,6 +121,57 @@ func (c *AuthController) Login(ctx *gin.Context) {
 
 }
 
+// SsoLogin starts a login through the SSO connection of an organization,
+// found by the "organization" identifier or else the "email" domain.
+func (c *AuthController) SsoLogin(ctx *gin.Context) {
+	connection, err := commands.ResolveSsoConnection(ctx, c.orgRepo, c.ssoRepo, ctx.Query("organization"), ctx.Query("email"))
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to find SSO connection")
+		return
+	}
+
+	idp, err := c.ssoProvider(ctx, connection)
+	if err != nil {
+		log.Printf("Error 4323: %v", err)
+		ctx.String(http.StatusBadGateway, "Failed to reach the identity provider of the organization.")
+		return
+	}
+
+	state, err := generateRandomState()
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	state = ssoStatePrefix + connection.Id().Value() + ":" + state
+
+	challenge := providers.NewLoginChallenge()
+	providers.SetLoginState(ctx, ssoStateCookie, state, challenge)
+
+	origin := ctx.Request.Header.Get("Origin")
+	if origin == "" {
+		ctx.Redirect(http.StatusTemporaryRedirect, idp.AuthCodeURL(state, "", challenge))
+		return
+	}
+
+	ctx.JSON(http.StatusTemporaryRedirect, gin.H{
+		"url": idp.AuthCodeURL(state, callbackURL(origin), challenge) + "&app=" + origin,
+	})
+}
+
+func (c *AuthController) ssoProvider(ctx *gin.Context, connection *entities.SsoConnection) (providers.IdentityProvider, error) {
+	return c.ssoProviders.For(ctx, connection.Id().Value(), connection.IssuerUrl(), connection.ClientId(), connection.ClientSecret())
+}
+
+// callbackURL is where the identity provider sends a user who signed in
+// to an application, or "" for the default callback.
+func callbackURL(origin string) string {
+	if origin == "" {
+		return ""
+	}
+
+	return origin + "/callback"
+}
+
 func (c *AuthController) Callback(ctx *gin.Context) {
 	jsonData, err := ctx.GetRawData()
 	if err != nil {
@@ -76,23 +189,18 @@ func (c *AuthController) Callback(ctx *gin.Context) {
 		log.Printf("Error: %v", err)
 	}
 
-	token, err := c.auth.Exchange(ctx.Request.Context(), params.Code)
-	if err != nil {
-		ctx.String(http.StatusUnauthorized, "Failed to exchange an authorization code for a token.")
+	if strings.HasPrefix(params.State, ssoStatePrefix) {
+		c.ssoCallback(ctx, params.Code, params.State)
 		return
 	}
 
-	_, rawIdToken, err := c.auth.VerifyIDToken(ctx.Request.Context(), token)
+	identity, err := c.idp.Exchange(ctx.Request.Context(), params.Code, callbackURL(ctx.Request.Header.Get("Origin")), providers.LoginChallenge{})
 	if err != nil {
-		ctx.String(http.StatusInternalServerError, "Failed to verify ID Token.")
+		log.Printf("Error: %v", err)
+		ctx.String(http.StatusUnauthorized, "Failed to exchange an authorization code for a token.")
 		return
 	}
 
-	claims, err := DecodeJWT(rawIdToken)
-	if err != nil {
-		log.Print(err)
-	}
-
 	var picture string
 	var user_id string
 	var email string
@@ -100,72 +208,388 @@ func (c *AuthController) Callback(ctx *gin.Context) {
 	row := c.db.QueryRow(`select u.id, picture, email from public.user u
 		LEFT JOIN user_identity ui
 		on u.id = ui.user_id
-		where ui.idp_id=$1`, claims["sub"])
+		where ui.idp_id=$1`, identity.Subject)
 	err = row.Scan(&user_id, &picture, &email)
 	if err != nil {
 		log.Printf("Error 4321: %v", err)
 	}
 
+	// On the first login, an invited person gets an account and joins the
+	// inviting organizations.
+	if user_id == "" && identity.EmailVerified && identity.Email != "" {
+		user_id, err = c.joinInvitedOrganizationsCommand.Execute(ctx, commands.JoinInvitedOrganizationsRequest{
+			IdpId:   identity.Subject,
+			Email:   identity.Email,
+			Name:    identity.Name,
+			Picture: identity.Picture,
+		})
+		if err != nil {
+			log.Printf("Error 4322: %v", err)
+			ctx.String(http.StatusInternalServerError, "Failed to accept invitations.")
+			return
+		}
+
+		if user_id != "" {
+			email, picture = identity.Email, identity.Picture
+		}
+	}
+
 	log.Println("User ID: ", user_id)
 
-	key = []byte(os.Getenv("JWT_SECRET"))
-	t = jwt.NewWithClaims(jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"iss":     "iam.sashore.com",
-			"sub":     user_id,
-			"picture": picture,
-			"email":   email,
-			"exp":     claims["exp"],
-			"iat":     claims["iat"],
-			"name":    claims["name"],
+	c.respondToken(ctx, identity, user_id, email, picture, entities.AuthMethodExternal)
+}
+
+// ssoCallback completes a login through an SSO connection, provisioning
+// the user on the first login. Only the browser that started the login
+// can complete it.
+func (c *AuthController) ssoCallback(ctx *gin.Context, code string, state string) {
+	challenge, ok := providers.TakeLoginState(ctx, ssoStateCookie, state)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "The SSO login was not started in this browser.")
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(state, ssoStatePrefix), ":", 2)
+
+	connectionId, err := vo.NewSsoConnectionId(parts[0])
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid state.")
+		return
+	}
+
+	connection, err := c.ssoRepo.FindById(ctx, connectionId)
+	if err != nil || connection == nil {
+		log.Printf("Error 4324: %v", err)
+		ctx.String(http.StatusUnauthorized, "The SSO connection no longer exists.")
+		return
+	}
+
+	idp, err := c.ssoProvider(ctx, connection)
+	if err != nil {
+		log.Printf("Error 4323: %v", err)
+		ctx.String(http.StatusBadGateway, "Failed to reach the identity provider of the organization.")
+		return
+	}
+
+	identity, err := idp.Exchange(ctx.Request.Context(), code, callbackURL(ctx.Request.Header.Get("Origin")), challenge)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.String(http.StatusUnauthorized, "Failed to exchange an authorization code for a token.")
+		return
+	}
+
+	provisioned, err := c.provisionSsoUserCommand.Execute(ctx, commands.ProvisionSsoUserRequest{
+		ConnectionId:  connection.Id().Value(),
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		Name:          identity.Name,
+		Picture:       identity.Picture,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to provision user")
+		return
+	}
+
+	// The email belongs to an existing account, which has to sign in with
+	// its own method and confirm the link with the token.
+	if provisioned.LinkToken != "" {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Sign in to your existing account to link it to SSO",
+			"data": gin.H{
+				"link_token": provisioned.LinkToken,
+			},
 		})
-	s, err = t.SignedString(key)
+		return
+	}
+
+	c.respondToken(ctx, identity, provisioned.UserId, entities.NormalizeEmail(identity.Email), identity.Picture, entities.AuthMethodExternal)
+}
+
+// PasswordLogin signs in a user with a password credential. Failed logins
+// are throttled per client IP and per email on top of the lockout of the
+// credential.
+func (c *AuthController) PasswordLogin(ctx *gin.Context) {
+	var params struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		log.Printf("Error 4325: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	keys := []string{"ip:" + ctx.ClientIP(), "email:" + entities.NormalizeEmail(params.Email)}
+
+	if ok, wait := c.loginThrottle.Allow(keys...); !ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many failed logins, try again later",
+		})
+		return
+	}
+
+	user, err := c.passwordLoginCommand.Execute(ctx, commands.PasswordLoginRequest{
+		Email:    params.Email,
+		Password: params.Password,
+	})
+	if errors.Is(err, entities.ErrInvalidPassword) {
+		c.loginThrottle.Fail(keys...)
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid email or password",
+		})
+		return
+	}
 	if err != nil {
-		log.Print(err)
+		respondCommandError(ctx, err, "Failed to sign in")
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"token": s})
+	c.loginThrottle.Succeed(keys...)
 
+	identity := providers.Identity{
+		Subject: user.Subject,
+		Email:   user.Email,
+		Name:    user.Name,
+		Picture: user.Picture,
+	}
+
+	c.respondToken(ctx, identity, user.UserId, user.Email, user.Picture, entities.AuthMethodPassword)
 }
 
-func (c *AuthController) Logout(ctx *gin.Context) {
-	logoutUrl, err := url.Parse("https://" + os.Getenv("AUTH0_DOMAIN") + "/v2/logout")
+// Refresh exchanges the refresh token of a session for a new access token
+// and the next refresh token. A refresh token works once; presenting it
+// again ends the session.
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var params struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		log.Printf("Error 4326: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	session, err := c.refreshSessionCommand.Execute(ctx, commands.RefreshSessionRequest{
+		RefreshToken: params.RefreshToken,
+		IpAddress:    ctx.ClientIP(),
+	})
+	if errors.Is(err, entities.ErrInvalidRefreshToken) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid refresh token",
+		})
+		return
+	}
 	if err != nil {
-		ctx.String(http.StatusInternalServerError, err.Error())
+		respondCommandError(ctx, err, "Failed to refresh session")
 		return
 	}
 
-	// scheme := "https"
-	// if ctx.Request.TLS != nil {
-	// 	scheme = "https"
-	// }
+	identity := providers.Identity{
+		Email:   session.Email,
+		Name:    session.Name,
+		Picture: session.Picture,
+	}
+
+	c.issueToken(ctx, identity, session.UserId, session.Email, session.Picture, session.SessionResponse)
+}
 
-	returnTo, err := url.Parse(ctx.Request.Header.Get("Origin"))
+// VerifyMfa checks a second factor for the session of the bearer token,
+// which completes a sign in waiting for one or steps up the session, and
+// responds with a new IAM token. The refresh token stays the same.
+func (c *AuthController) VerifyMfa(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok || principal.SessionId == "" {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var params struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		log.Printf("Error 4329: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	key := "mfa:" + principal.UserId
+
+	if ok, wait := c.mfaThrottle.Allow(key); !ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many failed attempts, try again later",
+		})
+		return
+	}
+
+	session, err := c.verifyMfaCommand.Execute(ctx, commands.VerifyMfaRequest{
+		UserId:    principal.UserId,
+		SessionId: principal.SessionId,
+		Code:      params.Code,
+	})
+	if errors.Is(err, entities.ErrInvalidMfaCode) {
+		c.mfaThrottle.Fail(key)
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid code",
+		})
+		return
+	}
 	if err != nil {
-		ctx.String(http.StatusInternalServerError, err.Error())
+		respondCommandError(ctx, err, "Failed to verify code")
+		return
+	}
+
+	c.mfaThrottle.Succeed(key)
+
+	identity := providers.Identity{
+		Email:   principal.Email,
+		Name:    principal.Name,
+		Picture: principal.PictureUrl,
+	}
+
+	c.issueToken(ctx, identity, principal.UserId, principal.Email, principal.PictureUrl, session)
+}
+
+// accessTokenTTL is how long an IAM token is valid. Clients keep signed
+// in past it with the refresh token of their session.
+const accessTokenTTL = 15 * time.Minute
+
+// respondToken starts a session for a user who signed in with authMethod
+// and signs their IAM token.
+func (c *AuthController) respondToken(ctx *gin.Context, identity providers.Identity, user_id string, email string, picture string, authMethod string) {
+	if user_id == "" {
+		c.issueToken(ctx, identity, user_id, email, picture, commands.SessionResponse{
+			AuthMethods: []string{authMethod},
+			Acr:         entities.AssuranceSingleFactor,
+			AuthTime:    time.Now(),
+		})
 		return
 	}
 
-	if returnTo.String() == "" {
-		returnTo, err = url.Parse("https://" + os.Getenv("AUTH0_LOGOUT_URL"))
+	session, err := c.startSessionCommand.Execute(ctx, commands.StartSessionRequest{
+		UserId:     user_id,
+		UserAgent:  ctx.Request.UserAgent(),
+		IpAddress:  ctx.ClientIP(),
+		AuthMethod: authMethod,
+	})
+	if err != nil {
+		log.Printf("Error 4327: %v", err)
+		ctx.String(http.StatusInternalServerError, "Failed to start session.")
+		return
+	}
+
+	c.issueToken(ctx, identity, user_id, email, picture, session)
+}
+
+// issueToken signs the IAM token of a session and responds with it and
+// the refresh token of the session. While the session waits for a second
+// factor, the token only serves to verify one and carries no permissions.
+func (c *AuthController) issueToken(ctx *gin.Context, identity providers.Identity, user_id string, email string, picture string, session commands.SessionResponse) {
+	now := time.Now()
+	tokenClaims := jwt.MapClaims{
+		"iss":     providers.TokenIssuer(),
+		"aud":     providers.TokenAudience(),
+		"sub":     user_id,
+		"picture": picture,
+		"email":   email,
+		"exp":     now.Add(accessTokenTTL).Unix(),
+		"iat":     now.Unix(),
+		"name":    identity.Name,
+		"amr":     session.AuthMethods,
+		"acr":     session.Acr,
+	}
+	if !session.AuthTime.IsZero() {
+		tokenClaims["auth_time"] = session.AuthTime.Unix()
+	}
+	if session.SessionId != "" {
+		tokenClaims["sid"] = session.SessionId
+	}
+	if session.MfaPending {
+		tokenClaims["mfa_pending"] = true
+	}
+
+	if c.enricher != nil && user_id != "" && !session.MfaPending {
+		authzClaims, err := c.enricher.Claims(ctx, user_id)
 		if err != nil {
-			ctx.String(http.StatusInternalServerError, err.Error())
+			log.Printf("Error: %v", err)
+			ctx.String(http.StatusInternalServerError, "Failed to resolve permissions.")
 			return
 		}
+
+		for name, value := range authzClaims {
+			tokenClaims[name] = value
+		}
 	}
 
-	parameters := url.Values{}
-	parameters.Add("returnTo", returnTo.String())
-	parameters.Add("client_id", os.Getenv("AUTH0_CLIENT_ID"))
-	logoutUrl.RawQuery = parameters.Encode()
+	s, err := c.keys.Sign(tokenClaims)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.String(http.StatusInternalServerError, "Failed to sign token.")
+		return
+	}
+
+	response := gin.H{"token": s, "expires_in": int(accessTokenTTL.Seconds())}
+	if session.RefreshToken != "" {
+		response["refresh_token"] = session.RefreshToken
+	}
+	if session.MfaPending {
+		response["mfa_required"] = true
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Logout ends the session of the bearer token, if one is sent, and
+// redirects to the logout page of the identity provider.
+func (c *AuthController) Logout(ctx *gin.Context) {
+	if token, ok := providers.BearerToken(ctx); ok {
+		principal, err := c.verifier.Verify(token)
+		if err == nil && principal.SessionId != "" {
+			_, err = c.revokeSessionCommand.Execute(ctx, commands.RevokeSessionRequest{
+				SessionId: principal.SessionId,
+				UserId:    principal.UserId,
+				Reason:    entities.SessionSignedOut,
+			})
+		}
+		if err != nil && !errors.Is(err, entities.ErrConflict) {
+			log.Printf("Error 4328: %v", err)
+		}
+	}
+
+	logoutUrl, err := c.idp.LogoutURL(ctx.Request.Header.Get("Origin"))
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	if ctx.Request.Header.Get("Origin") == "" {
-		ctx.Redirect(http.StatusTemporaryRedirect, logoutUrl.String())
+		ctx.Redirect(http.StatusTemporaryRedirect, logoutUrl)
 		return
 	}
 
 	ctx.JSON(http.StatusTemporaryRedirect, gin.H{
-		"url": logoutUrl.String(),
+		"url": logoutUrl,
 	})
 }
 
@@ -180,17 +604,3 @@ func generateRandomState() (string, error) {
 
 	return state, nil
 }
-
-func DecodeJWT(token string) (map[string]interface{}, error) {
-	tokenInstance, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
-	if err != nil {
-		return nil, err
-	}
-
-	claims, ok := tokenInstance.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid claims")
-	}
-
-	return claims, nil
-}
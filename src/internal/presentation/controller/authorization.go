@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"iyaem/internal/app/authorization"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchChecks = 100
+
+type AuthorizationController struct {
+	evaluator *authorization.Evaluator
+	enricher  *authorization.TokenEnricher
+}
+
+func NewAuthorizationController(
+	evaluator *authorization.Evaluator,
+	enricher *authorization.TokenEnricher,
+) *AuthorizationController {
+	return &AuthorizationController{evaluator, enricher}
+}
+
+func (c *AuthorizationController) Authorize(ctx *gin.Context) {
+	var params authorization.CheckRequest
+
+	err := ctx.ShouldBindJSON(&params)
+	if err != nil {
+		log.Printf("Error 1501: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	decision, err := c.evaluator.Check(ctx, params)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to evaluate permission",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decision)
+}
+
+func (c *AuthorizationController) AuthorizeBatch(ctx *gin.Context) {
+	var params struct {
+		Checks []authorization.CheckRequest `json:"checks" binding:"required,min=1,dive"`
+	}
+
+	err := ctx.ShouldBindJSON(&params)
+	if err != nil {
+		log.Printf("Error 1502: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	if len(params.Checks) > maxBatchChecks {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": "Too many checks in a single request",
+		})
+		return
+	}
+
+	decisions, err := c.evaluator.CheckBatch(ctx, params.Checks)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to evaluate permissions",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"results": decisions,
+	})
+}
+
+// UserPermissions returns the effective roles and permissions of the
+// caller per tenant. It is the target of the "authz_ref" token claim.
+func (c *AuthorizationController) UserPermissions(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tenants, err := c.enricher.TenantClaims(ctx, principal.UserId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get permissions",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"authz": tenants,
+	})
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"errors"
+	"iyaem/internal/app/queries"
+	"iyaem/internal/domain/entities"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// respondCommandError maps an error returned by a command to a response.
+// Errors that are not caused by the request are logged and hidden.
+func respondCommandError(ctx *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, entities.ErrInvalid), errors.Is(err, queries.ErrInvalidList):
+		status = http.StatusBadRequest
+	case errors.Is(err, entities.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, entities.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, entities.ErrConflict):
+		status = http.StatusConflict
+	}
+
+	if status == http.StatusInternalServerError {
+		log.Printf("Error: %v", err)
+		ctx.JSON(status, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return
+	}
+
+	ctx.JSON(status, gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// tenantScope returns the tenant named in the request body, if any. Roles
+// and groups owned by a tenant are only managed through that tenant.
+func tenantScope(ctx *gin.Context) string {
+	var params struct {
+		TenantId string `json:"tenant_id"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		return ""
+	}
+
+	return params.TenantId
+}
@@ -1,26 +1,55 @@
 package controller
 
 import (
-	"database/sql"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/app/queries"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type GroupController struct {
-	db *sql.DB
+	organizationQuery queries.OrganizationQuery
+
+	createGroupCommand         *commands.CreateGroupCommand
+	createTenantGroupCommand   *commands.CreateTenantGroupCommand
+	updateGroupCommand         *commands.UpdateGroupCommand
+	deleteGroupCommand         *commands.DeleteGroupCommand
+	attachRoleToGroupCommand   *commands.AttachRoleToGroupCommand
+	detachRoleFromGroupCommand *commands.DetachRoleFromGroupCommand
 }
 
-func NewGroupController(db *sql.DB) *GroupController {
-	return &GroupController{db}
+func NewGroupController(
+	organizationQuery queries.OrganizationQuery,
+	createGroupCommand *commands.CreateGroupCommand,
+	createTenantGroupCommand *commands.CreateTenantGroupCommand,
+	updateGroupCommand *commands.UpdateGroupCommand,
+	deleteGroupCommand *commands.DeleteGroupCommand,
+	attachRoleToGroupCommand *commands.AttachRoleToGroupCommand,
+	detachRoleFromGroupCommand *commands.DetachRoleFromGroupCommand,
+) *GroupController {
+	return &GroupController{
+		organizationQuery,
+		createGroupCommand,
+		createTenantGroupCommand,
+		updateGroupCommand,
+		deleteGroupCommand,
+		attachRoleToGroupCommand,
+		detachRoleFromGroupCommand,
+	}
 }
 
+// UsersWithGroup returns a page of the members of the organization who
+// have the group in the tenant, with the same sorting and filters as the
+// members of the organization.
 func (c *GroupController) UsersWithGroup(ctx *gin.Context) {
 	type Params struct {
 		OrganizationId string `form:"organization_id" binding:"required"`
 		TenantId       string `form:"tenant_id" binding:"required"`
 		GroupId        string `form:"group_id" binding:"required"`
+		listParams
 	}
 
 	var params Params
@@ -34,39 +63,161 @@ func (c *GroupController) UsersWithGroup(ctx *gin.Context) {
 		return
 	}
 
-	rows, err := c.db.Query(`
-		select distinct uo.id, u."picture", u."name", u.email from user_organization uo 
-		left join public."user" u on u.id = uo.user_id 
-		left join user_group ug on uo.id = ug.user_org_id
-		left join tenant t on ug.tenant_id = t.id 
-		where t.id=$1 and ug.group_id =$2;`, params.TenantId, params.GroupId)
+	list := params.query()
+	list.TenantId = params.TenantId
+	list.GroupId = params.GroupId
+
+	users, err := c.organizationQuery.UsersInOrganization(ctx, params.OrganizationId, list)
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to get users")
+		return
+	}
+
+	respondPage(ctx, users)
+}
+
+// Create defines an application group, or a group owned by the tenant when
+// the request names one.
+func (c *GroupController) Create(ctx *gin.Context) {
+	var params struct {
+		ApplicationId  string `json:"application_id" binding:"required_without=TenantId,omitempty,uuid"`
+		OrganizationId string `json:"organization_id" binding:"required_with=TenantId"`
+		TenantId       string `json:"tenant_id" binding:"omitempty,uuid"`
+		Name           string `json:"name" binding:"required"`
+		Description    string `json:"description"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1421: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var groupId string
+	if params.TenantId != "" {
+		groupId, err = c.createTenantGroupCommand.Execute(ctx, commands.CreateTenantGroupRequest{
+			OrganizationId: params.OrganizationId,
+			TenantId:       params.TenantId,
+			Name:           params.Name,
+			Description:    params.Description,
+		})
+	} else {
+		groupId, err = c.createGroupCommand.Execute(ctx, commands.CreateGroupRequest{
+			ApplicationId: params.ApplicationId,
+			Name:          params.Name,
+			Description:   params.Description,
+		})
+	}
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create group")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    groupId,
+	})
+}
+
+func (c *GroupController) Update(ctx *gin.Context) {
+	var params struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1422: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	groupId, err := c.updateGroupCommand.Execute(ctx, commands.UpdateGroupRequest{
+		GroupId:     ctx.Param("id"),
+		Name:        params.Name,
+		Description: params.Description,
+		TenantId:    tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to update group")
+		return
+	}
 
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    groupId,
+	})
+}
+
+func (c *GroupController) Delete(ctx *gin.Context) {
+	groupId, err := c.deleteGroupCommand.Execute(ctx, commands.DeleteGroupRequest{
+		GroupId:  ctx.Param("id"),
+		TenantId: tenantScope(ctx),
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.String(http.StatusInternalServerError, "Failed to get users")
+		respondCommandError(ctx, err, "Failed to delete group")
 		return
 	}
 
-	type User struct {
-		UserOrgId string `json:"user_org_id"`
-		Picture   string `json:"picture"`
-		Name      string `json:"name"`
-		Email     string `json:"email"`
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    groupId,
+	})
+}
+
+func (c *GroupController) AttachRole(ctx *gin.Context) {
+	var params struct {
+		RoleId string `json:"role_id" binding:"required,uuid"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1423: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
-	var users []User = make([]User, 0)
 
-	for rows.Next() {
-		var u User
+	groupId, err := c.attachRoleToGroupCommand.Execute(ctx, commands.AttachRoleToGroupRequest{
+		GroupId:  ctx.Param("id"),
+		RoleId:   params.RoleId,
+		TenantId: tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to attach role")
+		return
+	}
 
-		err = rows.Scan(&u.UserOrgId, &u.Picture, &u.Name, &u.Email)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			ctx.String(http.StatusInternalServerError, "Failed to get users")
-			return
-		}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    groupId,
+	})
+}
 
-		users = append(users, u)
+func (c *GroupController) DetachRole(ctx *gin.Context) {
+	groupId, err := c.detachRoleFromGroupCommand.Execute(ctx, commands.DetachRoleFromGroupRequest{
+		GroupId:  ctx.Param("id"),
+		RoleId:   ctx.Param("role_id"),
+		TenantId: tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to detach role")
+		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    groupId,
+	})
 }
@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type InvitationController struct {
+	inviteMemberCommand      *commands.InviteMemberCommand
+	acceptInvitationCommand  *commands.AcceptInvitationCommand
+	declineInvitationCommand *commands.DeclineInvitationCommand
+	revokeInvitationCommand  *commands.RevokeInvitationCommand
+
+	invRepo repositories.InvitationRepository
+}
+
+func NewInvitationController(
+	inviteMemberCommand *commands.InviteMemberCommand,
+	acceptInvitationCommand *commands.AcceptInvitationCommand,
+	declineInvitationCommand *commands.DeclineInvitationCommand,
+	revokeInvitationCommand *commands.RevokeInvitationCommand,
+	invRepo repositories.InvitationRepository,
+) *InvitationController {
+	return &InvitationController{
+		inviteMemberCommand,
+		acceptInvitationCommand,
+		declineInvitationCommand,
+		revokeInvitationCommand,
+		invRepo,
+	}
+}
+
+func (c *InvitationController) Invite(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		Email          string `json:"email" binding:"required"`
+		Level          string `json:"level" binding:"omitempty,oneof=member manager"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2001: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	invitationId, err := c.inviteMemberCommand.Execute(ctx, commands.InviteMemberRequest{
+		OrganizationId: params.OrganizationId,
+		Email:          params.Email,
+		Level:          params.Level,
+		InvitedBy:      principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to send invitation")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    invitationId,
+	})
+}
+
+// Pending lists the invitations of the organization that have not been
+// answered or revoked, including expired ones.
+func (c *InvitationController) Pending(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 2002: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	invitations, err := c.invRepo.FindPendingByOrganization(ctx, params.OrganizationId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get invitations",
+		})
+		return
+	}
+
+	type Invitation struct {
+		Id        string    `json:"id"`
+		Email     string    `json:"email"`
+		Level     string    `json:"level"`
+		InvitedBy string    `json:"invited_by"`
+		ExpiresAt time.Time `json:"expires_at"`
+		Expired   bool      `json:"expired"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	data := make([]Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		data = append(data, Invitation{
+			Id:        invitation.Id().Value(),
+			Email:     invitation.Email(),
+			Level:     string(invitation.Level()),
+			InvitedBy: invitation.InvitedBy(),
+			ExpiresAt: invitation.ExpiresAt(),
+			Expired:   invitation.IsExpired(),
+			CreatedAt: invitation.CreatedAt(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+func (c *InvitationController) Revoke(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2003: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	invitationId, err := c.revokeInvitationCommand.Execute(ctx, commands.RevokeInvitationRequest{
+		OrganizationId: params.OrganizationId,
+		InvitationId:   ctx.Param("id"),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to revoke invitation")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    invitationId,
+	})
+}
+
+// Accept adds the signed in user to the organization of the invitation.
+// People without an account join on their first login instead.
+func (c *InvitationController) Accept(ctx *gin.Context) {
+	var params struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2004: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	membershipId, err := c.acceptInvitationCommand.Execute(ctx, commands.AcceptInvitationRequest{
+		Token:  params.Token,
+		UserId: principal.UserId,
+		Email:  principal.Email,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to accept invitation")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    membershipId,
+	})
+}
+
+func (c *InvitationController) Decline(ctx *gin.Context) {
+	var params struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2005: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	invitationId, err := c.declineInvitationCommand.Execute(ctx, commands.DeclineInvitationRequest{
+		Token: params.Token,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to decline invitation")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    invitationId,
+	})
+}
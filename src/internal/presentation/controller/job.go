@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"fmt"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/infrastructure/jobs"
+	"iyaem/internal/providers"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobController struct {
+	queue *jobs.Queue
+}
+
+func NewJobController(queue *jobs.Queue) *JobController {
+	return &JobController{
+		queue,
+	}
+}
+
+// Get returns the status and progress of a job. Users only see the jobs
+// they started.
+func (c *JobController) Get(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	notFound := fmt.Errorf("could not find job: %w", entities.ErrNotFound)
+
+	id := ctx.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		respondCommandError(ctx, notFound, "")
+		return
+	}
+
+	job, err := c.queue.Find(ctx, id)
+	if err != nil {
+		respondCommandError(ctx, fmt.Errorf("could not find job: %s", err), "Failed to get job")
+		return
+	}
+	if job == nil || job.Actor.UserId == "" || !strings.EqualFold(job.Actor.UserId, principal.UserId) {
+		respondCommandError(ctx, notFound, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data": struct {
+			Id          string     `json:"id"`
+			Type        string     `json:"type"`
+			Status      string     `json:"status"`
+			Attempts    int        `json:"attempts"`
+			MaxAttempts int        `json:"max_attempts"`
+			Progress    gin.H      `json:"progress"`
+			LastError   string     `json:"last_error,omitempty"`
+			NextAttempt *time.Time `json:"next_attempt_at,omitempty"`
+			CreatedAt   time.Time  `json:"created_at"`
+			StartedAt   *time.Time `json:"started_at"`
+			FinishedAt  *time.Time `json:"finished_at"`
+		}{
+			Id:          job.Id,
+			Type:        job.Type,
+			Status:      job.Status,
+			Attempts:    job.Attempts,
+			MaxAttempts: job.MaxAttempts,
+			Progress: gin.H{
+				"done":  job.ProgressDone,
+				"total": job.ProgressTotal,
+			},
+			LastError:   job.LastError,
+			NextAttempt: nextAttempt(job),
+			CreatedAt:   job.CreatedAt,
+			StartedAt:   job.StartedAt,
+			FinishedAt:  job.FinishedAt,
+		},
+	})
+}
+
+// nextAttempt is when a job that failed is retried.
+func nextAttempt(job *jobs.Job) *time.Time {
+	if job.Status != jobs.StatusPending || job.Attempts == 0 {
+		return nil
+	}
+
+	return &job.RunAt
+}
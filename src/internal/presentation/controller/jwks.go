@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"iyaem/internal/providers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JwksController struct {
+	keys *providers.KeyStore
+}
+
+func NewJwksController(keys *providers.KeyStore) *JwksController {
+	return &JwksController{keys}
+}
+
+// Keys publishes the public signing keys so that tenant applications can
+// verify IAM tokens without calling back to this service.
+func (c *JwksController) Keys(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.keys.JWKS())
+}
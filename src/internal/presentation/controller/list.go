@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"iyaem/internal/app/queries"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listParams are the query parameters shared by list endpoints. Each list
+// applies the filters that make sense for it and ignores the others.
+type listParams struct {
+	Cursor     string    `form:"cursor"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1"`
+	Sort       string    `form:"sort"`
+	Name       string    `form:"name"`
+	Email      string    `form:"email"`
+	Level      string    `form:"level" binding:"omitempty,oneof=owner manager member"`
+	JoinedFrom time.Time `form:"joined_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	JoinedTo   time.Time `form:"joined_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	RoleId     string    `form:"role_id"`
+	GroupId    string    `form:"group_id"`
+}
+
+func (p listParams) query() queries.ListQuery {
+	limit := p.Limit
+	if limit == 0 {
+		limit = queries.DefaultPageSize
+	}
+	if limit > queries.MaxPageSize {
+		limit = queries.MaxPageSize
+	}
+
+	return queries.ListQuery{
+		Cursor:     p.Cursor,
+		Limit:      limit,
+		Sort:       p.Sort,
+		Name:       p.Name,
+		Email:      p.Email,
+		Level:      p.Level,
+		JoinedFrom: p.JoinedFrom,
+		JoinedTo:   p.JoinedTo,
+		RoleId:     p.RoleId,
+		GroupId:    p.GroupId,
+	}
+}
+
+// respondPage sends a page of a list. Clients pass next_cursor back as
+// cursor to get the next page; it is null on the last one.
+func respondPage[T any](ctx *gin.Context, page queries.Page[T]) {
+	var nextCursor interface{}
+	if page.NextCursor != "" {
+		nextCursor = page.NextCursor
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"message":     "success",
+		"data":        page.Items,
+		"next_cursor": nextCursor,
+	})
+}
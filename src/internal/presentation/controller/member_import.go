@@ -0,0 +1,356 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/app/queries"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxMemberImportSize is the largest import file accepted, in bytes.
+const maxMemberImportSize = 10 << 20
+
+// memberImportColumns are the columns of member import and export files.
+// Roles and groups are lists of ids separated by semicolons.
+var memberImportColumns = []string{"email", "level", "tenant_id", "roles", "groups"}
+
+type MemberImportController struct {
+	requestMemberImportCommand *commands.RequestMemberImportCommand
+	importRepo                 repositories.MemberImportRepository
+	organizationQuery          queries.OrganizationQuery
+}
+
+func NewMemberImportController(
+	requestMemberImportCommand *commands.RequestMemberImportCommand,
+	importRepo repositories.MemberImportRepository,
+	organizationQuery queries.OrganizationQuery,
+) *MemberImportController {
+	return &MemberImportController{
+		requestMemberImportCommand,
+		importRepo,
+		organizationQuery,
+	}
+}
+
+// Import queues an import of members, which a job runs in the background. The
+// file is either a JSON body with the rows, or a CSV file sent as the body
+// or as the "file" field of a form, with organization_id and dry_run in the
+// query.
+func (c *MemberImportController) Import(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	request, err := readMemberImport(ctx)
+	if err != nil {
+		log.Printf("Error 2701: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	request.ActorUserId = principal.UserId
+
+	importId, err := c.requestMemberImportCommand.Execute(ctx, request)
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to import members")
+		return
+	}
+
+	// The import is queued with a job of the same id.
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "success",
+		"data": gin.H{
+			"import_id": importId,
+			"job_id":    importId,
+			"status":    entities.MemberImportPending,
+		},
+	})
+}
+
+// Get returns the status of an import and, once it ran, the outcome of
+// every row.
+func (c *MemberImportController) Get(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 2703: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := vo.NewMemberImportId(ctx.Param("id"))
+	if err != nil {
+		respondCommandError(ctx, fmt.Errorf("%w: %s", entities.ErrInvalid, err), "")
+		return
+	}
+
+	memberImport, err := c.importRepo.FindById(ctx, id)
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to get member import")
+		return
+	}
+	if memberImport == nil || !strings.EqualFold(memberImport.OrganizationId().Value(), params.OrganizationId) {
+		respondCommandError(ctx, fmt.Errorf("could not find member import: %w", entities.ErrNotFound), "")
+		return
+	}
+
+	summary := make(map[string]int)
+	for _, result := range memberImport.Results() {
+		summary[result.Status]++
+	}
+
+	results := memberImport.Results()
+	if results == nil {
+		results = make([]entities.MemberImportResult, 0)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data": gin.H{
+			"import_id":   memberImport.Id().Value(),
+			"status":      memberImport.Status(),
+			"dry_run":     memberImport.DryRun(),
+			"rows":        len(memberImport.Rows()),
+			"summary":     summary,
+			"results":     results,
+			"failure":     memberImport.Failure(),
+			"created_at":  memberImport.CreatedAt(),
+			"finished_at": memberImport.FinishedAt(),
+		},
+	})
+}
+
+// Export streams the membership matrix of the organization, as CSV or as
+// a JSON array, in the format Import accepts.
+func (c *MemberImportController) Export(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+		Format         string `form:"format" binding:"omitempty,oneof=csv json"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 2704: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	filename := "members-" + time.Now().UTC().Format("20060102T150405Z")
+
+	if params.Format == "json" {
+		ctx.Header("Content-Type", "application/json")
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		err = writeMembershipJSON(ctx, c.organizationQuery, params.OrganizationId)
+	} else {
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		err = writeMembershipCSV(ctx, c.organizationQuery, params.OrganizationId)
+	}
+
+	// The status is already sent once streaming started, so a failure can
+	// only be logged.
+	if err != nil {
+		log.Printf("Error 2705: %v", err)
+	}
+}
+
+func readMemberImport(ctx *gin.Context) (commands.RequestMemberImportRequest, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxMemberImportSize)
+
+	if ctx.ContentType() == binding.MIMEJSON {
+		var params struct {
+			OrganizationId string                     `json:"organization_id" binding:"required"`
+			DryRun         bool                       `json:"dry_run"`
+			Rows           []entities.MemberImportRow `json:"rows" binding:"required"`
+		}
+
+		err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+		if err != nil {
+			return commands.RequestMemberImportRequest{}, err
+		}
+
+		return commands.RequestMemberImportRequest{
+			OrganizationId: params.OrganizationId,
+			Rows:           params.Rows,
+			DryRun:         params.DryRun,
+		}, nil
+	}
+
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+		DryRun         bool   `form:"dry_run"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		return commands.RequestMemberImportRequest{}, err
+	}
+
+	var file io.Reader = ctx.Request.Body
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			return commands.RequestMemberImportRequest{}, err
+		}
+
+		f, err := header.Open()
+		if err != nil {
+			return commands.RequestMemberImportRequest{}, err
+		}
+		defer f.Close()
+
+		file = f
+	}
+
+	rows, err := readMemberImportCSV(file)
+	if err != nil {
+		return commands.RequestMemberImportRequest{}, err
+	}
+
+	return commands.RequestMemberImportRequest{
+		OrganizationId: params.OrganizationId,
+		Rows:           rows,
+		DryRun:         params.DryRun,
+	}, nil
+}
+
+// readMemberImportCSV reads a CSV file with a header row naming its
+// columns. Only the email column is required.
+func readMemberImportCSV(file io.Reader) ([]entities.MemberImportRow, error) {
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("the file has no email column")
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]entities.MemberImportRow, 0)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == entities.MaxMemberImportRows {
+			return nil, fmt.Errorf("imports are limited to %d rows", entities.MaxMemberImportRows)
+		}
+
+		rows = append(rows, entities.MemberImportRow{
+			Email:    cell(record, "email"),
+			Level:    cell(record, "level"),
+			TenantId: cell(record, "tenant_id"),
+			Roles:    splitIds(cell(record, "roles")),
+			Groups:   splitIds(cell(record, "groups")),
+		})
+	}
+
+	return rows, nil
+}
+
+func splitIds(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || unicode.IsSpace(r)
+	})
+}
+
+func writeMembershipCSV(ctx *gin.Context, query queries.OrganizationQuery, organizationId string) error {
+	w := csv.NewWriter(ctx.Writer)
+
+	err := w.Write(memberImportColumns)
+	if err != nil {
+		return err
+	}
+
+	err = query.EachMembership(ctx, organizationId, func(row queries.MembershipRow) error {
+		return w.Write([]string{
+			row.Email, row.Level, row.TenantId,
+			strings.Join(row.Roles, ";"), strings.Join(row.Groups, ";"),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeMembershipJSON(ctx *gin.Context, query queries.OrganizationQuery, organizationId string) error {
+	_, err := ctx.Writer.WriteString("[")
+	if err != nil {
+		return err
+	}
+
+	first := true
+	err = query.EachMembership(ctx, organizationId, func(row queries.MembershipRow) error {
+		b, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+
+		if !first {
+			ctx.Writer.WriteString(",")
+		}
+		first = false
+
+		_, err = ctx.Writer.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.Writer.WriteString("]")
+	return err
+}
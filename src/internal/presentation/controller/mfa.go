@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"errors"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MfaController lets users enroll an authenticator app and manage their
+// recovery codes, and owners require multi-factor authentication for their
+// organization. Proving a second factor for a session is done by
+// AuthController.VerifyMfa.
+type MfaController struct {
+	enrollTotpCommand              *commands.EnrollTotpCommand
+	confirmTotpCommand             *commands.ConfirmTotpCommand
+	regenerateRecoveryCodesCommand *commands.RegenerateRecoveryCodesCommand
+	disableMfaCommand              *commands.DisableMfaCommand
+	setOrganizationMfaCommand      *commands.SetOrganizationMfaCommand
+
+	mfaRepo repositories.MfaFactorRepository
+
+	// mfaThrottle limits failed codes per user, shared with
+	// AuthController.VerifyMfa.
+	mfaThrottle *providers.LoginThrottle
+}
+
+func NewMfaController(
+	enrollTotpCommand *commands.EnrollTotpCommand,
+	confirmTotpCommand *commands.ConfirmTotpCommand,
+	regenerateRecoveryCodesCommand *commands.RegenerateRecoveryCodesCommand,
+	disableMfaCommand *commands.DisableMfaCommand,
+	setOrganizationMfaCommand *commands.SetOrganizationMfaCommand,
+	mfaRepo repositories.MfaFactorRepository,
+	mfaThrottle *providers.LoginThrottle,
+) *MfaController {
+	return &MfaController{
+		enrollTotpCommand,
+		confirmTotpCommand,
+		regenerateRecoveryCodesCommand,
+		disableMfaCommand,
+		setOrganizationMfaCommand,
+		mfaRepo,
+		mfaThrottle,
+	}
+}
+
+// Status tells whether the signed in user enabled multi-factor
+// authentication and how many recovery codes they have left.
+func (c *MfaController) Status(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userId, err := vo.NewUserId(principal.UserId)
+	if err != nil {
+		log.Printf("Error 2501: %v", err)
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	factor, err := c.mfaRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get multi-factor authentication",
+		})
+		return
+	}
+
+	data := gin.H{"enabled": false, "pending": principal.MfaPending}
+	if factor != nil && factor.IsConfirmed() {
+		data["enabled"] = true
+		data["confirmed_at"] = factor.ConfirmedAt().Format(time.RFC3339)
+		data["recovery_codes_remaining"] = factor.RemainingRecoveryCodes()
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// EnrollTotp starts enrolling an authenticator app and responds with its
+// secret.
+func (c *MfaController) EnrollTotp(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	enrollment, err := c.enrollTotpCommand.Execute(ctx, commands.EnrollTotpRequest{
+		UserId: principal.UserId,
+		Email:  principal.Email,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to enroll authenticator")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmTotp enables the authenticator being enrolled and responds with
+// the recovery codes. A session waiting for a second factor is completed
+// and can be refreshed for a full token.
+func (c *MfaController) ConfirmTotp(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	principal, code, ok := c.bindCode(ctx, "2502")
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := c.confirmTotpCommand.Execute(ctx, commands.ConfirmTotpRequest{
+		UserId:    principal.UserId,
+		SessionId: principal.SessionId,
+		Code:      code,
+	})
+	if !c.checkCode(ctx, principal, err, "Failed to confirm authenticator") {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    recoveryCodes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user.
+func (c *MfaController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	principal, code, ok := c.bindCode(ctx, "2503")
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := c.regenerateRecoveryCodesCommand.Execute(ctx, commands.RegenerateRecoveryCodesRequest{
+		UserId: principal.UserId,
+		Code:   code,
+	})
+	if !c.checkCode(ctx, principal, err, "Failed to regenerate recovery codes") {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    recoveryCodes,
+	})
+}
+
+// Disable turns multi-factor authentication off for the user.
+func (c *MfaController) Disable(ctx *gin.Context) {
+	principal, code, ok := c.bindCode(ctx, "2504")
+	if !ok {
+		return
+	}
+
+	factorId, err := c.disableMfaCommand.Execute(ctx, commands.DisableMfaRequest{
+		UserId: principal.UserId,
+		Code:   code,
+	})
+	if !c.checkCode(ctx, principal, err, "Failed to disable multi-factor authentication") {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    factorId,
+	})
+}
+
+// SetOrganizationRequirement decides whether the members of the
+// organization must sign in with a second factor.
+func (c *MfaController) SetOrganizationRequirement(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		Required       *bool  `json:"required" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2505: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	organizationId, err := c.setOrganizationMfaCommand.Execute(ctx, commands.SetOrganizationMfaRequest{
+		OrganizationId: params.OrganizationId,
+		Required:       *params.Required,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to change the multi-factor authentication policy")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    organizationId,
+	})
+}
+
+// bindCode reads the code of the request, unless the user made too many
+// failed attempts.
+func (c *MfaController) bindCode(ctx *gin.Context, errorCode string) (*providers.Principal, string, bool) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return nil, "", false
+	}
+
+	var params struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		log.Printf("Error %s: %v", errorCode, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, "", false
+	}
+
+	if ok, wait := c.mfaThrottle.Allow("mfa:" + principal.UserId); !ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many failed attempts, try again later",
+		})
+		return nil, "", false
+	}
+
+	return principal, params.Code, true
+}
+
+// checkCode responds to a failed command, counting invalid codes towards
+// the throttle.
+func (c *MfaController) checkCode(ctx *gin.Context, principal *providers.Principal, err error, message string) bool {
+	key := "mfa:" + principal.UserId
+
+	if errors.Is(err, entities.ErrInvalidMfaCode) {
+		c.mfaThrottle.Fail(key)
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid code",
+		})
+		return false
+	}
+	if err != nil {
+		respondCommandError(ctx, err, message)
+		return false
+	}
+
+	c.mfaThrottle.Succeed(key)
+	return true
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"errors"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+const clientTokenLifetime = 15 * time.Minute
+
+type OAuthController struct {
+	keys *providers.KeyStore
+
+	authenticateClientCommand *commands.AuthenticateApiClientCommand
+}
+
+func NewOAuthController(
+	keys *providers.KeyStore,
+	authenticateClientCommand *commands.AuthenticateApiClientCommand,
+) *OAuthController {
+	return &OAuthController{
+		keys,
+		authenticateClientCommand,
+	}
+}
+
+// Token implements the OAuth2 client credentials grant (RFC 6749 4.4).
+// Client credentials are accepted through HTTP basic auth or the form body.
+func (c *OAuthController) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	if ctx.PostForm("grant_type") != "client_credentials" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "unsupported_grant_type",
+		})
+		return
+	}
+
+	clientId, clientSecret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientId = ctx.PostForm("client_id")
+		clientSecret = ctx.PostForm("client_secret")
+	}
+
+	req := commands.AuthenticateApiClientRequest{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		Scopes:       strings.Fields(ctx.PostForm("scope")),
+	}
+	scopes, err := c.authenticateClientCommand.Execute(ctx, req)
+	if errors.Is(err, commands.ErrInvalidScope) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":             "invalid_scope",
+			"error_description": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error 1601: %v", err)
+		ctx.Header("WWW-Authenticate", `Basic realm="iam"`)
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid_client",
+		})
+		return
+	}
+
+	now := time.Now()
+	scope := strings.Join(scopes, " ")
+
+	accessToken, err := c.keys.Sign(jwt.MapClaims{
+		"iss":       providers.TokenIssuer(),
+		"aud":       providers.TokenAudience(),
+		"sub":       clientId,
+		"client_id": clientId,
+		"scope":     scope,
+		"iat":       now.Unix(),
+		"exp":       now.Add(clientTokenLifetime).Unix(),
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "server_error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(clientTokenLifetime.Seconds()),
+		"scope":        scope,
+	})
+}
@@ -2,45 +2,58 @@ package controller
 
 import (
 	"database/sql"
-	"encoding/json"
-	"io"
+	"errors"
 	"iyaem/internal/app/commands"
 	"iyaem/internal/app/queries"
 	"iyaem/internal/providers"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 )
 
 type OrganizationController struct {
-	db *sql.DB
+	db  *sql.DB
+	idp providers.IdentityProvider
 
 	createOrganizationCommand *commands.CreateOrganizationCommand
 	addUserCommand            *commands.AddOrganizationUserCommand
 	createUserCommand         *commands.CreateUserCommand
 	addRoleCommand            *commands.AddRoleToMemberCommand
+	removeMemberCommand       *commands.RemoveMemberCommand
+
+	// registerPasswordUserCommand is set when password login is enabled;
+	// users are then created with a local password credential instead of
+	// at the identity provider.
+	registerPasswordUserCommand *commands.RegisterPasswordUserCommand
 
 	organizationQuery queries.OrganizationQuery
+	memberSearch      *queries.MemberSearch
 }
 
 func NewOrganizationController(
 	db *sql.DB,
+	idp providers.IdentityProvider,
 	createOrganizationCommand *commands.CreateOrganizationCommand,
 	addUser *commands.AddOrganizationUserCommand,
 	createUser *commands.CreateUserCommand,
 	addRoleCommand *commands.AddRoleToMemberCommand,
+	removeMemberCommand *commands.RemoveMemberCommand,
+	registerPasswordUserCommand *commands.RegisterPasswordUserCommand,
 	organizationQuery queries.OrganizationQuery,
 ) *OrganizationController {
 	return &OrganizationController{
 		db,
+		idp,
 		createOrganizationCommand,
 		addUser,
 		createUser,
 		addRoleCommand,
+		removeMemberCommand,
+		registerPasswordUserCommand,
 		organizationQuery,
+		queries.NewMemberSearch(organizationQuery),
 	}
 }
 
@@ -93,7 +106,7 @@ func (c *OrganizationController) GetAllOrganizations(ctx *gin.Context) {
 }
 
 func (c *OrganizationController) FindById(ctx *gin.Context) {
-	organizationId := ctx.Param("id")
+	organizationId := ctx.Param("organization_id")
 
 	organization, err := c.organizationQuery.FindById(ctx, organizationId)
 	if err != nil {
@@ -106,16 +119,13 @@ func (c *OrganizationController) FindById(ctx *gin.Context) {
 }
 
 func (c *OrganizationController) GetAffiliatedOrganizations(ctx *gin.Context) {
-	token := GetBearerToken(ctx)
-
-	claims, err := DecodeJWT(token)
-	if err != nil {
-		log.Print(err)
-		ctx.Error(err)
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	organizations, err := c.organizationQuery.AllAffilatedOrganizations(ctx, claims["sub"].(string))
+	organizations, err := c.organizationQuery.AllAffilatedOrganizations(ctx, principal.UserId)
 	if err != nil {
 		log.Printf("Error: %v", err)
 		ctx.Error(err)
@@ -125,23 +135,70 @@ func (c *OrganizationController) GetAffiliatedOrganizations(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, organizations)
 }
 
+// GetUsers returns a page of the members of the organization, which can
+// be sorted by name, email, level or joined_at and filtered on each of
+// them as well as on a role or group.
 func (c *OrganizationController) GetUsers(ctx *gin.Context) {
 	var params struct {
 		OrganizationId string `form:"organization_id" binding:"required"`
+		TenantId       string `form:"tenant_id"`
+		listParams
 	}
-	if err := ctx.ShouldBind(&params); err != nil {
-		ctx.Error(err)
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		log.Printf("Error 0102: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
 		return
 	}
 
-	users, err := c.organizationQuery.UsersInOrganization(ctx, params.OrganizationId)
+	list := params.query()
+	list.TenantId = params.TenantId
+
+	users, err := c.organizationQuery.UsersInOrganization(ctx, params.OrganizationId, list)
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.Error(err)
+		respondCommandError(ctx, err, "Failed to get users")
 		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	respondPage(ctx, users)
+}
+
+// SearchUsers finds members of the organization by name or email, for a
+// member of the organization.
+func (c *OrganizationController) SearchUsers(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+		Query          string `form:"q" binding:"required,max=200"`
+		TenantId       string `form:"tenant_id"`
+		listParams
+	}
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		log.Printf("Error 0103: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// The limit of a search is not that of a page.
+	list := params.query()
+	list.TenantId = params.TenantId
+	list.Limit = params.Limit
+
+	users, err := c.memberSearch.Search(ctx, principal.UserId, params.OrganizationId, params.Query, list)
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to search users")
+		return
+	}
+
+	respondPage(ctx, queries.Page[queries.User]{Items: users})
 }
 
 func (c *OrganizationController) GetRecentUsers(ctx *gin.Context) {
@@ -178,10 +235,8 @@ func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
 		return
 	}
 
-	token := GetBearerToken(ctx)
-	claims, err := DecodeJWT(token)
-	if err != nil {
-		log.Print(err)
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
 		ctx.String(http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -191,7 +246,7 @@ func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
 	req := commands.CreateOrganizationRequest{
 		Name:       params.Name,
 		Identifier: params.Identifier,
-		UserId:     claims["sub"].(string),
+		UserId:     principal.UserId,
 	}
 
 	log.Printf("Debug satu")
@@ -263,78 +318,56 @@ func (c *OrganizationController) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	apiToken := providers.GetTokenSingleton().Token
-
-	url := "https://saasiam.us.auth0.com/api/v2/users"
-
-	payload := strings.NewReader("{\"email\":\"" + params.Email + "\",\"nickname\":\"" + params.Email + "\",\"name\":\"" + params.Name + "\",\"password\":\"" + params.Password + "\",\"connection\":\"Username-Password-Authentication\"}")
-
-	log.Printf("Payload: %v", payload)
-
-	req, _ := http.NewRequest("POST", url, payload)
-
-	req.Header.Add("content-type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+apiToken)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Failed to add user",
+	email := params.Email
+	if c.registerPasswordUserCommand != nil {
+		_, err := c.registerPasswordUserCommand.Execute(ctx, commands.RegisterPasswordUserRequest{
+			Email:    params.Email,
+			Name:     params.Name,
+			Password: params.Password,
 		})
-		return
-	}
-
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		log.Fatal(err)
-	}
-	bodyString := string(bodyBytes)
-	log.Printf("Body: %v", bodyString)
-
-	if res.StatusCode != 201 {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Failed to create user",
-		})
-		return
-	}
-
-	defer res.Body.Close()
-
-	type User struct {
-		IdpId   string `json:"user_id"`
-		Picture string `json:"picture"`
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-	}
-
-	var user User
-	err = json.Unmarshal(bodyBytes, &user)
-	if err != nil {
-		log.Printf("Error 0104: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Failed to insert user",
+		if err != nil {
+			respondCommandError(ctx, err, "Failed to create user")
+			return
+		}
+	} else {
+		user, err := c.idp.CreateUser(ctx, providers.IdentityProviderUser{
+			Email:    params.Email,
+			Name:     params.Name,
+			Password: params.Password,
 		})
-		return
-	}
+		if errors.Is(err, providers.ErrNotSupported) {
+			ctx.JSON(http.StatusNotImplemented, gin.H{
+				"message": "The identity provider does not support creating users",
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("Error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Failed to create user",
+			})
+			return
+		}
 
-	createReq := commands.CreateUserRequest{
-		Email:   user.Email,
-		Name:    user.Name,
-		Picture: user.Picture,
-		IdpId:   user.IdpId,
-	}
-	_, err = c.createUserCommand.Execute(ctx, createReq)
-	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"message": err.Error(),
-		})
-		return
+		createReq := commands.CreateUserRequest{
+			Email:   user.Email,
+			Name:    user.Name,
+			Picture: user.Picture,
+			IdpId:   user.Subject,
+		}
+		_, err = c.createUserCommand.Execute(ctx, createReq)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		email = user.Email
 	}
 
 	addReq := commands.AddOrganizationUserRequest{
-		Email:          user.Email,
+		Email:          email,
 		OrganizationId: params.OrganizationId,
 	}
 	membershipId, err := c.addUserCommand.Execute(ctx, addReq)
@@ -371,84 +404,19 @@ func (c *OrganizationController) RemoveUser(ctx *gin.Context) {
 		return
 	}
 
-	tx, err := c.db.Begin()
-	if err != nil {
-		log.Printf("Error 0102: %v", err)
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error":   true,
-			"message": "Failed to remove user from organization",
-		})
-		return
-	}
-
-	var level string
-	row := c.db.QueryRow(`
-		SELECT level FROM user_organization
-		WHERE id=$1;`, params.UserOrgId)
-
-	row.Scan(&level)
-
-	_, err = tx.Exec("DELETE FROM user_role WHERE user_org_id=$1;", params.UserOrgId)
-	if err != nil {
-		log.Printf("Error 0105: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   true,
-			"message": "Failed to remove user from organization",
-		})
-		return
-	}
-
-	_, err = tx.Exec("DELETE FROM user_group WHERE user_org_id=$1;", params.UserOrgId)
-	if err != nil {
-		log.Printf("Error 0105: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   true,
-			"message": "Failed to remove user from organization",
-		})
-		return
-	}
-
-	_, err = tx.Exec("DELETE FROM user_organization WHERE id=$1;", params.UserOrgId)
-	if err != nil {
-		log.Printf("Error 0105: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   true,
-			"message": "Failed to remove user from organization",
-		})
-		return
-	}
-
-	// decrement the member count
-	_, err = tx.Exec("UPDATE organization SET member_count = member_count - 1 WHERE id=$1;", params.OrganizationId)
-	if err != nil {
-		log.Printf("Error 3131: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   true,
-			"message": "Failed to add user to organization",
-		})
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	// decrement the manager count
-	if level == "manager" {
-		_, err = tx.Exec("UPDATE organization SET manager_count = manager_count - 1 WHERE id=$1;", params.OrganizationId)
-		if err != nil {
-			log.Printf("Error 3131: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error":   true,
-				"message": "Failed to add user to organization",
-			})
-			return
-		}
-	}
-
-	err = tx.Commit()
+	_, err = c.removeMemberCommand.Execute(ctx, commands.RemoveMemberRequest{
+		MembershipId:   params.UserOrgId,
+		OrganizationId: params.OrganizationId,
+		ActorUserId:    principal.UserId,
+	})
 	if err != nil {
-		log.Printf("Error 0106: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   true,
-			"message": "Failed to add user to organization",
-		})
+		respondCommandError(ctx, err, "Failed to remove user from organization")
 		return
 	}
 
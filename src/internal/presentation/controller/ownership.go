@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type OwnershipController struct {
+	addOwnerCommand                 *commands.AddOwnerCommand
+	requestOwnershipTransferCommand *commands.RequestOwnershipTransferCommand
+	confirmOwnershipTransferCommand *commands.ConfirmOwnershipTransferCommand
+	leaveOrganizationCommand        *commands.LeaveOrganizationCommand
+}
+
+func NewOwnershipController(
+	addOwnerCommand *commands.AddOwnerCommand,
+	requestOwnershipTransferCommand *commands.RequestOwnershipTransferCommand,
+	confirmOwnershipTransferCommand *commands.ConfirmOwnershipTransferCommand,
+	leaveOrganizationCommand *commands.LeaveOrganizationCommand,
+) *OwnershipController {
+	return &OwnershipController{
+		addOwnerCommand,
+		requestOwnershipTransferCommand,
+		confirmOwnershipTransferCommand,
+		leaveOrganizationCommand,
+	}
+}
+
+// AddOwner makes a member an additional owner of the organization.
+func (c *OwnershipController) AddOwner(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		UserOrgId      string `json:"user_org_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1901: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	membershipId, err := c.addOwnerCommand.Execute(ctx, commands.AddOwnerRequest{
+		OrganizationId: params.OrganizationId,
+		MembershipId:   params.UserOrgId,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to add owner")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    membershipId,
+	})
+}
+
+// TransferOwnership starts a transfer of the caller's ownership. The
+// returned transfer id must be confirmed by the caller to take effect.
+func (c *OwnershipController) TransferOwnership(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		UserOrgId      string `json:"user_org_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1902: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	transferId, err := c.requestOwnershipTransferCommand.Execute(ctx, commands.RequestOwnershipTransferRequest{
+		OrganizationId: params.OrganizationId,
+		MembershipId:   params.UserOrgId,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to request ownership transfer")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    transferId,
+	})
+}
+
+// ConfirmOwnershipTransfer completes a transfer requested by the caller.
+func (c *OwnershipController) ConfirmOwnershipTransfer(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1903: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	membershipId, err := c.confirmOwnershipTransferCommand.Execute(ctx, commands.ConfirmOwnershipTransferRequest{
+		OrganizationId: params.OrganizationId,
+		TransferId:     ctx.Param("id"),
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to transfer ownership")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    membershipId,
+	})
+}
+
+// Leave removes the caller from the organization.
+func (c *OwnershipController) Leave(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1904: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	membershipId, err := c.leaveOrganizationCommand.Execute(ctx, commands.LeaveOrganizationRequest{
+		OrganizationId: params.OrganizationId,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to leave organization")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    membershipId,
+	})
+}
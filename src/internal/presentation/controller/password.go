@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// PasswordController manages the password credentials of users who sign
+// in without an external identity provider. Signing in is done by
+// AuthController.PasswordLogin.
+type PasswordController struct {
+	registerPasswordUserCommand *commands.RegisterPasswordUserCommand
+	changePasswordCommand       *commands.ChangePasswordCommand
+	requestPasswordResetCommand *commands.RequestPasswordResetCommand
+	resetPasswordCommand        *commands.ResetPasswordCommand
+
+	// resetThrottle limits how many reset emails are sent per address.
+	resetThrottle *providers.LoginThrottle
+}
+
+func NewPasswordController(
+	registerPasswordUserCommand *commands.RegisterPasswordUserCommand,
+	changePasswordCommand *commands.ChangePasswordCommand,
+	requestPasswordResetCommand *commands.RequestPasswordResetCommand,
+	resetPasswordCommand *commands.ResetPasswordCommand,
+	resetThrottle *providers.LoginThrottle,
+) *PasswordController {
+	return &PasswordController{
+		registerPasswordUserCommand,
+		changePasswordCommand,
+		requestPasswordResetCommand,
+		resetPasswordCommand,
+		resetThrottle,
+	}
+}
+
+// Register creates a user who signs in with a password.
+func (c *PasswordController) Register(ctx *gin.Context) {
+	var params struct {
+		Email    string `json:"email" binding:"required,email"`
+		Name     string `json:"name"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2301: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userId, err := c.registerPasswordUserCommand.Execute(ctx, commands.RegisterPasswordUserRequest{
+		Email:    params.Email,
+		Name:     params.Name,
+		Password: params.Password,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create user")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    userId,
+	})
+}
+
+// Change replaces the password of the signed in user.
+func (c *PasswordController) Change(ctx *gin.Context) {
+	var params struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2302: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	credentialId, err := c.changePasswordCommand.Execute(ctx, commands.ChangePasswordRequest{
+		UserId:          principal.UserId,
+		CurrentPassword: params.CurrentPassword,
+		NewPassword:     params.NewPassword,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to change password")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    credentialId,
+	})
+}
+
+// RequestReset emails a password reset link. It answers the same whether
+// or not the email has a credential.
+func (c *PasswordController) RequestReset(ctx *gin.Context) {
+	var params struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2303: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	key := "reset:" + entities.NormalizeEmail(params.Email)
+	if ok, wait := c.resetThrottle.Allow(key); !ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many password resets, try again later",
+		})
+		return
+	}
+	c.resetThrottle.Fail(key)
+
+	_, err = c.requestPasswordResetCommand.Execute(ctx, commands.RequestPasswordResetRequest{
+		Email: params.Email,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to request password reset")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "If the email has an account, a reset link was sent to it",
+	})
+}
+
+// Reset sets a new password with the token of a reset link.
+func (c *PasswordController) Reset(ctx *gin.Context) {
+	var params struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2304: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userId, err := c.resetPasswordCommand.Execute(ctx, commands.ResetPasswordRequest{
+		Token:    params.Token,
+		Password: params.Password,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to reset password")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    userId,
+	})
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// PersonalAccessTokenController lets users issue tokens to script against
+// the API on their own behalf.
+type PersonalAccessTokenController struct {
+	createPersonalAccessTokenCommand *commands.CreatePersonalAccessTokenCommand
+	revokePersonalAccessTokenCommand *commands.RevokePersonalAccessTokenCommand
+
+	patRepo repositories.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenController(
+	createPersonalAccessTokenCommand *commands.CreatePersonalAccessTokenCommand,
+	revokePersonalAccessTokenCommand *commands.RevokePersonalAccessTokenCommand,
+	patRepo repositories.PersonalAccessTokenRepository,
+) *PersonalAccessTokenController {
+	return &PersonalAccessTokenController{
+		createPersonalAccessTokenCommand,
+		revokePersonalAccessTokenCommand,
+		patRepo,
+	}
+}
+
+// List returns the tokens of the signed in user that were not revoked,
+// without the tokens themselves.
+func (c *PersonalAccessTokenController) List(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userId, err := vo.NewUserId(principal.UserId)
+	if err != nil {
+		log.Printf("Error 2601: %v", err)
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := c.patRepo.FindByUser(ctx, userId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get personal access tokens",
+		})
+		return
+	}
+
+	now := time.Now()
+	data := make([]gin.H, 0, len(tokens))
+	for _, token := range tokens {
+		organizationIds := make([]string, 0, len(token.OrganizationIds()))
+		for _, id := range token.OrganizationIds() {
+			organizationIds = append(organizationIds, id.Value())
+		}
+
+		item := gin.H{
+			"id":               token.Id().Value(),
+			"name":             token.Name(),
+			"scopes":           token.Scopes(),
+			"organization_ids": organizationIds,
+			"created_at":       token.CreatedAt().Format(time.RFC3339),
+			"expires_at":       token.ExpiresAt().Format(time.RFC3339),
+			"expired":          !token.IsActive(now),
+		}
+		if token.LastUsedAt() != nil {
+			item["last_used_at"] = token.LastUsedAt().Format(time.RFC3339)
+			item["last_used_ip"] = token.LastUsedIp()
+		}
+
+		data = append(data, item)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// Create issues a token and responds with it. It is only shown once.
+func (c *PersonalAccessTokenController) Create(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var params struct {
+		Name            string   `json:"name" binding:"required"`
+		Scopes          []string `json:"scopes" binding:"required"`
+		OrganizationIds []string `json:"organization_ids" binding:"required"`
+		ExpiresInDays   int      `json:"expires_in_days" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2602: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	token, err := c.createPersonalAccessTokenCommand.Execute(ctx, commands.CreatePersonalAccessTokenRequest{
+		UserId:          principal.UserId,
+		Name:            params.Name,
+		Scopes:          params.Scopes,
+		OrganizationIds: params.OrganizationIds,
+		ExpiresInDays:   params.ExpiresInDays,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create personal access token")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    token,
+	})
+}
+
+// Revoke revokes a token of the signed in user.
+func (c *PersonalAccessTokenController) Revoke(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokenId, err := c.revokePersonalAccessTokenCommand.Execute(ctx, commands.RevokePersonalAccessTokenRequest{
+		TokenId: ctx.Param("id"),
+		UserId:  principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to revoke personal access token")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    tokenId,
+	})
+}
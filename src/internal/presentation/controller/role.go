@@ -1,26 +1,55 @@
 package controller
 
 import (
-	"database/sql"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/app/queries"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type RoleController struct {
-	db *sql.DB
+	organizationQuery queries.OrganizationQuery
+
+	createRoleCommand               *commands.CreateRoleCommand
+	createTenantRoleCommand         *commands.CreateTenantRoleCommand
+	updateRoleCommand               *commands.UpdateRoleCommand
+	deleteRoleCommand               *commands.DeleteRoleCommand
+	attachPermissionToRoleCommand   *commands.AttachPermissionToRoleCommand
+	detachPermissionFromRoleCommand *commands.DetachPermissionFromRoleCommand
 }
 
-func NewRoleController(db *sql.DB) *RoleController {
-	return &RoleController{db}
+func NewRoleController(
+	organizationQuery queries.OrganizationQuery,
+	createRoleCommand *commands.CreateRoleCommand,
+	createTenantRoleCommand *commands.CreateTenantRoleCommand,
+	updateRoleCommand *commands.UpdateRoleCommand,
+	deleteRoleCommand *commands.DeleteRoleCommand,
+	attachPermissionToRoleCommand *commands.AttachPermissionToRoleCommand,
+	detachPermissionFromRoleCommand *commands.DetachPermissionFromRoleCommand,
+) *RoleController {
+	return &RoleController{
+		organizationQuery,
+		createRoleCommand,
+		createTenantRoleCommand,
+		updateRoleCommand,
+		deleteRoleCommand,
+		attachPermissionToRoleCommand,
+		detachPermissionFromRoleCommand,
+	}
 }
 
+// UsersWithRole returns a page of the members of the organization who
+// have the role in the tenant, with the same sorting and filters as the
+// members of the organization.
 func (c *RoleController) UsersWithRole(ctx *gin.Context) {
 	type Params struct {
 		OrganizationId string `form:"organization_id" binding:"required"`
 		TenantId       string `form:"tenant_id" binding:"required"`
 		RoleId         string `form:"role_id" binding:"required"`
+		listParams
 	}
 
 	var params Params
@@ -34,39 +63,161 @@ func (c *RoleController) UsersWithRole(ctx *gin.Context) {
 		return
 	}
 
-	rows, err := c.db.Query(`
-		select distinct uo.id, u."picture", u."name", u.email from user_organization uo 
-		left join public."user" u on u.id = uo.user_id 
-		left join user_role ur on uo.id = ur.user_org_id
-		left join tenant t on ur.tenant_id = t.id 
-		where t.id=$1 and ur.role_id =$2;`, params.TenantId, params.RoleId)
+	list := params.query()
+	list.TenantId = params.TenantId
+	list.RoleId = params.RoleId
+
+	users, err := c.organizationQuery.UsersInOrganization(ctx, params.OrganizationId, list)
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to get users")
+		return
+	}
+
+	respondPage(ctx, users)
+}
+
+// Create defines an application role, or a role owned by the tenant when
+// the request names one.
+func (c *RoleController) Create(ctx *gin.Context) {
+	var params struct {
+		ApplicationId  string `json:"application_id" binding:"required_without=TenantId,omitempty,uuid"`
+		OrganizationId string `json:"organization_id" binding:"required_with=TenantId"`
+		TenantId       string `json:"tenant_id" binding:"omitempty,uuid"`
+		Name           string `json:"name" binding:"required"`
+		Description    string `json:"description"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1411: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var roleId string
+	if params.TenantId != "" {
+		roleId, err = c.createTenantRoleCommand.Execute(ctx, commands.CreateTenantRoleRequest{
+			OrganizationId: params.OrganizationId,
+			TenantId:       params.TenantId,
+			Name:           params.Name,
+			Description:    params.Description,
+		})
+	} else {
+		roleId, err = c.createRoleCommand.Execute(ctx, commands.CreateRoleRequest{
+			ApplicationId: params.ApplicationId,
+			Name:          params.Name,
+			Description:   params.Description,
+		})
+	}
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create role")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    roleId,
+	})
+}
+
+func (c *RoleController) Update(ctx *gin.Context) {
+	var params struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1412: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	roleId, err := c.updateRoleCommand.Execute(ctx, commands.UpdateRoleRequest{
+		RoleId:      ctx.Param("id"),
+		Name:        params.Name,
+		Description: params.Description,
+		TenantId:    tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to update role")
+		return
+	}
 
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    roleId,
+	})
+}
+
+func (c *RoleController) Delete(ctx *gin.Context) {
+	roleId, err := c.deleteRoleCommand.Execute(ctx, commands.DeleteRoleRequest{
+		RoleId:   ctx.Param("id"),
+		TenantId: tenantScope(ctx),
+	})
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.String(http.StatusInternalServerError, "Failed to get users")
+		respondCommandError(ctx, err, "Failed to delete role")
 		return
 	}
 
-	type User struct {
-		UserOrgId string `json:"user_org_id"`
-		Picture   string `json:"picture"`
-		Name      string `json:"name"`
-		Email     string `json:"email"`
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    roleId,
+	})
+}
+
+func (c *RoleController) AttachPermission(ctx *gin.Context) {
+	var params struct {
+		PermissionId string `json:"permission_id" binding:"required,uuid"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 1413: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
-	var users []User = make([]User, 0)
 
-	for rows.Next() {
-		var u User
+	roleId, err := c.attachPermissionToRoleCommand.Execute(ctx, commands.AttachPermissionToRoleRequest{
+		RoleId:       ctx.Param("id"),
+		PermissionId: params.PermissionId,
+		TenantId:     tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to attach permission")
+		return
+	}
 
-		err = rows.Scan(&u.UserOrgId, &u.Picture, &u.Name, &u.Email)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			ctx.String(http.StatusInternalServerError, "Failed to get users")
-			return
-		}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    roleId,
+	})
+}
 
-		users = append(users, u)
+func (c *RoleController) DetachPermission(ctx *gin.Context) {
+	roleId, err := c.detachPermissionFromRoleCommand.Execute(ctx, commands.DetachPermissionFromRoleRequest{
+		RoleId:       ctx.Param("id"),
+		PermissionId: ctx.Param("permission_id"),
+		TenantId:     tenantScope(ctx),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to detach permission")
+		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    roleId,
+	})
 }
@@ -0,0 +1,667 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/app/queries"
+	"iyaem/internal/app/scim"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scimMaxResults is the most resources returned in a page, and the page
+// size when the client does not ask for one.
+const scimMaxResults = 100
+
+// ScimController serves the SCIM 2.0 API provisioning clients use to
+// manage the members of an organization, and the groups of a tenant when
+// their token is bound to one.
+type ScimController struct {
+	provisionScimUserCommand     *commands.ProvisionScimUserCommand
+	removeMemberCommand          *commands.RemoveMemberCommand
+	createTenantGroupCommand     *commands.CreateTenantGroupCommand
+	updateGroupCommand           *commands.UpdateGroupCommand
+	deleteGroupCommand           *commands.DeleteGroupCommand
+	addGroupToMemberCommand      *commands.AddGroupToMemberCommand
+	removeGroupFromMemberCommand *commands.RemoveGroupFromMemberCommand
+
+	orgQuery   queries.OrganizationQuery
+	groupQuery queries.GroupQuery
+
+	// baseUrl prefixes the location of resources.
+	baseUrl string
+}
+
+func NewScimController(
+	provisionScimUserCommand *commands.ProvisionScimUserCommand,
+	removeMemberCommand *commands.RemoveMemberCommand,
+	createTenantGroupCommand *commands.CreateTenantGroupCommand,
+	updateGroupCommand *commands.UpdateGroupCommand,
+	deleteGroupCommand *commands.DeleteGroupCommand,
+	addGroupToMemberCommand *commands.AddGroupToMemberCommand,
+	removeGroupFromMemberCommand *commands.RemoveGroupFromMemberCommand,
+	orgQuery queries.OrganizationQuery,
+	groupQuery queries.GroupQuery,
+	baseUrl string,
+) *ScimController {
+	return &ScimController{
+		provisionScimUserCommand,
+		removeMemberCommand,
+		createTenantGroupCommand,
+		updateGroupCommand,
+		deleteGroupCommand,
+		addGroupToMemberCommand,
+		removeGroupFromMemberCommand,
+		orgQuery,
+		groupQuery,
+		strings.TrimSuffix(baseUrl, "/") + "/scim/v2",
+	}
+}
+
+func (c *ScimController) ServiceProviderConfig(ctx *gin.Context) {
+	supported := func(supported bool) gin.H { return gin.H{"supported": supported} }
+
+	respondScim(ctx, http.StatusOK, gin.H{
+		"schemas":        []string{scim.ServiceProviderConfigSchema},
+		"patch":          supported(true),
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "A SCIM token issued to the organization",
+		}},
+	})
+}
+
+func (c *ScimController) ResourceTypes(ctx *gin.Context) {
+	resourceType := func(name string, endpoint string, schema string) gin.H {
+		return gin.H{
+			"schemas":  []string{scim.ResourceTypeSchema},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+		}
+	}
+
+	respondScim(ctx, http.StatusOK, scim.NewListResponse([]interface{}{
+		resourceType("User", "/Users", scim.UserSchema),
+		resourceType("Group", "/Groups", scim.GroupSchema),
+	}, 1, 2))
+}
+
+// ListUsers pages through the members matching the filter. A lookup by
+// userName, which clients send before provisioning a user, is served by
+// the email of the member.
+func (c *ScimController) ListUsers(ctx *gin.Context) {
+	client := scimClient(ctx)
+
+	filter, err := scim.ParseFilter(ctx.Query("filter"), "username", "displayname", "active", "emails", "emails.value")
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	startIndex, count := page(ctx)
+
+	if email, ok := filter.Lookup("username", "emails", "emails.value"); ok {
+		member, err := c.orgQuery.FindMemberByEmail(ctx, client.OrganizationId, email)
+		if err != nil {
+			respondScimError(ctx, err)
+			return
+		}
+
+		resources := make([]interface{}, 0, 1)
+		if member != nil {
+			resources = append(resources, c.user(*member))
+		}
+
+		respondScim(ctx, http.StatusOK, scim.NewListResponse(resources, startIndex, count))
+		return
+	}
+
+	members, total, err := c.orgQuery.ScimUsers(ctx, client.OrganizationId, filter, startIndex, count)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	resources := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		resources = append(resources, c.user(member))
+	}
+
+	respondScim(ctx, http.StatusOK, scim.NewPagedListResponse(resources, total, startIndex))
+}
+
+func (c *ScimController) GetUser(ctx *gin.Context) {
+	member, err := c.findMember(ctx, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	respondScim(ctx, http.StatusOK, c.user(*member))
+}
+
+func (c *ScimController) CreateUser(ctx *gin.Context) {
+	var user scim.User
+	if err := ctx.ShouldBindJSON(&user); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	if !user.IsActive() {
+		respondScimError(ctx, fmt.Errorf("%w: inactive users are not provisioned", scim.ErrInvalidValue))
+		return
+	}
+
+	userId, err := c.provisionScimUserCommand.Execute(ctx, commands.ProvisionScimUserRequest{
+		OrganizationId: scimClient(ctx).OrganizationId,
+		Email:          user.Email(),
+		Name:           user.FullName(),
+	})
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	c.respondUser(ctx, http.StatusCreated, userId)
+}
+
+// ReplaceUser applies the active attribute of the user: inactive users are
+// deprovisioned. The name and email belong to the user's account, which
+// may be shared with other organizations, and are not changed. Users who
+// are not members are not found; they are provisioned with CreateUser.
+func (c *ScimController) ReplaceUser(ctx *gin.Context) {
+	var user scim.User
+	if err := ctx.ShouldBindJSON(&user); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	member, err := c.findMember(ctx, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	c.updateUser(ctx, member, user)
+}
+
+func (c *ScimController) PatchUser(ctx *gin.Context) {
+	var patch scim.PatchRequest
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	member, err := c.findMember(ctx, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	user := c.user(*member)
+	err = patch.ApplyToUser(&user)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	c.updateUser(ctx, member, user)
+}
+
+func (c *ScimController) updateUser(ctx *gin.Context, member *queries.User, user scim.User) {
+	if user.IsActive() {
+		respondScim(ctx, http.StatusOK, c.user(*member))
+		return
+	}
+
+	_, err := c.removeMemberCommand.Execute(ctx, commands.RemoveMemberRequest{
+		OrganizationId: scimClient(ctx).OrganizationId,
+		MembershipId:   member.UserOrgId,
+	})
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	deprovisioned := c.user(*member)
+	deprovisioned.Active = user.Active
+	respondScim(ctx, http.StatusOK, deprovisioned)
+}
+
+// DeleteUser deprovisions the user, who keeps the account but is no
+// longer a member of the organization.
+func (c *ScimController) DeleteUser(ctx *gin.Context) {
+	member, err := c.findMember(ctx, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	_, err = c.removeMemberCommand.Execute(ctx, commands.RemoveMemberRequest{
+		OrganizationId: scimClient(ctx).OrganizationId,
+		MembershipId:   member.UserOrgId,
+	})
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ScimController) ListGroups(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	filter, err := scim.ParseFilter(ctx.Query("filter"), "displayname", "members", "members.value")
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	groups, err := c.groupQuery.TenantGroups(ctx, client.TenantId)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	withoutMembers := strings.Contains(strings.ToLower(ctx.Query("excludedAttributes")), "members")
+
+	resources := make([]interface{}, 0, len(groups))
+	for _, tenantGroup := range groups {
+		group := c.group(tenantGroup)
+		if !filter.Matches(group.Attributes()) {
+			continue
+		}
+
+		if withoutMembers {
+			group.Members = nil
+		}
+		resources = append(resources, group)
+	}
+
+	startIndex, count := page(ctx)
+	respondScim(ctx, http.StatusOK, scim.NewListResponse(resources, startIndex, count))
+}
+
+func (c *ScimController) GetGroup(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	group, err := c.findGroup(ctx, client, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	respondScim(ctx, http.StatusOK, c.group(*group))
+}
+
+func (c *ScimController) CreateGroup(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	var group scim.Group
+	if err := ctx.ShouldBindJSON(&group); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	groupId, err := c.createTenantGroupCommand.Execute(ctx, commands.CreateTenantGroupRequest{
+		OrganizationId: client.OrganizationId,
+		TenantId:       client.TenantId,
+		Name:           group.DisplayName,
+	})
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	err = c.setMembers(ctx, client, groupId, nil, group.MemberIds())
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	c.respondGroup(ctx, http.StatusCreated, client, groupId)
+}
+
+func (c *ScimController) ReplaceGroup(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	var group scim.Group
+	if err := ctx.ShouldBindJSON(&group); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	c.updateGroup(ctx, client, ctx.Param("id"), func(*scim.Group) (scim.Group, error) {
+		return group, nil
+	})
+}
+
+func (c *ScimController) PatchGroup(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	var patch scim.PatchRequest
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		respondScimError(ctx, fmt.Errorf("%w: %v", scim.ErrInvalidValue, err))
+		return
+	}
+
+	c.updateGroup(ctx, client, ctx.Param("id"), func(current *scim.Group) (scim.Group, error) {
+		group := *current
+		group.Members = append([]scim.Member(nil), current.Members...)
+
+		err := patch.ApplyToGroup(&group)
+		return group, err
+	})
+}
+
+// updateGroup renames the group and changes its members to those of the
+// group returned by change.
+func (c *ScimController) updateGroup(ctx *gin.Context, client *providers.ScimClient, groupId string, change func(*scim.Group) (scim.Group, error)) {
+	tenantGroup, err := c.findGroup(ctx, client, groupId)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	current := c.group(*tenantGroup)
+
+	group, err := change(&current)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	if group.DisplayName != current.DisplayName {
+		_, err = c.updateGroupCommand.Execute(ctx, commands.UpdateGroupRequest{
+			GroupId:     groupId,
+			Name:        group.DisplayName,
+			Description: tenantGroup.Description,
+			TenantId:    client.TenantId,
+		})
+		if err != nil {
+			respondScimError(ctx, err)
+			return
+		}
+	}
+
+	err = c.setMembers(ctx, client, groupId, current.MemberIds(), group.MemberIds())
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	c.respondGroup(ctx, http.StatusOK, client, groupId)
+}
+
+func (c *ScimController) DeleteGroup(ctx *gin.Context) {
+	client, ok := scimTenantClient(ctx)
+	if !ok {
+		return
+	}
+
+	_, err := c.findGroup(ctx, client, ctx.Param("id"))
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	_, err = c.deleteGroupCommand.Execute(ctx, commands.DeleteGroupRequest{
+		GroupId:  ctx.Param("id"),
+		TenantId: client.TenantId,
+	})
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// setMembers adds the group to the members in desired but not in current,
+// and removes it from those in current but not in desired. Members are
+// identified by their user id.
+func (c *ScimController) setMembers(ctx *gin.Context, client *providers.ScimClient, groupId string, current []string, desired []string) error {
+	for _, userId := range desired {
+		if contains(current, userId) {
+			continue
+		}
+
+		member, err := c.findMember(ctx, userId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return fmt.Errorf("%w: user %s is not provisioned", scim.ErrInvalidValue, userId)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = c.addGroupToMemberCommand.Execute(ctx, commands.AddGroupToMemberRequest{
+			OrganizationId: client.OrganizationId,
+			MembershipId:   member.UserOrgId,
+			GroupId:        groupId,
+			TenantId:       client.TenantId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, userId := range current {
+		if contains(desired, userId) {
+			continue
+		}
+
+		member, err := c.findMember(ctx, userId)
+		if errors.Is(err, entities.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = c.removeGroupFromMemberCommand.Execute(ctx, commands.RemoveGroupFromMemberRequest{
+			OrganizationId: client.OrganizationId,
+			MembershipId:   member.UserOrgId,
+			GroupId:        groupId,
+			TenantId:       client.TenantId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findMember finds the member of the client's organization with the user
+// id. Ids that are not user ids cannot belong to a member.
+func (c *ScimController) findMember(ctx *gin.Context, userId string) (*queries.User, error) {
+	if _, err := vo.NewUserId(userId); err != nil {
+		return nil, fmt.Errorf("could not find user %s: %w", userId, entities.ErrNotFound)
+	}
+
+	member, err := c.orgQuery.FindMember(ctx, scimClient(ctx).OrganizationId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("could not find user %s: %w", userId, entities.ErrNotFound)
+	}
+
+	return member, nil
+}
+
+func (c *ScimController) findGroup(ctx *gin.Context, client *providers.ScimClient, groupId string) (*queries.TenantGroup, error) {
+	groups, err := c.groupQuery.TenantGroups(ctx, client.TenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if group.Id == groupId {
+			return &group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find group %s: %w", groupId, entities.ErrNotFound)
+}
+
+func (c *ScimController) respondUser(ctx *gin.Context, status int, userId string) {
+	member, err := c.findMember(ctx, userId)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	respondScim(ctx, status, c.user(*member))
+}
+
+func (c *ScimController) respondGroup(ctx *gin.Context, status int, client *providers.ScimClient, groupId string) {
+	group, err := c.findGroup(ctx, client, groupId)
+	if err != nil {
+		respondScimError(ctx, err)
+		return
+	}
+
+	respondScim(ctx, status, c.group(*group))
+}
+
+func (c *ScimController) user(member queries.User) scim.User {
+	active := true
+
+	return scim.User{
+		Schemas:     []string{scim.UserSchema},
+		Id:          member.UserId,
+		UserName:    member.Email,
+		Name:        &scim.Name{Formatted: member.Name},
+		DisplayName: member.Name,
+		Emails:      []scim.Email{{Value: member.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta:        &scim.Meta{ResourceType: "User", Location: c.baseUrl + "/Users/" + member.UserId},
+	}
+}
+
+func (c *ScimController) group(group queries.TenantGroup) scim.Group {
+	members := make([]scim.Member, 0, len(group.UserIds))
+	for _, userId := range group.UserIds {
+		members = append(members, scim.Member{Value: userId})
+	}
+
+	return scim.Group{
+		Schemas:     []string{scim.GroupSchema},
+		Id:          group.Id,
+		DisplayName: group.Name,
+		Members:     members,
+		Meta:        &scim.Meta{ResourceType: "Group", Location: c.baseUrl + "/Groups/" + group.Id},
+	}
+}
+
+func scimClient(ctx *gin.Context) *providers.ScimClient {
+	client, _ := providers.GetScimClient(ctx)
+	return client
+}
+
+// scimTenantClient returns the client of a request for groups, which only
+// clients bound to a tenant can manage.
+func scimTenantClient(ctx *gin.Context) (*providers.ScimClient, bool) {
+	client := scimClient(ctx)
+	if client.TenantId == "" {
+		respondScim(ctx, http.StatusForbidden, scim.NewError(http.StatusForbidden, "", "the token is not bound to a tenant"))
+		return nil, false
+	}
+
+	return client, true
+}
+
+// page returns the startIndex and count query parameters.
+func page(ctx *gin.Context) (int, int) {
+	startIndex, err := strconv.Atoi(ctx.Query("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(ctx.Query("count"))
+	if err != nil || count > scimMaxResults {
+		count = scimMaxResults
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	return startIndex, count
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func respondScim(ctx *gin.Context, status int, body interface{}) {
+	ctx.Header("Content-Type", "application/scim+json")
+	ctx.JSON(status, body)
+}
+
+// respondScimError maps an error to a SCIM error response. Errors that are
+// not caused by the request are logged and hidden.
+func respondScimError(ctx *gin.Context, err error) {
+	status, scimType := http.StatusInternalServerError, ""
+
+	switch {
+	case errors.Is(err, scim.ErrInvalidFilter):
+		status, scimType = http.StatusBadRequest, "invalidFilter"
+	case errors.Is(err, scim.ErrInvalidPath):
+		status, scimType = http.StatusBadRequest, "invalidPath"
+	case errors.Is(err, entities.ErrInvalid):
+		status, scimType = http.StatusBadRequest, "invalidValue"
+	case errors.Is(err, entities.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, entities.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, entities.ErrConflict):
+		status, scimType = http.StatusConflict, "uniqueness"
+	}
+
+	detail := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("Error 2201: %v", err)
+		detail = "Internal Server Error"
+	}
+
+	respondScim(ctx, status, scim.NewError(status, scimType, detail))
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/repositories"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type ScimTokenController struct {
+	createScimTokenCommand *commands.CreateScimTokenCommand
+	revokeScimTokenCommand *commands.RevokeScimTokenCommand
+
+	scimRepo repositories.ScimTokenRepository
+}
+
+func NewScimTokenController(
+	createScimTokenCommand *commands.CreateScimTokenCommand,
+	revokeScimTokenCommand *commands.RevokeScimTokenCommand,
+	scimRepo repositories.ScimTokenRepository,
+) *ScimTokenController {
+	return &ScimTokenController{
+		createScimTokenCommand,
+		revokeScimTokenCommand,
+		scimRepo,
+	}
+}
+
+// List returns the SCIM tokens of the organization, without the tokens
+// themselves.
+func (c *ScimTokenController) List(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 2202: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tokens, err := c.scimRepo.FindByOrganization(ctx, params.OrganizationId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get SCIM tokens",
+		})
+		return
+	}
+
+	type Token struct {
+		Id          string     `json:"id"`
+		TenantId    string     `json:"tenant_id,omitempty"`
+		Description string     `json:"description"`
+		CreatedAt   time.Time  `json:"created_at"`
+		RevokedAt   *time.Time `json:"revoked_at"`
+	}
+
+	data := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		t := Token{
+			Id:          token.Id().Value(),
+			Description: token.Description(),
+			CreatedAt:   token.CreatedAt(),
+			RevokedAt:   token.RevokedAt(),
+		}
+		if token.TenantId() != nil {
+			t.TenantId = token.TenantId().Value()
+		}
+
+		data = append(data, t)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// Create issues a SCIM token. The token is only returned in this response.
+func (c *ScimTokenController) Create(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		TenantId       string `json:"tenant_id"`
+		Description    string `json:"description" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2203: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := c.createScimTokenCommand.Execute(ctx, commands.CreateScimTokenRequest{
+		OrganizationId: params.OrganizationId,
+		TenantId:       params.TenantId,
+		Description:    params.Description,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create SCIM token")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data": gin.H{
+			"id":    response.TokenId,
+			"token": response.Token,
+		},
+	})
+}
+
+func (c *ScimTokenController) Revoke(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2204: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tokenId, err := c.revokeScimTokenCommand.Execute(ctx, commands.RevokeScimTokenRequest{
+		OrganizationId: params.OrganizationId,
+		TokenId:        ctx.Param("id"),
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to revoke SCIM token")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    tokenId,
+	})
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/repositories"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController lets users see where they are signed in and sign out
+// devices.
+type SessionController struct {
+	revokeSessionCommand     *commands.RevokeSessionCommand
+	revokeAllSessionsCommand *commands.RevokeAllSessionsCommand
+
+	sessionRepo repositories.SessionRepository
+}
+
+func NewSessionController(
+	revokeSessionCommand *commands.RevokeSessionCommand,
+	revokeAllSessionsCommand *commands.RevokeAllSessionsCommand,
+	sessionRepo repositories.SessionRepository,
+) *SessionController {
+	return &SessionController{
+		revokeSessionCommand,
+		revokeAllSessionsCommand,
+		sessionRepo,
+	}
+}
+
+// List returns the active sessions of the signed in user, marking the one
+// making the request as current.
+func (c *SessionController) List(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userId, err := vo.NewUserId(principal.UserId)
+	if err != nil {
+		log.Printf("Error 2401: %v", err)
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessions, err := c.sessionRepo.FindActiveByUser(ctx, userId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get sessions",
+		})
+		return
+	}
+
+	data := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, gin.H{
+			"id":           session.Id().Value(),
+			"user_agent":   session.UserAgent(),
+			"ip_address":   session.IpAddress(),
+			"created_at":   session.CreatedAt().Format(time.RFC3339),
+			"last_used_at": session.LastUsedAt().Format(time.RFC3339),
+			"expires_at":   session.ExpiresAt().Format(time.RFC3339),
+			"current":      session.Id().Value() == principal.SessionId,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// Revoke signs out one session of the user, which may be the current one.
+func (c *SessionController) Revoke(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionId, err := c.revokeSessionCommand.Execute(ctx, commands.RevokeSessionRequest{
+		SessionId: ctx.Param("id"),
+		UserId:    principal.UserId,
+		Reason:    entities.SessionRevokedByUser,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to revoke session")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    sessionId,
+	})
+}
+
+// RevokeAll signs out every other session of the user. With
+// include_current=true the current session is signed out too.
+func (c *SessionController) RevokeAll(ctx *gin.Context) {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	except := principal.SessionId
+	if ctx.Query("include_current") == "true" {
+		except = ""
+	}
+
+	sessionIds, err := c.revokeAllSessionsCommand.Execute(ctx, commands.RevokeAllSessionsRequest{
+		UserId:          principal.UserId,
+		ExceptSessionId: except,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to revoke sessions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    sessionIds,
+	})
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"iyaem/internal/app/commands"
+	"iyaem/internal/domain/repositories"
+	"iyaem/internal/providers"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type SsoController struct {
+	createSsoConnectionCommand *commands.CreateSsoConnectionCommand
+	updateSsoConnectionCommand *commands.UpdateSsoConnectionCommand
+	deleteSsoConnectionCommand *commands.DeleteSsoConnectionCommand
+	verifySsoDomainCommand     *commands.VerifySsoDomainCommand
+	confirmIdentityLinkCommand *commands.ConfirmIdentityLinkCommand
+
+	ssoRepo repositories.SsoConnectionRepository
+}
+
+func NewSsoController(
+	createSsoConnectionCommand *commands.CreateSsoConnectionCommand,
+	updateSsoConnectionCommand *commands.UpdateSsoConnectionCommand,
+	deleteSsoConnectionCommand *commands.DeleteSsoConnectionCommand,
+	verifySsoDomainCommand *commands.VerifySsoDomainCommand,
+	confirmIdentityLinkCommand *commands.ConfirmIdentityLinkCommand,
+	ssoRepo repositories.SsoConnectionRepository,
+) *SsoController {
+	return &SsoController{
+		createSsoConnectionCommand,
+		updateSsoConnectionCommand,
+		deleteSsoConnectionCommand,
+		verifySsoDomainCommand,
+		confirmIdentityLinkCommand,
+		ssoRepo,
+	}
+}
+
+// Connection returns the SSO connection of the organization, with the DNS
+// record that verifies each domain. The client secret is never returned.
+func (c *SsoController) Connection(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `form:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		log.Printf("Error 2101: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	connection, err := c.ssoRepo.FindByOrganization(ctx, params.OrganizationId)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to get SSO connection",
+		})
+		return
+	}
+	if connection == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "The organization has no SSO connection",
+		})
+		return
+	}
+
+	domains := make([]gin.H, 0)
+	for _, domain := range connection.DomainVerifications() {
+		domains = append(domains, gin.H{
+			"domain":           domain.Name,
+			"verified":         domain.IsVerified(),
+			"txt_record_name":  domain.TxtRecordName(),
+			"txt_record_value": domain.TxtRecordValue(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data": gin.H{
+			"id":         connection.Id().Value(),
+			"protocol":   connection.Protocol(),
+			"issuer_url": connection.IssuerUrl(),
+			"client_id":  connection.ClientId(),
+			"domains":    domains,
+		},
+	})
+}
+
+func (c *SsoController) Create(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string   `json:"organization_id" binding:"required"`
+		Protocol       string   `json:"protocol" binding:"omitempty,oneof=oidc saml"`
+		IssuerUrl      string   `json:"issuer_url" binding:"required"`
+		ClientId       string   `json:"client_id" binding:"required"`
+		ClientSecret   string   `json:"client_secret" binding:"required"`
+		Domains        []string `json:"domains" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2102: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	connectionId, err := c.createSsoConnectionCommand.Execute(ctx, commands.CreateSsoConnectionRequest{
+		OrganizationId: params.OrganizationId,
+		Protocol:       params.Protocol,
+		IssuerUrl:      params.IssuerUrl,
+		ClientId:       params.ClientId,
+		ClientSecret:   params.ClientSecret,
+		Domains:        params.Domains,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to create SSO connection")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    connectionId,
+	})
+}
+
+// Update changes the SSO connection of the organization. Leaving out the
+// client secret keeps the current one.
+func (c *SsoController) Update(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string   `json:"organization_id" binding:"required"`
+		IssuerUrl      string   `json:"issuer_url" binding:"required"`
+		ClientId       string   `json:"client_id" binding:"required"`
+		ClientSecret   string   `json:"client_secret"`
+		Domains        []string `json:"domains" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2103: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	connectionId, err := c.updateSsoConnectionCommand.Execute(ctx, commands.UpdateSsoConnectionRequest{
+		OrganizationId: params.OrganizationId,
+		IssuerUrl:      params.IssuerUrl,
+		ClientId:       params.ClientId,
+		ClientSecret:   params.ClientSecret,
+		Domains:        params.Domains,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to update SSO connection")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    connectionId,
+	})
+}
+
+func (c *SsoController) Delete(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2104: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	connectionId, err := c.deleteSsoConnectionCommand.Execute(ctx, commands.DeleteSsoConnectionRequest{
+		OrganizationId: params.OrganizationId,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to delete SSO connection")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    connectionId,
+	})
+}
+
+// VerifyDomain checks the DNS record of a domain of the SSO connection.
+// Logins are only routed by verified domains.
+func (c *SsoController) VerifyDomain(ctx *gin.Context) {
+	var params struct {
+		OrganizationId string `json:"organization_id" binding:"required"`
+		Domain         string `json:"domain" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2105: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	connectionId, err := c.verifySsoDomainCommand.Execute(ctx, commands.VerifySsoDomainRequest{
+		OrganizationId: params.OrganizationId,
+		Domain:         params.Domain,
+		ActorUserId:    principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to verify domain")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    connectionId,
+	})
+}
+
+// ConfirmLink links the SSO identity of a login that matched an existing
+// account, with the token returned by that login. The user must be
+// signed in to the existing account.
+func (c *SsoController) ConfirmLink(ctx *gin.Context) {
+	var params struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	err := ctx.ShouldBindBodyWith(&params, binding.JSON)
+	if err != nil {
+		log.Printf("Error 2106: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userId, err := c.confirmIdentityLinkCommand.Execute(ctx, commands.ConfirmIdentityLinkRequest{
+		Token:       params.Token,
+		ActorUserId: principal.UserId,
+	})
+	if err != nil {
+		respondCommandError(ctx, err, "Failed to link identity")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "success",
+		"data":    userId,
+	})
+}
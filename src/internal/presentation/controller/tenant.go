@@ -2,6 +2,7 @@ package controller
 
 import (
 	"database/sql"
+	"iyaem/internal/app/queries"
 	"log"
 	"net/http"
 
@@ -10,10 +11,13 @@ import (
 
 type TenantController struct {
 	db *sql.DB
+
+	roleQuery  queries.RoleQuery
+	groupQuery queries.GroupQuery
 }
 
-func NewTenantController(db *sql.DB) *TenantController {
-	return &TenantController{db}
+func NewTenantController(db *sql.DB, roleQuery queries.RoleQuery, groupQuery queries.GroupQuery) *TenantController {
+	return &TenantController{db, roleQuery, groupQuery}
 }
 
 func (c *TenantController) TenantList(ctx *gin.Context) {
@@ -65,9 +69,12 @@ func (c *TenantController) TenantList(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, users)
 }
 
+// Roles returns a page of the roles available in the tenant, sorted and
+// filtered by name.
 func (c *TenantController) Roles(ctx *gin.Context) {
 	type Params struct {
 		TenantId string `form:"tenant_id" binding:"required"`
+		listParams
 	}
 
 	var params Params
@@ -81,59 +88,23 @@ func (c *TenantController) Roles(ctx *gin.Context) {
 		return
 	}
 
-	rows, err := c.db.Query(`
-		SELECT role.id, role.name, role.description, p."name" as permission  FROM role
-		LEFT JOIN role_permission rp on role.id = rp.role_id
-		LEFT JOIN "permission" p on rp.permission_id = p.id 
-		LEFT JOIN tenant t on role.application_id = t.app_id
-		WHERE t.id = $1;`, params.TenantId)
-
+	roles, err := c.roleQuery.RolesInTenant(ctx, params.TenantId, params.query())
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.String(http.StatusInternalServerError, "Failed to get roles")
+		respondCommandError(ctx, err, "Failed to get roles")
 		return
 	}
 
-	type Role struct {
-		Id          string   `json:"id"`
-		Name        string   `json:"name"`
-		RoleDesc    string   `json:"description"`
-		Permissions []string `json:"permissions"`
-	}
-	var roles []Role = make([]Role, 0)
-
-	var prevRole Role
-	for rows.Next() {
-		var r Role
-		var permName string
-
-		err = rows.Scan(&r.Id, &r.Name, &r.RoleDesc, &permName)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			ctx.String(http.StatusInternalServerError, "Failed to get roles")
-			return
-		}
-
-		if prevRole.Id != r.Id {
-			r.Permissions = []string{permName}
-			roles = append(roles, r)
-
-			prevRole = r
-		} else {
-			roles[len(roles)-1].Permissions = append(roles[len(roles)-1].Permissions, permName)
-		}
-	}
-
-	ctx.JSON(http.StatusOK, roles)
+	respondPage(ctx, roles)
 }
 
+// Groups returns a page of the groups available in the tenant, sorted and
+// filtered by name.
 func (c *TenantController) Groups(ctx *gin.Context) {
 	type Params struct {
 		TenantId string `form:"tenant_id" binding:"required"`
+		listParams
 	}
 
-	log.Printf("Tenant ID: %v", ctx.Query("tenant_id"))
-
 	var params Params
 
 	err := ctx.ShouldBindQuery(&params)
@@ -145,48 +116,11 @@ func (c *TenantController) Groups(ctx *gin.Context) {
 		return
 	}
 
-	rows, err := c.db.Query(`
-		SELECT g.id, g."name", g.description, r."name" FROM "group" g 
-		LEFT JOIN group_role gr on g.id = gr.group_id 
-		LEFT JOIN "role" r on gr.role_id = r.id 
-		LEFT JOIN tenant t on g.application_id = t.app_id
-		WHERE t.id=$1;`, params.TenantId)
-
+	groups, err := c.groupQuery.GroupsInTenant(ctx, params.TenantId, params.query())
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.String(http.StatusInternalServerError, "Failed to get groups")
+		respondCommandError(ctx, err, "Failed to get groups")
 		return
 	}
 
-	type Group struct {
-		Id        string   `json:"id"`
-		Name      string   `json:"name"`
-		GroupDesc string   `json:"description"`
-		Roles     []string `json:"roles"`
-	}
-	var groups []Group = make([]Group, 0)
-
-	var prevGroup Group
-	for rows.Next() {
-		var g Group
-		var roleName string
-
-		err = rows.Scan(&g.Id, &g.Name, &g.GroupDesc, &roleName)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			ctx.String(http.StatusInternalServerError, "Failed to get groups")
-			return
-		}
-
-		if prevGroup.Id != g.Id {
-			g.Roles = []string{roleName}
-			groups = append(groups, g)
-
-			prevGroup = g
-		} else {
-			groups[len(groups)-1].Roles = append(groups[len(groups)-1].Roles, roleName)
-		}
-	}
-
-	ctx.JSON(http.StatusOK, groups)
+	respondPage(ctx, groups)
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"iyaem/internal/app/commands"
 	"iyaem/internal/app/queries"
+	"iyaem/internal/providers"
 	"log"
 	"net/http"
 
@@ -56,16 +57,15 @@ func (c *UserController) UserLevel(ctx *gin.Context) {
 		ctx.Error(err)
 	}
 
-	token := GetBearerToken(ctx)
-	claims, err := DecodeJWT(token)
-	if err != nil {
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"message": "Unauthorized",
 		})
 		return
 	}
 
-	level, err := c.userQuery.UserLevel(ctx, claims["email"].(string), params.OrganizationId)
+	level, err := c.userQuery.UserLevel(ctx, principal.Email, params.OrganizationId)
 	if err != nil {
 		log.Printf("Error: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -252,13 +252,6 @@ func (c *UserController) UserGroups(ctx *gin.Context) {
 }
 
 func (c *UserController) DoesUserExist(ctx *gin.Context) {
-	authorizationHeader := ctx.Request.Header.Get("Authorization")
-
-	if authorizationHeader == "" {
-		ctx.String(http.StatusUnauthorized, "Unauthorized")
-		return
-	}
-
 	type Params struct {
 		Email string `form:"email" binding:"required"`
 	}
@@ -275,16 +268,6 @@ func (c *UserController) DoesUserExist(ctx *gin.Context) {
 		return
 	}
 
-	token := authorizationHeader[len("Bearer "):]
-	_, err = DecodeJWT(token)
-	if err != nil {
-		log.Print(err)
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"message": "Unauthorized",
-		})
-		return
-	}
-
 	var userExists bool
 	row := c.db.QueryRow(`
 		SELECT EXISTS(
@@ -508,11 +491,7 @@ func (c *UserController) Promote(ctx *gin.Context) {
 	}
 	membershipId, err := c.promoteUserCommand.Execute(ctx, req)
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+		respondCommandError(ctx, err, "Failed to promote user")
 		return
 	}
 
@@ -538,17 +517,20 @@ func (c *UserController) Demote(ctx *gin.Context) {
 		return
 	}
 
+	principal, ok := providers.GetPrincipal(ctx)
+	if !ok {
+		ctx.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	req := commands.DemoteUserRequest{
 		OrganizationId: params.OrganizationId,
 		MembershipId:   params.UserOrgId,
+		ActorUserId:    principal.UserId,
 	}
 	membershipId, err := c.demoteUserCommand.Execute(ctx, req)
 	if err != nil {
-		log.Printf("Error: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+		respondCommandError(ctx, err, "Failed to demote user")
 		return
 	}
 
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"database/sql"
+	"iyaem/internal/app/commands"
+	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/infrastructure/jobs"
+	"iyaem/internal/infrastructure/listeners"
+	"iyaem/internal/providers"
+)
+
+// NewJobRunner returns the runner of the background jobs queued by the
+// router and the listeners.
+func NewJobRunner(idp providers.IdentityProvider, db *sql.DB) *jobs.Runner {
+	orgRepo := postgresql.NewOrganizationRepository(db)
+
+	jobRepo := postgresql.NewJobRepository(db)
+	runner := jobs.NewRunner(jobRepo, jobs.DefaultRunnerConfig())
+
+	listeners.NewJobHandlers(
+		commands.NewRunMemberImportCommand(
+			postgresql.NewMemberImportRepository(db, jobs.NewQueue(jobRepo)),
+			orgRepo,
+			postgresql.NewUserRepository(db),
+			postgresql.NewRoleRepository(db),
+			postgresql.NewGroupRepository(db),
+		),
+		commands.NewAddTenantCommand(orgRepo),
+		idp,
+	).Register(runner)
+
+	return runner
+}
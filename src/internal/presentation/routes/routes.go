@@ -1,40 +1,121 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
+	"iyaem/internal/app/authorization"
 	"iyaem/internal/app/commands"
 	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/infrastructure/jobs"
 	"iyaem/internal/presentation/controller"
 	"iyaem/internal/providers"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(auth *providers.Authenticator, db *sql.DB) *gin.Engine {
+// Scopes that machine clients can be granted.
+const (
+	scopeOrganizationsRead = "organizations:read"
+	scopeAuthorize         = "authorize"
+	scopeApplicationsWrite = "applications:write"
+)
+
+func NewRouter(idp providers.IdentityProvider, keys *providers.KeyStore, secrets *providers.SecretBox, db *sql.DB) *gin.Engine {
 	r := gin.Default()
+	// Lets handlers pass the request context, which carries the audit
+	// actor, to commands through the gin context.
+	r.ContextWithFallback = true
+
+	verifier := providers.NewTokenVerifier(keys, providers.TokenIssuer(), providers.TokenAudience())
 
 	orgRepo := postgresql.NewOrganizationRepository(db)
 	userRepo := postgresql.NewUserRepository(db)
 	memRepo := postgresql.NewMembershipRepository(db)
+	appRepo := postgresql.NewApplicationRepository(db)
+	roleRepo := postgresql.NewRoleRepository(db)
+	groupRepo := postgresql.NewGroupRepository(db)
+	invRepo := postgresql.NewInvitationRepository(db)
+	ssoRepo := postgresql.NewSsoConnectionRepository(db, secrets)
+	scimRepo := postgresql.NewScimTokenRepository(db)
+	credRepo := postgresql.NewCredentialRepository(db)
+	sessionRepo := postgresql.NewSessionRepository(db)
+	mfaRepo := postgresql.NewMfaFactorRepository(db, secrets)
+	patRepo := postgresql.NewPersonalAccessTokenRepository(db)
+	linkRepo := postgresql.NewIdentityLinkRepository(db)
 
 	createOrgCommand := commands.NewCreateOrganizationCommand(orgRepo)
 	promoteUserCommand := commands.NewPromoteUserCommand(orgRepo, memRepo)
 	demoteUserCommand := commands.NewDemoteUserCommand(orgRepo, memRepo)
 	addOrgUserCommand := commands.NewAddOrganizationUserCommand(orgRepo, memRepo, userRepo)
 	createUserCommand := commands.NewCreateUserCommand(userRepo)
-	addRoleCommand := commands.NewAddRoleToMemberCommand(orgRepo, memRepo)
+	addRoleCommand := commands.NewAddRoleToMemberCommand(orgRepo, memRepo, roleRepo)
 	removeRoleCommand := commands.NewRemoveRoleFromMemberCommand(orgRepo, memRepo)
-	addGroupCommand := commands.NewAddGroupToMemberCommand(orgRepo)
+	addGroupCommand := commands.NewAddGroupToMemberCommand(orgRepo, groupRepo)
 	removeGroupCommand := commands.NewRemoveGroupFromMemberCommand(orgRepo)
+	revokeSessionCommand := commands.NewRevokeSessionCommand(sessionRepo)
+	registerPasswordUserCommand := commands.NewRegisterPasswordUserCommand(userRepo, credRepo)
+
+	// Password login makes the service an identity source of its own, next
+	// to the identity provider.
+	passwordLogin := os.Getenv("PASSWORD_LOGIN") == "true"
+	var localUserCommand *commands.RegisterPasswordUserCommand
+	if passwordLogin {
+		localUserCommand = registerPasswordUserCommand
+	}
+
+	grantQuery := postgresql.NewGrantQuery(db)
+	tokenEnricher := authorization.NewTokenEnricher(grantQuery, authorization.EnricherConfig{
+		MaxBytes:     tokenAuthzMaxBytes(),
+		ReferenceUrl: os.Getenv("IAM_BASE_URL") + "/user/permissions",
+	})
+
+	var authEnricher *authorization.TokenEnricher
+	if os.Getenv("JWT_EMBED_PERMISSIONS") == "true" {
+		authEnricher = tokenEnricher
+	}
+
+	// Failed second factor codes are limited per user, whichever route
+	// they are sent to.
+	mfaThrottle := providers.NewLoginThrottle(5, 15*time.Minute)
 
-	authController := controller.NewAuthController(auth, db)
+	authController := controller.NewAuthController(
+		idp,
+		keys,
+		verifier,
+		db,
+		authEnricher,
+		commands.NewJoinInvitedOrganizationsCommand(orgRepo, userRepo, invRepo),
+		commands.NewProvisionSsoUserCommand(orgRepo, userRepo, ssoRepo, linkRepo),
+		commands.NewPasswordLoginCommand(userRepo, credRepo),
+		commands.NewStartSessionCommand(sessionRepo, mfaRepo),
+		commands.NewRefreshSessionCommand(sessionRepo, userRepo, mfaRepo),
+		revokeSessionCommand,
+		commands.NewVerifyMfaCommand(mfaRepo, sessionRepo),
+		orgRepo,
+		ssoRepo,
+		providers.NewSsoProviders(),
+		providers.NewLoginThrottle(10, 15*time.Minute),
+		mfaThrottle,
+	)
+	jwksController := controller.NewJwksController(keys)
+	oauthController := controller.NewOAuthController(
+		keys,
+		commands.NewAuthenticateApiClientCommand(postgresql.NewApiClientRepository(db)),
+	)
 	orgController := controller.NewOrganizationController(
 		db,
+		idp,
 		createOrgCommand,
 		addOrgUserCommand,
 		createUserCommand,
 		addRoleCommand,
+		commands.NewRemoveMemberCommand(orgRepo),
+		localUserCommand,
 		postgresql.NewOrganizationQuery(db),
 	)
 	userController := controller.NewUserController(
@@ -47,9 +128,110 @@ func NewRouter(auth *providers.Authenticator, db *sql.DB) *gin.Engine {
 		removeGroupCommand,
 		postgresql.NewUserQuery(db),
 	)
-	tenantController := controller.NewTenantController(db)
-	roleController := controller.NewRoleController(db)
-	groupController := controller.NewGroupController(db)
+	tenantController := controller.NewTenantController(db, postgresql.NewRoleQuery(db), postgresql.NewGroupQuery(db))
+	applicationController := controller.NewApplicationController(
+		commands.NewCreateApplicationCommand(appRepo),
+		commands.NewRenameApplicationCommand(appRepo),
+		commands.NewDeleteApplicationCommand(appRepo),
+		commands.NewAddPermissionCommand(appRepo),
+		commands.NewRenamePermissionCommand(appRepo),
+		commands.NewRemovePermissionCommand(appRepo),
+	)
+	roleController := controller.NewRoleController(
+		postgresql.NewOrganizationQuery(db),
+		commands.NewCreateRoleCommand(appRepo, roleRepo),
+		commands.NewCreateTenantRoleCommand(orgRepo, roleRepo),
+		commands.NewUpdateRoleCommand(roleRepo),
+		commands.NewDeleteRoleCommand(roleRepo),
+		commands.NewAttachPermissionToRoleCommand(appRepo, roleRepo),
+		commands.NewDetachPermissionFromRoleCommand(roleRepo),
+	)
+	groupController := controller.NewGroupController(
+		postgresql.NewOrganizationQuery(db),
+		commands.NewCreateGroupCommand(appRepo, groupRepo),
+		commands.NewCreateTenantGroupCommand(orgRepo, groupRepo),
+		commands.NewUpdateGroupCommand(groupRepo),
+		commands.NewDeleteGroupCommand(groupRepo),
+		commands.NewAttachRoleToGroupCommand(roleRepo, groupRepo),
+		commands.NewDetachRoleFromGroupCommand(groupRepo),
+	)
+	auditController := controller.NewAuditController(postgresql.NewAuditLogQuery(db))
+	queue := jobs.NewQueue(postgresql.NewJobRepository(db))
+	jobController := controller.NewJobController(queue)
+	importRepo := postgresql.NewMemberImportRepository(db, queue)
+	memberImportController := controller.NewMemberImportController(
+		commands.NewRequestMemberImportCommand(orgRepo, importRepo),
+		importRepo,
+		postgresql.NewOrganizationQuery(db),
+	)
+	ownershipController := controller.NewOwnershipController(
+		commands.NewAddOwnerCommand(orgRepo),
+		commands.NewRequestOwnershipTransferCommand(orgRepo),
+		commands.NewConfirmOwnershipTransferCommand(orgRepo),
+		commands.NewLeaveOrganizationCommand(orgRepo),
+	)
+	invitationController := controller.NewInvitationController(
+		commands.NewInviteMemberCommand(orgRepo, userRepo, invRepo, providers.NewMailer(), os.Getenv("INVITATION_URL")),
+		commands.NewAcceptInvitationCommand(orgRepo, invRepo),
+		commands.NewDeclineInvitationCommand(invRepo),
+		commands.NewRevokeInvitationCommand(invRepo),
+		invRepo,
+	)
+	ssoController := controller.NewSsoController(
+		commands.NewCreateSsoConnectionCommand(orgRepo, ssoRepo),
+		commands.NewUpdateSsoConnectionCommand(orgRepo, ssoRepo),
+		commands.NewDeleteSsoConnectionCommand(orgRepo, ssoRepo),
+		commands.NewVerifySsoDomainCommand(orgRepo, ssoRepo, net.DefaultResolver),
+		commands.NewConfirmIdentityLinkCommand(linkRepo),
+		ssoRepo,
+	)
+	scimTokenController := controller.NewScimTokenController(
+		commands.NewCreateScimTokenCommand(orgRepo, scimRepo),
+		commands.NewRevokeScimTokenCommand(scimRepo),
+		scimRepo,
+	)
+	scimController := controller.NewScimController(
+		commands.NewProvisionScimUserCommand(orgRepo, userRepo, ssoRepo),
+		commands.NewRemoveMemberCommand(orgRepo),
+		commands.NewCreateTenantGroupCommand(orgRepo, groupRepo),
+		commands.NewUpdateGroupCommand(groupRepo),
+		commands.NewDeleteGroupCommand(groupRepo),
+		addGroupCommand,
+		removeGroupCommand,
+		postgresql.NewOrganizationQuery(db),
+		postgresql.NewGroupQuery(db),
+		os.Getenv("IAM_BASE_URL"),
+	)
+	passwordController := controller.NewPasswordController(
+		registerPasswordUserCommand,
+		commands.NewChangePasswordCommand(credRepo),
+		commands.NewRequestPasswordResetCommand(credRepo, providers.NewMailer(), os.Getenv("PASSWORD_RESET_URL")),
+		commands.NewResetPasswordCommand(credRepo, sessionRepo),
+		providers.NewLoginThrottle(3, time.Hour),
+	)
+	sessionController := controller.NewSessionController(
+		revokeSessionCommand,
+		commands.NewRevokeAllSessionsCommand(sessionRepo),
+		sessionRepo,
+	)
+	mfaController := controller.NewMfaController(
+		commands.NewEnrollTotpCommand(mfaRepo, mfaIssuer()),
+		commands.NewConfirmTotpCommand(mfaRepo, sessionRepo),
+		commands.NewRegenerateRecoveryCodesCommand(mfaRepo),
+		commands.NewDisableMfaCommand(mfaRepo),
+		commands.NewSetOrganizationMfaCommand(orgRepo),
+		mfaRepo,
+		mfaThrottle,
+	)
+	patController := controller.NewPersonalAccessTokenController(
+		commands.NewCreatePersonalAccessTokenCommand(orgRepo, patRepo),
+		commands.NewRevokePersonalAccessTokenCommand(patRepo),
+		patRepo,
+	)
+	authorizationController := controller.NewAuthorizationController(
+		authorization.NewEvaluator(grantQuery),
+		tokenEnricher,
+	)
 
 	r.Use(providers.CORSMiddleware())
 
@@ -60,18 +242,92 @@ func NewRouter(auth *providers.Authenticator, db *sql.DB) *gin.Engine {
 	r.GET("/login", authController.Login)
 	r.POST("/callback", authController.Callback)
 	r.GET("/logout", authController.Logout)
+	r.POST("/token/refresh", authController.Refresh)
+	r.GET("/sso/login", authController.SsoLogin)
+	r.GET("/.well-known/jwks.json", jwksController.Keys)
+
+	if passwordLogin {
+		r.POST("/password/login", authController.PasswordLogin)
+		r.POST("/password/reset-requests", passwordController.RequestReset)
+		r.POST("/password/reset", passwordController.Reset)
+
+		if os.Getenv("PASSWORD_SIGNUP") == "true" {
+			r.POST("/password/register", passwordController.Register)
+		}
+	}
+
+	// A session waiting for a second factor can only prove or enroll one.
+	isPartiallyAuthenticated := providers.IsPartiallyAuthenticated(verifier, sessionRepo.IsActive)
+
+	r.GET("/mfa", isPartiallyAuthenticated, mfaController.Status)
+	r.POST("/mfa/verify", isPartiallyAuthenticated, authController.VerifyMfa)
+	r.POST("/mfa/totp", isPartiallyAuthenticated, mfaController.EnrollTotp)
+	r.POST("/mfa/totp/confirm", isPartiallyAuthenticated, mfaController.ConfirmTotp)
 
 	isManager := providers.IsOrganizationManager(db)
 	isTenantValid := providers.IsTenantValid(db)
+	requireStepUp := providers.RequireStepUp(mfaStepUpMaxAge())
+
+	r.POST("/oauth/token", oauthController.Token)
+
+	r.POST("/invitations/decline", invitationController.Decline)
+
+	isScimClient := providers.IsScimClient(scimClientAuthenticator(commands.NewAuthenticateScimTokenCommand(scimRepo)))
 
-	r.GET("/api/organization", providers.IsMachine(), orgController.GetAllOrganizations)
+	r.GET("/scim/v2/ServiceProviderConfig", isScimClient, scimController.ServiceProviderConfig)
+	r.GET("/scim/v2/ResourceTypes", isScimClient, scimController.ResourceTypes)
+	r.GET("/scim/v2/Users", isScimClient, scimController.ListUsers)
+	r.POST("/scim/v2/Users", isScimClient, scimController.CreateUser)
+	r.GET("/scim/v2/Users/:id", isScimClient, scimController.GetUser)
+	r.PUT("/scim/v2/Users/:id", isScimClient, scimController.ReplaceUser)
+	r.PATCH("/scim/v2/Users/:id", isScimClient, scimController.PatchUser)
+	r.DELETE("/scim/v2/Users/:id", isScimClient, scimController.DeleteUser)
+	r.GET("/scim/v2/Groups", isScimClient, scimController.ListGroups)
+	r.POST("/scim/v2/Groups", isScimClient, scimController.CreateGroup)
+	r.GET("/scim/v2/Groups/:id", isScimClient, scimController.GetGroup)
+	r.PUT("/scim/v2/Groups/:id", isScimClient, scimController.ReplaceGroup)
+	r.PATCH("/scim/v2/Groups/:id", isScimClient, scimController.PatchGroup)
+	r.DELETE("/scim/v2/Groups/:id", isScimClient, scimController.DeleteGroup)
 
-	r.Use(providers.IsAuthenticated)
+	r.GET("/api/organization", providers.RequireScopes(verifier, scopeOrganizationsRead), orgController.GetAllOrganizations)
+	r.POST("/authorize", providers.RequireScopes(verifier, scopeAuthorize), authorizationController.Authorize)
+	r.POST("/authorize/batch", providers.RequireScopes(verifier, scopeAuthorize), authorizationController.AuthorizeBatch)
 
-	r.GET("/organization/:id", orgController.FindById)
+	canWriteApplications := providers.RequireScopes(verifier, scopeApplicationsWrite)
 
-	r.GET("/organization", orgController.GetAffiliatedOrganizations)
-	r.POST("/organization", orgController.CreateOrganization)
+	r.POST("/api/applications", canWriteApplications, applicationController.Create)
+	r.PUT("/api/applications/:id", canWriteApplications, applicationController.Rename)
+	r.DELETE("/api/applications/:id", canWriteApplications, applicationController.Delete)
+	r.POST("/api/applications/:id/permissions", canWriteApplications, applicationController.AddPermission)
+	r.PUT("/api/applications/:id/permissions/:permission_id", canWriteApplications, applicationController.RenamePermission)
+	r.DELETE("/api/applications/:id/permissions/:permission_id", canWriteApplications, applicationController.RemovePermission)
+
+	r.POST("/api/roles", canWriteApplications, roleController.Create)
+	r.PUT("/api/roles/:id", canWriteApplications, roleController.Update)
+	r.DELETE("/api/roles/:id", canWriteApplications, roleController.Delete)
+	r.POST("/api/roles/:id/permissions", canWriteApplications, roleController.AttachPermission)
+	r.DELETE("/api/roles/:id/permissions/:permission_id", canWriteApplications, roleController.DetachPermission)
+
+	r.POST("/api/groups", canWriteApplications, groupController.Create)
+	r.PUT("/api/groups/:id", canWriteApplications, groupController.Update)
+	r.DELETE("/api/groups/:id", canWriteApplications, groupController.Delete)
+	r.POST("/api/groups/:id/roles", canWriteApplications, groupController.AttachRole)
+	r.DELETE("/api/groups/:id/roles/:role_id", canWriteApplications, groupController.DetachRole)
+
+	r.Use(providers.IsAuthenticated(
+		verifier,
+		sessionRepo.IsActive,
+		personalAccessTokenAuthenticator(commands.NewAuthenticatePersonalAccessTokenCommand(patRepo, userRepo)),
+	))
+
+	// Personal access tokens cannot manage how their user signs in, nor
+	// call the routes that do not take an organization.
+	isInteractive := providers.DenyPersonalAccessTokens()
+
+	r.GET("/organization/:organization_id", orgController.FindById)
+
+	r.GET("/organization", isInteractive, orgController.GetAffiliatedOrganizations)
+	r.POST("/organization", isInteractive, orgController.CreateOrganization)
 
 	r.GET("/organization/statistics", orgController.Statistics)
 
@@ -79,24 +335,74 @@ func NewRouter(auth *providers.Authenticator, db *sql.DB) *gin.Engine {
 	r.POST("/organization/create-user", orgController.CreateUser)
 	r.GET("/organization/level", userController.UserLevel)
 	r.GET("/organization/users", orgController.GetUsers)
+	r.GET("/organization/users/search", orgController.SearchUsers)
 	r.GET("/organization/recent-users", orgController.GetRecentUsers)
+	r.POST("/organization/leave", ownershipController.Leave)
+	r.POST("/invitations/accept", isInteractive, invitationController.Accept)
 
-	r.GET("/user", userController.DoesUserExist)
+	r.GET("/user", isInteractive, userController.DoesUserExist)
 
 	r.GET("/tenants", tenantController.TenantList)
 	r.GET("/tenant/roles", isTenantValid, tenantController.Roles)
 	r.GET("/tenant/groups", isTenantValid, tenantController.Groups)
 
-	r.GET("/user/details", userController.UserDetails)
-	r.GET("/user/roles", userController.UserRoles)
-	r.GET("/user/groups", userController.UserGroups)
+	if passwordLogin {
+		r.PUT("/password", isInteractive, passwordController.Change)
+	}
+
+	r.POST("/mfa/recovery-codes", isInteractive, mfaController.RegenerateRecoveryCodes)
+	r.DELETE("/mfa/totp", isInteractive, mfaController.Disable)
+
+	r.POST("/sso/link", isInteractive, ssoController.ConfirmLink)
+
+	r.GET("/sessions", isInteractive, sessionController.List)
+	r.DELETE("/sessions", isInteractive, sessionController.RevokeAll)
+	r.DELETE("/sessions/:id", isInteractive, sessionController.Revoke)
+
+	r.GET("/personal-access-tokens", isInteractive, patController.List)
+	r.POST("/personal-access-tokens", isInteractive, patController.Create)
+	r.DELETE("/personal-access-tokens/:id", isInteractive, patController.Revoke)
+
+	r.GET("/user/details", isInteractive, userController.UserDetails)
+	r.GET("/user/roles", isInteractive, userController.UserRoles)
+	r.GET("/user/groups", isInteractive, userController.UserGroups)
+	r.GET("/user/permissions", isInteractive, authorizationController.UserPermissions)
+
+	r.GET("/jobs/:id", isInteractive, jobController.Get)
 
 	r.GET("/role/users", roleController.UsersWithRole)
 	r.GET("/group/users", groupController.UsersWithGroup)
 
 	r.Use(isManager)
 
-	r.DELETE("/organization/remove-user", orgController.RemoveUser)
+	r.DELETE("/organization/remove-user", requireStepUp, orgController.RemoveUser)
+
+	r.PUT("/organization/owners", requireStepUp, ownershipController.AddOwner)
+	r.POST("/organization/ownership-transfers", requireStepUp, ownershipController.TransferOwnership)
+	r.POST("/organization/ownership-transfers/:id/confirm", requireStepUp, ownershipController.ConfirmOwnershipTransfer)
+
+	r.PUT("/organization/mfa", requireStepUp, mfaController.SetOrganizationRequirement)
+
+	r.GET("/organization/invitations", invitationController.Pending)
+	r.POST("/organization/invitations", invitationController.Invite)
+	r.DELETE("/organization/invitations/:id", invitationController.Revoke)
+
+	r.GET("/organization/sso", ssoController.Connection)
+	r.POST("/organization/sso", requireStepUp, ssoController.Create)
+	r.PUT("/organization/sso", requireStepUp, ssoController.Update)
+	r.DELETE("/organization/sso", requireStepUp, ssoController.Delete)
+	r.POST("/organization/sso/domains/verify", requireStepUp, ssoController.VerifyDomain)
+
+	r.GET("/organization/scim-tokens", scimTokenController.List)
+	r.POST("/organization/scim-tokens", requireStepUp, scimTokenController.Create)
+	r.DELETE("/organization/scim-tokens/:id", requireStepUp, scimTokenController.Revoke)
+
+	r.GET("/organization/audit-log", auditController.AuditLog)
+	r.GET("/organization/audit-log/export", auditController.Export)
+
+	r.POST("/organization/member-imports", memberImportController.Import)
+	r.GET("/organization/member-imports/:id", memberImportController.Get)
+	r.GET("/organization/members/export", memberImportController.Export)
 
 	r.POST("/user/role", userController.AssignRole)
 	r.DELETE("/user/role", userController.RemoveRole)
@@ -104,8 +410,92 @@ func NewRouter(auth *providers.Authenticator, db *sql.DB) *gin.Engine {
 	r.POST("/user/group", isTenantValid, userController.AssignGroup)
 	r.DELETE("/user/group", isTenantValid, userController.RemoveGroup)
 
-	r.PUT("/user/promote", userController.Promote)
-	r.PUT("/user/demote", userController.Demote)
+	r.POST("/tenant/roles", isTenantValid, roleController.Create)
+	r.PUT("/tenant/roles/:id", isTenantValid, roleController.Update)
+	r.DELETE("/tenant/roles/:id", isTenantValid, roleController.Delete)
+	r.POST("/tenant/roles/:id/permissions", isTenantValid, roleController.AttachPermission)
+	r.DELETE("/tenant/roles/:id/permissions/:permission_id", isTenantValid, roleController.DetachPermission)
+
+	r.POST("/tenant/groups", isTenantValid, groupController.Create)
+	r.PUT("/tenant/groups/:id", isTenantValid, groupController.Update)
+	r.DELETE("/tenant/groups/:id", isTenantValid, groupController.Delete)
+	r.POST("/tenant/groups/:id/roles", isTenantValid, groupController.AttachRole)
+	r.DELETE("/tenant/groups/:id/roles/:role_id", isTenantValid, groupController.DetachRole)
+
+	r.PUT("/user/promote", requireStepUp, userController.Promote)
+	r.PUT("/user/demote", requireStepUp, userController.Demote)
 
 	return r
 }
+
+// scimClientAuthenticator resolves SCIM tokens to the client they were
+// issued to.
+func scimClientAuthenticator(authenticate *commands.AuthenticateScimTokenCommand) func(ctx context.Context, token string) (providers.ScimClient, error) {
+	return func(ctx context.Context, token string) (providers.ScimClient, error) {
+		scimToken, err := authenticate.Execute(ctx, token)
+		if err != nil {
+			return providers.ScimClient{}, err
+		}
+
+		client := providers.ScimClient{
+			TokenId:        scimToken.Id().Value(),
+			OrganizationId: scimToken.OrganizationId().Value(),
+		}
+		if scimToken.TenantId() != nil {
+			client.TenantId = scimToken.TenantId().Value()
+		}
+
+		return client, nil
+	}
+}
+
+// personalAccessTokenAuthenticator resolves personal access tokens to the
+// user they were issued to.
+func personalAccessTokenAuthenticator(authenticate *commands.AuthenticatePersonalAccessTokenCommand) func(ctx context.Context, token string, ipAddress string) (*providers.Principal, error) {
+	return func(ctx context.Context, token string, ipAddress string) (*providers.Principal, error) {
+		pat, err := authenticate.Execute(ctx, token, ipAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		return &providers.Principal{
+			UserId:          pat.UserId,
+			Email:           pat.Email,
+			Name:            pat.Name,
+			TokenId:         pat.TokenId,
+			Scopes:          pat.Scopes,
+			OrganizationIds: pat.OrganizationIds,
+		}, nil
+	}
+}
+
+// mfaIssuer names the service in authenticator apps.
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+
+	return providers.TokenIssuer()
+}
+
+// mfaStepUpMaxAge is how recently managers must have verified a second
+// factor to perform sensitive actions, or zero when they need not.
+func mfaStepUpMaxAge() time.Duration {
+	maxAge, err := time.ParseDuration(os.Getenv("MFA_STEP_UP_MAX_AGE"))
+	if err != nil || maxAge < 0 {
+		return 0
+	}
+
+	return maxAge
+}
+
+// tokenAuthzMaxBytes is the size above which the embedded permissions are
+// replaced by a reference claim.
+func tokenAuthzMaxBytes() int {
+	maxBytes, err := strconv.Atoi(os.Getenv("JWT_AUTHZ_MAX_BYTES"))
+	if err != nil || maxBytes <= 0 {
+		return 4096
+	}
+
+	return maxBytes
+}
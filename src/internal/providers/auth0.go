@@ -0,0 +1,183 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const defaultAuth0Connection = "Username-Password-Authentication"
+
+type Auth0Config struct {
+	Domain       string
+	ClientId     string
+	ClientSecret string
+
+	// ManagementClientId and ManagementClientSecret identify the machine
+	// client allowed to use the Management API. They default to the
+	// sign in client.
+	ManagementClientId     string
+	ManagementClientSecret string
+
+	// Connection is the database connection users are created in.
+	Connection string
+
+	CallbackURL string
+	LogoutURL   string
+}
+
+// Auth0Provider signs users in with Auth0 and manages users and callback
+// URLs through the Auth0 Management API.
+type Auth0Provider struct {
+	*OIDCProvider
+
+	config     Auth0Config
+	management *http.Client
+}
+
+func NewAuth0Provider(ctx context.Context, config Auth0Config) (*Auth0Provider, error) {
+	provider, err := NewOIDCProvider(ctx, OIDCConfig{
+		IssuerURL:    "https://" + config.Domain + "/",
+		ClientId:     config.ClientId,
+		ClientSecret: config.ClientSecret,
+		CallbackURL:  config.CallbackURL,
+		LogoutURL:    config.LogoutURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ManagementClientId == "" {
+		config.ManagementClientId, config.ManagementClientSecret = config.ClientId, config.ClientSecret
+	}
+	if config.Connection == "" {
+		config.Connection = defaultAuth0Connection
+	}
+
+	// The token source caches the Management API token and renews it
+	// when it expires.
+	credentials := clientcredentials.Config{
+		ClientID:       config.ManagementClientId,
+		ClientSecret:   config.ManagementClientSecret,
+		TokenURL:       "https://" + config.Domain + "/oauth/token",
+		EndpointParams: url.Values{"audience": {"https://" + config.Domain + "/api/v2/"}},
+	}
+
+	return &Auth0Provider{provider, config, credentials.Client(context.Background())}, nil
+}
+
+func (p *Auth0Provider) LogoutURL(returnTo string) (string, error) {
+	if returnTo == "" {
+		returnTo = p.config.LogoutURL
+	}
+
+	parameters := url.Values{}
+	parameters.Add("returnTo", returnTo)
+	parameters.Add("client_id", p.config.ClientId)
+
+	return "https://" + p.config.Domain + "/v2/logout?" + parameters.Encode(), nil
+}
+
+func (p *Auth0Provider) CreateUser(ctx context.Context, user IdentityProviderUser) (Identity, error) {
+	var created struct {
+		UserId        string `json:"user_id"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+
+	err := p.call(ctx, http.MethodPost, "/api/v2/users", map[string]interface{}{
+		"email":      user.Email,
+		"nickname":   user.Email,
+		"name":       user.Name,
+		"password":   user.Password,
+		"connection": p.config.Connection,
+	}, &created)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{
+		Subject:       created.UserId,
+		Email:         created.Email,
+		EmailVerified: created.EmailVerified,
+		Name:          created.Name,
+		Picture:       created.Picture,
+	}, nil
+}
+
+// AddCallbackURL adds the URLs to the sign in client, keeping the ones
+// already allowed.
+func (p *Auth0Provider) AddCallbackURL(ctx context.Context, callbackURL string, logoutURL string) error {
+	path := "/api/v2/clients/" + url.PathEscape(p.config.ClientId)
+
+	var client struct {
+		Callbacks         []string `json:"callbacks"`
+		AllowedLogoutUrls []string `json:"allowed_logout_urls"`
+	}
+
+	err := p.call(ctx, http.MethodGet, path, nil, &client)
+	if err != nil {
+		return err
+	}
+
+	return p.call(ctx, http.MethodPatch, path, map[string]interface{}{
+		"callbacks":           appendMissing(client.Callbacks, callbackURL),
+		"allowed_logout_urls": appendMissing(client.AllowedLogoutUrls, logoutURL),
+	}, nil)
+}
+
+func (p *Auth0Provider) call(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "https://"+p.config.Domain+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.management.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("auth0 %s %s: %s: %s", method, path, res.Status, string(b))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(b, result)
+}
+
+func appendMissing(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
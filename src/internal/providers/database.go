@@ -15,10 +15,12 @@ type DatabaseConfig struct {
 	Database string
 }
 
-func NewDatabase(config DatabaseConfig) (*sql.DB, error) {
-	connStr := fmt.Sprintf("postgresql://postgres:%s@%s:%s/%s?sslmode=disable", config.Password, config.Host, config.Port, config.Database)
+func (config DatabaseConfig) ConnectionString() string {
+	return fmt.Sprintf("postgresql://postgres:%s@%s:%s/%s?sslmode=disable", config.Password, config.Host, config.Port, config.Database)
+}
 
-	db, err := sql.Open("postgres", connStr)
+func NewDatabase(config DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.ConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf(err.Error())
 	}
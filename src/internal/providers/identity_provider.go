@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrNotSupported is returned by identity providers for operations they
+// cannot perform, e.g. creating users through a generic OIDC provider.
+var ErrNotSupported = errors.New("not supported by the identity provider")
+
+// Identity is the verified identity of a user who signed in with the
+// identity provider.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+}
+
+// IdentityProviderUser is the input for creating a user with a password.
+type IdentityProviderUser struct {
+	Email    string
+	Name     string
+	Password string
+}
+
+// IdentityProvider is the external service users sign in with. Redirect
+// URLs are passed on each call, as they depend on the application the user
+// signs in to; an empty URL means the configured default.
+type IdentityProvider interface {
+	// AuthCodeURL returns the sign in page, which sends the user back to
+	// redirectURL with an authorization code.
+	AuthCodeURL(state string, redirectURL string, challenge LoginChallenge) string
+
+	// Exchange trades an authorization code for the identity of the user.
+	// redirectURL and challenge must be the ones the code was requested
+	// with.
+	Exchange(ctx context.Context, code string, redirectURL string, challenge LoginChallenge) (Identity, error)
+
+	// LogoutURL returns the page that ends the session with the provider
+	// and then sends the user to returnTo.
+	LogoutURL(returnTo string) (string, error)
+
+	// CreateUser registers a user who signs in with a password, returning
+	// the identity of the new user.
+	CreateUser(ctx context.Context, user IdentityProviderUser) (Identity, error)
+
+	// AddCallbackURL allows an application to receive sign ins and
+	// logouts at the given URLs.
+	AddCallbackURL(ctx context.Context, callbackURL string, logoutURL string) error
+}
+
+// NewIdentityProvider configures the identity provider named by
+// IDENTITY_PROVIDER: "auth0" (the default), "oidc", or "memory" to run
+// without any external service.
+func NewIdentityProvider(ctx context.Context) (IdentityProvider, error) {
+	switch name := os.Getenv("IDENTITY_PROVIDER"); name {
+	case "", "auth0":
+		return NewAuth0Provider(ctx, Auth0Config{
+			Domain:                 os.Getenv("AUTH0_DOMAIN"),
+			ClientId:               os.Getenv("AUTH0_CLIENT_ID"),
+			ClientSecret:           os.Getenv("AUTH0_CLIENT_SECRET"),
+			ManagementClientId:     os.Getenv("AUTH0_MANAGEMENT_CLIENT_ID"),
+			ManagementClientSecret: os.Getenv("AUTH0_MANAGEMENT_CLIENT_SECRET"),
+			Connection:             os.Getenv("AUTH0_CONNECTION"),
+			CallbackURL:            "https://" + os.Getenv("AUTH0_CALLBACK_URL"),
+			LogoutURL:              "https://" + os.Getenv("AUTH0_LOGOUT_URL"),
+		})
+	case "oidc":
+		return NewOIDCProvider(ctx, OIDCConfig{
+			IssuerURL:    os.Getenv("OIDC_ISSUER_URL"),
+			ClientId:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			CallbackURL:  os.Getenv("OIDC_CALLBACK_URL"),
+			LogoutURL:    os.Getenv("OIDC_LOGOUT_URL"),
+		})
+	case "memory":
+		idp := NewInMemoryIdentityProvider()
+		if email := os.Getenv("MEMORY_IDP_USER"); email != "" {
+			user, err := idp.CreateUser(ctx, IdentityProviderUser{Email: email, Name: email})
+			if err != nil {
+				return nil, err
+			}
+
+			idp.SignInAs(user.Subject)
+		}
+
+		return idp, nil
+	default:
+		return nil, fmt.Errorf("unknown identity provider %q", name)
+	}
+}
@@ -1,81 +1,238 @@
 package providers
 
 import (
+	"crypto"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
 
-func decodeJWT(token string) (*IamToken, error) {
+const (
+	defaultTokenIssuer   = "iam.sashore.com"
+	defaultTokenAudience = "iam.sashore.com"
+
+	// PrincipalKey is the gin context key under which IsAuthenticated
+	// stores the verified *Principal.
+	PrincipalKey = "principal"
+)
+
+// TokenIssuer is the "iss" claim of the tokens minted by this service.
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+
+	return defaultTokenIssuer
+}
+
+// TokenAudience is the "aud" claim of the tokens minted by this service.
+func TokenAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+
+	return defaultTokenAudience
+}
+
+// Principal is the authenticated caller, built from a verified token.
+// Machine clients have a ClientId and no user information. Users signed in
+// since sessions were introduced have a SessionId, and MfaPending is set
+// until they prove a second factor their session requires. Users calling
+// with a personal access token have its TokenId, Scopes and
+// OrganizationIds instead.
+type Principal struct {
+	UserId          string
+	Email           string
+	Name            string
+	PictureUrl      string
+	ClientId        string
+	SessionId       string
+	TokenId         string
+	Scopes          []string
+	OrganizationIds []string
+	AuthMethods     []string
+	Acr             string
+	AuthTime        time.Time
+	MfaPending      bool
+	IssuedAt        time.Time
+	ExpiresAt       time.Time
+}
+
+func (p *Principal) IsMachine() bool {
+	return p.ClientId != ""
+}
+
+func (p *Principal) IsPersonalAccessToken() bool {
+	return p.TokenId != ""
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPrincipal returns the principal stored by IsAuthenticated.
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	value, ok := ctx.Get(PrincipalKey)
+	if !ok {
+		return nil, false
+	}
+
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// VerificationKeySource resolves the "kid" header of a token to the public
+// key it was signed with.
+type VerificationKeySource interface {
+	VerificationKey(kid string) (crypto.PublicKey, string, error)
+}
+
+// TokenVerifier checks the signature and the registered claims of the
+// tokens issued by AuthController.Callback.
+type TokenVerifier struct {
+	keys     VerificationKeySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+func NewTokenVerifier(keys VerificationKeySource, issuer string, audience string) *TokenVerifier {
+	return &TokenVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   30 * time.Second,
+	}
+}
+
+func (v *TokenVerifier) Verify(token string) (*Principal, error) {
 	claims := &IamToken{}
-	var err error
-	var tokenInstance *jwt.Token
 
-	log.Printf("Token: %v", token)
+	_, err := new(jwt.Parser).ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing kid header")
+		}
+
+		key, algorithm, err := v.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
 
-	tokenInstance, err = new(jwt.Parser).ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method.Alg() != algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %s", token.Header["alg"])
 		}
 
-		return []byte(os.Getenv("JWT_SECRET")), nil
+		return key, nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
 
-	log.Printf("Token Instance: %v", tokenInstance.Claims)
-	log.Printf("Claims: %v", claims)
+	now := time.Now()
 
-	if !tokenInstance.Valid {
-		return nil, fmt.Errorf("invalid token: %s", err)
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return nil, errors.New("token is expired")
 	}
 
-	if err != nil {
-		return nil, err
+	if claims.IssuedAt == 0 || now.Add(v.leeway).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, errors.New("token used before issued")
+	}
+
+	if claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("unexpected issuer: %s", claims.Issuer)
 	}
 
-	return claims, nil
+	if !claims.Audience.Contains(v.audience) {
+		return nil, fmt.Errorf("unexpected audience: %v", claims.Audience)
+	}
+
+	if claims.UserId == "" {
+		return nil, errors.New("missing subject")
+	}
+
+	principal := &Principal{
+		UserId:      claims.UserId,
+		Email:       claims.Email,
+		Name:        claims.Name,
+		PictureUrl:  claims.PictureUrl,
+		ClientId:    claims.ClientId,
+		SessionId:   claims.SessionId,
+		Scopes:      strings.Fields(claims.Scope),
+		AuthMethods: claims.AuthMethods,
+		Acr:         claims.Acr,
+		MfaPending:  claims.MfaPending,
+		IssuedAt:    time.Unix(claims.IssuedAt, 0),
+		ExpiresAt:   time.Unix(claims.ExpiresAt, 0),
+	}
+	if claims.AuthTime != 0 {
+		principal.AuthTime = time.Unix(claims.AuthTime, 0)
+	}
+
+	return principal, nil
 }
 
 type IamToken struct {
-	Email      string `json:"email,omitempty"`
-	ExpiresAt  int64  `json:"exp,omitempty"`
-	IssuedAt   int64  `json:"iat,omitempty"`
-	Name       string `json:"name,omitempty"`
-	PictureUrl string `json:"picture,omitempty"`
-	UserId     string `json:"sub,omitempty"`
+	Acr         string   `json:"acr,omitempty"`
+	AuthMethods []string `json:"amr,omitempty"`
+	Audience    Audience `json:"aud,omitempty"`
+	AuthTime    int64    `json:"auth_time,omitempty"`
+	ClientId    string   `json:"client_id,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	ExpiresAt   int64    `json:"exp,omitempty"`
+	IssuedAt    int64    `json:"iat,omitempty"`
+	Issuer      string   `json:"iss,omitempty"`
+	MfaPending  bool     `json:"mfa_pending,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	PictureUrl  string   `json:"picture,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	SessionId   string   `json:"sid,omitempty"`
+	UserId      string   `json:"sub,omitempty"`
 }
 
-// Valid Validates time based claims "exp, iat, nbf".
-// There is no accounting for clock skew.
-// As well, if any of the above claims are not in the token, it will still
-// be considered a valid claim.
+// Valid is called by the jwt parser. The time based claims are checked by
+// TokenVerifier, which also accounts for clock skew.
 func (c IamToken) Valid() error {
-	now := time.Now().Unix()
+	return nil
+}
+
+// Audience is the "aud" claim, which may be a single string or an array.
+type Audience []string
 
-	if !c.verifyExp(c.ExpiresAt, now, false) {
-		delta := time.Unix(now, 0).Sub(time.Unix(c.ExpiresAt, 0))
-		return fmt.Errorf("token is expired by %v", delta)
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
 	}
 
-	if !c.verifyIat(c.IssuedAt, now, false) {
-		return fmt.Errorf("token used before issued")
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
 	}
 
+	*a = many
 	return nil
 }
 
-func (c IamToken) verifyExp(exp int64, now int64, required bool) bool {
-	if exp == 0 {
-		return !required
+func (a Audience) Contains(audience string) bool {
+	for _, aud := range a {
+		if aud == audience {
+			return true
+		}
 	}
-	return now <= exp
-}
 
-func (c IamToken) verifyIat(iat int64, now int64, required bool) bool {
-	if iat == 0 {
-		return !required
-	}
-	return now >= iat
+	return false
 }
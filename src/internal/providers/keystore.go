@@ -0,0 +1,454 @@
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	// KeyActive keys sign new tokens (the newest one is used) and verify.
+	KeyActive = "active"
+	// KeyInactive keys have been rotated out; they only verify tokens that
+	// were issued before the rotation.
+	KeyInactive = "inactive"
+	// KeyRetired keys are no longer published nor accepted.
+	KeyRetired = "retired"
+)
+
+type SigningKey struct {
+	Id         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	Status     string
+	CreatedAt  time.Time
+	RotatedAt  *time.Time
+}
+
+func (k SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// GenerateSigningKey creates a new key pair for the given algorithm.
+// RS256 and ES256 are supported.
+func GenerateSigningKey(algorithm string) (SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "RS256":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{
+		Id:         uuid.NewString(),
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		Status:     KeyActive,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+type KeyRepository interface {
+	All(ctx context.Context) ([]SigningKey, error)
+	Insert(ctx context.Context, key SigningKey) error
+	// InsertFirst inserts the key unless there already is an active one,
+	// which another instance may have inserted concurrently. It reports
+	// whether the key was inserted.
+	InsertFirst(ctx context.Context, key SigningKey) (bool, error)
+	UpdateStatus(ctx context.Context, id string, status string) error
+}
+
+// KeyReloadInterval is the minimum time between two reloads caused by a
+// token signed with an unknown key.
+const KeyReloadInterval = 30 * time.Second
+
+// KeyStore holds the keys used to sign and verify IAM tokens.
+type KeyStore struct {
+	repo      KeyRepository
+	algorithm string
+
+	mu   sync.RWMutex
+	keys []SigningKey
+
+	reloadMu   sync.Mutex
+	reloadedAt time.Time
+}
+
+func NewKeyStore(repo KeyRepository, algorithm string) *KeyStore {
+	return &KeyStore{
+		repo:      repo,
+		algorithm: algorithm,
+	}
+}
+
+// Load reads the keys from the repository, generating the first key when
+// there is no active one yet. Instances starting together race to insert
+// it, so the losers read back the key of the winner.
+func (s *KeyStore) Load(ctx context.Context) error {
+	err := s.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.SigningKey(); err == nil {
+		return nil
+	}
+
+	key, err := GenerateSigningKey(s.algorithm)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := s.repo.InsertFirst(ctx, key)
+	if err != nil {
+		return fmt.Errorf("insert signing key: %w", err)
+	}
+
+	if inserted {
+		log.Printf("Generated signing key, key id %s", key.Id)
+	}
+
+	return s.reload(ctx)
+}
+
+func (s *KeyStore) reload(ctx context.Context) error {
+	keys, err := s.repo.All(ctx)
+	if err != nil {
+		return fmt.Errorf("load signing keys: %w", err)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// SigningKey returns the newest active key.
+func (s *KeyStore) SigningKey() (SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.Status == KeyActive {
+			return key, nil
+		}
+	}
+
+	return SigningKey{}, errors.New("no active signing key")
+}
+
+// VerificationKey returns the public key for kid, unless it was retired.
+// A kid that is not known yet may come from a key another instance just
+// rotated in, so the keys are reloaded, at most once per
+// KeyReloadInterval, before the kid is rejected.
+func (s *KeyStore) VerificationKey(kid string) (crypto.PublicKey, string, error) {
+	key, found := s.findKey(kid)
+	if !found && s.reloadUnknown() {
+		key, found = s.findKey(kid)
+	}
+
+	if !found || key.Status == KeyRetired {
+		return nil, "", fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	return key.PrivateKey.Public(), key.Algorithm, nil
+}
+
+func (s *KeyStore) findKey(kid string) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.Id == kid {
+			return key, true
+		}
+	}
+
+	return SigningKey{}, false
+}
+
+// reloadUnknown reloads the keys unless they were reloaded less than
+// KeyReloadInterval ago, so tokens with made up kids cannot flood the
+// database. It reports whether the keys were reloaded.
+func (s *KeyStore) reloadUnknown() bool {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if time.Since(s.reloadedAt) < KeyReloadInterval {
+		return false
+	}
+	s.reloadedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.reload(ctx); err != nil {
+		log.Printf("Error: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// Sign signs the claims with the current key and sets the "kid" header.
+func (s *KeyStore) Sign(claims jwt.Claims) (string, error) {
+	key, err := s.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.Id
+
+	return token.SignedString(key.PrivateKey)
+}
+
+// Rotate generates a new active key. Previously active keys are kept for
+// verification until they are retired.
+func (s *KeyStore) Rotate(ctx context.Context) (SigningKey, error) {
+	key, err := GenerateSigningKey(s.algorithm)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	err = s.repo.Insert(ctx, key)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("insert signing key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i, k := range s.keys {
+		if k.Status != KeyActive {
+			continue
+		}
+
+		err = s.repo.UpdateStatus(ctx, k.Id, KeyInactive)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("deactivate signing key: %w", err)
+		}
+
+		s.keys[i].Status = KeyInactive
+		s.keys[i].RotatedAt = &now
+	}
+
+	s.keys = append([]SigningKey{key}, s.keys...)
+
+	log.Printf("Rotated signing key, new key id %s", key.Id)
+
+	return key, nil
+}
+
+func (s *KeyStore) Retire(ctx context.Context, kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, k := range s.keys {
+		if k.Id != kid {
+			continue
+		}
+
+		if k.Status == KeyActive {
+			return errors.New("cannot retire an active signing key")
+		}
+
+		err := s.repo.UpdateStatus(ctx, kid, KeyRetired)
+		if err != nil {
+			return err
+		}
+
+		s.keys[i].Status = KeyRetired
+		return nil
+	}
+
+	return fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// RunRotation rotates the signing key every rotateAfter and retires
+// inactive keys once they have been out of rotation for retireAfter, which
+// should be longer than the lifetime of the tokens.
+func (s *KeyStore) RunRotation(ctx context.Context, rotateAfter time.Duration, retireAfter time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Other instances may have rotated in the meantime.
+		err := s.Load(ctx)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			continue
+		}
+
+		current, err := s.SigningKey()
+		if err == nil && time.Since(current.CreatedAt) >= rotateAfter {
+			_, err = s.Rotate(ctx)
+			if err != nil {
+				log.Printf("Error: rotate signing key: %v", err)
+			}
+		}
+
+		for _, key := range s.Keys() {
+			if key.Status == KeyInactive && key.RotatedAt != nil && time.Since(*key.RotatedAt) >= retireAfter {
+				err = s.Retire(ctx, key.Id)
+				if err != nil {
+					log.Printf("Error: retire signing key: %v", err)
+				}
+			}
+		}
+	}
+}
+
+func (s *KeyStore) Keys() []SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]SigningKey, len(s.keys))
+	copy(keys, s.keys)
+
+	return keys
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public part of every key that is not retired.
+func (s *KeyStore) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0)}
+
+	for _, key := range s.Keys() {
+		if key.Status == KeyRetired {
+			continue
+		}
+
+		jwk := JSONWebKey{Use: "sig", Alg: key.Algorithm, Kid: key.Id}
+
+		switch publicKey := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = publicKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// MemoryKeyRepository keeps the keys in memory. Tokens do not survive a
+// restart, so it is only meant for tests and local development.
+type MemoryKeyRepository struct {
+	mu   sync.Mutex
+	keys []SigningKey
+}
+
+func NewMemoryKeyRepository() *MemoryKeyRepository {
+	return &MemoryKeyRepository{}
+}
+
+func (r *MemoryKeyRepository) All(ctx context.Context) ([]SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]SigningKey, len(r.keys))
+	copy(keys, r.keys)
+
+	return keys, nil
+}
+
+func (r *MemoryKeyRepository) Insert(ctx context.Context, key SigningKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *MemoryKeyRepository) InsertFirst(ctx context.Context, key SigningKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.Status == KeyActive {
+			return false, nil
+		}
+	}
+
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func (r *MemoryKeyRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, key := range r.keys {
+		if key.Id == id {
+			now := time.Now()
+			r.keys[i].Status = status
+			if status == KeyInactive {
+				r.keys[i].RotatedAt = &now
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown signing key: %s", id)
+}
@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// LoginStateTTL is how long a browser has to come back from the identity
+// provider.
+const LoginStateTTL = 10 * time.Minute
+
+// LoginChallenge ties an authorization code to the sign in that asked for
+// it: the PKCE verifier must be presented to redeem the code, and the
+// nonce must come back in the ID token. The zero challenge sends neither.
+type LoginChallenge struct {
+	Verifier string
+	Nonce    string
+}
+
+// NewLoginChallenge generates a challenge for a new sign in.
+func NewLoginChallenge() LoginChallenge {
+	return LoginChallenge{
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    oauth2.GenerateVerifier(),
+	}
+}
+
+// SetLoginState keeps the state and challenge of a sign in in an HttpOnly
+// cookie of the browser that starts it. Only that browser can complete the
+// sign in, so a link to the callback made by someone else does not sign
+// the user in as them. The callback is posted by applications on other
+// origins, hence SameSite=None.
+func SetLoginState(ctx *gin.Context, name string, state string, challenge LoginChallenge) {
+	value := url.Values{
+		"state":    {state},
+		"verifier": {challenge.Verifier},
+		"nonce":    {challenge.Nonce},
+	}
+
+	ctx.SetSameSite(http.SameSiteNoneMode)
+	ctx.SetCookie(name, value.Encode(), int(LoginStateTTL.Seconds()), "/", "", true, true)
+}
+
+// TakeLoginState returns the challenge of the sign in started by this
+// browser with the state, and clears it so that it is used once. It
+// reports false when the browser did not start a sign in with that state.
+func TakeLoginState(ctx *gin.Context, name string, state string) (LoginChallenge, bool) {
+	cookie, err := ctx.Cookie(name)
+
+	ctx.SetSameSite(http.SameSiteNoneMode)
+	ctx.SetCookie(name, "", -1, "/", "", true, true)
+
+	if err != nil {
+		return LoginChallenge{}, false
+	}
+
+	value, err := url.ParseQuery(cookie)
+	if err != nil || value.Get("state") == "" || subtle.ConstantTimeCompare([]byte(value.Get("state")), []byte(state)) != 1 {
+		return LoginChallenge{}, false
+	}
+
+	return LoginChallenge{
+		Verifier: value.Get("verifier"),
+		Nonce:    value.Get("nonce"),
+	}, true
+}
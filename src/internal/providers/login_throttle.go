@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginThrottle limits failed logins per key, such as a client IP or an
+// email, within a sliding window. It complements the lockout of password
+// credentials, which does not cover guesses spread over many emails.
+//
+// Attempts are counted in memory, so each instance of the service keeps
+// its own counts.
+type LoginThrottle struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	failures map[string][]time.Time
+}
+
+func NewLoginThrottle(limit int, window time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		limit:    limit,
+		window:   window,
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether another login may be attempted for every key, and
+// otherwise how long to wait before the next attempt.
+func (t *LoginThrottle) Allow(keys ...string) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range keys {
+		failures := t.prune(key, now)
+		if len(failures) < t.limit {
+			continue
+		}
+
+		if retry := failures[len(failures)-t.limit].Add(t.window).Sub(now); retry > wait {
+			wait = retry
+		}
+	}
+
+	return wait == 0, wait
+}
+
+// Fail records a failed login for each key.
+func (t *LoginThrottle) Fail(keys ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		t.failures[key] = append(t.prune(key, now), now)
+	}
+
+	// Keys that stopped failing are only pruned when looked up again, so
+	// sweep them once the map grows.
+	if len(t.failures) > 10000 {
+		for key := range t.failures {
+			t.prune(key, now)
+		}
+	}
+}
+
+// Succeed forgets the failures of each key.
+func (t *LoginThrottle) Succeed(keys ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range keys {
+		delete(t.failures, key)
+	}
+}
+
+func (t *LoginThrottle) prune(key string, now time.Time) []time.Time {
+	failures := t.failures[key]
+
+	i := 0
+	for i < len(failures) && !failures[i].After(now.Add(-t.window)) {
+		i++
+	}
+
+	if i == len(failures) {
+		delete(t.failures, key)
+		return nil
+	}
+
+	failures = failures[i:]
+	t.failures[key] = failures
+	return failures
+}
@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"iyaem/internal/app/mail"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMailer returns an SMTP mailer when MAIL_SMTP_HOST is set, and a
+// LogMailer otherwise. MAIL_SINK_FILE makes the LogMailer append the
+// emails to a file instead of the log, which is handy for local testing.
+func NewMailer() mail.Mailer {
+	if host := os.Getenv("MAIL_SMTP_HOST"); host != "" {
+		port := os.Getenv("MAIL_SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		return NewSMTPMailer(
+			net.JoinHostPort(host, port),
+			os.Getenv("MAIL_SMTP_USERNAME"),
+			os.Getenv("MAIL_SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	}
+
+	return NewLogMailer(os.Getenv("MAIL_SINK_FILE"))
+}
+
+// SMTPMailer sends emails through an SMTP server, authenticating with
+// PLAIN when a username is configured.
+type SMTPMailer struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(addr string, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{addr, username, password, from}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message mail.Message) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, _ := net.SplitHostPort(m.addr)
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	return smtp.SendMail(m.addr, auth, m.from, []string{message.To}, formatMessage(m.from, message))
+}
+
+// LogMailer writes emails to the application log, or appends them to a
+// file when a path is given. It never delivers anything.
+type LogMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message mail.Message) error {
+	if m.path == "" {
+		log.Printf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(formatMessage("", message), '\n'))
+	return err
+}
+
+func formatMessage(from string, message mail.Message) []byte {
+	var b strings.Builder
+
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
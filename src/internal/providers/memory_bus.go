@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBus delivers messages within the process, which lets the event
+// listeners run without a broker, e.g. locally. Each topic is a queue
+// that its subscribers share. Messages are lost when the process stops.
+type MemoryBus struct {
+	config BusConfig
+	mu     sync.Mutex
+	topics map[string]*memoryTopic
+}
+
+type memoryTopic struct {
+	messages []Message
+	ready    chan struct{}
+}
+
+func NewMemoryBus(config BusConfig) *MemoryBus {
+	return &MemoryBus{
+		config: config,
+		topics: make(map[string]*memoryTopic),
+	}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error {
+	b.mu.Lock()
+	t := b.topic(topic)
+	t.messages = append(t.messages, Message{
+		Id:         messageId(attributes, ""),
+		Topic:      topic,
+		Data:       data,
+		Attributes: attributes,
+	})
+	b.mu.Unlock()
+
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Subscribe handles the messages of the topic named by the subscription.
+func (b *MemoryBus) Subscribe(ctx context.Context, subscription string, handler Handler) error {
+	b.mu.Lock()
+	t := b.topic(subscription)
+	b.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		msg, ok := b.next(t)
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-t.ready:
+			}
+			continue
+		}
+
+		deliver(ctx, b, b.config, subscription, handler, msg)
+	}
+}
+
+func (b *MemoryBus) Close() error {
+	return nil
+}
+
+// topic must be called with the lock held.
+func (b *MemoryBus) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{ready: make(chan struct{}, 1)}
+		b.topics[name] = t
+	}
+
+	return t
+}
+
+func (b *MemoryBus) next(t *memoryTopic) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(t.messages) == 0 {
+		return Message{}, false
+	}
+
+	msg := t.messages[0]
+	t.messages = t.messages[1:]
+
+	// Wake up the other subscribers of the topic, if any is waiting.
+	if len(t.messages) > 0 {
+		select {
+		case t.ready <- struct{}{}:
+		default:
+		}
+	}
+
+	return msg, true
+}
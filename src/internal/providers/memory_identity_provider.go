@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InMemoryIdentityProvider is an identity provider without any external
+// service, for tests and for running the stack offline. Users sign in by
+// exchanging codes issued with IssueCode, or automatically as the user
+// set with SignInAs.
+type InMemoryIdentityProvider struct {
+	mu         sync.Mutex
+	users      map[string]Identity
+	codes      map[string]string
+	challenges map[string]LoginChallenge
+	callbacks  map[string]string
+	signedIn   string
+}
+
+func NewInMemoryIdentityProvider() *InMemoryIdentityProvider {
+	return &InMemoryIdentityProvider{
+		users:      make(map[string]Identity),
+		codes:      make(map[string]string),
+		challenges: make(map[string]LoginChallenge),
+		callbacks:  make(map[string]string),
+	}
+}
+
+// IssueCode returns an authorization code that signs the user in.
+func (p *InMemoryIdentityProvider) IssueCode(subject string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := uuid.NewString()
+	p.codes[code] = subject
+	return code
+}
+
+// SignInAs makes the sign in page send every user back already signed in
+// as the given user.
+func (p *InMemoryIdentityProvider) SignInAs(subject string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.signedIn = subject
+}
+
+// Callbacks returns the allowed logout URL of each callback URL.
+func (p *InMemoryIdentityProvider) Callbacks() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	callbacks := make(map[string]string, len(p.callbacks))
+	for callback, logout := range p.callbacks {
+		callbacks[callback] = logout
+	}
+
+	return callbacks
+}
+
+// AuthCodeURL signs in the user set with SignInAs. Their code can only be
+// exchanged with the same challenge.
+func (p *InMemoryIdentityProvider) AuthCodeURL(state string, redirectURL string, challenge LoginChallenge) string {
+	parameters := url.Values{"state": {state}}
+
+	p.mu.Lock()
+	signedIn := p.signedIn
+	p.mu.Unlock()
+
+	if signedIn != "" {
+		code := p.IssueCode(signedIn)
+		parameters.Set("code", code)
+
+		p.mu.Lock()
+		p.challenges[code] = challenge
+		p.mu.Unlock()
+	}
+
+	return redirectURL + "?" + parameters.Encode()
+}
+
+func (p *InMemoryIdentityProvider) Exchange(ctx context.Context, code string, redirectURL string, challenge LoginChallenge) (Identity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subject, ok := p.codes[code]
+	if !ok {
+		return Identity{}, fmt.Errorf("invalid authorization code")
+	}
+	delete(p.codes, code)
+
+	expected, ok := p.challenges[code]
+	delete(p.challenges, code)
+	if ok && expected != challenge {
+		return Identity{}, fmt.Errorf("the code was issued for another sign in")
+	}
+
+	identity := p.users[subject]
+	identity.IssuedAt = time.Now()
+	identity.ExpiresAt = identity.IssuedAt.Add(time.Hour)
+
+	return identity, nil
+}
+
+func (p *InMemoryIdentityProvider) LogoutURL(returnTo string) (string, error) {
+	return returnTo, nil
+}
+
+func (p *InMemoryIdentityProvider) CreateUser(ctx context.Context, user IdentityProviderUser) (Identity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	for _, existing := range p.users {
+		if existing.Email == email {
+			return Identity{}, fmt.Errorf("user %s already exists", email)
+		}
+	}
+
+	identity := Identity{
+		Subject:       "memory|" + uuid.NewString(),
+		Email:         email,
+		EmailVerified: true,
+		Name:          user.Name,
+	}
+
+	p.users[identity.Subject] = identity
+
+	return identity, nil
+}
+
+func (p *InMemoryIdentityProvider) AddCallbackURL(ctx context.Context, callbackURL string, logoutURL string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.callbacks[callbackURL] = logoutURL
+	return nil
+}
@@ -0,0 +1,239 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a message delivered by a MessageBus.
+type Message struct {
+	Id         string
+	Topic      string
+	Data       []byte
+	Attributes map[string]string
+	// Attempt counts the deliveries of the message, this one included. It
+	// is 0 when the bus does not know.
+	Attempt int
+}
+
+// Handler processes a message. Returning an error nacks the message, which
+// is delivered again until it is dead-lettered.
+type Handler func(ctx context.Context, msg Message) error
+
+// MessageBus carries events between services.
+type MessageBus interface {
+	Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error
+	// Subscribe delivers the messages of the subscription to the handler
+	// until the context is cancelled, and then returns once the message
+	// being handled is done with.
+	Subscribe(ctx context.Context, subscription string, handler Handler) error
+	Close() error
+}
+
+type BusConfig struct {
+	// MaxDeliveries is how often a message is delivered before it is
+	// published to the dead-letter topic of its subscription.
+	MaxDeliveries int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+func DefaultBusConfig() BusConfig {
+	return BusConfig{
+		MaxDeliveries: 5,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: 30 * time.Second,
+	}
+}
+
+// DeadLetterTopic is where the messages of a subscription that could not
+// be handled are published, for inspection.
+func DeadLetterTopic(subscription string) string {
+	return subscription + "_dead_letter"
+}
+
+// NewMessageBus configures the bus named by MESSAGE_BUS: "gcp" for Google
+// Cloud Pub/Sub, "nats" for NATS JetStream at NATS_URL, "postgres" for
+// LISTEN/NOTIFY on the database, or "memory" to stay in the process. When
+// it is not set, Pub/Sub is used if GCP_PROJECT_ID is set, and the memory
+// bus otherwise.
+func NewMessageBus(database DatabaseConfig) (MessageBus, error) {
+	name := os.Getenv("MESSAGE_BUS")
+	if name == "" && os.Getenv("GCP_PROJECT_ID") != "" {
+		name = "gcp"
+	}
+
+	config := DefaultBusConfig()
+
+	var bus MessageBus
+	var err error
+
+	switch name {
+	case "gcp":
+		bus, err = NewPubSub(os.Getenv("GCP_PROJECT_ID"), config)
+	case "nats":
+		bus, err = NewNatsBus(os.Getenv("NATS_URL"), config)
+	case "postgres":
+		bus, err = NewPostgresBus(database.ConnectionString(), config)
+	case "", "memory":
+		bus = NewMemoryBus(config)
+	default:
+		err = fmt.Errorf("unknown message bus %q", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bus, nil
+}
+
+// rejectedError is the failure of a message that no delivery can fix.
+type rejectedError struct {
+	err error
+}
+
+func (e rejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e rejectedError) Unwrap() error {
+	return e.err
+}
+
+// Reject marks the error of a handler as final, e.g. for a malformed
+// message, so that the message is dead-lettered without further
+// deliveries.
+func Reject(err error) error {
+	return rejectedError{err}
+}
+
+func isRejected(err error) bool {
+	var r rejectedError
+	return errors.As(err, &r)
+}
+
+// messageId returns the id the publisher gave the message, or fallback.
+func messageId(attributes map[string]string, fallback string) string {
+	if id := attributes["message_id"]; id != "" {
+		return id
+	}
+	if fallback != "" {
+		return fallback
+	}
+
+	return uuid.NewString()
+}
+
+// handle calls the handler, turning a panic into a failure. The handler
+// gets a context that is not cancelled at shutdown, so that it finishes
+// the message.
+func handle(ctx context.Context, handler Handler, msg Message) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return handler(context.WithoutCancel(ctx), msg)
+}
+
+// deadLetter publishes a message that could not be handled to the
+// dead-letter topic of the subscription.
+func deadLetter(ctx context.Context, bus MessageBus, subscription string, msg Message, cause error) error {
+	attributes := make(map[string]string, len(msg.Attributes)+3)
+	for key, value := range msg.Attributes {
+		attributes[key] = value
+	}
+	attributes["message_id"] = msg.Id
+	attributes["original_topic"] = msg.Topic
+	attributes["dead_letter_reason"] = cause.Error()
+
+	log.Printf("Error: message %s of %s dead-lettered after %d attempts: %v", msg.Id, subscription, msg.Attempt, cause)
+
+	return bus.Publish(context.WithoutCancel(ctx), DeadLetterTopic(subscription), msg.Data, attributes)
+}
+
+// deliver hands a message to the handler, for buses that do not redeliver
+// messages themselves, retrying with backoff until it succeeds, runs out
+// of deliveries or is rejected. Nothing would deliver the message again
+// after a shutdown, so a message still failing then is dead-lettered.
+func deliver(ctx context.Context, bus MessageBus, config BusConfig, subscription string, handler Handler, msg Message) {
+	for msg.Attempt = 1; ; msg.Attempt++ {
+		err := handle(ctx, handler, msg)
+		if err == nil {
+			return
+		}
+
+		if isRejected(err) || msg.Attempt >= config.MaxDeliveries {
+			if err := deadLetter(ctx, bus, subscription, msg, err); err != nil {
+				log.Printf("Error: message %s: %v", msg.Id, err)
+			}
+			return
+		}
+
+		log.Printf("Error: message %s of %s, attempt %d: %v", msg.Id, subscription, msg.Attempt, err)
+
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("shut down before the message was handled: %w", err)
+			if err := deadLetter(ctx, bus, subscription, msg, err); err != nil {
+				log.Printf("Error: message %s: %v", msg.Id, err)
+			}
+			return
+		case <-time.After(retryDelay(config, msg.Attempt+1)):
+		}
+	}
+}
+
+// retryDelay is how long to wait before the given delivery of a message
+// that failed: RetryDelay, doubled for every further attempt up to
+// MaxRetryDelay.
+func retryDelay(config BusConfig, attempt int) time.Duration {
+	delay := config.RetryDelay
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if delay >= config.MaxRetryDelay {
+			return config.MaxRetryDelay
+		}
+	}
+
+	return delay
+}
+
+// envelope carries the id and attributes of a message on buses that only
+// carry a payload.
+type envelope struct {
+	Id         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Data       []byte            `json:"data"`
+}
+
+func encodeEnvelope(data []byte, attributes map[string]string) ([]byte, error) {
+	return json.Marshal(envelope{
+		Id:         messageId(attributes, ""),
+		Attributes: attributes,
+		Data:       data,
+	})
+}
+
+func decodeEnvelope(topic string, payload []byte) (Message, error) {
+	var e envelope
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Id:         messageId(e.Attributes, e.Id),
+		Topic:      topic,
+		Data:       e.Data,
+		Attributes: e.Attributes,
+	}, nil
+}
@@ -1,49 +1,246 @@
 package providers
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"iyaem/internal/app/audit"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
-	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
-// IsAuthenticated is a middleware that checks if
-// the user has already been authenticated previously.
-func IsAuthenticated(ctx *gin.Context) {
-	authorizationHeader := ctx.Request.Header.Get("Authorization")
+// IsAuthenticated is a middleware that verifies the bearer token and
+// stores the resulting *Principal in the context. Tokens of a session are
+// refused once isSessionActive reports the session ended; tokens issued
+// without a session are accepted until they expire. Tokens of a session
+// still waiting for a second factor are refused too.
+//
+// Bearer tokens that are not JWTs are checked with authenticatePat as
+// personal access tokens, which are limited to their scopes and
+// organizations (see checkPersonalAccessToken).
+func IsAuthenticated(
+	verifier *TokenVerifier,
+	isSessionActive func(ctx context.Context, sessionId string) (bool, error),
+	authenticatePat func(ctx context.Context, token string, ipAddress string) (*Principal, error),
+) gin.HandlerFunc {
+	return authenticate(verifier, isSessionActive, authenticatePat, false)
+}
+
+// IsPartiallyAuthenticated is IsAuthenticated for the routes that let a
+// user prove a second factor, which also accepts the tokens of a session
+// waiting for one.
+func IsPartiallyAuthenticated(verifier *TokenVerifier, isSessionActive func(ctx context.Context, sessionId string) (bool, error)) gin.HandlerFunc {
+	return authenticate(verifier, isSessionActive, nil, true)
+}
+
+func authenticate(
+	verifier *TokenVerifier,
+	isSessionActive func(ctx context.Context, sessionId string) (bool, error),
+	authenticatePat func(ctx context.Context, token string, ipAddress string) (*Principal, error),
+	allowMfaPending bool,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := BearerToken(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Unauthorized",
+			})
+			return
+		}
+
+		if authenticatePat != nil && !isJwt(token) {
+			principal, err := authenticatePat(ctx.Request.Context(), token, ctx.ClientIP())
+			if err != nil {
+				log.Printf("Error 9879: %v", err)
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"message": "Invalid Token",
+				})
+				return
+			}
+
+			if !checkPersonalAccessToken(ctx, principal) {
+				return
+			}
+
+			setPrincipal(ctx, principal)
+			ctx.Next()
+			return
+		}
+
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			log.Printf("Error 9876: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Invalid Token",
+			})
+			return
+		}
+
+		if principal.IsMachine() {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Machine tokens are not accepted on this route",
+			})
+			return
+		}
+
+		if principal.MfaPending && !allowMfaPending {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "mfa_required",
+				"message": "Multi-factor authentication required",
+			})
+			return
+		}
+
+		if principal.SessionId != "" && isSessionActive != nil {
+			active, err := isSessionActive(ctx.Request.Context(), principal.SessionId)
+			if err != nil {
+				log.Printf("Error 9878: %v", err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"message": "Internal Server Error",
+				})
+				return
+			}
+
+			if !active {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"message": "Session ended",
+				})
+				return
+			}
+		}
+
+		setPrincipal(ctx, principal)
+		ctx.Next()
+	}
+}
+
+// acrMultiFactor is the "acr" claim of a session verified with a second
+// factor.
+const acrMultiFactor = "aal2"
+
+// RequireStepUp is a middleware for sensitive actions, which runs after
+// IsAuthenticated. It asks for a second factor verified within maxAge,
+// which users prove again through POST /mfa/verify. A maxAge of zero
+// turns the check off.
+func RequireStepUp(maxAge time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if maxAge <= 0 {
+			ctx.Next()
+			return
+		}
+
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Unauthorized",
+			})
+			return
+		}
+
+		if principal.Acr != acrMultiFactor || time.Since(principal.AuthTime) > maxAge {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "step_up_required",
+				"message": "Verify a second factor to perform this action.",
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireScopes is a middleware for machine to machine routes. It accepts
+// client credentials tokens that carry every one of the given scopes.
+func RequireScopes(verifier *TokenVerifier, scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := BearerToken(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Unauthorized",
+			})
+			return
+		}
+
+		principal, err := verifier.Verify(token)
+		if err != nil || !principal.IsMachine() {
+			log.Printf("Error 9877: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Invalid Token",
+			})
+			return
+		}
+
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"message": "Missing scope " + scope,
+				})
+				return
+			}
+		}
+
+		setPrincipal(ctx, principal)
+		ctx.Next()
+	}
+}
+
+// setPrincipal stores the principal in the context and attaches it to the
+// request context as the actor of any change made while handling it.
+func setPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(PrincipalKey, principal)
 
-	if authorizationHeader == "" || len("Bearer ") >= len(authorizationHeader) {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"message": "Unauthorized",
-		})
-		return
+	requestId := ctx.GetHeader("X-Request-Id")
+	if requestId == "" {
+		requestId = uuid.NewString()
 	}
 
-	token := authorizationHeader[len("Bearer "):]
-	claims, err := decodeJWT(token)
-	if err != nil {
-		log.Printf("Error 9876: %v", err)
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"message": "Invalid Token",
-		})
-		return
+	actor := audit.Actor{UserId: principal.UserId, Email: principal.Email, ClientId: principal.ClientId}
+	if principal.IsMachine() {
+		actor.UserId = ""
 	}
-	ctx.Set("token", claims)
-	ctx.Next()
+	if principal.IsPersonalAccessToken() {
+		actor.ClientId = "pat:" + principal.TokenId
+	}
+
+	ctx.Request = ctx.Request.WithContext(audit.WithActor(ctx.Request.Context(), actor, audit.Metadata{
+		IpAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		RequestId: requestId,
+	}))
 }
 
+// BearerToken extracts the token from the Authorization header.
+func BearerToken(ctx *gin.Context) (string, bool) {
+	authorizationHeader := ctx.Request.Header.Get("Authorization")
+
+	if !strings.HasPrefix(authorizationHeader, "Bearer ") || len("Bearer ") >= len(authorizationHeader) {
+		return "", false
+	}
+
+	return authorizationHeader[len("Bearer "):], true
+}
+
+// CORSMiddleware lets applications on any origin call the API. The origin
+// is echoed rather than "*", which browsers refuse with credentials, so
+// that the cookie of an SSO login reaches the callback. Other routes
+// authenticate with bearer tokens, which are not sent by themselves.
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if origin == "" {
+			origin = "*"
+		}
+
 		c.Writer.Header().Set("Content-Type", "application/json")
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Add("Vary", "Origin")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE, UPDATE")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Max, Set-Cookie")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
@@ -55,6 +252,9 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// IsOrganizationManager lets owners and managers of the organization of
+// the request through. Sensitive manager routes add RequireStepUp to ask
+// for a recent second factor as well.
 func IsOrganizationManager(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		type OrgParams struct {
@@ -63,7 +263,8 @@ func IsOrganizationManager(db *sql.DB) gin.HandlerFunc {
 
 		var params OrgParams
 
-		if ctx.Request.Method == "GET" {
+		// File uploads name the organization in the query, like reads.
+		if ctx.Request.Method == "GET" || ctx.ContentType() == "text/csv" || ctx.ContentType() == binding.MIMEMultipartPOSTForm {
 			err := ctx.ShouldBindQuery(&params)
 			if err != nil {
 				log.Printf("Error 6969: %v", err)
@@ -83,18 +284,8 @@ func IsOrganizationManager(db *sql.DB) gin.HandlerFunc {
 			}
 		}
 
-		authorizationHeader := ctx.Request.Header.Get("Authorization")
-
-		if authorizationHeader == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "Unauthorized",
-			})
-			return
-		}
-
-		token := authorizationHeader[len("Bearer "):]
-		claims, err := DecodeJWT(token)
-		if err != nil {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "Unauthorized",
 			})
@@ -103,7 +294,7 @@ func IsOrganizationManager(db *sql.DB) gin.HandlerFunc {
 
 		var level string
 
-		err = db.QueryRow("SELECT level FROM user_organization uo left join public.user u on uo.user_id = u.id  WHERE u.email=$1 and uo.organization_id=$2;", claims["email"], params.OrganizationId).Scan(&level)
+		err := db.QueryRow("SELECT level FROM user_organization uo left join public.user u on uo.user_id = u.id  WHERE u.email=$1 and uo.organization_id=$2;", principal.Email, params.OrganizationId).Scan(&level)
 		if err != nil {
 			log.Printf("Error 6901: %v", err)
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
@@ -181,63 +372,3 @@ func IsTenantValid(db *sql.DB) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
-
-func SetSubDomain(auth *Authenticator) gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		host, err := url.Parse(ctx.Request.Host)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		log.Printf("Host: %v", host)
-
-		hostUrl := strings.TrimSpace(host.String())
-		//Figure out if a subdomain exists in the host given.
-		hostParts := strings.Split(hostUrl, ".")
-
-		log.Printf("Host Parts: %v", hostParts)
-
-		if len(hostParts) > 1 {
-			auth.SetSubDomain(hostParts[0])
-		} else {
-			auth.ResetSubDomain()
-		}
-
-		ctx.Next()
-	}
-}
-
-func DecodeJWT(token string) (map[string]interface{}, error) {
-	tokenInstance, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
-	if err != nil {
-		return nil, err
-	}
-
-	claims, ok := tokenInstance.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid claims")
-	}
-
-	return claims, nil
-}
-
-func IsMachine() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-
-		authorizationHeader := ctx.Request.Header.Get("Authorization")
-
-		token := authorizationHeader[len("Bearer "):]
-
-		log.Printf("API Token: %v\n", token)
-
-		if token != "iam" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "Unauthorized",
-			})
-
-			return
-		}
-
-		ctx.Next()
-	}
-}
@@ -0,0 +1,248 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsAckWait is how long a message may be handled before the server
+// delivers it again.
+const natsAckWait = time.Minute
+
+// NatsBus is a MessageBus on NATS JetStream. Topics are subjects, each kept
+// in a work queue stream of its own, and the instances subscribed to a
+// subject share its messages through a durable consumer. Messages are
+// stored until a subscriber acknowledges them, so they survive restarts
+// and nacked messages are redelivered by the server.
+type NatsBus struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config BusConfig
+
+	mu      sync.Mutex
+	streams map[string]bool
+}
+
+// NewNatsBus connects to the servers at the NATS url, e.g.
+// nats://[user:password@]host[:port], or several of them separated by
+// commas.
+func NewNatsBus(url string, config BusConfig) (*NatsBus, error) {
+	if url == "" {
+		url = nats.DefaultURL
+	}
+
+	conn, err := nats.Connect(url,
+		nats.Name("iyaem"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("Error: NATS disconnected: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NatsBus{
+		conn:    conn,
+		js:      js,
+		config:  config,
+		streams: make(map[string]bool),
+	}, nil
+}
+
+// Publish stores the message in the stream of the topic. The id of the
+// message lets the server drop a message published twice.
+func (b *NatsBus) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error {
+	stream, err := b.stream(ctx, topic)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(topic)
+	msg.Data = data
+	for key, value := range attributes {
+		msg.Header.Set(key, value)
+	}
+
+	_, err = b.js.PublishMsg(ctx, msg, jetstream.WithMsgID(messageId(attributes, "")), jetstream.WithExpectStream(stream))
+	return err
+}
+
+// Subscribe handles the messages of the subject named by the subscription.
+// Failed messages are redelivered by the server after a backoff, until
+// they are dead-lettered.
+func (b *NatsBus) Subscribe(ctx context.Context, subscription string, handler Handler) error {
+	stream, err := b.stream(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       stream,
+		FilterSubject: subscription,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       natsAckWait,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer %s: %w", stream, err)
+	}
+
+	messages, err := consumer.Messages()
+	if err != nil {
+		return err
+	}
+
+	// Stopping the iterator ends Next below at shutdown. The message being
+	// handled is finished first, and the ones not handled yet are
+	// redelivered to another instance.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			messages.Stop()
+		case <-done:
+		}
+	}()
+
+	log.Printf("Subscribed to %s", subscription)
+
+	for {
+		m, err := messages.Next()
+		if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		b.receive(ctx, stream, subscription, handler, m)
+	}
+}
+
+func (b *NatsBus) receive(ctx context.Context, stream string, subscription string, handler Handler, m jetstream.Msg) {
+	metadata, err := m.Metadata()
+	if err != nil {
+		log.Printf("Error: malformed message on %s: %v", subscription, err)
+		m.Term()
+		return
+	}
+
+	attributes := make(map[string]string, len(m.Headers()))
+	for key := range m.Headers() {
+		if !strings.HasPrefix(key, "Nats-") {
+			attributes[key] = m.Headers().Get(key)
+		}
+	}
+
+	// Messages of publishers that set no id are known by their place in
+	// the stream, which stays the same across deliveries.
+	id := m.Headers().Get(nats.MsgIdHdr)
+	if id == "" {
+		id = fmt.Sprintf("%s:%d", stream, metadata.Sequence.Stream)
+	}
+
+	msg := Message{
+		Id:         messageId(attributes, id),
+		Topic:      subscription,
+		Data:       m.Data(),
+		Attributes: attributes,
+		Attempt:    int(metadata.NumDelivered),
+	}
+
+	err = handle(ctx, handler, msg)
+	if err == nil {
+		b.ack(m, msg)
+		return
+	}
+
+	if isRejected(err) || msg.Attempt >= b.config.MaxDeliveries {
+		if err := deadLetter(ctx, b, subscription, msg, err); err != nil {
+			log.Printf("Error: message %s: %v", msg.Id, err)
+			m.NakWithDelay(retryDelay(b.config, msg.Attempt+1))
+			return
+		}
+
+		b.ack(m, msg)
+		return
+	}
+
+	log.Printf("Error: message %s of %s, attempt %d: %v", msg.Id, subscription, msg.Attempt, err)
+	m.NakWithDelay(retryDelay(b.config, msg.Attempt+1))
+}
+
+// ack waits for the server to confirm the ack, so that a message is not
+// delivered again once handled, unless the ack is lost.
+func (b *NatsBus) ack(m jetstream.Msg, msg Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := m.DoubleAck(ctx); err != nil {
+		log.Printf("Error: ack message %s: %v", msg.Id, err)
+	}
+}
+
+// stream creates the stream of the subject unless it was already, and
+// returns its name. Streams are work queues: a message is removed once
+// acknowledged.
+func (b *NatsBus) stream(ctx context.Context, subject string) (string, error) {
+	name := natsStreamName(subject)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.streams[subject] {
+		return name, nil
+	}
+
+	_, err := b.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      name,
+		Subjects:  []string{subject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create stream %s: %w", name, err)
+	}
+
+	b.streams[subject] = true
+	return name, nil
+}
+
+// Close waits for the messages being published, then disconnects.
+func (b *NatsBus) Close() error {
+	if err := b.conn.Flush(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		log.Printf("Error: NATS flush: %v", err)
+	}
+
+	b.conn.Close()
+	return nil
+}
+
+// natsStreamName turns a subject into a stream name, which cannot contain
+// dots, wildcards or whitespace.
+func natsStreamName(subject string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '/', '\\':
+			return '_'
+		}
+		return r
+	}, subject)
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientId     string
+	ClientSecret string
+
+	// CallbackURL and LogoutURL are used when a call does not name its
+	// own redirect URL.
+	CallbackURL string
+	LogoutURL   string
+}
+
+// OIDCProvider signs users in with any OpenID Connect provider found
+// through discovery. Users and callback URLs are managed in the provider
+// itself.
+type OIDCProvider struct {
+	provider *oidc.Provider
+	config   OIDCConfig
+
+	endSessionEndpoint string
+}
+
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata struct {
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return nil, err
+	}
+
+	return &OIDCProvider{provider, config, metadata.EndSessionEndpoint}, nil
+}
+
+func (p *OIDCProvider) oauth2Config(redirectURL string) oauth2.Config {
+	if redirectURL == "" {
+		redirectURL = p.config.CallbackURL
+	}
+
+	return oauth2.Config{
+		ClientID:     p.config.ClientId,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint:     p.provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+	}
+}
+
+func (p *OIDCProvider) AuthCodeURL(state string, redirectURL string, challenge LoginChallenge) string {
+	config := p.oauth2Config(redirectURL)
+
+	options := make([]oauth2.AuthCodeOption, 0, 2)
+	if challenge.Verifier != "" {
+		options = append(options, oauth2.S256ChallengeOption(challenge.Verifier))
+	}
+	if challenge.Nonce != "" {
+		options = append(options, oidc.Nonce(challenge.Nonce))
+	}
+
+	return config.AuthCodeURL(state, options...)
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, redirectURL string, challenge LoginChallenge) (Identity, error) {
+	config := p.oauth2Config(redirectURL)
+
+	options := make([]oauth2.AuthCodeOption, 0, 1)
+	if challenge.Verifier != "" {
+		options = append(options, oauth2.VerifierOption(challenge.Verifier))
+	}
+
+	token, err := config.Exchange(ctx, code, options...)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, errors.New("no id_token field in oauth2 token")
+	}
+
+	idToken, err := p.provider.Verifier(&oidc.Config{ClientID: p.config.ClientId}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if challenge.Nonce != "" && idToken.Nonce != challenge.Nonce {
+		return Identity{}, errors.New("the id_token was not issued for this sign in")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		IssuedAt:      idToken.IssuedAt,
+		ExpiresAt:     idToken.Expiry,
+	}, nil
+}
+
+// LogoutURL uses RP-initiated logout when the provider supports it, and
+// otherwise only returns to the application.
+func (p *OIDCProvider) LogoutURL(returnTo string) (string, error) {
+	if returnTo == "" {
+		returnTo = p.config.LogoutURL
+	}
+
+	if p.endSessionEndpoint == "" {
+		return returnTo, nil
+	}
+
+	logoutUrl, err := url.Parse(p.endSessionEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	parameters := logoutUrl.Query()
+	parameters.Add("client_id", p.config.ClientId)
+	parameters.Add("post_logout_redirect_uri", returnTo)
+	logoutUrl.RawQuery = parameters.Encode()
+
+	return logoutUrl.String(), nil
+}
+
+func (p *OIDCProvider) CreateUser(ctx context.Context, user IdentityProviderUser) (Identity, error) {
+	return Identity{}, ErrNotSupported
+}
+
+func (p *OIDCProvider) AddCallbackURL(ctx context.Context, callbackURL string, logoutURL string) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Scopes of personal access tokens, as granted by
+// entities.CreatePersonalAccessToken.
+const (
+	patScopeRead  = "read"
+	patScopeWrite = "write"
+)
+
+// isJwt tells IAM tokens apart from personal access tokens, which are
+// opaque.
+func isJwt(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// checkPersonalAccessToken limits a request made with a personal access
+// token to the scopes and organizations of the token. Reading requires
+// the read scope and changing the write scope, and the request must name
+// one of the organizations of the token with organization_id, in the path,
+// the query or a JSON body. Handlers read one of them, so a request naming
+// different organizations in several is refused. Routes that do not take
+// an organization refuse personal access tokens instead (see
+// DenyPersonalAccessTokens).
+func checkPersonalAccessToken(ctx *gin.Context, principal *Principal) bool {
+	scope := patScopeWrite
+	if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+		scope = patScopeRead
+	}
+
+	if !principal.HasScope(scope) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "Missing scope " + scope,
+		})
+		return false
+	}
+
+	named := []string{ctx.Param("organization_id"), ctx.Query("organization_id")}
+	if ctx.Request.ContentLength != 0 {
+		var params struct {
+			OrganizationId string `json:"organization_id"`
+		}
+
+		// The body is kept for the handlers, which bind it again.
+		if err := ctx.ShouldBindBodyWith(&params, binding.JSON); err == nil {
+			named = append(named, params.OrganizationId)
+		}
+	}
+
+	organizationId := ""
+	for _, id := range named {
+		if id == "" {
+			continue
+		}
+
+		if organizationId != "" && !strings.EqualFold(organizationId, id) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": "The request names different organizations",
+			})
+			return false
+		}
+
+		organizationId = id
+	}
+
+	for _, id := range principal.OrganizationIds {
+		if organizationId != "" && strings.EqualFold(id, organizationId) {
+			return true
+		}
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"message": "The token is not valid for this organization",
+	})
+	return false
+}
+
+// DenyPersonalAccessTokens is a middleware for the routes personal access
+// tokens cannot call: those that manage the sign in of a user, such as
+// their sessions and tokens, and those that do not take an organization,
+// which the token could not be limited to.
+func DenyPersonalAccessTokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if ok && principal.IsPersonalAccessToken() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": "Personal access tokens are not accepted on this route",
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxNotifyPayload is the largest payload NOTIFY accepts, in bytes.
+const maxNotifyPayload = 8000
+
+// PostgresBus carries messages with LISTEN/NOTIFY, so that a deployment
+// with only a database still runs its event listeners. Topics are
+// channels. Every listening instance receives each message. Delivery is at
+// most once: messages sent while no one listens, or while a listener
+// reconnects, are lost, and nothing delivers them again. Deployments that
+// cannot lose events use the NATS or Pub/Sub bus.
+type PostgresBus struct {
+	db       *sql.DB
+	conninfo string
+	config   BusConfig
+}
+
+func NewPostgresBus(conninfo string, config BusConfig) (*PostgresBus, error) {
+	db, err := sql.Open("postgres", conninfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresBus{
+		db:       db,
+		conninfo: conninfo,
+		config:   config,
+	}, nil
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error {
+	payload, err := encodeEnvelope(data, attributes)
+	if err != nil {
+		return err
+	}
+	if len(payload) >= maxNotifyPayload {
+		return fmt.Errorf("message of %d bytes is too large for NOTIFY", len(payload))
+	}
+
+	_, err = b.db.ExecContext(ctx, `SELECT pg_notify($1, $2);`, topic, string(payload))
+	return err
+}
+
+// Subscribe listens on the channel named by the subscription.
+func (b *PostgresBus) Subscribe(ctx context.Context, subscription string, handler Handler) error {
+	listener := pq.NewListener(b.conninfo, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Error: listener %s: %v", subscription, err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(subscription); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(90 * time.Second):
+			// Checks the connection, which reconnects if it was lost.
+			go listener.Ping()
+		case notification := <-listener.Notify:
+			if notification == nil {
+				log.Printf("Error: listener %s reconnected, messages sent in the meantime were lost", subscription)
+				continue
+			}
+
+			msg, err := decodeEnvelope(subscription, []byte(notification.Extra))
+			if err != nil {
+				// The raw payload is kept for inspection, as the other
+				// buses do with the messages their handlers reject.
+				msg = Message{Id: messageId(nil, ""), Topic: subscription, Data: []byte(notification.Extra), Attempt: 1}
+				if err := deadLetter(ctx, b, subscription, msg, fmt.Errorf("malformed envelope: %w", err)); err != nil {
+					log.Printf("Error: message %s: %v", msg.Id, err)
+				}
+				continue
+			}
+
+			deliver(ctx, b, b.config, subscription, handler, msg)
+		}
+	}
+}
+
+func (b *PostgresBus) Close() error {
+	return b.db.Close()
+}
@@ -2,61 +2,101 @@ package providers
 
 import (
 	"context"
-	"encoding/json"
 	"log"
+	"sync"
 
 	"cloud.google.com/go/pubsub"
 )
 
+// PubSub is a MessageBus on Google Cloud Pub/Sub. Nacked messages are
+// redelivered by Pub/Sub. The number of deliveries is only known for
+// subscriptions with a dead-letter policy, which then dead-letter
+// messages themselves; this bus dead-letters a message once that number
+// reaches MaxDeliveries, in case the policy allows more.
 type PubSub struct {
-	client        *pubsub.Client
-	context       context.Context
-	subscriptions map[string]*pubsub.Subscription
+	client   *pubsub.Client
+	config   BusConfig
+	topics   map[string]*pubsub.Topic
+	topicsMu sync.Mutex
 }
 
-type Callback func(ctx context.Context, payload map[string]interface{})
-
-func NewPubSub(projectId string) (*PubSub, error) {
-	ctx := context.Background()
-
-	client, err := pubsub.NewClient(ctx, projectId)
+func NewPubSub(projectId string, config BusConfig) (*PubSub, error) {
+	client, err := pubsub.NewClient(context.Background(), projectId)
 	if err != nil {
-		log.Fatalf("Failed to create pubsub client: %v", err)
+		return nil, err
 	}
 
 	return &PubSub{
-		client:        client,
-		context:       ctx,
-		subscriptions: make(map[string]*pubsub.Subscription),
+		client: client,
+		config: config,
+		topics: make(map[string]*pubsub.Topic),
 	}, nil
 }
 
-func (p *PubSub) CloseConnection() {
-	p.client.Close()
+func (p *PubSub) Close() error {
+	p.topicsMu.Lock()
+	for _, topic := range p.topics {
+		topic.Stop()
+	}
+	p.topicsMu.Unlock()
+
+	return p.client.Close()
 }
 
-func (p *PubSub) Subscribe(subscriptionId string, callbacks []Callback) error {
+// Publish sends a message to the given topic and blocks until the server
+// has acknowledged it.
+func (p *PubSub) Publish(ctx context.Context, topicId string, data []byte, attributes map[string]string) error {
+	p.topicsMu.Lock()
+	topic, ok := p.topics[topicId]
+	if !ok {
+		topic = p.client.Topic(topicId)
+		p.topics[topicId] = topic
+	}
+	p.topicsMu.Unlock()
 
-	err := p.client.Subscription(subscriptionId).Receive(p.context, func(ctx context.Context, msg *pubsub.Message) {
+	result := topic.Publish(ctx, &pubsub.Message{
+		Data:       data,
+		Attributes: attributes,
+	})
 
-		var payload map[string]interface{}
-		err := json.Unmarshal(msg.Data, &payload)
-		if err != nil {
-			log.Printf("Failed to unmarshal message: %v", err)
-		}
+	_, err := result.Get(ctx)
+	return err
+}
 
-		for _, callback := range callbacks {
-			callback(ctx, payload)
+// Subscribe receives the messages of the Pub/Sub subscription. Receive
+// waits for the handlers to return before it does.
+func (p *PubSub) Subscribe(ctx context.Context, subscriptionId string, handler Handler) error {
+	log.Printf("Subscribed to %s", subscriptionId)
+
+	return p.client.Subscription(subscriptionId).Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+		msg := Message{
+			Id:         messageId(m.Attributes, m.ID),
+			Topic:      subscriptionId,
+			Data:       m.Data,
+			Attributes: m.Attributes,
+		}
+		if m.DeliveryAttempt != nil {
+			msg.Attempt = *m.DeliveryAttempt
 		}
 
-		msg.Ack()
-	})
+		err := handle(ctx, handler, msg)
+		if err == nil {
+			m.Ack()
+			return
+		}
 
-	if err != nil {
-		log.Fatalf("Failed to receive message: %v", err)
-	}
+		if isRejected(err) || msg.Attempt >= p.config.MaxDeliveries {
+			if err := deadLetter(ctx, p, subscriptionId, msg, err); err != nil {
+				log.Printf("Error: message %s: %v", msg.Id, err)
+				m.Nack()
+				return
+			}
 
-	log.Printf("subscribed to %s", subscriptionId)
+			m.Ack()
+			return
+		}
 
-	return nil
+		log.Printf("Error: message %s of %s, attempt %d: %v", msg.Id, subscriptionId, msg.Attempt, err)
+		m.Nack()
+	})
 }
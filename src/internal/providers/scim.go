@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ScimClientKey = "scim_client"
+
+// ScimClient is the provisioning client of an organization, identified by
+// its SCIM token. TenantId is empty for a token not bound to a tenant.
+type ScimClient struct {
+	TokenId        string
+	OrganizationId string
+	TenantId       string
+}
+
+// IsScimClient authenticates the bearer token of a SCIM request with
+// authenticate. Changes made by the client are recorded with the token as
+// the actor.
+func IsScimClient(authenticate func(ctx context.Context, token string) (ScimClient, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, _ := BearerToken(ctx)
+
+		client, err := authenticate(ctx.Request.Context(), token)
+		if err != nil {
+			log.Printf("Error 9878: %v", err)
+			ctx.Header("Content-Type", "application/scim+json")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+				"status":  "401",
+				"detail":  "Invalid token",
+			})
+			return
+		}
+
+		ctx.Set(ScimClientKey, &client)
+		setPrincipal(ctx, &Principal{ClientId: "scim:" + client.TokenId})
+		ctx.Next()
+	}
+}
+
+// GetScimClient returns the client stored by IsScimClient.
+func GetScimClient(ctx *gin.Context) (*ScimClient, bool) {
+	value, ok := ctx.Get(ScimClientKey)
+	if !ok {
+		return nil, false
+	}
+
+	client, ok := value.(*ScimClient)
+	return client, ok
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealedPrefix marks the values sealed by a SecretBox, which tells them
+// from the ones stored before secrets were encrypted.
+const sealedPrefix = "sealed:v1:"
+
+// SecretBox encrypts the secrets the service has to read back, such as
+// signing keys, TOTP keys and the client secrets of SSO connections, so
+// that a copy of the database alone does not reveal them. Secrets it only
+// compares are hashed instead.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox uses AES-256-GCM with the 32 byte key.
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("the secret key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretBox{aead}, nil
+}
+
+// NewSecretBoxFromEnv uses the base64 encoded key in SECRET_ENCRYPTION_KEY,
+// e.g. generated with `openssl rand -base64 32`.
+func NewSecretBoxFromEnv() (*SecretBox, error) {
+	encoded := os.Getenv("SECRET_ENCRYPTION_KEY")
+	if encoded == "" {
+		return nil, errors.New("SECRET_ENCRYPTION_KEY is not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("SECRET_ENCRYPTION_KEY is not base64: %w", err)
+	}
+
+	return NewSecretBox(key)
+}
+
+// Seal encrypts the secret. The binding, e.g. the column and the id of the
+// row, is authenticated with it, so that a sealed value copied to another
+// row does not open.
+func (b *SecretBox) Seal(secret string, binding string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(secret), []byte(binding))
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed with the same binding. Values stored before
+// secrets were encrypted are returned as they are.
+func (b *SecretBox) Open(value string, binding string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", errors.New("malformed sealed secret")
+	}
+
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	secret, err := b.aead.Open(nil, nonce, ciphertext, []byte(binding))
+	if err != nil {
+		return "", errors.New("could not open sealed secret")
+	}
+
+	return string(secret), nil
+}
+
+// IsSealed reports whether the value was sealed by a SecretBox.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
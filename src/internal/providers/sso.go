@@ -0,0 +1,70 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// SsoProviders keeps the identity provider of each SSO connection, so that
+// discovery runs once per connection and again only when its settings
+// change.
+type SsoProviders struct {
+	mu        sync.Mutex
+	providers map[string]ssoProvider
+
+	callbackURL string
+	open        func(ctx context.Context, config OIDCConfig) (IdentityProvider, error)
+}
+
+type ssoProvider struct {
+	config   OIDCConfig
+	provider IdentityProvider
+}
+
+// NewSsoProviders creates the providers of SSO connections, sending users
+// back to SSO_CALLBACK_URL unless a login names its own callback.
+func NewSsoProviders() *SsoProviders {
+	return NewSsoProvidersWith(os.Getenv("SSO_CALLBACK_URL"), func(ctx context.Context, config OIDCConfig) (IdentityProvider, error) {
+		return NewOIDCProvider(ctx, config)
+	})
+}
+
+// NewSsoProvidersWith creates the providers of SSO connections with open,
+// e.g. to use in-memory providers in tests.
+func NewSsoProvidersWith(callbackURL string, open func(ctx context.Context, config OIDCConfig) (IdentityProvider, error)) *SsoProviders {
+	return &SsoProviders{
+		providers:   make(map[string]ssoProvider),
+		callbackURL: callbackURL,
+		open:        open,
+	}
+}
+
+// For returns the identity provider of the connection with the given id.
+func (p *SsoProviders) For(ctx context.Context, connectionId string, issuerURL string, clientId string, clientSecret string) (IdentityProvider, error) {
+	config := OIDCConfig{
+		IssuerURL:    issuerURL,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		CallbackURL:  p.callbackURL,
+	}
+
+	p.mu.Lock()
+	cached, ok := p.providers[connectionId]
+	p.mu.Unlock()
+
+	if ok && cached.config == config {
+		return cached.provider, nil
+	}
+
+	provider, err := p.open(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.providers[connectionId] = ssoProvider{config, provider}
+	p.mu.Unlock()
+
+	return provider, nil
+}
@@ -0,0 +1,63 @@
+package domain_test
+
+import (
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+	"time"
+)
+
+const testClientSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestApiClient(t *testing.T) entities.ApiClient {
+	secretHash, err := vo.NewSecretHash(testClientSecret)
+	if err != nil {
+		t.Fatalf("NewSecretHash() failed: %v", err)
+	}
+
+	return entities.NewApiClient(
+		vo.GenerateApiClientId(),
+		"billing-job",
+		secretHash,
+		[]string{"organizations:read", "authorize"},
+		time.Now(),
+		nil,
+	)
+}
+
+func TestApiClientAuthenticate(t *testing.T) {
+	client := newTestApiClient(t)
+
+	if err := client.Authenticate(testClientSecret); err != nil {
+		t.Fatalf("Authenticate() failed: %v", err)
+	}
+
+	if err := client.Authenticate("wrong"); err == nil {
+		t.Fatalf("Authenticate() failed, wrong secret accepted")
+	}
+
+	client.Revoke()
+
+	if err := client.Authenticate(testClientSecret); err == nil {
+		t.Fatalf("Authenticate() failed, revoked client accepted")
+	}
+}
+
+func TestApiClientGrantScopes(t *testing.T) {
+	client := newTestApiClient(t)
+
+	scopes, err := client.GrantScopes(nil)
+	if err != nil || len(scopes) != 2 {
+		t.Fatalf("GrantScopes() failed, expected all scopes, got %v", scopes)
+	}
+
+	scopes, err = client.GrantScopes([]string{"authorize"})
+	if err != nil || len(scopes) != 1 {
+		t.Fatalf("GrantScopes() failed, expected requested scope, got %v", scopes)
+	}
+
+	_, err = client.GrantScopes([]string{"organizations:write"})
+	if err == nil {
+		t.Fatalf("GrantScopes() failed, unregistered scope granted")
+	}
+}
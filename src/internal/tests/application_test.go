@@ -0,0 +1,170 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+)
+
+func TestAddDuplicatePermission(t *testing.T) {
+	app, err := entities.CreateApplication("Billing")
+	if err != nil {
+		t.Fatalf("CreateApplication() failed: %v", err)
+	}
+
+	if _, err := app.AddPermission("invoice.read"); err != nil {
+		t.Fatalf("AddPermission() failed: %v", err)
+	}
+
+	_, err = app.AddPermission("Invoice.Read")
+	if !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("AddPermission() failed, expected conflict, got %v", err)
+	}
+
+	if len(app.Permissions()) != 1 {
+		t.Fatalf("AddPermission() failed, expected 1 permission, got %d", len(app.Permissions()))
+	}
+}
+
+func TestRenameAndRemovePermission(t *testing.T) {
+	app, _ := entities.CreateApplication("Billing")
+	read, _ := app.AddPermission("invoice.read")
+	app.AddPermission("invoice.write")
+
+	err := app.RenamePermission(read.Id(), "invoice.write")
+	if !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("RenamePermission() failed, expected conflict, got %v", err)
+	}
+
+	if err := app.RenamePermission(read.Id(), "invoice.view"); err != nil {
+		t.Fatalf("RenamePermission() failed: %v", err)
+	}
+
+	if err := app.RemovePermission(read.Id()); err != nil {
+		t.Fatalf("RemovePermission() failed: %v", err)
+	}
+
+	if app.FindPermissionById(read.Id()) != nil {
+		t.Fatalf("RemovePermission() failed, permission still present")
+	}
+
+	err = app.RemovePermission(read.Id())
+	if !errors.Is(err, entities.ErrNotFound) {
+		t.Fatalf("RemovePermission() failed, expected not found, got %v", err)
+	}
+}
+
+func TestCreateRoleRequiresName(t *testing.T) {
+	_, err := entities.CreateRole(" ", "", vo.GenerateApplicationId())
+	if !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("CreateRole() failed, expected invalid, got %v", err)
+	}
+}
+
+func TestAttachPermissionToRole(t *testing.T) {
+	app, _ := entities.CreateApplication("Billing")
+	read, _ := app.AddPermission("invoice.read")
+
+	role, _ := entities.CreateRole("Viewer", "", app.Id())
+
+	if err := role.AttachPermission(read); err != nil {
+		t.Fatalf("AttachPermission() failed: %v", err)
+	}
+
+	if err := role.AttachPermission(read); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("AttachPermission() failed, expected conflict, got %v", err)
+	}
+
+	other, _ := entities.CreateApplication("Other")
+	foreign, _ := other.AddPermission("invoice.read")
+
+	if err := role.AttachPermission(foreign); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("AttachPermission() failed, expected invalid, got %v", err)
+	}
+
+	if err := role.DetachPermission(read.Id()); err != nil {
+		t.Fatalf("DetachPermission() failed: %v", err)
+	}
+
+	last := role.Events()[len(role.Events())-1]
+	if _, ok := last.(events.PermissionDetachedFromRole); !ok {
+		t.Fatalf("DetachPermission() failed, expected event, got %v", last.Name())
+	}
+}
+
+func TestAttachRoleToGroup(t *testing.T) {
+	appId := vo.GenerateApplicationId()
+	role, _ := entities.CreateRole("Viewer", "", appId)
+	group, _ := entities.CreateGroup("Finance", "", appId)
+
+	if err := group.AttachRole(role); err != nil {
+		t.Fatalf("AttachRole() failed: %v", err)
+	}
+
+	if len(group.Roles()) != 1 {
+		t.Fatalf("AttachRole() failed, expected 1 role, got %d", len(group.Roles()))
+	}
+
+	foreign, _ := entities.CreateRole("Viewer", "", vo.GenerateApplicationId())
+	if err := group.AttachRole(foreign); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("AttachRole() failed, expected invalid, got %v", err)
+	}
+
+	if err := group.DetachRole(role.Id()); err != nil {
+		t.Fatalf("DetachRole() failed: %v", err)
+	}
+
+	if err := group.DetachRole(role.Id()); !errors.Is(err, entities.ErrNotFound) {
+		t.Fatalf("DetachRole() failed, expected not found, got %v", err)
+	}
+}
+
+func TestTenantRoleAvailability(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+	appId := vo.GenerateApplicationId()
+	tenant := entities.NewTenant(vo.GenerateTenantId(), orgId, appId)
+	other := entities.NewTenant(vo.GenerateTenantId(), vo.GenerateOrganizationId(), appId)
+
+	shared, _ := entities.CreateRole("Viewer", "", appId)
+	custom, err := entities.CreateTenantRole("Auditor", "", tenant)
+	if err != nil {
+		t.Fatalf("CreateTenantRole() failed: %v", err)
+	}
+
+	if !custom.ApplicationId().Equals(appId) || custom.TenantId() == nil {
+		t.Fatalf("CreateTenantRole() failed, wrong owner")
+	}
+
+	if !shared.IsAvailableIn(tenant) || !shared.IsAvailableIn(other) {
+		t.Fatalf("IsAvailableIn() failed, application role should be shared")
+	}
+
+	if !custom.IsAvailableIn(tenant) || custom.IsAvailableIn(other) {
+		t.Fatalf("IsAvailableIn() failed, tenant role leaked to another tenant")
+	}
+}
+
+func TestAttachTenantRoleToGroup(t *testing.T) {
+	appId := vo.GenerateApplicationId()
+	tenant := entities.NewTenant(vo.GenerateTenantId(), vo.GenerateOrganizationId(), appId)
+	other := entities.NewTenant(vo.GenerateTenantId(), vo.GenerateOrganizationId(), appId)
+
+	custom, _ := entities.CreateTenantRole("Auditor", "", tenant)
+	shared, _ := entities.CreateGroup("Finance", "", appId)
+	own, _ := entities.CreateTenantGroup("Finance", "", tenant)
+	foreign, _ := entities.CreateTenantGroup("Finance", "", other)
+
+	if err := shared.AttachRole(custom); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("AttachRole() failed, tenant role attached to application group")
+	}
+
+	if err := foreign.AttachRole(custom); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("AttachRole() failed, tenant role attached to another tenant")
+	}
+
+	if err := own.AttachRole(custom); err != nil {
+		t.Fatalf("AttachRole() failed: %v", err)
+	}
+}
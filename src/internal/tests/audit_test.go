@@ -0,0 +1,71 @@
+package domain_test
+
+import (
+	"context"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/domain/entities"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+)
+
+func TestAuditEntriesRecordActorAndChanges(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+	member := entities.NewMembership(
+		vo.GenerateMembershipId(),
+		vo.GenerateUserId(),
+		orgId,
+		"member",
+		make([]vo.UserRole, 0),
+		make([]vo.UserGroup, 0),
+	)
+	org := entities.NewOrganization(orgId, "Test Corp", "test_corp", []entities.Membership{member}, make([]entities.Tenant, 0))
+
+	org.PromoteMember(member)
+	org.RemoveMember(vo.MembershipId{}, member.Id())
+
+	ctx := audit.WithActor(context.Background(),
+		audit.Actor{UserId: "user-1", Email: "admin@test.com"},
+		audit.Metadata{IpAddress: "10.0.0.1", RequestId: "req-1"},
+	)
+
+	entries := audit.Entries(ctx, audit.Scope{OrganizationId: orgId.Value()}, "organization", orgId.Value(), org.Events())
+	if len(entries) != 2 {
+		t.Fatalf("Entries() failed, expected 2 entries, got %d", len(entries))
+	}
+
+	promoted := entries[0]
+	if promoted.Action != "member_promoted" || promoted.TargetId != member.Id().Value() {
+		t.Fatalf("Entries() failed, wrong target %v", promoted)
+	}
+
+	if promoted.Before["level"] != "member" || promoted.After["level"] != "manager" {
+		t.Fatalf("Entries() failed, wrong changes %v -> %v", promoted.Before, promoted.After)
+	}
+
+	if promoted.Actor.Email != "admin@test.com" || promoted.Metadata.RequestId != "req-1" || promoted.OrganizationId != orgId.Value() {
+		t.Fatalf("Entries() failed, wrong actor or metadata %v", promoted)
+	}
+
+	removed := entries[1]
+	if removed.Action != "member_removed" || removed.Before["level"] != "manager" || removed.After != nil {
+		t.Fatalf("Entries() failed, wrong removal %v", removed)
+	}
+}
+
+func TestAuditEntriesForTenantRole(t *testing.T) {
+	tenant := entities.NewTenant(vo.GenerateTenantId(), vo.GenerateOrganizationId(), vo.GenerateApplicationId())
+	role, _ := entities.CreateTenantRole("Auditor", "", tenant)
+	role.Update("Auditor", "Reads invoices")
+
+	tenantId := tenant.Id()
+	entries := audit.Entries(context.Background(), audit.Scope{TenantId: tenantId.Value()}, "role", role.Id().Value(), role.Events())
+
+	updated := entries[1]
+	if updated.TenantId != tenantId.Value() || updated.Before["description"] != "" || updated.After["description"] != "Reads invoices" {
+		t.Fatalf("Entries() failed, wrong update %v", updated)
+	}
+
+	if updated.Actor != (audit.Actor{}) {
+		t.Fatalf("Entries() failed, expected no actor outside a request, got %v", updated.Actor)
+	}
+}
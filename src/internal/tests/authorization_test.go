@@ -0,0 +1,110 @@
+package domain_test
+
+import (
+	"context"
+	"iyaem/internal/app/authorization"
+	"testing"
+)
+
+type memoryGrantQuery struct {
+	members map[string]bool
+	grants  []authorization.Grant
+}
+
+func (q *memoryGrantQuery) IsMemberOfTenant(ctx context.Context, membershipId string, tenantId string) (bool, error) {
+	return q.members[membershipId+"/"+tenantId], nil
+}
+
+func (q *memoryGrantQuery) Grants(ctx context.Context, membershipId string, tenantId string) ([]authorization.Grant, error) {
+	return q.grants, nil
+}
+
+func (q *memoryGrantQuery) UserGrants(ctx context.Context, userId string) ([]authorization.Grant, error) {
+	return q.grants, nil
+}
+
+func newTestEvaluator() *authorization.Evaluator {
+	return authorization.NewEvaluator(&memoryGrantQuery{
+		members: map[string]bool{"member-1/tenant-1": true},
+		grants: []authorization.Grant{
+			{PermissionName: "invoice.read", RoleId: "role-1", RoleName: "Viewer"},
+			{PermissionName: "invoice.write", RoleId: "role-2", RoleName: "Editor", GroupId: "group-1", GroupName: "Finance"},
+		},
+	})
+}
+
+func TestAuthorizeDirectRole(t *testing.T) {
+	decision, err := newTestEvaluator().Check(context.Background(), authorization.CheckRequest{
+		MembershipId: "member-1", TenantId: "tenant-1", Permission: "invoice.read",
+	})
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+
+	if !decision.Allowed || len(decision.Reasons) != 1 || decision.Reasons[0].Via != "role" {
+		t.Fatalf("Check() failed, expected direct role grant, got %v", decision)
+	}
+}
+
+func TestAuthorizeGroupInheritedRole(t *testing.T) {
+	decision, _ := newTestEvaluator().Check(context.Background(), authorization.CheckRequest{
+		MembershipId: "member-1", TenantId: "tenant-1", Permission: "invoice.write",
+	})
+
+	if !decision.Allowed || decision.Reasons[0].Via != "group" || decision.Reasons[0].GroupName != "Finance" {
+		t.Fatalf("Check() failed, expected group grant, got %v", decision)
+	}
+}
+
+func TestAuthorizeDenied(t *testing.T) {
+	decisions, _ := newTestEvaluator().CheckBatch(context.Background(), []authorization.CheckRequest{
+		{MembershipId: "member-1", TenantId: "tenant-1", Permission: "invoice.delete"},
+		{MembershipId: "member-2", TenantId: "tenant-1", Permission: "invoice.read"},
+	})
+
+	for _, decision := range decisions {
+		if decision.Allowed {
+			t.Fatalf("CheckBatch() failed, expected deny, got %v", decision)
+		}
+	}
+}
+
+func TestEnricherEmbedsTenantClaims(t *testing.T) {
+	grants := &memoryGrantQuery{grants: []authorization.Grant{
+		{TenantId: "tenant-1", PermissionName: "invoice.read", RoleName: "Viewer"},
+		{TenantId: "tenant-1", PermissionName: "invoice.write", RoleName: "Editor", GroupName: "Finance"},
+		{TenantId: "tenant-2", RoleName: "Empty"},
+	}}
+
+	enricher := authorization.NewTokenEnricher(grants, authorization.EnricherConfig{MaxBytes: 4096})
+	tenants, _ := enricher.TenantClaims(context.Background(), "user-1")
+
+	if len(tenants["tenant-1"].Permissions) != 2 || len(tenants["tenant-1"].Roles) != 2 {
+		t.Fatalf("TenantClaims() failed, got %v", tenants["tenant-1"])
+	}
+
+	if len(tenants["tenant-2"].Roles) != 1 || len(tenants["tenant-2"].Permissions) != 0 {
+		t.Fatalf("TenantClaims() failed, got %v", tenants["tenant-2"])
+	}
+
+	claims, _ := enricher.Claims(context.Background(), "user-1")
+	if _, ok := claims["authz"]; !ok {
+		t.Fatalf("Claims() failed, expected authz claim")
+	}
+}
+
+func TestEnricherFallsBackToReference(t *testing.T) {
+	grants := &memoryGrantQuery{grants: []authorization.Grant{
+		{TenantId: "tenant-1", PermissionName: "invoice.read", RoleName: "Viewer"},
+	}}
+
+	enricher := authorization.NewTokenEnricher(grants, authorization.EnricherConfig{
+		MaxBytes:     10,
+		ReferenceUrl: "https://iam.test/user/permissions",
+	})
+
+	claims, _ := enricher.Claims(context.Background(), "user-1")
+	if claims["authz_ref"] != "https://iam.test/user/permissions" || claims["authz"] != nil {
+		t.Fatalf("Claims() failed, expected reference claim, got %v", claims)
+	}
+}
@@ -115,9 +115,18 @@ func TestMemberDemotedEvent(t *testing.T) {
 		make([]vo.UserRole, 0),
 		make([]vo.UserGroup, 0),
 	)
+	owner := entities.NewMembership(
+		vo.GenerateMembershipId(),
+		vo.GenerateUserId(),
+		orgId,
+		"owner",
+		make([]vo.UserRole, 0),
+		make([]vo.UserGroup, 0),
+	)
 
 	org.AddMember(member)
-	org.DemoteMember(member)
+	org.AddMember(owner)
+	org.DemoteMember(owner.Id(), member)
 
 	found := false
 	for _, e := range org.Events() {
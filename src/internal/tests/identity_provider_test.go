@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"context"
+	"iyaem/internal/providers"
+	"net/url"
+	"testing"
+)
+
+var _ providers.IdentityProvider = (*providers.InMemoryIdentityProvider)(nil)
+
+func TestInMemoryIdentityProviderSignIn(t *testing.T) {
+	ctx := context.Background()
+	idp := providers.NewInMemoryIdentityProvider()
+
+	user, err := idp.CreateUser(ctx, providers.IdentityProviderUser{Email: "Jane@Example.com", Name: "Jane", Password: "secret"})
+	if err != nil {
+		t.Fatalf("CreateUser() failed, %v", err)
+	}
+
+	if _, err := idp.CreateUser(ctx, providers.IdentityProviderUser{Email: "jane@example.com"}); err == nil {
+		t.Fatalf("CreateUser() failed, expected a duplicate email to be rejected")
+	}
+
+	code := idp.IssueCode(user.Subject)
+
+	identity, err := idp.Exchange(ctx, code, "", providers.LoginChallenge{})
+	if err != nil {
+		t.Fatalf("Exchange() failed, %v", err)
+	}
+
+	if identity.Subject != user.Subject || identity.Email != "jane@example.com" || !identity.EmailVerified || !identity.ExpiresAt.After(identity.IssuedAt) {
+		t.Fatalf("Exchange() failed, got %v", identity)
+	}
+
+	if _, err := idp.Exchange(ctx, code, "", providers.LoginChallenge{}); err == nil {
+		t.Fatalf("Exchange() failed, expected a code to be usable once")
+	}
+}
+
+func TestInMemoryIdentityProviderSignInAs(t *testing.T) {
+	ctx := context.Background()
+	idp := providers.NewInMemoryIdentityProvider()
+
+	user, _ := idp.CreateUser(ctx, providers.IdentityProviderUser{Email: "jane@example.com"})
+	idp.SignInAs(user.Subject)
+
+	signIn, err := url.Parse(idp.AuthCodeURL("state-1", "https://app.example.com/callback", providers.LoginChallenge{}))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() failed, %v", err)
+	}
+
+	if signIn.Host != "app.example.com" || signIn.Query().Get("state") != "state-1" {
+		t.Fatalf("AuthCodeURL() failed, got %v", signIn)
+	}
+
+	identity, err := idp.Exchange(ctx, signIn.Query().Get("code"), "https://app.example.com/callback", providers.LoginChallenge{})
+	if err != nil || identity.Subject != user.Subject {
+		t.Fatalf("Exchange() failed, got %v, %v", identity, err)
+	}
+}
+
+func TestInMemoryIdentityProviderCallbacks(t *testing.T) {
+	idp := providers.NewInMemoryIdentityProvider()
+
+	err := idp.AddCallbackURL(context.Background(), "https://acme.example.com/callback", "https://acme.example.com")
+	if err != nil {
+		t.Fatalf("AddCallbackURL() failed, %v", err)
+	}
+
+	if idp.Callbacks()["https://acme.example.com/callback"] != "https://acme.example.com" {
+		t.Fatalf("AddCallbackURL() failed, got %v", idp.Callbacks())
+	}
+}
@@ -0,0 +1,207 @@
+package domain_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/infrastructure/jobs"
+	"iyaem/internal/infrastructure/listeners"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryInbox keeps the mark of a message only when its handler succeeds,
+// as the transaction of the database inbox does.
+type memoryInbox struct {
+	processed map[string]bool
+}
+
+func (i *memoryInbox) Process(ctx context.Context, consumer string, messageId string, handle func(ctx context.Context) error) error {
+	key := consumer + "/" + messageId
+	if i.processed[key] {
+		return nil
+	}
+
+	if err := handle(ctx); err != nil {
+		return err
+	}
+
+	i.processed[key] = true
+	return nil
+}
+
+func TestOnceProcessesEachEventOnce(t *testing.T) {
+	inbox := &memoryInbox{processed: make(map[string]bool)}
+
+	calls := 0
+	fail := true
+	handle := func(ctx context.Context, event listeners.Event[listeners.TenantPersistedData]) error {
+		calls++
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	}
+
+	once := listeners.Once(inbox, "tenant_persisted.add_tenant", handle)
+	other := listeners.Once(inbox, "tenant_persisted.audit", handle)
+	event := listeners.Event[listeners.TenantPersistedData]{Id: "e1"}
+
+	if err := once(context.Background(), event); err == nil {
+		t.Fatalf("Once() failed, expected the error of the handler")
+	}
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		if err := once(context.Background(), event); err != nil {
+			t.Fatalf("Once() failed, %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("Once() failed, expected a failed event to be processed again and a redelivered one not, got %d calls", calls)
+	}
+
+	if err := other(context.Background(), event); err != nil || calls != 3 {
+		t.Fatalf("Once() failed, expected another consumer to process the event, got %d calls (%v)", calls, err)
+	}
+}
+
+// recordingConnector is a database driver that records the statements run
+// on each connection, to tell whether they share a transaction. The
+// unchanged statement affects no rows, as when its guard does not match.
+type recordingConnector struct {
+	mu        sync.Mutex
+	conns     int
+	log       []string
+	duplicate bool
+	unchanged string
+}
+
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conns++
+	return &recordingConn{c, c.conns}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *recordingConnector) record(id int, statement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.log = append(c.log, fmt.Sprintf("conn%d %s", id, statement))
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+	id        int
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.connector.record(c.id, "BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	c.connector.record(c.id, "COMMIT")
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	c.connector.record(c.id, "ROLLBACK")
+	return nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	statement := strings.Join(strings.Fields(query)[:3], " ")
+	c.connector.record(c.id, statement)
+
+	if statement == "INSERT INTO inbox" && c.connector.duplicate || statement == c.connector.unchanged {
+		return driver.RowsAffected(0), nil
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+// QueryContext answers the RETURNING id of an insert with the first
+// argument.
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.connector.record(c.id, strings.Join(strings.Fields(query)[:3], " "))
+	return &recordingRows{values: []driver.Value{args[0].Value}}, nil
+}
+
+type recordingRows struct {
+	values []driver.Value
+}
+
+func (r *recordingRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *recordingRows) Close() error {
+	return nil
+}
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func TestInboxRepositoryProcessesInTransaction(t *testing.T) {
+	enqueue := func(db *sql.DB, fail bool) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			// The handler writes through a repository that joins the
+			// transaction of its context.
+			_, err := postgresql.NewJobRepository(db).Enqueue(ctx, jobs.Job{Id: "job-1", Type: "test", Payload: []byte(`{}`)})
+			if err != nil || fail {
+				return errors.New("unavailable")
+			}
+			return nil
+		}
+	}
+
+	cases := []struct {
+		name      string
+		duplicate bool
+		fail      bool
+		expected  []string
+	}{
+		{"processed", false, false, []string{"conn1 BEGIN", "conn1 INSERT INTO inbox", "conn1 INSERT INTO job", "conn1 COMMIT"}},
+		{"failed", false, true, []string{"conn1 BEGIN", "conn1 INSERT INTO inbox", "conn1 INSERT INTO job", "conn1 ROLLBACK"}},
+		{"redelivered", true, false, []string{"conn1 BEGIN", "conn1 INSERT INTO inbox", "conn1 ROLLBACK"}},
+	}
+
+	for _, c := range cases {
+		connector := &recordingConnector{duplicate: c.duplicate}
+		db := sql.OpenDB(connector)
+
+		postgresql.NewInboxRepository(db).Process(context.Background(), "consumer", "m1", enqueue(db, c.fail))
+		db.Close()
+
+		if !reflect.DeepEqual(connector.log, c.expected) {
+			t.Fatalf("Process() failed for a %s message, expected %v, got %v", c.name, c.expected, connector.log)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/app/mail"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateInvitation(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+
+	invitation, token, err := entities.CreateInvitation(orgId, " Jane@Example.com ", "manager", "inviter", time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvitation() failed, %v", err)
+	}
+
+	if invitation.Email() != "jane@example.com" || invitation.Status() != entities.InvitationPending {
+		t.Fatalf("CreateInvitation() failed, got %v %v", invitation.Email(), invitation.Status())
+	}
+
+	// Only the hash of the token is kept, and it identifies the invitation.
+	if token == "" || invitation.TokenHash() != vo.NewTokenHash(token) || invitation.TokenHash().Value() == token {
+		t.Fatalf("CreateInvitation() failed, token is not hashed")
+	}
+
+	created, ok := invitation.Events()[0].(events.InvitationCreated)
+	if !ok || created.Email != "jane@example.com" || created.Level != "manager" || created.InvitedBy != "inviter" {
+		t.Fatalf("CreateInvitation() failed, wrong event %v", invitation.Events()[0])
+	}
+
+	for _, email := range []string{"not-an-email", "jane@example.com\r\nBcc: x@example.com", "Jane <jane@example.com>"} {
+		if _, _, err := entities.CreateInvitation(orgId, email, "member", "", time.Hour); !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("CreateInvitation(%q) failed, expected ErrInvalid, got %v", email, err)
+		}
+	}
+
+	if _, _, err := entities.CreateInvitation(orgId, "jane@example.com", "owner", "", time.Hour); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("CreateInvitation() failed, expected owners not to be invitable, got %v", err)
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+	invitation, _, _ := entities.CreateInvitation(orgId, "jane@example.com", "member", "", time.Hour)
+	userId := vo.GenerateUserId()
+
+	if _, err := invitation.Accept(userId, "john@example.com"); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Accept() failed, expected ErrForbidden for another email, got %v", err)
+	}
+
+	membership, err := invitation.Accept(userId, "JANE@example.com")
+	if err != nil {
+		t.Fatalf("Accept() failed, %v", err)
+	}
+
+	if !membership.UserId().Equals(userId) || !membership.OrganizationId().Equals(orgId) || membership.Level() != "member" {
+		t.Fatalf("Accept() failed, wrong membership %v", membership)
+	}
+
+	if invitation.Status() != entities.InvitationAccepted {
+		t.Fatalf("Accept() failed, status is %v", invitation.Status())
+	}
+
+	if err := invitation.Decline(); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Decline() failed, expected an accepted invitation to conflict, got %v", err)
+	}
+
+	if err := invitation.Revoke(); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Revoke() failed, expected an accepted invitation to conflict, got %v", err)
+	}
+}
+
+func TestExpiredInvitation(t *testing.T) {
+	invitation := entities.NewInvitation(
+		vo.GenerateInvitationId(),
+		vo.GenerateOrganizationId(),
+		"jane@example.com",
+		"member",
+		vo.NewTokenHash("token"),
+		entities.InvitationPending,
+		"",
+		time.Now().Add(-time.Minute),
+		time.Now().Add(-time.Hour),
+	)
+
+	if _, err := invitation.Accept(vo.GenerateUserId(), "jane@example.com"); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Accept() failed, expected an expired invitation to conflict, got %v", err)
+	}
+
+	if err := invitation.Revoke(); err != nil {
+		t.Fatalf("Revoke() failed, expired invitations should be revocable, %v", err)
+	}
+
+	if _, ok := invitation.Events()[0].(events.InvitationRevoked); !ok || invitation.Status() != entities.InvitationRevoked {
+		t.Fatalf("Revoke() failed, wrong event %v", invitation.Events())
+	}
+}
+
+func TestLogMailerWritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer := providers.NewLogMailer(path)
+
+	err := mailer.Send(context.Background(), mail.Message{
+		To:      "jane@example.com",
+		Subject: "You are invited",
+		Body:    "Accept the invitation: https://example.com/?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send() failed, %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Send() failed, %v", err)
+	}
+
+	if !strings.Contains(string(b), "To: jane@example.com") || !strings.Contains(string(b), "token=abc") {
+		t.Fatalf("Send() failed, wrote %q", string(b))
+	}
+}
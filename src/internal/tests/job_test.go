@@ -0,0 +1,256 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/app/audit"
+	"iyaem/internal/infrastructure/jobs"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryJobStore struct {
+	mu        sync.Mutex
+	locks     sync.Map
+	jobs      map[string]*jobs.Job
+	succeeded []string
+	failed    []string
+	dead      []string
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]*jobs.Job)}
+}
+
+func (s *memoryJobStore) Enqueue(ctx context.Context, job jobs.Job) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.jobs {
+		if job.IdempotencyKey != "" && existing.IdempotencyKey == job.IdempotencyKey {
+			return existing.Id, nil
+		}
+	}
+
+	s.jobs[job.Id] = &job
+	return job.Id, nil
+}
+
+func (s *memoryJobStore) FetchPending(ctx context.Context, types []string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]jobs.Job, 0)
+	for _, job := range s.jobs {
+		if job.Status == jobs.StatusPending && len(due) < limit {
+			job.Status = jobs.StatusRunning
+			job.Attempts++
+			due = append(due, *job)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *memoryJobStore) ExtendLease(ctx context.Context, id string, lease time.Duration) error {
+	return nil
+}
+
+func (s *memoryJobStore) SetProgress(ctx context.Context, id string, done int, total int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id].ProgressDone, s.jobs[id].ProgressTotal = done, total
+	return nil
+}
+
+func (s *memoryJobStore) MarkSucceeded(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id].Status = jobs.StatusSucceeded
+	s.succeeded = append(s.succeeded, id)
+	return nil
+}
+
+func (s *memoryJobStore) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id].Status = jobs.StatusPending
+	s.jobs[id].LastError = cause.Error()
+	s.failed = append(s.failed, id)
+	return nil
+}
+
+func (s *memoryJobStore) MarkDead(ctx context.Context, id string, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id].Status = jobs.StatusDead
+	s.jobs[id].LastError = cause.Error()
+	s.dead = append(s.dead, id)
+	return nil
+}
+
+func (s *memoryJobStore) FindById(ctx context.Context, id string) (*jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	found := *job
+	return &found, nil
+}
+
+func (s *memoryJobStore) Postpone(ctx context.Context, id string, runAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id].Status = jobs.StatusPending
+	s.jobs[id].Attempts--
+	return nil
+}
+
+func (s *memoryJobStore) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	lock, _ := s.locks.LoadOrStore(key, &sync.Mutex{})
+	if !lock.(*sync.Mutex).TryLock() {
+		return nil, false, nil
+	}
+
+	return lock.(*sync.Mutex).Unlock, true, nil
+}
+
+var testJob = jobs.Type[string]{Name: "test"}
+
+func TestQueueEnqueuesOnceByIdempotencyKey(t *testing.T) {
+	queue := jobs.NewQueue(newMemoryJobStore())
+	ctx := audit.WithActor(context.Background(), audit.Actor{UserId: "user-1"}, audit.Metadata{})
+
+	first, err := jobs.Enqueue(ctx, queue, testJob, "a", jobs.Options{IdempotencyKey: "key"})
+	if err != nil {
+		t.Fatalf("Enqueue() failed, %v", err)
+	}
+
+	second, err := jobs.Enqueue(ctx, queue, testJob, "b", jobs.Options{IdempotencyKey: "key"})
+	if err != nil || second != first {
+		t.Fatalf("Enqueue() failed, expected job %s, got %s (%v)", first, second, err)
+	}
+
+	job, _ := queue.Find(ctx, first)
+	if job.Actor.UserId != "user-1" || job.MaxAttempts != jobs.DefaultMaxAttempts || job.Status != jobs.StatusPending {
+		t.Fatalf("Enqueue() failed, unexpected job %+v", job)
+	}
+
+	third, err := jobs.Enqueue(ctx, queue, testJob, "c", jobs.Options{Id: "import-1"})
+	if err != nil || third != "import-1" {
+		t.Fatalf("Enqueue() failed, expected job import-1, got %s (%v)", third, err)
+	}
+}
+
+func TestRunnerRetriesAndDeadLettersJobs(t *testing.T) {
+	store := newMemoryJobStore()
+	queue := jobs.NewQueue(store)
+	ctx := audit.WithActor(context.Background(), audit.Actor{UserId: "user-1"}, audit.Metadata{})
+
+	runner := jobs.NewRunner(store, jobs.DefaultRunnerConfig())
+	jobs.Handle(runner, testJob, func(ctx context.Context, run *jobs.Run, payload string) error {
+		if actor, _ := audit.FromContext(ctx); actor.UserId != "user-1" {
+			t.Errorf("Handle() failed, expected the actor of the job, got %+v", actor)
+		}
+
+		switch payload {
+		case "retry":
+			return errors.New("unavailable")
+		case "invalid":
+			return jobs.Permanent(errors.New("invalid"))
+		case "panic":
+			panic("boom")
+		}
+
+		return run.Progress(ctx, 3, 3)
+	})
+
+	ok, _ := jobs.Enqueue(ctx, queue, testJob, "ok", jobs.Options{})
+	retry, _ := jobs.Enqueue(ctx, queue, testJob, "retry", jobs.Options{MaxAttempts: 2})
+	invalid, _ := jobs.Enqueue(ctx, queue, testJob, "invalid", jobs.Options{})
+	panics, _ := jobs.Enqueue(ctx, queue, testJob, "panic", jobs.Options{MaxAttempts: 1})
+
+	ran, err := runner.Flush(context.Background())
+	if err != nil || ran != 4 {
+		t.Fatalf("Flush() failed, expected 4 jobs to run, got %d (%v)", ran, err)
+	}
+
+	if job, _ := queue.Find(ctx, ok); job.Status != jobs.StatusSucceeded || job.ProgressDone != 3 {
+		t.Fatalf("Flush() failed, expected job to succeed, got %+v", job)
+	}
+	if job, _ := queue.Find(ctx, retry); job.Status != jobs.StatusPending || job.LastError != "unavailable" {
+		t.Fatalf("Flush() failed, expected job to be retried, got %+v", job)
+	}
+	if job, _ := queue.Find(ctx, invalid); job.Status != jobs.StatusDead || job.Attempts != 1 {
+		t.Fatalf("Flush() failed, expected a permanent failure to be dead-lettered, got %+v", job)
+	}
+	if job, _ := queue.Find(ctx, panics); job.Status != jobs.StatusDead {
+		t.Fatalf("Flush() failed, expected a panic at the last attempt to be dead-lettered, got %+v", job)
+	}
+
+	runner.Flush(context.Background())
+
+	if job, _ := queue.Find(ctx, retry); job.Status != jobs.StatusDead || job.Attempts != 2 {
+		t.Fatalf("Flush() failed, expected job to be dead-lettered after 2 attempts, got %+v", job)
+	}
+}
+
+func TestRunnerSerializesJobs(t *testing.T) {
+	store := newMemoryJobStore()
+	queue := jobs.NewQueue(store)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	running, overlapped := 0, false
+
+	runner := jobs.NewRunner(store, jobs.DefaultRunnerConfig())
+	jobs.HandleSerially(runner, testJob, func(ctx context.Context, run *jobs.Run, payload string) error {
+		mu.Lock()
+		running++
+		overlapped = overlapped || running > 1
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	for _, payload := range []string{"a", "b", "c"} {
+		jobs.Enqueue(ctx, queue, testJob, payload, jobs.Options{})
+	}
+
+	// The jobs fetched while another runs are postponed, and run in
+	// later rounds.
+	for round := 0; round < 10 && len(store.succeeded) < 3; round++ {
+		if _, err := runner.Flush(ctx); err != nil {
+			t.Fatalf("Flush() failed, %v", err)
+		}
+	}
+
+	if overlapped {
+		t.Fatalf("Flush() failed, serial jobs ran concurrently")
+	}
+
+	if len(store.succeeded) != 3 || len(store.failed) != 0 {
+		t.Fatalf("Flush() failed, expected 3 jobs to succeed, got %d succeeded and %d failed", len(store.succeeded), len(store.failed))
+	}
+
+	for _, id := range store.succeeded {
+		if attempts := store.jobs[id].Attempts; attempts != 1 {
+			t.Fatalf("Flush() failed, expected a postponed job not to count an attempt, got %d", attempts)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/app/queries"
+	"testing"
+	"time"
+)
+
+func TestListQuerySortBy(t *testing.T) {
+	field, desc, err := queries.ListQuery{}.SortBy("name", "name", "joined_at")
+	if err != nil || field != "name" || desc {
+		t.Fatalf("SortBy() failed, expected the fallback in ascending order, got %s %v %v", field, desc, err)
+	}
+
+	field, desc, err = queries.ListQuery{Sort: "-joined_at"}.SortBy("name", "name", "joined_at")
+	if err != nil || field != "joined_at" || !desc {
+		t.Fatalf("SortBy() failed, expected joined_at in descending order, got %s %v %v", field, desc, err)
+	}
+
+	if _, _, err := (queries.ListQuery{Sort: "password"}).SortBy("name", "name", "joined_at"); !errors.Is(err, queries.ErrInvalidList) {
+		t.Fatalf("SortBy() failed, expected ErrInvalidList, got %v", err)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cursor := queries.Cursor{Sort: "-joined_at", Value: "2024-01-01 10:00:00+00", Id: "0b6c6f0e-8d4c-4d9e-9d3a-1f1f1f1f1f1f"}
+
+	decoded, err := queries.DecodeCursor(cursor.Encode(), "-joined_at")
+	if err != nil || decoded != cursor {
+		t.Fatalf("DecodeCursor() failed, expected %+v, got %+v %v", cursor, decoded, err)
+	}
+
+	if _, err := queries.DecodeCursor(cursor.Encode(), "joined_at"); !errors.Is(err, queries.ErrInvalidList) {
+		t.Fatalf("DecodeCursor() failed, expected a cursor of another order to be refused, got %v", err)
+	}
+
+	if _, err := queries.DecodeCursor("not a cursor", "name"); !errors.Is(err, queries.ErrInvalidList) {
+		t.Fatalf("DecodeCursor() failed, expected ErrInvalidList, got %v", err)
+	}
+}
+
+func TestCursorRefusesTamperedValues(t *testing.T) {
+	id := "0b6c6f0e-8d4c-4d9e-9d3a-1f1f1f1f1f1f"
+
+	for name, cursor := range map[string]queries.Cursor{
+		"id":  {Sort: "name", Value: "Ada", Id: "1 OR 1=1"},
+		"nul": {Sort: "name", Value: "Ada\x00", Id: id},
+	} {
+		if _, err := queries.DecodeCursor(cursor.Encode(), "name"); !errors.Is(err, queries.ErrInvalidList) {
+			t.Fatalf("DecodeCursor() failed, expected a tampered %s to be refused, got %v", name, err)
+		}
+	}
+
+	joined := queries.Cursor{Sort: "joined_at", Value: "2024-01-01 10:00:00.123456+05:30", Id: id}
+	if at, err := joined.TimeValue(); err != nil || !at.Equal(time.Date(2024, 1, 1, 4, 30, 0, 123456000, time.UTC)) {
+		t.Fatalf("TimeValue() failed, expected the joining date, got %v %v", at, err)
+	}
+
+	joined.Value = "yesterday"
+	if _, err := joined.TimeValue(); !errors.Is(err, queries.ErrInvalidList) {
+		t.Fatalf("TimeValue() failed, expected ErrInvalidList, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+)
+
+func TestImportMember(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "member")
+	applicationId := vo.GenerateApplicationId()
+	tenant := entities.NewTenant(vo.GenerateTenantId(), org.Id(), applicationId)
+	org.AddTenant(tenant)
+	tenantId := tenant.Id()
+	role := entities.NewRole(vo.GenerateRoleId(), "viewer", "", applicationId, nil, nil)
+
+	userId := vo.GenerateUserId()
+	membershipId, outcome, err := org.ImportMember(userId, "manager", &tenantId, []*entities.Role{&role, &role}, nil)
+	if err != nil || outcome != entities.MemberImportRowCreated {
+		t.Fatalf("ImportMember() failed, expected a new member, got %s %v", outcome, err)
+	}
+	if levelOf(org, membershipId) != "manager" || len(org.FindMemberById(membershipId).Roles()) != 1 {
+		t.Fatalf("ImportMember() failed, expected a manager with one role")
+	}
+
+	// Importing the same row again changes nothing.
+	if _, outcome, err := org.ImportMember(userId, "manager", &tenantId, []*entities.Role{&role}, nil); err != nil || outcome != entities.MemberImportRowUnchanged {
+		t.Fatalf("ImportMember() failed, expected the row to be unchanged, got %s %v", outcome, err)
+	}
+
+	if _, outcome, err := org.ImportMember(members[1].UserId(), "manager", nil, nil, nil); err != nil || outcome != entities.MemberImportRowUpdated {
+		t.Fatalf("ImportMember() failed, expected the member to be promoted, got %s %v", outcome, err)
+	}
+	if levelOf(org, members[1].Id()) != "manager" {
+		t.Fatalf("ImportMember() failed, expected a manager, got %s", levelOf(org, members[1].Id()))
+	}
+
+	if _, _, err := org.ImportMember(members[0].UserId(), "member", nil, nil, nil); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("ImportMember() failed, expected owners to be left alone, got %v", err)
+	}
+}
+
+func TestImportMemberRejectsInvalidRows(t *testing.T) {
+	org, _ := newOwnedOrganization("owner")
+	applicationId := vo.GenerateApplicationId()
+	tenant := entities.NewTenant(vo.GenerateTenantId(), org.Id(), applicationId)
+	org.AddTenant(tenant)
+	tenantId := tenant.Id()
+	otherTenant := vo.GenerateTenantId()
+
+	role := entities.NewRole(vo.GenerateRoleId(), "viewer", "", applicationId, nil, nil)
+	otherRole := entities.NewRole(vo.GenerateRoleId(), "viewer", "", vo.GenerateApplicationId(), nil, nil)
+	otherGroup := entities.NewGroup(vo.GenerateGroupId(), "team", "", vo.GenerateApplicationId(), nil, nil)
+
+	invalid := []struct {
+		level    vo.MembershipLevel
+		tenantId *vo.TenantId
+		roles    []*entities.Role
+		groups   []*entities.Group
+	}{
+		{"owner", nil, nil, nil},
+		{"admin", nil, nil, nil},
+		{"member", nil, []*entities.Role{&role}, nil},
+		{"member", &otherTenant, []*entities.Role{&role}, nil},
+		// The member would be added and granted the first role before the
+		// second one is found unavailable.
+		{"member", &tenantId, []*entities.Role{&role, &otherRole}, nil},
+		{"member", &tenantId, []*entities.Role{&role}, []*entities.Group{&otherGroup}},
+	}
+
+	for _, c := range invalid {
+		members, events := len(org.Members()), len(org.Events())
+		if _, _, err := org.ImportMember(vo.GenerateUserId(), c.level, c.tenantId, c.roles, c.groups); !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("ImportMember() failed, expected ErrInvalid for %+v, got %v", c, err)
+		}
+		if len(org.Members()) != members || len(org.Events()) != events {
+			t.Fatalf("ImportMember() failed, expected an invalid row to change nothing, got %v", org.Events()[events:])
+		}
+	}
+}
+
+func TestMemberImportLifecycle(t *testing.T) {
+	rows := []entities.MemberImportRow{{Email: " Ada@Example.com "}, {Email: "bob@example.com"}}
+
+	if _, err := entities.RequestMemberImport(vo.GenerateOrganizationId(), vo.GenerateUserId(), nil, false); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("RequestMemberImport() failed, expected an empty file to be refused, got %v", err)
+	}
+
+	memberImport, err := entities.RequestMemberImport(vo.GenerateOrganizationId(), vo.GenerateUserId(), rows, true)
+	if err != nil {
+		t.Fatalf("RequestMemberImport() failed, %v", err)
+	}
+	if memberImport.Rows()[0].Email != "ada@example.com" || memberImport.Status() != entities.MemberImportPending {
+		t.Fatalf("RequestMemberImport() failed, unexpected import %+v", memberImport.Rows())
+	}
+
+	if err := memberImport.Complete(nil); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Complete() failed, expected a pending import to be refused, got %v", err)
+	}
+
+	if err := memberImport.Start(); err != nil {
+		t.Fatalf("Start() failed, %v", err)
+	}
+	if err := memberImport.Start(); err != nil {
+		t.Fatalf("Start() failed, expected a running import to restart, got %v", err)
+	}
+
+	err = memberImport.Complete([]entities.MemberImportResult{
+		{Row: 1, Email: "ada@example.com", Status: entities.MemberImportRowCreated},
+		{Row: 2, Email: "bob@example.com", Status: entities.MemberImportRowFailed, Error: "no user"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() failed, %v", err)
+	}
+
+	if err := memberImport.Start(); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Start() failed, expected a completed import to run once, got %v", err)
+	}
+
+	completed, ok := memberImport.Events()[len(memberImport.Events())-1].(events.MemberImportCompleted)
+	if !ok || completed.Created != 1 || completed.Failed != 1 || !completed.DryRun {
+		t.Fatalf("Complete() failed, expected MemberImportCompleted, got %v", memberImport.Events())
+	}
+}
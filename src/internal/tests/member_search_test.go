@@ -0,0 +1,103 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/app/queries"
+	"iyaem/internal/domain/entities"
+	"testing"
+)
+
+// memberSearchQuery serves the members of one organization and records
+// the list query searches are made with.
+type memberSearchQuery struct {
+	queries.OrganizationQuery
+
+	organizationId string
+	members        []queries.User
+	matches        []queries.SearchMatch
+	list           queries.ListQuery
+}
+
+func (q *memberSearchQuery) FindMember(ctx context.Context, organizationId string, userId string) (*queries.User, error) {
+	for _, member := range q.members {
+		if organizationId == q.organizationId && member.UserId == userId {
+			return &member, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (q *memberSearchQuery) SearchUsersInOrganization(ctx context.Context, organizationId string, term string, list queries.ListQuery) ([]queries.SearchMatch, error) {
+	q.list = list
+	return append([]queries.SearchMatch(nil), q.matches...), nil
+}
+
+func TestRankMatches(t *testing.T) {
+	matches := []queries.SearchMatch{
+		{User: queries.User{UserOrgId: "1", Name: "Janine"}, Similarity: 0.9},
+		{User: queries.User{UserOrgId: "2", Name: "Mary Jane"}, Prefix: true, Similarity: 0.4},
+		{User: queries.User{UserOrgId: "3", Name: "Jan"}, Prefix: true, Similarity: 0.6},
+		{User: queries.User{UserOrgId: "4", Name: "Jean"}, Similarity: 0.5},
+		{User: queries.User{UserOrgId: "5", Name: "Alice Jane"}, Prefix: true, Similarity: 0.4},
+	}
+
+	ranked := queries.RankMatches(matches, 10)
+
+	// Prefix matches beat closer resemblances, ties are ordered by name.
+	expected := []string{"3", "5", "2", "1", "4"}
+	for i, id := range expected {
+		if ranked[i].UserOrgId != id {
+			t.Fatalf("RankMatches() failed, expected %v, got %v", expected, ranked)
+		}
+	}
+
+	if ranked := queries.RankMatches(matches, 2); len(ranked) != 2 || ranked[1].UserOrgId != "5" {
+		t.Fatalf("RankMatches() failed, expected the best 2, got %v", ranked)
+	}
+}
+
+func TestMemberSearch(t *testing.T) {
+	q := &memberSearchQuery{
+		organizationId: "org",
+		members:        []queries.User{{UserId: "jane", Name: "Jane"}},
+		matches: []queries.SearchMatch{
+			{User: queries.User{UserOrgId: "1", Name: "Janine"}, Similarity: 0.9},
+			{User: queries.User{UserOrgId: "2", Name: "Jane"}, Prefix: true, Similarity: 0.5},
+		},
+	}
+	search := queries.NewMemberSearch(q)
+	ctx := context.Background()
+
+	if _, err := search.Search(ctx, "john", "org", "jan", queries.ListQuery{}); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Search() failed, expected ErrForbidden for a non-member, got %v", err)
+	}
+	if _, err := search.Search(ctx, "jane", "other", "jan", queries.ListQuery{}); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Search() failed, expected ErrForbidden in another organization, got %v", err)
+	}
+
+	users, err := search.Search(ctx, "jane", "org", " Jan ", queries.ListQuery{Level: "member", TenantId: "tenant", RoleId: "role", GroupId: "group"})
+	if err != nil {
+		t.Fatalf("Search() failed, %v", err)
+	}
+	if len(users) != 2 || users[0].UserOrgId != "2" {
+		t.Fatalf("Search() failed, expected the prefix match first, got %v", users)
+	}
+
+	// Filters reach the query, with the default limit.
+	if q.list.Level != "member" || q.list.TenantId != "tenant" || q.list.RoleId != "role" || q.list.GroupId != "group" || q.list.Limit != queries.DefaultSearchSize {
+		t.Fatalf("Search() failed, filters not passed on, got %+v", q.list)
+	}
+
+	search.Search(ctx, "jane", "org", "jan", queries.ListQuery{Limit: 1000})
+	if q.list.Limit != queries.MaxSearchSize {
+		t.Fatalf("Search() failed, expected the limit capped, got %d", q.list.Limit)
+	}
+
+	for _, list := range []queries.ListQuery{{Sort: "name"}, {Cursor: "abc"}} {
+		if _, err := search.Search(ctx, "jane", "org", "jan", list); !errors.Is(err, queries.ErrInvalidList) {
+			t.Fatalf("Search(%+v) failed, expected ErrInvalidList, got %v", list, err)
+		}
+	}
+}
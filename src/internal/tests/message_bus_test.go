@@ -0,0 +1,133 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/providers"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBusConfig() providers.BusConfig {
+	return providers.BusConfig{
+		MaxDeliveries: 3,
+		RetryDelay:    time.Millisecond,
+		MaxRetryDelay: time.Millisecond,
+	}
+}
+
+// receive subscribes to the topic until it got count messages.
+func receive(t *testing.T, bus providers.MessageBus, topic string, count int) []providers.Message {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make([]providers.Message, 0, count)
+	bus.Subscribe(ctx, topic, func(ctx context.Context, msg providers.Message) error {
+		received = append(received, msg)
+		if len(received) == count {
+			cancel()
+		}
+		return nil
+	})
+
+	if len(received) != count {
+		t.Fatalf("Subscribe() failed, expected %d messages on %s, got %d", count, topic, len(received))
+	}
+
+	return received
+}
+
+func TestMemoryBusDeliversMessages(t *testing.T) {
+	bus := providers.NewMemoryBus(testBusConfig())
+	ctx := context.Background()
+
+	bus.Publish(ctx, "iam_tenant_persisted", []byte(`{"tenant_id":"1"}`), map[string]string{"message_id": "m1"})
+	bus.Publish(ctx, "iam_tenant_persisted", []byte(`{"tenant_id":"2"}`), nil)
+
+	received := receive(t, bus, "iam_tenant_persisted", 2)
+	if received[0].Id != "m1" || string(received[0].Data) != `{"tenant_id":"1"}` || received[1].Id == "" {
+		t.Fatalf("Subscribe() failed, unexpected messages %+v", received)
+	}
+}
+
+func TestMemoryBusRetriesThenDeadLetters(t *testing.T) {
+	bus := providers.NewMemoryBus(testBusConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus.Publish(ctx, "iam_domain_registered", []byte(`{}`), map[string]string{"message_id": "m1"})
+
+	attempts := 0
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := bus.Subscribe(ctx, "iam_domain_registered", func(ctx context.Context, msg providers.Message) error {
+		attempts = msg.Attempt
+		if msg.Attempt == 2 {
+			panic("boom")
+		}
+		return errors.New("unavailable")
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() failed, expected a clean shutdown, got %v", err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("Subscribe() failed, expected 3 deliveries, got %d", attempts)
+	}
+
+	dead := receive(t, bus, providers.DeadLetterTopic("iam_domain_registered"), 1)
+	if dead[0].Id != "m1" || dead[0].Attributes["dead_letter_reason"] != "unavailable" ||
+		dead[0].Attributes["original_topic"] != "iam_domain_registered" {
+		t.Fatalf("Subscribe() failed, unexpected dead letter %+v", dead[0])
+	}
+}
+
+func TestMemoryBusDeadLettersRejectedMessages(t *testing.T) {
+	bus := providers.NewMemoryBus(testBusConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus.Publish(ctx, "iam_tenant_persisted", []byte(`not json`), nil)
+
+	attempts := 0
+	bus.Subscribe(ctx, "iam_tenant_persisted", func(ctx context.Context, msg providers.Message) error {
+		attempts++
+		cancel()
+		return providers.Reject(errors.New("malformed"))
+	})
+
+	if attempts != 1 {
+		t.Fatalf("Subscribe() failed, expected a rejected message to be delivered once, got %d", attempts)
+	}
+
+	receive(t, bus, providers.DeadLetterTopic("iam_tenant_persisted"), 1)
+}
+
+func TestMemoryBusDeadLettersAtShutdown(t *testing.T) {
+	config := testBusConfig()
+	config.RetryDelay, config.MaxRetryDelay = time.Hour, time.Hour
+	bus := providers.NewMemoryBus(config)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus.Publish(ctx, "iam_domain_registered", []byte(`{}`), map[string]string{"message_id": "m1"})
+
+	attempts := 0
+	bus.Subscribe(ctx, "iam_domain_registered", func(ctx context.Context, msg providers.Message) error {
+		attempts++
+		cancel()
+		return errors.New("unavailable")
+	})
+
+	if attempts != 1 {
+		t.Fatalf("Subscribe() failed, expected 1 delivery before shutdown, got %d", attempts)
+	}
+
+	dead := receive(t, bus, providers.DeadLetterTopic("iam_domain_registered"), 1)
+	if dead[0].Id != "m1" || !strings.Contains(dead[0].Attributes["dead_letter_reason"], "unavailable") {
+		t.Fatalf("Subscribe() failed, expected the message to be dead-lettered at shutdown, got %+v", dead[0])
+	}
+}
@@ -0,0 +1,263 @@
+package domain_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/providers"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTotpCode(t *testing.T) {
+	// The SHA-1 test vectors of RFC 6238, truncated to six digits.
+	secret, err := vo.TotpSecretFromString("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("TotpSecretFromString() failed, %v", err)
+	}
+
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		if code := secret.Code(vo.TotpCounter(time.Unix(unix, 0))); code != expected {
+			t.Fatalf("Code() failed at %d, expected %s, got %s", unix, expected, code)
+		}
+	}
+
+	if _, ok := secret.Verify("287082", time.Unix(59+30, 0)); !ok {
+		t.Fatalf("Verify() failed, expected the previous step to be accepted")
+	}
+	if _, ok := secret.Verify("287082", time.Unix(59+90, 0)); ok {
+		t.Fatalf("Verify() failed, expected an old code to be refused")
+	}
+}
+
+func newConfirmedMfaFactor(t *testing.T, now time.Time) (entities.MfaFactor, []string) {
+	factor, err := entities.EnrollTotp(vo.GenerateUserId())
+	if err != nil {
+		t.Fatalf("EnrollTotp() failed, %v", err)
+	}
+
+	recoveryCodes, err := factor.Confirm(factor.Secret().Code(vo.TotpCounter(now)), now)
+	if err != nil {
+		t.Fatalf("Confirm() failed, %v", err)
+	}
+
+	return factor, recoveryCodes
+}
+
+func TestMfaFactorConfirmAndReplay(t *testing.T) {
+	now := time.Now()
+
+	factor, err := entities.EnrollTotp(vo.GenerateUserId())
+	if err != nil {
+		t.Fatalf("EnrollTotp() failed, %v", err)
+	}
+
+	if err := factor.Verify(factor.Secret().Code(vo.TotpCounter(now)), now); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Verify() failed, expected an unconfirmed factor to be refused, got %v", err)
+	}
+
+	wrong := "000000"
+	if factor.Secret().Code(vo.TotpCounter(now)) == wrong {
+		wrong = "111111"
+	}
+	if _, err := factor.Confirm(wrong, now); !errors.Is(err, entities.ErrInvalidMfaCode) {
+		t.Fatalf("Confirm() failed, expected ErrInvalidMfaCode, got %v", err)
+	}
+
+	code := factor.Secret().Code(vo.TotpCounter(now))
+	recoveryCodes, err := factor.Confirm(code, now)
+	if err != nil {
+		t.Fatalf("Confirm() failed, %v", err)
+	}
+	if len(recoveryCodes) != entities.RecoveryCodeCount || factor.RemainingRecoveryCodes() != entities.RecoveryCodeCount {
+		t.Fatalf("Confirm() failed, expected %d recovery codes, got %d", entities.RecoveryCodeCount, len(recoveryCodes))
+	}
+	if _, ok := factor.Events()[0].(events.MfaEnabled); !ok {
+		t.Fatalf("Confirm() failed, expected MfaEnabled")
+	}
+
+	if err := factor.Verify(code, now); !errors.Is(err, entities.ErrInvalidMfaCode) {
+		t.Fatalf("Verify() failed, expected a replayed code to be refused, got %v", err)
+	}
+
+	later := now.Add(30 * time.Second)
+	if err := factor.Verify(factor.Secret().Code(vo.TotpCounter(later)), later); err != nil {
+		t.Fatalf("Verify() failed, expected the next code to work, %v", err)
+	}
+}
+
+func TestMfaRecoveryCodes(t *testing.T) {
+	now := time.Now()
+	factor, recoveryCodes := newConfirmedMfaFactor(t, now)
+
+	// Recovery codes are accepted whatever their case and separators.
+	if err := factor.Verify(strings.ToLower(recoveryCodes[0]), now); err != nil {
+		t.Fatalf("Verify() failed, expected a recovery code to work, %v", err)
+	}
+
+	used, ok := factor.Events()[len(factor.Events())-1].(events.RecoveryCodeUsed)
+	if !ok || used.Remaining != entities.RecoveryCodeCount-1 {
+		t.Fatalf("Verify() failed, expected RecoveryCodeUsed, got %v", factor.Events())
+	}
+
+	if err := factor.Verify(recoveryCodes[0], now); !errors.Is(err, entities.ErrInvalidMfaCode) {
+		t.Fatalf("Verify() failed, expected a used recovery code to be refused, got %v", err)
+	}
+
+	regenerated, err := factor.RegenerateRecoveryCodes(recoveryCodes[1], now)
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() failed, %v", err)
+	}
+	if factor.RemainingRecoveryCodes() != entities.RecoveryCodeCount {
+		t.Fatalf("RegenerateRecoveryCodes() failed, expected a full set, got %d", factor.RemainingRecoveryCodes())
+	}
+
+	if err := factor.Verify(recoveryCodes[2], now); !errors.Is(err, entities.ErrInvalidMfaCode) {
+		t.Fatalf("Verify() failed, expected a replaced recovery code to be refused, got %v", err)
+	}
+	if err := factor.Disable(regenerated[0], now); err != nil {
+		t.Fatalf("Disable() failed, %v", err)
+	}
+	if _, ok := factor.Events()[len(factor.Events())-1].(events.MfaDisabled); !ok {
+		t.Fatalf("Disable() failed, expected MfaDisabled")
+	}
+}
+
+func TestMfaFactorRepositoryRefusesConcurrentUse(t *testing.T) {
+	now := time.Now()
+	confirmed, recoveryCodes := newConfirmedMfaFactor(t, now)
+
+	// load returns the factor as read back from the database.
+	load := func() *entities.MfaFactor {
+		codes := append([]entities.RecoveryCode(nil), confirmed.RecoveryCodes()...)
+		factor := entities.NewMfaFactor(confirmed.Id(), confirmed.UserId(), confirmed.Secret(), confirmed.LastCounter(),
+			codes, confirmed.ConfirmedAt(), confirmed.CreatedAt())
+		return &factor
+	}
+
+	later := now.Add(30 * time.Second)
+	useTotp := func(factor *entities.MfaFactor) error {
+		return factor.Verify(factor.Secret().Code(vo.TotpCounter(later)), later)
+	}
+	useRecoveryCode := func(factor *entities.MfaFactor) error {
+		return factor.Verify(recoveryCodes[0], now)
+	}
+
+	cases := []struct {
+		name      string
+		use       func(factor *entities.MfaFactor) error
+		statement string
+	}{
+		{"password", useTotp, "UPDATE mfa_factor SET"},
+		{"recovery code", useRecoveryCode, "UPDATE mfa_recovery_code SET"},
+	}
+
+	for _, c := range cases {
+		for _, concurrent := range []bool{false, true} {
+			connector := &recordingConnector{}
+			if concurrent {
+				connector.unchanged = c.statement
+			}
+			db := sql.OpenDB(connector)
+			repo := postgresql.NewMfaFactorRepository(db, newTestSecretBox(t, 1))
+
+			factor := load()
+			if err := c.use(factor); err != nil {
+				t.Fatalf("Verify() failed for a %s, %v", c.name, err)
+			}
+
+			err := repo.Update(context.Background(), factor)
+			db.Close()
+
+			if concurrent && !errors.Is(err, entities.ErrInvalidMfaCode) {
+				t.Fatalf("Update() failed, expected a %s used concurrently to be refused, got %v", c.name, err)
+			}
+			if !concurrent && err != nil {
+				t.Fatalf("Update() failed for a %s, %v", c.name, err)
+			}
+
+			log := strings.Join(connector.log, "\n")
+			if !strings.Contains(log, c.statement) || strings.Contains(log, "DELETE FROM mfa_recovery_code") {
+				t.Fatalf("Update() failed, expected the %s to be compared and swapped, got\n%s", c.name, log)
+			}
+		}
+	}
+}
+
+func TestSessionMfa(t *testing.T) {
+	session, _, _ := entities.StartSession(vo.GenerateUserId(), "Firefox", "1.2.3.4", entities.AuthMethodPassword, true, time.Hour)
+
+	if !session.MfaPending() || session.Acr() != entities.AssuranceSingleFactor {
+		t.Fatalf("StartSession() failed, expected a session waiting for a second factor")
+	}
+
+	now := time.Now()
+	if err := session.VerifyMfa(now); err != nil {
+		t.Fatalf("VerifyMfa() failed, %v", err)
+	}
+
+	if session.MfaPending() || session.Acr() != entities.AssuranceMultiFactor || !session.AuthTime().Equal(now) {
+		t.Fatalf("VerifyMfa() failed, expected a multi-factor session, got %s", session.Acr())
+	}
+
+	methods := session.AuthMethods()
+	if len(methods) != 3 || methods[0] != entities.AuthMethodPassword || methods[2] != entities.AuthMethodMfa {
+		t.Fatalf("VerifyMfa() failed, unexpected methods %v", methods)
+	}
+
+	if _, ok := session.Events()[len(session.Events())-1].(events.SessionMfaVerified); !ok {
+		t.Fatalf("VerifyMfa() failed, expected SessionMfaVerified")
+	}
+}
+
+func TestOrganizationMfaRequiresOwner(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "manager")
+
+	if err := org.SetMfaRequired(members[1].Id(), true); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("SetMfaRequired() failed, expected ErrForbidden, got %v", err)
+	}
+
+	if err := org.SetMfaRequired(members[0].Id(), true); err != nil {
+		t.Fatalf("SetMfaRequired() failed, %v", err)
+	}
+
+	changed, ok := org.Events()[0].(events.OrganizationMfaRequirementChanged)
+	if !ok || !changed.Required {
+		t.Fatalf("SetMfaRequired() failed, expected OrganizationMfaRequirementChanged, got %v", org.Events())
+	}
+}
+
+func TestVerifyTokenMfaClaims(t *testing.T) {
+	keys := newTestKeyStore(t, "RS256")
+	verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+	authTime := time.Now().Add(-time.Minute).Unix()
+
+	claims := validTestClaims()
+	claims["amr"] = []string{"pwd", "otp", "mfa"}
+	claims["acr"] = "aal2"
+	claims["auth_time"] = authTime
+	claims["mfa_pending"] = true
+
+	principal, err := verifier.Verify(signTestToken(t, keys, claims))
+	if err != nil {
+		t.Fatalf("Verify() failed, %v", err)
+	}
+
+	if principal.Acr != "aal2" || len(principal.AuthMethods) != 3 || principal.AuthTime.Unix() != authTime || !principal.MfaPending {
+		t.Fatalf("Verify() failed, unexpected principal %+v", principal)
+	}
+}
@@ -97,7 +97,7 @@ func TestDemoteMember(t *testing.T) {
 	)
 
 	org.AddMember(member)
-	org.DemoteMember(member)
+	org.DemoteMember(vo.MembershipId{}, member)
 
 	found := false
 	for _, m := range org.Members() {
@@ -130,9 +130,18 @@ func TestRemoveMember(t *testing.T) {
 		make([]vo.UserRole, 0),
 		make([]vo.UserGroup, 0),
 	)
+	owner := entities.NewMembership(
+		vo.GenerateMembershipId(),
+		vo.GenerateUserId(),
+		orgId,
+		"owner",
+		make([]vo.UserRole, 0),
+		make([]vo.UserGroup, 0),
+	)
 
 	org.AddMember(member)
-	org.RemoveMember(member.Id())
+	org.AddMember(owner)
+	org.RemoveMember(owner.Id(), member.Id())
 
 	found := false
 	for _, m := range org.Members() {
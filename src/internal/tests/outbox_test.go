@@ -0,0 +1,91 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/infrastructure/outbox"
+	"testing"
+	"time"
+)
+
+type memoryOutboxStore struct {
+	pending []outbox.Message
+	sent    []string
+	failed  []string
+	dead    []string
+}
+
+func (s *memoryOutboxStore) FetchPending(ctx context.Context, limit int, lease time.Duration) ([]outbox.Message, error) {
+	messages := s.pending
+	s.pending = nil
+	return messages, nil
+}
+
+func (s *memoryOutboxStore) MarkSent(ctx context.Context, id string) error {
+	s.sent = append(s.sent, id)
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	s.failed = append(s.failed, id)
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkDead(ctx context.Context, id string, cause error) error {
+	s.dead = append(s.dead, id)
+	return nil
+}
+
+type failingPublisher struct {
+	topics []string
+	fail   map[string]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) error {
+	p.topics = append(p.topics, topic)
+	if p.fail[attributes["message_id"]] {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+func TestRelayMarksPublishedMessagesAsSent(t *testing.T) {
+	store := &memoryOutboxStore{pending: []outbox.Message{
+		{Id: "1", EventType: "member_added"},
+		{Id: "2", EventType: "role_added_to_member"},
+	}}
+	publisher := &failingPublisher{fail: map[string]bool{"2": true}}
+
+	relay := outbox.NewRelay(store, publisher, outbox.DefaultRelayConfig())
+	sent, err := relay.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	if sent != 1 || len(store.sent) != 1 || store.sent[0] != "1" {
+		t.Fatalf("Flush() failed, expected message 1 to be sent, got %v", store.sent)
+	}
+
+	if len(store.failed) != 1 || store.failed[0] != "2" {
+		t.Fatalf("Flush() failed, expected message 2 to be retried, got %v", store.failed)
+	}
+
+	if publisher.topics[0] != "iam_member_added" {
+		t.Fatalf("Flush() failed, wrong topic %v", publisher.topics[0])
+	}
+}
+
+func TestRelayGivesUpAfterMaxAttempts(t *testing.T) {
+	config := outbox.DefaultRelayConfig()
+	store := &memoryOutboxStore{pending: []outbox.Message{
+		{Id: "1", EventType: "member_added", Attempts: config.MaxAttempts - 1},
+	}}
+	publisher := &failingPublisher{fail: map[string]bool{"1": true}}
+
+	relay := outbox.NewRelay(store, publisher, config)
+	relay.Flush(context.Background())
+
+	if len(store.dead) != 1 || len(store.failed) != 0 {
+		t.Fatalf("Flush() failed, expected message to be dead-lettered")
+	}
+}
@@ -0,0 +1,186 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+	"time"
+)
+
+func newOwnedOrganization(levels ...string) (entities.Organization, []entities.Membership) {
+	orgId := vo.GenerateOrganizationId()
+
+	members := make([]entities.Membership, 0, len(levels))
+	for _, level := range levels {
+		members = append(members, entities.NewMembership(
+			vo.GenerateMembershipId(),
+			vo.GenerateUserId(),
+			orgId,
+			vo.MembershipLevel(level),
+			make([]vo.UserRole, 0),
+			make([]vo.UserGroup, 0),
+		))
+	}
+
+	org := entities.NewOrganization(orgId, "Test Corp", "test_corp", members, make([]entities.Tenant, 0))
+	return org, members
+}
+
+func levelOf(org entities.Organization, id vo.MembershipId) vo.MembershipLevel {
+	member := org.FindMemberById(id)
+	if member == nil {
+		return ""
+	}
+
+	return member.Level()
+}
+
+func TestLastOwnerIsKept(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "member")
+	owner := members[0]
+
+	if err := org.DemoteMember(owner.Id(), owner); !errors.Is(err, entities.ErrLastOwner) {
+		t.Fatalf("DemoteMember() failed, expected ErrLastOwner, got %v", err)
+	}
+
+	if err := org.RemoveMember(owner.Id(), owner.Id()); !errors.Is(err, entities.ErrLastOwner) {
+		t.Fatalf("RemoveMember() failed, expected ErrLastOwner, got %v", err)
+	}
+
+	if !errors.Is(entities.ErrLastOwner, entities.ErrConflict) {
+		t.Fatalf("ErrLastOwner should be a conflict")
+	}
+
+	if levelOf(org, owner.Id()) != "owner" || len(org.Events()) != 0 {
+		t.Fatalf("last owner was changed, events %v", org.Events())
+	}
+}
+
+func TestOnlyOwnersChangeOwners(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "owner", "manager")
+	owner, other, manager := members[0], members[1], members[2]
+
+	if err := org.DemoteMember(manager.Id(), owner); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("DemoteMember() failed, expected ErrForbidden for a manager, got %v", err)
+	}
+
+	if err := org.RemoveMember(manager.Id(), owner.Id()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("RemoveMember() failed, expected ErrForbidden for a manager, got %v", err)
+	}
+
+	if err := org.RemoveMember(vo.MembershipId{}, owner.Id()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("RemoveMember() failed, expected ErrForbidden without a member acting, got %v", err)
+	}
+
+	if len(org.Events()) != 0 {
+		t.Fatalf("owners were changed by a manager, events %v", org.Events())
+	}
+
+	// An owner steps down to manager rather than member.
+	if err := org.DemoteMember(other.Id(), owner); err != nil {
+		t.Fatalf("DemoteMember() failed, %v", err)
+	}
+
+	if levelOf(org, owner.Id()) != "manager" {
+		t.Fatalf("DemoteMember() failed, expected an owner to become manager, got %v", levelOf(org, owner.Id()))
+	}
+
+	// Managers and members are managed by managers.
+	if err := org.DemoteMember(manager.Id(), *org.FindMemberById(owner.Id())); err != nil {
+		t.Fatalf("DemoteMember() failed, %v", err)
+	}
+
+	if levelOf(org, owner.Id()) != "member" {
+		t.Fatalf("DemoteMember() failed, expected a manager to become member, got %v", levelOf(org, owner.Id()))
+	}
+}
+
+func TestAddOwner(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "manager", "member")
+	owner, manager, member := members[0], members[1], members[2]
+
+	if err := org.AddOwner(manager.Id(), member.Id()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("AddOwner() failed, expected ErrForbidden for a manager, got %v", err)
+	}
+
+	if err := org.AddOwner(owner.Id(), manager.Id()); err != nil {
+		t.Fatalf("AddOwner() failed, %v", err)
+	}
+
+	if levelOf(org, manager.Id()) != "owner" {
+		t.Fatalf("AddOwner() failed, level is %v", levelOf(org, manager.Id()))
+	}
+
+	if err := org.PromoteMember(manager); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("PromoteMember() failed, expected an owner not to be promoted, got %v", err)
+	}
+
+	// With two owners, either of them can step down.
+	if err := org.RemoveMember(owner.Id(), owner.Id()); err != nil {
+		t.Fatalf("RemoveMember() failed, %v", err)
+	}
+
+	added, ok := org.Events()[0].(events.OwnerAdded)
+	if !ok || added.MembershipId != manager.Id().Value() || added.PreviousLevel != "manager" {
+		t.Fatalf("AddOwner() failed, wrong event %v", org.Events()[0])
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "member")
+	owner, member := members[0], members[1]
+
+	if _, err := org.RequestOwnershipTransfer(member.Id(), owner.Id(), time.Hour); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("RequestOwnershipTransfer() failed, expected ErrForbidden, got %v", err)
+	}
+
+	transfer, err := org.RequestOwnershipTransfer(owner.Id(), member.Id(), time.Hour)
+	if err != nil {
+		t.Fatalf("RequestOwnershipTransfer() failed, %v", err)
+	}
+
+	if levelOf(org, member.Id()) != "member" {
+		t.Fatalf("RequestOwnershipTransfer() failed, ownership changed before confirmation")
+	}
+
+	if err := org.TransferOwnership(transfer, member.Id()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("TransferOwnership() failed, expected ErrForbidden for the new owner, got %v", err)
+	}
+
+	if err := org.TransferOwnership(transfer, owner.Id()); err != nil {
+		t.Fatalf("TransferOwnership() failed, %v", err)
+	}
+
+	if levelOf(org, member.Id()) != "owner" || levelOf(org, owner.Id()) != "manager" {
+		t.Fatalf("TransferOwnership() failed, levels %v and %v", levelOf(org, member.Id()), levelOf(org, owner.Id()))
+	}
+
+	transferred, ok := org.Events()[1].(events.OwnershipTransferred)
+	if !ok || transferred.TransferId != transfer.Id().Value() || transferred.PreviousLevel != "member" {
+		t.Fatalf("TransferOwnership() failed, wrong event %v", org.Events()[1])
+	}
+}
+
+func TestTransferOwnershipExpires(t *testing.T) {
+	org, members := newOwnedOrganization("owner", "member")
+	owner, member := members[0], members[1]
+
+	expired := entities.NewOwnershipTransfer(
+		vo.GenerateOwnershipTransferId(),
+		org.Id(),
+		owner.Id(),
+		member.Id(),
+		time.Now().Add(-time.Minute),
+		nil,
+	)
+
+	if err := org.TransferOwnership(expired, owner.Id()); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("TransferOwnership() failed, expected an expired transfer to conflict, got %v", err)
+	}
+
+	if levelOf(org, member.Id()) != "member" {
+		t.Fatalf("TransferOwnership() failed, expired transfer took effect")
+	}
+}
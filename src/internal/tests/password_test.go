@@ -0,0 +1,191 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse battery staple"
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := vo.NewPasswordHash(testPassword)
+	if err != nil {
+		t.Fatalf("NewPasswordHash() failed, %v", err)
+	}
+
+	if !strings.HasPrefix(hash.Value(), "$argon2id$v=19$") {
+		t.Fatalf("NewPasswordHash() failed, expected an argon2id hash, got %s", hash.Value())
+	}
+	if !hash.Matches(testPassword) || hash.Matches(testPassword+"!") {
+		t.Fatalf("Matches() failed")
+	}
+	if hash.NeedsRehash() {
+		t.Fatalf("NeedsRehash() failed, expected a new hash to be current")
+	}
+
+	other, _ := vo.NewPasswordHash(testPassword)
+	if other.Value() == hash.Value() {
+		t.Fatalf("NewPasswordHash() failed, expected a random salt")
+	}
+
+	imported, _ := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	legacy := vo.PasswordHashFromString(string(imported))
+	if !legacy.Matches(testPassword) || !legacy.NeedsRehash() {
+		t.Fatalf("Matches() failed, expected bcrypt hashes to match and need a rehash")
+	}
+
+	if vo.PasswordHashFromString("garbage").Matches(testPassword) {
+		t.Fatalf("Matches() failed, expected an invalid hash to never match")
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	weak := []string{
+		"short",
+		"password1234",
+		"jane.doe-is-the-best",
+		strings.Repeat("x", entities.MaxPasswordLength+1),
+	}
+	for _, password := range weak {
+		if err := entities.ValidatePassword(password, "Jane.Doe@example.com"); !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("ValidatePassword(%q) failed, expected ErrInvalid, got %v", password, err)
+		}
+	}
+
+	if err := entities.ValidatePassword(testPassword, "jane.doe@example.com"); err != nil {
+		t.Fatalf("ValidatePassword() failed, %v", err)
+	}
+}
+
+func TestCredentialLockout(t *testing.T) {
+	credential, err := entities.CreateCredential(vo.GenerateUserId(), " Jane@Example.com ", testPassword)
+	if err != nil {
+		t.Fatalf("CreateCredential() failed, %v", err)
+	}
+
+	if credential.Email() != "jane@example.com" || !strings.HasPrefix(credential.Subject(), "password|") {
+		t.Fatalf("CreateCredential() failed, got %s %s", credential.Email(), credential.Subject())
+	}
+
+	now := time.Now()
+	for i := 0; i < entities.MaxFailedLogins; i++ {
+		if err := credential.Authenticate("wrong password", now); !errors.Is(err, entities.ErrInvalidPassword) {
+			t.Fatalf("Authenticate() failed, expected ErrInvalidPassword, got %v", err)
+		}
+	}
+
+	if !credential.IsLocked(now) {
+		t.Fatalf("Authenticate() failed, expected the credential to be locked")
+	}
+	if err := credential.Authenticate(testPassword, now); !errors.Is(err, entities.ErrInvalidPassword) {
+		t.Fatalf("Authenticate() failed, expected a locked credential to refuse the right password")
+	}
+
+	locked := credential.Events()[len(credential.Events())-1]
+	if _, ok := locked.(events.CredentialLocked); !ok {
+		t.Fatalf("Authenticate() failed, expected CredentialLocked, got %s", locked.Name())
+	}
+
+	later := now.Add(entities.LockoutDuration + time.Second)
+	if err := credential.Authenticate(testPassword, later); err != nil {
+		t.Fatalf("Authenticate() failed, expected the lockout to end, got %v", err)
+	}
+	if credential.FailedAttempts() != 0 || credential.LockedUntil() != nil {
+		t.Fatalf("Authenticate() failed, expected a success to clear failures")
+	}
+}
+
+func TestCredentialRehashesLegacyHash(t *testing.T) {
+	imported, _ := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	credential := entities.NewCredential(
+		vo.GenerateCredentialId(), vo.GenerateUserId(), "jane@example.com",
+		vo.PasswordHashFromString(string(imported)), 0, nil, time.Now(), time.Now(),
+	)
+
+	if err := credential.Authenticate(testPassword, time.Now()); err != nil {
+		t.Fatalf("Authenticate() failed, %v", err)
+	}
+	if !strings.HasPrefix(credential.Hash().Value(), "$argon2id$") {
+		t.Fatalf("Authenticate() failed, expected the bcrypt hash to be replaced")
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	credential, _ := entities.CreateCredential(vo.GenerateUserId(), "jane@example.com", testPassword)
+	for i := 0; i < entities.MaxFailedLogins; i++ {
+		credential.Authenticate("wrong password", time.Now())
+	}
+
+	reset, token, err := entities.RequestPasswordReset(&credential, time.Hour)
+	if err != nil {
+		t.Fatalf("RequestPasswordReset() failed, %v", err)
+	}
+	if reset.TokenHash() != vo.NewTokenHash(token) {
+		t.Fatalf("RequestPasswordReset() failed, expected the token hash to be kept")
+	}
+
+	if err := credential.ResetPassword(&reset, "short", time.Now()); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("ResetPassword() failed, expected the policy to apply, got %v", err)
+	}
+
+	reset, _, _ = entities.RequestPasswordReset(&credential, time.Hour)
+	newPassword := "a much better passphrase"
+	if err := credential.ResetPassword(&reset, newPassword, time.Now()); err != nil {
+		t.Fatalf("ResetPassword() failed, %v", err)
+	}
+	if credential.IsLocked(time.Now()) || credential.Authenticate(newPassword, time.Now()) != nil {
+		t.Fatalf("ResetPassword() failed, expected the new password to unlock the credential")
+	}
+
+	changed := credential.Events()[len(credential.Events())-1].(events.PasswordChanged)
+	if changed.Reason != "reset" || changed.ResetId != reset.Id().Value() {
+		t.Fatalf("ResetPassword() failed, got %+v", changed)
+	}
+
+	if err := credential.ResetPassword(&reset, "yet another passphrase", time.Now()); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("ResetPassword() failed, expected a used reset to conflict, got %v", err)
+	}
+
+	expired, _, _ := entities.RequestPasswordReset(&credential, time.Hour)
+	if err := credential.ResetPassword(&expired, "yet another passphrase", time.Now().Add(2*time.Hour)); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("ResetPassword() failed, expected an expired reset to conflict, got %v", err)
+	}
+
+	other, _ := entities.CreateCredential(vo.GenerateUserId(), "john@example.com", testPassword)
+	foreign, _, _ := entities.RequestPasswordReset(&other, time.Hour)
+	if err := credential.ResetPassword(&foreign, "yet another passphrase", time.Now()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("ResetPassword() failed, expected a reset of another credential to be forbidden, got %v", err)
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	throttle := providers.NewLoginThrottle(2, time.Minute)
+
+	throttle.Fail("ip:1.2.3.4", "email:jane@example.com")
+	if ok, _ := throttle.Allow("ip:1.2.3.4", "email:jane@example.com"); !ok {
+		t.Fatalf("Allow() failed, expected an attempt below the limit")
+	}
+
+	throttle.Fail("ip:1.2.3.4", "email:jane@example.com")
+	ok, wait := throttle.Allow("ip:5.6.7.8", "email:jane@example.com")
+	if ok || wait <= 0 || wait > time.Minute {
+		t.Fatalf("Allow() failed, expected the email to be throttled, got %v %v", ok, wait)
+	}
+
+	if ok, _ := throttle.Allow("ip:5.6.7.8", "email:john@example.com"); !ok {
+		t.Fatalf("Allow() failed, expected other keys to be allowed")
+	}
+
+	throttle.Succeed("ip:1.2.3.4", "email:jane@example.com")
+	if ok, _ := throttle.Allow("ip:1.2.3.4", "email:jane@example.com"); !ok {
+		t.Fatalf("Allow() failed, expected a success to clear failures")
+	}
+}
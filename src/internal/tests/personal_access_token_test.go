@@ -0,0 +1,163 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreatePersonalAccessToken(t *testing.T) {
+	orgIds := []vo.OrganizationId{vo.GenerateOrganizationId()}
+
+	invalid := []struct {
+		name   string
+		scopes []string
+		orgIds []vo.OrganizationId
+		ttl    time.Duration
+	}{
+		{"", []string{entities.PersonalAccessTokenRead}, orgIds, time.Hour},
+		{"ci", nil, orgIds, time.Hour},
+		{"ci", []string{"admin"}, orgIds, time.Hour},
+		{"ci", []string{entities.PersonalAccessTokenRead}, nil, time.Hour},
+		{"ci", []string{entities.PersonalAccessTokenRead}, orgIds, entities.MaxPersonalAccessTokenTTL + time.Hour},
+	}
+
+	for _, c := range invalid {
+		if _, _, err := entities.CreatePersonalAccessToken(vo.GenerateUserId(), c.name, c.scopes, c.orgIds, c.ttl); !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("CreatePersonalAccessToken() failed, expected ErrInvalid for %+v, got %v", c, err)
+		}
+	}
+
+	pat, token, err := entities.CreatePersonalAccessToken(vo.GenerateUserId(), " ci ", []string{entities.PersonalAccessTokenRead}, orgIds, time.Hour)
+	if err != nil {
+		t.Fatalf("CreatePersonalAccessToken() failed, %v", err)
+	}
+
+	if !strings.HasPrefix(token, entities.PersonalAccessTokenPrefix) || pat.TokenHash() != vo.NewTokenHash(token) {
+		t.Fatalf("CreatePersonalAccessToken() failed, expected a prefixed token matching its hash")
+	}
+	if pat.Name() != "ci" {
+		t.Fatalf("CreatePersonalAccessToken() failed, expected a trimmed name, got %q", pat.Name())
+	}
+	if _, ok := pat.Events()[0].(events.PersonalAccessTokenCreated); !ok {
+		t.Fatalf("CreatePersonalAccessToken() failed, expected PersonalAccessTokenCreated")
+	}
+}
+
+func TestPersonalAccessTokenUse(t *testing.T) {
+	pat, _, _ := entities.CreatePersonalAccessToken(vo.GenerateUserId(), "ci", []string{entities.PersonalAccessTokenRead}, []vo.OrganizationId{vo.GenerateOrganizationId()}, time.Hour)
+
+	now := time.Now()
+	if used, err := pat.Use("1.2.3.4", now); err != nil || !used {
+		t.Fatalf("Use() failed, expected the first use to be recorded, %v", err)
+	}
+	if used, _ := pat.Use("1.2.3.4", now.Add(10*time.Second)); used {
+		t.Fatalf("Use() failed, expected a use within a minute not to be recorded")
+	}
+	if used, _ := pat.Use("5.6.7.8", now.Add(10*time.Second)); !used || pat.LastUsedIp() != "5.6.7.8" {
+		t.Fatalf("Use() failed, expected a use from another address to be recorded")
+	}
+
+	if _, err := pat.Use("1.2.3.4", pat.ExpiresAt()); !errors.Is(err, entities.ErrInvalidPersonalAccessToken) {
+		t.Fatalf("Use() failed, expected an expired token to be refused, got %v", err)
+	}
+
+	if err := pat.Revoke(); err != nil {
+		t.Fatalf("Revoke() failed, %v", err)
+	}
+	if err := pat.Revoke(); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Revoke() failed, expected ErrConflict, got %v", err)
+	}
+	if _, err := pat.Use("1.2.3.4", now); !errors.Is(err, entities.ErrInvalidPersonalAccessToken) {
+		t.Fatalf("Use() failed, expected a revoked token to be refused, got %v", err)
+	}
+}
+
+func TestPersonalAccessTokenOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	allowed, other := vo.GenerateOrganizationId().Value(), vo.GenerateOrganizationId().Value()
+	isAuthenticated := providers.IsAuthenticated(nil, nil, func(ctx context.Context, token string, ipAddress string) (*providers.Principal, error) {
+		return &providers.Principal{
+			UserId:          vo.GenerateUserId().Value(),
+			TokenId:         "token",
+			Scopes:          []string{"read", "write"},
+			OrganizationIds: []string{allowed},
+		}, nil
+	})
+
+	cases := []struct {
+		query, body string
+		status      int
+	}{
+		{allowed, "", http.StatusOK},
+		{"", `{"organization_id":"` + allowed + `"}`, http.StatusOK},
+		{allowed, `{"organization_id":"` + strings.ToUpper(allowed) + `"}`, http.StatusOK},
+		{other, "", http.StatusForbidden},
+		{"", `{"organization_id":"` + other + `"}`, http.StatusForbidden},
+		// The handler binds the body, so the query cannot vouch for it.
+		{allowed, `{"organization_id":"` + other + `"}`, http.StatusForbidden},
+		{other, `{"organization_id":"` + allowed + `"}`, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		r := gin.New()
+		r.POST("/organization/add-user", isAuthenticated, func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest(http.MethodPost, "/organization/add-user?organization_id="+c.query, strings.NewReader(c.body))
+		req.Header.Set("Authorization", "Bearer "+entities.PersonalAccessTokenPrefix+"token")
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != c.status {
+			t.Fatalf("IsAuthenticated() failed, expected %d for query %q and body %q, got %d", c.status, c.query, c.body, w.Code)
+		}
+	}
+
+	paths := []struct {
+		path   string
+		status int
+	}{
+		{"/organization/" + allowed, http.StatusOK},
+		{"/organization/" + other, http.StatusForbidden},
+		// The handler reads the path, so the query cannot vouch for it.
+		{"/organization/" + other + "?organization_id=" + allowed, http.StatusForbidden},
+		{"/organization", http.StatusForbidden},
+		{"/organization?organization_id=" + allowed, http.StatusForbidden},
+	}
+
+	for _, c := range paths {
+		r := gin.New()
+		r.Use(isAuthenticated)
+		r.GET("/organization/:organization_id", func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/organization", providers.DenyPersonalAccessTokens(), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, c.path, nil)
+		req.Header.Set("Authorization", "Bearer "+entities.PersonalAccessTokenPrefix+"token")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != c.status {
+			t.Fatalf("IsAuthenticated() failed, expected %d for %s, got %d", c.status, c.path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"iyaem/internal/app/scim"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"testing"
+)
+
+func TestScimFilter(t *testing.T) {
+	user := scim.User{UserName: "Jane@Example.com", DisplayName: "Jane Doe", Emails: []scim.Email{{Value: "jane@example.com"}}}
+
+	matching := []string{
+		``,
+		`userName eq "jane@example.com"`,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "jane@example.com"`,
+		`emails.value co "example"`,
+		`displayName sw "Jane" and displayName ew "doe"`,
+		`displayName eq "Jane Doe"`,
+		`active eq true`,
+		`userName pr`,
+	}
+	for _, f := range matching {
+		filter, err := scim.ParseFilter(f, "username", "displayname", "active", "emails.value")
+		if err != nil {
+			t.Fatalf("ParseFilter(%q) failed, %v", f, err)
+		}
+		if !filter.Matches(user.Attributes()) {
+			t.Fatalf("Matches(%q) failed, expected a match", f)
+		}
+	}
+
+	filter, _ := scim.ParseFilter(`userName ne "jane@example.com"`, "username")
+	if filter.Matches(user.Attributes()) {
+		t.Fatalf("Matches() failed, expected no match for ne")
+	}
+
+	lookups := map[string]bool{
+		`userName eq "jane@example.com"`:                             true,
+		`userName sw "jane"`:                                         false,
+		`displayName eq "Jane Doe"`:                                  false,
+		`userName eq "jane@example.com" and displayName pr`:          false,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "x"`: true,
+	}
+	for f, expected := range lookups {
+		filter, _ := scim.ParseFilter(f, "username", "displayname")
+		if _, ok := filter.Lookup("username"); ok != expected {
+			t.Fatalf("Lookup(%q) failed, expected %v", f, expected)
+		}
+	}
+
+	invalid := []string{
+		`userName eq`,
+		`userName gt "a"`,
+		`password eq "secret"`,
+		`userName eq "jane" or userName eq "john"`,
+		`userName eq "unterminated`,
+	}
+	for _, f := range invalid {
+		if _, err := scim.ParseFilter(f, "username"); !errors.Is(err, scim.ErrInvalidFilter) || !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("ParseFilter(%q) failed, expected ErrInvalidFilter, got %v", f, err)
+		}
+	}
+}
+
+func TestScimPatchUser(t *testing.T) {
+	active := true
+	user := scim.User{UserName: "jane@example.com", Active: &active}
+
+	var patch scim.PatchRequest
+	json.Unmarshal([]byte(`{"Operations": [
+		{"op": "Replace", "path": "displayName", "value": "Jane"},
+		{"op": "replace", "value": {"active": "False"}},
+		{"op": "add", "path": "externalId", "value": "42"}
+	]}`), &patch)
+
+	if err := patch.ApplyToUser(&user); err != nil {
+		t.Fatalf("ApplyToUser() failed, %v", err)
+	}
+
+	if user.IsActive() || user.FullName() != "Jane" {
+		t.Fatalf("ApplyToUser() failed, got active %v name %q", user.IsActive(), user.FullName())
+	}
+
+	json.Unmarshal([]byte(`{"Operations": [{"op": "remove", "path": "active"}]}`), &patch)
+	if err := patch.ApplyToUser(&user); !errors.Is(err, scim.ErrInvalidPath) {
+		t.Fatalf("ApplyToUser() failed, expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestScimPatchGroup(t *testing.T) {
+	group := scim.Group{DisplayName: "Engineering", Members: []scim.Member{{Value: "a"}, {Value: "b"}}}
+
+	var patch scim.PatchRequest
+	json.Unmarshal([]byte(`{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "c"}, {"value": "a"}]},
+		{"op": "remove", "path": "members[value eq \"b\"]"},
+		{"op": "replace", "value": {"displayName": "Platform"}}
+	]}`), &patch)
+
+	if err := patch.ApplyToGroup(&group); err != nil {
+		t.Fatalf("ApplyToGroup() failed, %v", err)
+	}
+
+	ids := group.MemberIds()
+	if group.DisplayName != "Platform" || len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Fatalf("ApplyToGroup() failed, got %q %v", group.DisplayName, ids)
+	}
+
+	json.Unmarshal([]byte(`{"Operations": [{"op": "remove", "path": "members"}]}`), &patch)
+	if err := patch.ApplyToGroup(&group); err != nil || len(group.Members) != 0 {
+		t.Fatalf("ApplyToGroup() failed, expected no members, got %v %v", group.Members, err)
+	}
+
+	json.Unmarshal([]byte(`{"Operations": [{"op": "remove", "path": "members[display eq \"x\"]"}]}`), &patch)
+	if err := patch.ApplyToGroup(&group); !errors.Is(err, scim.ErrInvalidPath) {
+		t.Fatalf("ApplyToGroup() failed, expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestScimListResponse(t *testing.T) {
+	resources := []interface{}{1, 2, 3, 4, 5}
+
+	page := scim.NewListResponse(resources, 2, 2)
+	if page.TotalResults != 5 || page.ItemsPerPage != 2 || page.Resources[0] != 2 {
+		t.Fatalf("NewListResponse() failed, got %+v", page)
+	}
+
+	page = scim.NewListResponse(resources, 10, 2)
+	if page.TotalResults != 5 || page.ItemsPerPage != 0 || len(page.Resources) != 0 {
+		t.Fatalf("NewListResponse() failed past the end, got %+v", page)
+	}
+
+	page = scim.NewPagedListResponse([]interface{}{3, 4}, 5, 3)
+	if page.TotalResults != 5 || page.ItemsPerPage != 2 || page.StartIndex != 3 {
+		t.Fatalf("NewPagedListResponse() failed, got %+v", page)
+	}
+}
+
+func TestScimToken(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+	tenant := entities.NewTenant(vo.GenerateTenantId(), orgId, vo.GenerateApplicationId())
+
+	token, secret, err := entities.CreateScimToken(orgId, &tenant, "Okta")
+	if err != nil {
+		t.Fatalf("CreateScimToken() failed, %v", err)
+	}
+
+	if secret == "" || token.TokenHash() != vo.NewTokenHash(secret) || token.TenantId() == nil {
+		t.Fatalf("CreateScimToken() failed, got %v", token)
+	}
+
+	created, ok := token.Events()[0].(events.ScimTokenCreated)
+	if !ok || created.TenantId != tenant.Id().Value() || created.Description != "Okta" {
+		t.Fatalf("CreateScimToken() failed, wrong event %v", token.Events()[0])
+	}
+
+	if _, _, err := entities.CreateScimToken(vo.GenerateOrganizationId(), &tenant, "Okta"); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("CreateScimToken() failed, expected ErrInvalid for another organization's tenant, got %v", err)
+	}
+
+	if err := token.Revoke(); err != nil || !token.IsRevoked() {
+		t.Fatalf("Revoke() failed, %v", err)
+	}
+
+	if err := token.Revoke(); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Revoke() failed, expected ErrConflict, got %v", err)
+	}
+}
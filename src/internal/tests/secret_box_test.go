@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"bytes"
+	"iyaem/internal/providers"
+	"testing"
+)
+
+func newTestSecretBox(t *testing.T, key byte) *providers.SecretBox {
+	box, err := providers.NewSecretBox(bytes.Repeat([]byte{key}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretBox() failed: %v", err)
+	}
+
+	return box
+}
+
+func TestSecretBoxSealsSecrets(t *testing.T) {
+	box := newTestSecretBox(t, 1)
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP", "mfa_factor.secret:1")
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+
+	if !providers.IsSealed(sealed) || bytes.Contains([]byte(sealed), []byte("JBSWY3DPEHPK3PXP")) {
+		t.Fatalf("Seal() failed, secret stored in the clear: %s", sealed)
+	}
+
+	if again, _ := box.Seal("JBSWY3DPEHPK3PXP", "mfa_factor.secret:1"); again == sealed {
+		t.Fatalf("Seal() failed, expected a new nonce for every seal")
+	}
+
+	secret, err := box.Open(sealed, "mfa_factor.secret:1")
+	if err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open() failed, expected the secret, got %q (%v)", secret, err)
+	}
+
+	if _, err := box.Open(sealed, "mfa_factor.secret:2"); err == nil {
+		t.Fatalf("Open() failed, secret opened for another row")
+	}
+
+	if _, err := newTestSecretBox(t, 2).Open(sealed, "mfa_factor.secret:1"); err == nil {
+		t.Fatalf("Open() failed, secret opened with another key")
+	}
+
+	if secret, err := box.Open("JBSWY3DPEHPK3PXP", "mfa_factor.secret:1"); err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open() failed, expected a secret stored before encryption as is, got %q (%v)", secret, err)
+	}
+
+	if _, err := providers.NewSecretBox([]byte("short")); err == nil {
+		t.Fatalf("NewSecretBox() failed, short key accepted")
+	}
+}
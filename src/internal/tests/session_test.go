@@ -0,0 +1,99 @@
+package domain_test
+
+import (
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"testing"
+	"time"
+)
+
+func TestSessionRefreshRotatesToken(t *testing.T) {
+	session, token, err := entities.StartSession(vo.GenerateUserId(), "Firefox", "1.2.3.4", entities.AuthMethodPassword, false, time.Hour)
+	if err != nil {
+		t.Fatalf("StartSession() failed, %v", err)
+	}
+
+	if _, ok := session.Events()[0].(events.SessionStarted); !ok {
+		t.Fatalf("StartSession() failed, expected SessionStarted")
+	}
+	if session.RefreshTokenHash() != vo.NewTokenHash(token) {
+		t.Fatalf("StartSession() failed, expected the refresh token hash to be kept")
+	}
+
+	next, err := session.Refresh(vo.NewTokenHash(token), "5.6.7.8", time.Now())
+	if err != nil {
+		t.Fatalf("Refresh() failed, %v", err)
+	}
+	if next == token || session.RefreshTokenHash() != vo.NewTokenHash(next) {
+		t.Fatalf("Refresh() failed, expected a new refresh token")
+	}
+	if session.IpAddress() != "5.6.7.8" {
+		t.Fatalf("Refresh() failed, expected the last IP address, got %s", session.IpAddress())
+	}
+
+	if _, err := session.Refresh(vo.NewTokenHash(next), "", time.Now()); err != nil {
+		t.Fatalf("Refresh() failed, expected the new token to work, %v", err)
+	}
+	if len(session.Events()) != 1 {
+		t.Fatalf("Refresh() failed, expected no events, got %d", len(session.Events()))
+	}
+}
+
+func TestSessionRefreshTokenReuseRevokesSession(t *testing.T) {
+	session, token, _ := entities.StartSession(vo.GenerateUserId(), "Firefox", "1.2.3.4", entities.AuthMethodPassword, false, time.Hour)
+	next, _ := session.Refresh(vo.NewTokenHash(token), "", time.Now())
+
+	if _, err := session.Refresh(vo.NewTokenHash(token), "", time.Now()); !errors.Is(err, entities.ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() failed, expected a reused token to be refused, got %v", err)
+	}
+
+	if session.IsActive(time.Now()) {
+		t.Fatalf("Refresh() failed, expected reuse to revoke the session")
+	}
+
+	revoked, ok := session.Events()[len(session.Events())-1].(events.SessionRevoked)
+	if !ok || revoked.Reason != entities.SessionRefreshTokenReuse {
+		t.Fatalf("Refresh() failed, expected SessionRevoked for reuse, got %v", session.Events())
+	}
+
+	if _, err := session.Refresh(vo.NewTokenHash(next), "", time.Now()); !errors.Is(err, entities.ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() failed, expected the latest token to stop working too, got %v", err)
+	}
+}
+
+func TestSessionExpiryAndRevoke(t *testing.T) {
+	session, token, _ := entities.StartSession(vo.GenerateUserId(), "Firefox", "1.2.3.4", entities.AuthMethodPassword, false, time.Hour)
+
+	if _, err := session.Refresh(vo.NewTokenHash(token), "", time.Now().Add(2*time.Hour)); !errors.Is(err, entities.ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() failed, expected an expired session to be refused, got %v", err)
+	}
+
+	if err := session.Revoke(entities.SessionSignedOut); err != nil {
+		t.Fatalf("Revoke() failed, %v", err)
+	}
+	if err := session.Revoke(entities.SessionSignedOut); !errors.Is(err, entities.ErrConflict) {
+		t.Fatalf("Revoke() failed, expected ErrConflict, got %v", err)
+	}
+	if _, err := session.Refresh(vo.NewTokenHash(token), "", time.Now()); !errors.Is(err, entities.ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() failed, expected a revoked session to be refused, got %v", err)
+	}
+}
+
+func TestVerifyTokenSession(t *testing.T) {
+	keys := newTestKeyStore(t, "RS256")
+	verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+	claims := validTestClaims()
+	claims["sid"] = "session-1"
+
+	principal, err := verifier.Verify(signTestToken(t, keys, claims))
+	if err != nil {
+		t.Fatalf("Verify() failed, %v", err)
+	}
+	if principal.SessionId != "session-1" {
+		t.Fatalf("Verify() failed, expected the session id, got %q", principal.SessionId)
+	}
+}
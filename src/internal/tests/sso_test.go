@@ -0,0 +1,283 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"iyaem/internal/domain/entities"
+	"iyaem/internal/domain/events"
+	vo "iyaem/internal/domain/valueobjects"
+	"iyaem/internal/providers"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateSsoConnection(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+
+	connection, err := entities.CreateSsoConnection(orgId, "oidc", "https://idp.example.com", "client", "secret", []string{" Example.com ", "example.com", "example.org"})
+	if err != nil {
+		t.Fatalf("CreateSsoConnection() failed, %v", err)
+	}
+
+	if len(connection.Domains()) != 2 || connection.Domains()[0] != "example.com" {
+		t.Fatalf("CreateSsoConnection() failed, domains not normalized, got %v", connection.Domains())
+	}
+
+	created, ok := connection.Events()[0].(events.SsoConnectionCreated)
+	if !ok || created.OrganizationId != orgId.Value() || created.Domains != "example.com,example.org" {
+		t.Fatalf("CreateSsoConnection() failed, wrong event %v", connection.Events()[0])
+	}
+
+	invalid := []struct {
+		protocol, issuer, secret string
+		domains                  []string
+	}{
+		{"saml", "https://idp.example.com", "secret", []string{"example.com"}},
+		{"oidc", "http://idp.example.com", "secret", []string{"example.com"}},
+		{"oidc", "https://idp.example.com", "", []string{"example.com"}},
+		{"oidc", "https://idp.example.com", "secret", nil},
+		{"oidc", "https://idp.example.com", "secret", []string{"jane@example.com"}},
+	}
+	for _, c := range invalid {
+		if _, err := entities.CreateSsoConnection(orgId, c.protocol, c.issuer, "client", c.secret, c.domains); !errors.Is(err, entities.ErrInvalid) {
+			t.Fatalf("CreateSsoConnection(%v) failed, expected ErrInvalid, got %v", c, err)
+		}
+	}
+}
+
+func TestUpdateSsoConnection(t *testing.T) {
+	connection, _ := entities.CreateSsoConnection(vo.GenerateOrganizationId(), "oidc", "https://idp.example.com", "client", "secret", []string{"example.com"})
+
+	if err := connection.Update("https://login.example.com", "client2", "", []string{"example.org"}); err != nil {
+		t.Fatalf("Update() failed, %v", err)
+	}
+
+	// An empty secret keeps the current one.
+	if connection.ClientSecret() != "secret" || connection.ClientId() != "client2" {
+		t.Fatalf("Update() failed, got %v %v", connection.ClientId(), connection.ClientSecret())
+	}
+
+	updated, ok := connection.Events()[1].(events.SsoConnectionUpdated)
+	if !ok || updated.PreviousDomains != "example.com" || updated.Domains != "example.org" {
+		t.Fatalf("Update() failed, wrong event %v", connection.Events()[1])
+	}
+
+	connection.Delete()
+	if _, ok := connection.Events()[2].(events.SsoConnectionDeleted); !ok {
+		t.Fatalf("Delete() failed, wrong event %v", connection.Events()[2])
+	}
+}
+
+func TestProvisionSsoUser(t *testing.T) {
+	orgId := vo.GenerateOrganizationId()
+	connection, _ := entities.CreateSsoConnection(orgId, "oidc", "https://idp.example.com", "client", "secret", []string{"example.com"})
+	userId := vo.GenerateUserId()
+
+	if _, err := connection.Provision(userId, "jane@other.com"); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Provision() failed, expected ErrForbidden outside the domains, got %v", err)
+	}
+
+	if _, err := connection.Provision(userId, "jane@example.com"); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Provision() failed, expected ErrForbidden before the domain is verified, got %v", err)
+	}
+
+	verifyDomain(t, &connection, "example.com")
+
+	membership, err := connection.Provision(userId, "Jane@Example.com")
+	if err != nil {
+		t.Fatalf("Provision() failed, %v", err)
+	}
+
+	if membership.Level() != "member" || membership.OrganizationId() != orgId || membership.UserId() != userId {
+		t.Fatalf("Provision() failed, got %v", membership)
+	}
+
+	// Subjects of different connections never collide.
+	other, _ := entities.CreateSsoConnection(orgId, "oidc", "https://idp.example.com", "client", "secret", []string{"example.com"})
+	if connection.Subject("42") == other.Subject("42") {
+		t.Fatalf("Subject() failed, subjects collide")
+	}
+}
+
+func verifyDomain(t *testing.T, connection *entities.SsoConnection, name string) {
+	domain, err := connection.Domain(name)
+	if err != nil {
+		t.Fatalf("Domain() failed, %v", err)
+	}
+
+	if err := connection.VerifyDomain(name, []string{"v=spf1 -all", domain.TxtRecordValue()}); err != nil {
+		t.Fatalf("VerifyDomain() failed, %v", err)
+	}
+}
+
+func TestVerifySsoDomain(t *testing.T) {
+	connection, _ := entities.CreateSsoConnection(vo.GenerateOrganizationId(), "oidc", "https://idp.example.com", "client", "secret", []string{"example.com", "example.org"})
+
+	domain, _ := connection.Domain("example.com")
+	if domain.IsVerified() || domain.VerificationToken == "" || domain.TxtRecordName() != "_iyaem-verification.example.com" {
+		t.Fatalf("CreateSsoConnection() failed, expected an unverified domain with a token, got %v", domain)
+	}
+
+	if err := connection.VerifyDomain("example.com", []string{"iyaem-verification=guess"}); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("VerifyDomain() failed, expected ErrForbidden without the token, got %v", err)
+	}
+
+	if err := connection.VerifyDomain("example.net", nil); !errors.Is(err, entities.ErrNotFound) {
+		t.Fatalf("VerifyDomain() failed, expected ErrNotFound for another domain, got %v", err)
+	}
+
+	verifyDomain(t, &connection, "Example.com")
+
+	verified, ok := connection.Events()[1].(events.SsoDomainVerified)
+	if !ok || verified.Domain != "example.com" {
+		t.Fatalf("VerifyDomain() failed, wrong event %v", connection.Events()[1])
+	}
+
+	if got := connection.VerifiedDomains(); len(got) != 1 || got[0] != "example.com" {
+		t.Fatalf("VerifiedDomains() failed, got %v", got)
+	}
+
+	// Domains kept by an update stay verified, new ones start unverified.
+	if err := connection.Update("https://idp.example.com", "client", "", []string{"example.com", "example.net"}); err != nil {
+		t.Fatalf("Update() failed, %v", err)
+	}
+
+	if got := connection.VerifiedDomains(); len(got) != 1 || got[0] != "example.com" {
+		t.Fatalf("Update() failed, expected example.com to stay verified, got %v", got)
+	}
+}
+
+func TestConfirmIdentityLink(t *testing.T) {
+	userId := vo.GenerateUserId()
+	link, token, err := entities.RequestIdentityLink(userId, vo.GenerateOrganizationId(), "sso|connection|42")
+	if err != nil {
+		t.Fatalf("RequestIdentityLink() failed, %v", err)
+	}
+
+	if token == "" || link.TokenHash() != vo.NewTokenHash(token) {
+		t.Fatalf("RequestIdentityLink() failed, the token does not match its hash")
+	}
+
+	if _, err := link.Confirm(vo.GenerateUserId()); !errors.Is(err, entities.ErrForbidden) {
+		t.Fatalf("Confirm() failed, expected ErrForbidden for another account, got %v", err)
+	}
+
+	identity, err := link.Confirm(userId)
+	if err != nil {
+		t.Fatalf("Confirm() failed, %v", err)
+	}
+
+	if identity.IdpId() != "sso|connection|42" || identity.UserId() != userId {
+		t.Fatalf("Confirm() failed, got %v", identity)
+	}
+
+	if _, ok := link.Events()[1].(events.IdentityLinked); !ok {
+		t.Fatalf("Confirm() failed, wrong event %v", link.Events()[1])
+	}
+
+	expired := entities.NewIdentityLink(link.TokenHash(), userId, link.OrganizationId(), link.IdpId(), time.Now().Add(-time.Minute))
+	if _, err := expired.Confirm(userId); !errors.Is(err, entities.ErrInvalid) {
+		t.Fatalf("Confirm() failed, expected ErrInvalid once expired, got %v", err)
+	}
+}
+
+func TestSsoProvidersCache(t *testing.T) {
+	opened := 0
+	ssoProviders := providers.NewSsoProvidersWith("https://iam.example.com/callback", func(ctx context.Context, config providers.OIDCConfig) (providers.IdentityProvider, error) {
+		opened++
+		return providers.NewInMemoryIdentityProvider(), nil
+	})
+
+	ctx := context.Background()
+	ssoProviders.For(ctx, "connection", "https://idp.example.com", "client", "secret")
+	ssoProviders.For(ctx, "connection", "https://idp.example.com", "client", "secret")
+	if opened != 1 {
+		t.Fatalf("For() failed, expected the provider to be cached, opened %d", opened)
+	}
+
+	ssoProviders.For(ctx, "connection", "https://idp.example.com", "client", "rotated")
+	if opened != 2 {
+		t.Fatalf("For() failed, expected a new provider after a change, opened %d", opened)
+	}
+}
+
+func TestSsoLoginStateIsBoundToTheBrowser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	idp := providers.NewInMemoryIdentityProvider()
+	user, _ := idp.CreateUser(ctx, providers.IdentityProviderUser{Email: "jane@example.com"})
+	idp.SignInAs(user.Subject)
+
+	var signIn *url.URL
+	r := gin.New()
+	r.GET("/sso/login", func(c *gin.Context) {
+		challenge := providers.NewLoginChallenge()
+		providers.SetLoginState(c, "sso_login", c.Query("state"), challenge)
+		signIn, _ = url.Parse(idp.AuthCodeURL(c.Query("state"), "https://app.example.com/callback", challenge))
+	})
+	r.POST("/callback", func(c *gin.Context) {
+		challenge, ok := providers.TakeLoginState(c, "sso_login", c.Query("state"))
+		if !ok {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+
+		if _, err := idp.Exchange(c, c.Query("code"), "https://app.example.com/callback", challenge); err != nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	start := func(state string) []*http.Cookie {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sso/login?state="+state, nil))
+		return w.Result().Cookies()
+	}
+	callback := func(state string, code string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/callback?state="+url.QueryEscape(state)+"&code="+code, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	cookies := start("sso:1:browser")
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("SetLoginState() failed, expected an HttpOnly and Secure cookie, got %v", cookies)
+	}
+	code := signIn.Query().Get("code")
+
+	// The callback link of a login started elsewhere does not complete in
+	// this browser.
+	if w := callback("sso:1:browser", code, nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("TakeLoginState() failed, expected a callback without the cookie to be refused, got %d", w.Code)
+	}
+	if w := callback("sso:1:attacker", code, cookies); w.Code != http.StatusUnauthorized {
+		t.Fatalf("TakeLoginState() failed, expected another state to be refused, got %d", w.Code)
+	}
+
+	cookies = start("sso:1:browser")
+	w := callback("sso:1:browser", signIn.Query().Get("code"), cookies)
+	if w.Code != http.StatusOK {
+		t.Fatalf("TakeLoginState() failed, expected the login started in this browser to complete, got %d", w.Code)
+	}
+	if cleared := w.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Fatalf("TakeLoginState() failed, expected the state to be cleared, got %v", cleared)
+	}
+
+	// A code is redeemed with the challenge of its own login only.
+	start("sso:1:browser")
+	if _, err := idp.Exchange(ctx, signIn.Query().Get("code"), "https://app.example.com/callback", providers.NewLoginChallenge()); err == nil {
+		t.Fatalf("Exchange() failed, expected a code to be refused with another challenge")
+	}
+}
@@ -0,0 +1,189 @@
+package domain_test
+
+import (
+	"context"
+	"iyaem/internal/providers"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func newTestKeyStore(t *testing.T, algorithm string) *providers.KeyStore {
+	keys := providers.NewKeyStore(providers.NewMemoryKeyRepository(), algorithm)
+	if err := keys.Load(context.Background()); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	return keys
+}
+
+func signTestToken(t *testing.T, keys *providers.KeyStore, claims jwt.MapClaims) string {
+	token, err := keys.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	return token
+}
+
+func validTestClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   "iam.test",
+		"aud":   "iam.test",
+		"sub":   "user-1",
+		"email": "user@test.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyValidToken(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256"} {
+		keys := newTestKeyStore(t, algorithm)
+		verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+		principal, err := verifier.Verify(signTestToken(t, keys, validTestClaims()))
+		if err != nil {
+			t.Fatalf("Verify() failed for %s: %v", algorithm, err)
+		}
+
+		if principal.UserId != "user-1" || principal.Email != "user@test.com" {
+			t.Fatalf("Verify() failed, wrong principal %v", principal)
+		}
+	}
+}
+
+func TestVerifyRejectsForgedToken(t *testing.T) {
+	keys := newTestKeyStore(t, "RS256")
+	verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+	forged := newTestKeyStore(t, "RS256")
+	_, err := verifier.Verify(signTestToken(t, forged, validTestClaims()))
+	if err == nil {
+		t.Fatalf("Verify() failed, token from unknown key accepted")
+	}
+
+	current, _ := keys.SigningKey()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, validTestClaims())
+	token.Header["kid"] = current.Id
+	hmac, _ := token.SignedString([]byte("secret"))
+
+	_, err = verifier.Verify(hmac)
+	if err == nil {
+		t.Fatalf("Verify() failed, HS256 token accepted")
+	}
+}
+
+func TestVerifyRejectsInvalidClaims(t *testing.T) {
+	keys := newTestKeyStore(t, "ES256")
+	verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+	cases := map[string]func(jwt.MapClaims){
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"missing exp":    func(c jwt.MapClaims) { delete(c, "exp") },
+		"future iat":     func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "evil.test" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = []string{"other.test"} },
+	}
+
+	for name, mutate := range cases {
+		claims := validTestClaims()
+		mutate(claims)
+
+		_, err := verifier.Verify(signTestToken(t, keys, claims))
+		if err == nil {
+			t.Fatalf("Verify() failed, %s token accepted", name)
+		}
+	}
+}
+
+func TestKeyRotationAndRetirement(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeyStore(t, "RS256")
+	verifier := providers.NewTokenVerifier(keys, "iam.test", "iam.test")
+
+	old, _ := keys.SigningKey()
+	oldToken := signTestToken(t, keys, validTestClaims())
+
+	if _, err := keys.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate() failed: %v", err)
+	}
+
+	current, _ := keys.SigningKey()
+	if current.Id == old.Id {
+		t.Fatalf("Rotate() failed, signing key unchanged")
+	}
+
+	if _, err := verifier.Verify(oldToken); err != nil {
+		t.Fatalf("Verify() failed, token from rotated key rejected: %v", err)
+	}
+
+	if len(keys.JWKS().Keys) != 2 {
+		t.Fatalf("JWKS() failed, expected both keys to be published")
+	}
+
+	if err := keys.Retire(ctx, current.Id); err == nil {
+		t.Fatalf("Retire() failed, active key retired")
+	}
+
+	if err := keys.Retire(ctx, old.Id); err != nil {
+		t.Fatalf("Retire() failed: %v", err)
+	}
+
+	if _, err := verifier.Verify(oldToken); err == nil {
+		t.Fatalf("Verify() failed, token from retired key accepted")
+	}
+
+	if len(keys.JWKS().Keys) != 1 {
+		t.Fatalf("JWKS() failed, retired key still published")
+	}
+}
+
+type countingKeyRepository struct {
+	*providers.MemoryKeyRepository
+	loads int
+}
+
+func (r *countingKeyRepository) All(ctx context.Context) ([]providers.SigningKey, error) {
+	r.loads++
+	return r.MemoryKeyRepository.All(ctx)
+}
+
+func TestKeyStoresShareKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingKeyRepository{MemoryKeyRepository: providers.NewMemoryKeyRepository()}
+
+	first := providers.NewKeyStore(repo, "RS256")
+	second := providers.NewKeyStore(repo, "RS256")
+	for _, keys := range []*providers.KeyStore{first, second} {
+		if err := keys.Load(ctx); err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+	}
+
+	if len(second.Keys()) != 1 {
+		t.Fatalf("Load() failed, expected a single key for both stores, got %d", len(second.Keys()))
+	}
+
+	if _, err := first.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate() failed: %v", err)
+	}
+
+	verifier := providers.NewTokenVerifier(second, "iam.test", "iam.test")
+	if _, err := verifier.Verify(signTestToken(t, first, validTestClaims())); err != nil {
+		t.Fatalf("Verify() failed, token from a key rotated elsewhere rejected: %v", err)
+	}
+
+	loads := repo.loads
+	forged := newTestKeyStore(t, "RS256")
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(signTestToken(t, forged, validTestClaims())); err == nil {
+			t.Fatalf("Verify() failed, token from unknown key accepted")
+		}
+	}
+
+	if repo.loads != loads {
+		t.Fatalf("VerificationKey() failed, expected reloads to be rate limited, got %d", repo.loads-loads)
+	}
+}
@@ -3,11 +3,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
+	"iyaem/internal/infrastructure/database/postgresql"
+	"iyaem/internal/infrastructure/jobs"
+	"iyaem/internal/infrastructure/listeners"
+	"iyaem/internal/infrastructure/outbox"
 	"iyaem/internal/presentation/routes"
 	"iyaem/internal/providers"
 )
@@ -15,12 +25,10 @@ import (
 func main() {
 	godotenv.Load()
 
-	log.Printf("Starting the server...")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	auth, err := providers.NewAuthenticator()
-	if err != nil {
-		log.Fatalf("Failed to initialize the authenticator: %v", err)
-	}
+	log.Printf("Starting the server...")
 
 	dbConfig := providers.DatabaseConfig{
 		Host:     os.Getenv("DB_HOST"),
@@ -37,29 +45,100 @@ func main() {
 
 	defer db.Close()
 
-	router := routes.NewRouter(auth, db)
+	if handled, err := runClientCommand(db, os.Args[1:]); handled {
+		if err != nil {
+			log.Fatalf("Failed to run the client command: %v", err)
+		}
+		return
+	}
+
+	idp, err := providers.NewIdentityProvider(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to initialize the identity provider: %v", err)
+	}
+
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = "RS256"
+	}
+
+	secrets, err := providers.NewSecretBoxFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize the secret encryption: %v", err)
+	}
+
+	keys := providers.NewKeyStore(postgresql.NewSigningKeyRepository(db, secrets), algorithm)
+	if err := keys.Load(context.Background()); err != nil {
+		log.Fatalf("Failed to initialize the signing keys: %v", err)
+	}
+
+	go keys.RunRotation(ctx, 30*24*time.Hour, 7*24*time.Hour)
+
+	router := routes.NewRouter(idp, keys, secrets, db)
+
+	bus, err := providers.NewMessageBus(dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize the message bus: %v", err)
+	}
 
-	// pubSub, err := providers.NewPubSub(os.Getenv("GCP_PROJECT_ID"))
-	// if err != nil {
-	// 	log.Fatalf("Failed to initialize the pubsub client: %v", err)
-	// }
+	defer bus.Close()
 
-	// defer pubSub.CloseConnection()
+	// Events are only logged when the bus does not leave the process, as
+	// nothing in it subscribes to them.
+	var publisher outbox.Publisher = bus
+	if _, ok := bus.(*providers.MemoryBus); ok {
+		publisher = outbox.NewLogPublisher()
+	}
 
-	// orgRepo := postgresql.NewOrganizationRepository(db)
+	var workers sync.WaitGroup
+	work := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
 
-	// iamDomainRegisteredHandlers := listeners.NewIamDomainRegisteredHandlers(
-	// 	orgRepo,
-	// )
-	// tenantPersistedHandlers := listeners.NewTenantPersistedHandlers(
-	// 	commands.NewAddTenantCommand(orgRepo),
-	// )
+	relay := outbox.NewRelay(postgresql.NewOutboxRepository(db), publisher, outbox.DefaultRelayConfig())
+	work(relay.Run)
 
-	// go pubSub.Subscribe("iam_domain_registered", iamDomainRegisteredHandlers.GetHandlers())
-	// go pubSub.Subscribe("iam_tenant_persisted", tenantPersistedHandlers.GetHandlers())
+	runner := routes.NewJobRunner(idp, db)
+	work(runner.Run)
 
-	log.Print("Server listening on http://localhost:8080/")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("There was an error with the http server: %v", err)
+	queue := jobs.NewQueue(postgresql.NewJobRepository(db))
+	inbox := postgresql.NewInboxRepository(db)
+	subscriptions := map[string]providers.Handler{
+		"iam_domain_registered": listeners.NewIamDomainRegisteredHandlers(queue, inbox).Handler(),
+		"iam_tenant_persisted":  listeners.NewTenantPersistedHandlers(queue, inbox).Handler(),
 	}
+
+	for subscription, handler := range subscriptions {
+		subscription, handler := subscription, handler
+		work(func(ctx context.Context) {
+			if err := bus.Subscribe(ctx, subscription, handler); err != nil {
+				log.Printf("Error: subscription %s stopped: %v", subscription, err)
+			}
+		})
+	}
+
+	server := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		log.Print("Server listening on http://localhost:8080/")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("There was an error with the http server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Print("Shutting down the server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error: shutting down the http server: %v", err)
+	}
+
+	// Waits for the messages and jobs being handled.
+	workers.Wait()
 }
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbox (
+	id uuid PRIMARY KEY,
+	aggregate_type text NOT NULL,
+	aggregate_id text NOT NULL,
+	event_type text NOT NULL,
+	payload jsonb NOT NULL,
+	status text NOT NULL DEFAULT 'pending',
+	attempts integer NOT NULL DEFAULT 0,
+	last_error text,
+	next_attempt_at timestamptz NOT NULL DEFAULT now(),
+	locked_until timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	sent_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, created_at) WHERE status = 'pending';
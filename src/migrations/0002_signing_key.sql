@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS signing_key (
+	id uuid PRIMARY KEY,
+	algorithm text NOT NULL,
+	private_key text NOT NULL,
+	status text NOT NULL DEFAULT 'active',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	rotated_at timestamptz
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS api_client (
+	id uuid PRIMARY KEY,
+	name text NOT NULL,
+	secret_hash text NOT NULL,
+	scopes text[] NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	revoked_at timestamptz
+);
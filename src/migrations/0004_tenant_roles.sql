@@ -0,0 +1,5 @@
+ALTER TABLE "role" ADD COLUMN IF NOT EXISTS tenant_id uuid REFERENCES tenant (id) ON DELETE CASCADE;
+ALTER TABLE "group" ADD COLUMN IF NOT EXISTS tenant_id uuid REFERENCES tenant (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS role_tenant_id_idx ON "role" (tenant_id) WHERE tenant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS group_tenant_id_idx ON "group" (tenant_id) WHERE tenant_id IS NOT NULL;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+	id uuid PRIMARY KEY,
+	organization_id uuid,
+	tenant_id uuid,
+	actor_id text NOT NULL DEFAULT '',
+	actor_email text NOT NULL DEFAULT '',
+	actor_client_id text NOT NULL DEFAULT '',
+	action text NOT NULL,
+	target_type text NOT NULL,
+	target_id text NOT NULL,
+	before jsonb,
+	after jsonb,
+	ip_address text NOT NULL DEFAULT '',
+	user_agent text NOT NULL DEFAULT '',
+	request_id text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_organization_idx ON audit_log (organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_id);
+
+-- The audit log is append-only.
+CREATE OR REPLACE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
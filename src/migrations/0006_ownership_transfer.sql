@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS ownership_transfer (
+	id uuid PRIMARY KEY,
+	organization_id uuid NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+	from_membership_id uuid NOT NULL REFERENCES user_organization (id) ON DELETE CASCADE,
+	to_membership_id uuid NOT NULL REFERENCES user_organization (id) ON DELETE CASCADE,
+	expires_at timestamptz NOT NULL,
+	confirmed_at timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ownership_transfer_organization_idx ON ownership_transfer (organization_id);
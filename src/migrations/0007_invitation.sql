@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS invitation (
+	id uuid PRIMARY KEY,
+	organization_id uuid NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+	email text NOT NULL,
+	level text NOT NULL,
+	token_hash text NOT NULL UNIQUE,
+	status text NOT NULL DEFAULT 'pending',
+	invited_by text NOT NULL DEFAULT '',
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	responded_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS invitation_organization_idx ON invitation (organization_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS invitation_email_idx ON invitation (email) WHERE status = 'pending';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS sso_connection (
+	id uuid PRIMARY KEY,
+	organization_id uuid NOT NULL UNIQUE REFERENCES organization (id) ON DELETE CASCADE,
+	protocol text NOT NULL,
+	issuer_url text NOT NULL,
+	client_id text NOT NULL,
+	client_secret text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- A domain routes logins to a single connection.
+CREATE TABLE IF NOT EXISTS sso_connection_domain (
+	domain text PRIMARY KEY,
+	connection_id uuid NOT NULL REFERENCES sso_connection (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sso_connection_domain_connection_idx ON sso_connection_domain (connection_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS scim_token (
+	id uuid PRIMARY KEY,
+	organization_id uuid NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+	tenant_id uuid REFERENCES tenant (id) ON DELETE CASCADE,
+	description text NOT NULL,
+	token_hash text NOT NULL UNIQUE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	last_used_at timestamptz,
+	revoked_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS scim_token_organization_idx ON scim_token (organization_id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS credential (
+	id uuid PRIMARY KEY,
+	user_id uuid NOT NULL UNIQUE REFERENCES public.user (id) ON DELETE CASCADE,
+	email text NOT NULL UNIQUE,
+	password_hash text NOT NULL,
+	failed_attempts integer NOT NULL DEFAULT 0,
+	locked_until timestamptz,
+	password_changed_at timestamptz NOT NULL DEFAULT now(),
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS password_reset (
+	id uuid PRIMARY KEY,
+	credential_id uuid NOT NULL REFERENCES credential (id) ON DELETE CASCADE,
+	token_hash text NOT NULL UNIQUE,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_credential_idx ON password_reset (credential_id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS session (
+	id uuid PRIMARY KEY,
+	user_id uuid NOT NULL REFERENCES public.user (id) ON DELETE CASCADE,
+	user_agent text NOT NULL DEFAULT '',
+	ip_address text NOT NULL DEFAULT '',
+	refresh_token_hash text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	last_used_at timestamptz NOT NULL DEFAULT now(),
+	expires_at timestamptz NOT NULL,
+	revoked_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS session_user_idx ON session (user_id);
+
+-- Every refresh token issued for a session, so that reusing one that was
+-- already exchanged is recognized.
+CREATE TABLE IF NOT EXISTS session_refresh_token (
+	token_hash text PRIMARY KEY,
+	session_id uuid NOT NULL REFERENCES session (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS mfa_factor (
+	id uuid PRIMARY KEY,
+	user_id uuid NOT NULL UNIQUE REFERENCES public.user (id) ON DELETE CASCADE,
+	secret text NOT NULL,
+	-- The time step of the last accepted password, which cannot be
+	-- replayed.
+	last_counter bigint NOT NULL DEFAULT 0,
+	confirmed_at timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_code (
+	factor_id uuid NOT NULL REFERENCES mfa_factor (id) ON DELETE CASCADE,
+	code_hash text NOT NULL,
+	used_at timestamptz,
+	PRIMARY KEY (factor_id, code_hash)
+);
+
+ALTER TABLE session ADD COLUMN IF NOT EXISTS auth_method text NOT NULL DEFAULT 'ext';
+ALTER TABLE session ADD COLUMN IF NOT EXISTS mfa_required boolean NOT NULL DEFAULT false;
+ALTER TABLE session ADD COLUMN IF NOT EXISTS mfa_verified_at timestamptz;
+
+ALTER TABLE organization ADD COLUMN IF NOT EXISTS require_mfa boolean NOT NULL DEFAULT false;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS personal_access_token (
+	id uuid PRIMARY KEY,
+	user_id uuid NOT NULL REFERENCES public.user (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	token_hash text NOT NULL UNIQUE,
+	scopes text[] NOT NULL DEFAULT '{}',
+	organization_ids uuid[] NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	expires_at timestamptz NOT NULL,
+	last_used_at timestamptz,
+	last_used_ip text NOT NULL DEFAULT '',
+	revoked_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_token_user_idx ON personal_access_token (user_id);
@@ -0,0 +1,6 @@
+-- Lists of members are paged through by keyset, on the sort column and
+-- then the id.
+CREATE INDEX IF NOT EXISTS user_organization_joined_idx ON user_organization (organization_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS user_role_role_idx ON user_role (role_id, tenant_id);
+CREATE INDEX IF NOT EXISTS user_group_group_idx ON user_group (group_id, tenant_id);
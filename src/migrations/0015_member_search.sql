@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Member search matches prefixes and resemblance of lowercased names and
+-- emails.
+CREATE INDEX IF NOT EXISTS user_name_trgm_idx ON public.user USING gin (lower(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_email_trgm_idx ON public.user USING gin (lower(email) gin_trgm_ops);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS member_import (
+	id uuid PRIMARY KEY,
+	organization_id uuid NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+	requested_by uuid NOT NULL,
+	dry_run boolean NOT NULL DEFAULT false,
+	rows jsonb NOT NULL,
+	status text NOT NULL,
+	results jsonb NOT NULL DEFAULT '[]',
+	failure text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	finished_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS member_import_organization_idx ON member_import (organization_id, created_at DESC);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS job (
+	id uuid PRIMARY KEY,
+	type text NOT NULL,
+	payload jsonb NOT NULL,
+	idempotency_key text UNIQUE,
+	organization_id uuid,
+	actor jsonb NOT NULL DEFAULT '{}',
+	status text NOT NULL DEFAULT 'pending',
+	attempts integer NOT NULL DEFAULT 0,
+	max_attempts integer NOT NULL,
+	progress_done integer NOT NULL DEFAULT 0,
+	progress_total integer NOT NULL DEFAULT 0,
+	last_error text NOT NULL DEFAULT '',
+	run_at timestamptz NOT NULL DEFAULT now(),
+	locked_until timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	started_at timestamptz,
+	finished_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS job_due_idx ON job (run_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS inbox (
+	consumer text NOT NULL,
+	message_id text NOT NULL,
+	processed_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (consumer, message_id)
+);
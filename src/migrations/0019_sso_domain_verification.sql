@@ -0,0 +1,20 @@
+-- Domains are claimed by any number of connections, but only route logins
+-- once verified, and only one connection can verify a domain.
+ALTER TABLE sso_connection_domain ADD COLUMN IF NOT EXISTS verification_token text NOT NULL DEFAULT '';
+ALTER TABLE sso_connection_domain ADD COLUMN IF NOT EXISTS verified_at timestamptz;
+
+UPDATE sso_connection_domain SET verification_token = md5(random()::text || domain) WHERE verification_token = '';
+
+ALTER TABLE sso_connection_domain DROP CONSTRAINT IF EXISTS sso_connection_domain_pkey;
+ALTER TABLE sso_connection_domain ADD PRIMARY KEY (connection_id, domain);
+
+CREATE UNIQUE INDEX IF NOT EXISTS sso_connection_domain_verified_idx ON sso_connection_domain (domain) WHERE verified_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS identity_link (
+	token_hash text PRIMARY KEY,
+	user_id uuid NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+	idp_id text NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+-- SCIM looks up single members by user id and by email.
+CREATE INDEX IF NOT EXISTS user_organization_user_idx ON user_organization (organization_id, user_id);
+CREATE INDEX IF NOT EXISTS user_email_lower_idx ON public.user (lower(email));